@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
@@ -22,3 +23,39 @@ type BatchStore interface {
 type CloseStore interface {
 	Close() error
 }
+
+// TaggedStore is optionally implemented by stores that can invalidate groups
+// of keys. Tags are additive labels attached at write time; a key may carry
+// several. Implementations may over-invalidate: a key re-written without a
+// tag can still be removed by InvalidateTag for that tag, so callers must
+// treat bulk invalidation as "at least these keys".
+type TaggedStore interface {
+	SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error
+	InvalidateTag(ctx context.Context, tag string) error
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// StatsStore is optionally implemented by stores that track occupancy and
+// eviction counters.
+type StatsStore interface {
+	Stats() Stats
+}
+
+// Stats is a point-in-time snapshot of a store's occupancy and lifetime counters.
+type Stats struct {
+	Entries     int    `json:"entries"`
+	Bytes       int64  `json:"bytes"`
+	Hits        uint64 `json:"hits"`
+	Misses      uint64 `json:"misses"`
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+	// Rejections counts values the store declined to keep, either because they
+	// exceed the byte budget or because admission refused them.
+	Rejections uint64 `json:"rejections"`
+}
+
+// String renders the snapshot as space-separated key=value pairs.
+func (s Stats) String() string {
+	return fmt.Sprintf("entries=%d bytes=%d hits=%d misses=%d evictions=%d expirations=%d rejections=%d",
+		s.Entries, s.Bytes, s.Hits, s.Misses, s.Evictions, s.Expirations, s.Rejections)
+}
@@ -68,11 +68,25 @@ func (c *Component) Health(ctx context.Context) component.Health {
 	if _, err := c.store.Exists(ctx, ".health"); err != nil {
 		return component.Health{Name: c.Name(), Status: component.StatusUnhealthy, Message: fmt.Sprintf("health probe failed: %v", err)}
 	}
+	if stats, ok := c.store.(StatsStore); ok {
+		return component.Health{Name: c.Name(), Status: component.StatusHealthy, Message: stats.Stats().String()}
+	}
 	return component.Health{Name: c.Name(), Status: component.StatusHealthy}
 }
 
 func (c *Component) Describe() component.Description {
-	return component.Description{Name: "Cache", Type: "cache", Details: fmt.Sprintf("provider=%s", c.cfg.Provider)}
+	details := fmt.Sprintf("provider=%s", c.cfg.Provider)
+	if memCfg, ok := c.providerCfg.(*MemoryConfig); ok && memCfg.Bounded() {
+		eviction := memCfg.Eviction
+		if eviction == "" {
+			eviction = DefaultEviction
+		}
+		details += fmt.Sprintf(" max_entries=%d max_bytes=%d eviction=%s", memCfg.MaxEntries, memCfg.MaxBytes, eviction)
+	}
+	if stats, ok := c.store.(StatsStore); ok {
+		details += " " + stats.Stats().String()
+	}
+	return component.Description{Name: "Cache", Type: "cache", Details: details}
 }
 
 var _ component.Component = (*Component)(nil)
@@ -3,6 +3,7 @@
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -55,6 +56,35 @@ func TestComponentLifecycle(t *testing.T) {
 	}
 }
 
+func TestComponentReportsMemoryStats(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	reg := NewFactoryRegistry()
+	if err := RegisterMemory(reg); err != nil {
+		t.Fatalf("RegisterMemory: %v", err)
+	}
+	memCfg := &MemoryConfig{MaxEntries: 1}
+	cmp := NewComponent(reg, Config{Provider: ProviderMemory, Enabled: true}, memCfg, logging.NewDefault("test"))
+	if err := cmp.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	for _, key := range []string{"a", "b"} {
+		if err := cmp.Store().Set(ctx, key, []byte("v"), 0); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+
+	health := cmp.Health(ctx)
+	if health.Status != component.StatusHealthy || !strings.Contains(health.Message, "evictions=1") {
+		t.Fatalf("Health = %+v", health)
+	}
+	desc := cmp.Describe()
+	if !strings.Contains(desc.Details, "max_entries=1 max_bytes=0 eviction=lru") || !strings.Contains(desc.Details, "entries=1 ") {
+		t.Fatalf("Describe = %+v", desc)
+	}
+}
+
 func TestComponentDisabled(t *testing.T) {
 	t.Parallel()
 
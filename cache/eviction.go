@@ -0,0 +1,281 @@
+package cache
+
+import (
+	"container/list"
+	"encoding"
+	"fmt"
+	"hash/maphash"
+)
+
+var (
+	_ encoding.TextMarshaler   = EvictionPolicy("")
+	_ encoding.TextUnmarshaler = (*EvictionPolicy)(nil)
+)
+
+// EvictionPolicy selects which entry MemoryStore drops when a size budget is exceeded.
+type EvictionPolicy string
+
+const (
+	// EvictionLRU evicts the least recently used entry.
+	EvictionLRU EvictionPolicy = "lru"
+	// EvictionLFU evicts the least frequently used entry, breaking ties by recency.
+	EvictionLFU EvictionPolicy = "lfu"
+	// EvictionTinyLFU evicts in LRU order but only admits a new key when its
+	// estimated access frequency beats the entry it would displace.
+	EvictionTinyLFU EvictionPolicy = "tinylfu"
+)
+
+// DefaultEviction is used when a budget is configured without a policy.
+const DefaultEviction = EvictionLRU
+
+// MarshalText serializes an eviction policy for config encoders.
+func (p EvictionPolicy) MarshalText() ([]byte, error) {
+	policy := p
+	if policy == "" {
+		policy = DefaultEviction
+	}
+	if !policy.valid() {
+		return nil, invalidEvictionPolicy(policy)
+	}
+	return []byte(policy), nil
+}
+
+// UnmarshalText parses an eviction policy from config text.
+func (p *EvictionPolicy) UnmarshalText(text []byte) error {
+	policy := EvictionPolicy(text)
+	if policy == "" {
+		*p = DefaultEviction
+		return nil
+	}
+	if !policy.valid() {
+		return invalidEvictionPolicy(policy)
+	}
+	*p = policy
+	return nil
+}
+
+func (p EvictionPolicy) valid() bool {
+	switch p {
+	case EvictionLRU, EvictionLFU, EvictionTinyLFU:
+		return true
+	default:
+		return false
+	}
+}
+
+func invalidEvictionPolicy(policy EvictionPolicy) error {
+	return fmt.Errorf("cache: eviction must be one of lru, lfu, tinylfu, got %q", string(policy))
+}
+
+// evictor orders resident entries for eviction. Implementations are not
+// goroutine-safe; MemoryStore serializes access under its write lock.
+type evictor interface {
+	// added registers a newly stored entry.
+	added(e *memoryEntry)
+	// accessed records a read or overwrite of a resident entry.
+	accessed(e *memoryEntry)
+	// removed unregisters an entry that was deleted, expired, or evicted.
+	removed(e *memoryEntry)
+	// victim returns the next entry to evict, skipping exclude, or nil.
+	victim(exclude *memoryEntry) *memoryEntry
+	// admit is consulted for every new key. victim is the entry the key would
+	// displace, or nil when the budget has room.
+	admit(key string, victim *memoryEntry) bool
+}
+
+func newEvictor(policy EvictionPolicy, maxEntries int) evictor {
+	switch policy {
+	case EvictionLFU:
+		return newLFUEvictor()
+	case EvictionTinyLFU:
+		return newTinyLFUEvictor(maxEntries)
+	default:
+		return newLRUEvictor()
+	}
+}
+
+// lruEvictor keeps entries in a recency list, most recent at the front.
+type lruEvictor struct {
+	order *list.List
+}
+
+func newLRUEvictor() *lruEvictor {
+	return &lruEvictor{order: list.New()}
+}
+
+func (l *lruEvictor) added(e *memoryEntry) {
+	e.elem = l.order.PushFront(e)
+}
+
+func (l *lruEvictor) accessed(e *memoryEntry) {
+	l.order.MoveToFront(e.elem)
+}
+
+func (l *lruEvictor) removed(e *memoryEntry) {
+	l.order.Remove(e.elem)
+	e.elem = nil
+}
+
+func (l *lruEvictor) victim(exclude *memoryEntry) *memoryEntry {
+	for el := l.order.Back(); el != nil; el = el.Prev() {
+		if e := el.Value.(*memoryEntry); e != exclude {
+			return e
+		}
+	}
+	return nil
+}
+
+func (l *lruEvictor) admit(string, *memoryEntry) bool { return true }
+
+// lfuEvictor is the constant-time LFU scheme: an ascending list of frequency
+// buckets, each holding its entries in recency order.
+type lfuEvictor struct {
+	buckets *list.List
+}
+
+type lfuBucket struct {
+	freq    uint64
+	entries *list.List
+}
+
+func newLFUEvictor() *lfuEvictor {
+	return &lfuEvictor{buckets: list.New()}
+}
+
+func (l *lfuEvictor) added(e *memoryEntry) {
+	front := l.buckets.Front()
+	if front == nil || front.Value.(*lfuBucket).freq != 1 {
+		front = l.buckets.PushFront(&lfuBucket{freq: 1, entries: list.New()})
+	}
+	e.bucket = front
+	e.elem = front.Value.(*lfuBucket).entries.PushFront(e)
+}
+
+func (l *lfuEvictor) accessed(e *memoryEntry) {
+	current := e.bucket
+	bucket := current.Value.(*lfuBucket)
+	next := current.Next()
+	if next == nil || next.Value.(*lfuBucket).freq != bucket.freq+1 {
+		next = l.buckets.InsertAfter(&lfuBucket{freq: bucket.freq + 1, entries: list.New()}, current)
+	}
+	bucket.entries.Remove(e.elem)
+	if bucket.entries.Len() == 0 {
+		l.buckets.Remove(current)
+	}
+	e.bucket = next
+	e.elem = next.Value.(*lfuBucket).entries.PushFront(e)
+}
+
+func (l *lfuEvictor) removed(e *memoryEntry) {
+	bucket := e.bucket.Value.(*lfuBucket)
+	bucket.entries.Remove(e.elem)
+	if bucket.entries.Len() == 0 {
+		l.buckets.Remove(e.bucket)
+	}
+	e.bucket, e.elem = nil, nil
+}
+
+func (l *lfuEvictor) victim(exclude *memoryEntry) *memoryEntry {
+	for b := l.buckets.Front(); b != nil; b = b.Next() {
+		for el := b.Value.(*lfuBucket).entries.Back(); el != nil; el = el.Prev() {
+			if e := el.Value.(*memoryEntry); e != exclude {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+func (l *lfuEvictor) admit(string, *memoryEntry) bool { return true }
+
+// tinyLFUEvictor evicts in LRU order and gates admission with a count-min
+// sketch of recent key frequencies, so one-hit wonders cannot flush hot keys.
+type tinyLFUEvictor struct {
+	*lruEvictor
+	sketch *frequencySketch
+}
+
+func newTinyLFUEvictor(maxEntries int) *tinyLFUEvictor {
+	return &tinyLFUEvictor{lruEvictor: newLRUEvictor(), sketch: newFrequencySketch(maxEntries)}
+}
+
+func (t *tinyLFUEvictor) accessed(e *memoryEntry) {
+	t.lruEvictor.accessed(e)
+	t.sketch.increment(e.key)
+}
+
+func (t *tinyLFUEvictor) admit(key string, victim *memoryEntry) bool {
+	t.sketch.increment(key)
+	return victim == nil || t.sketch.estimate(key) > t.sketch.estimate(victim.key)
+}
+
+const (
+	sketchDepth    = 4
+	sketchMinWidth = 1024
+	sketchMaxCount = 15
+)
+
+// frequencySketch is a 4-bit-saturating count-min sketch that halves every
+// counter after a sample window so stale popularity decays.
+type frequencySketch struct {
+	seed      maphash.Seed
+	rows      [sketchDepth][]uint8
+	mask      uint64
+	additions int
+	window    int
+}
+
+func newFrequencySketch(maxEntries int) *frequencySketch {
+	width := sketchMinWidth
+	for width < maxEntries {
+		width <<= 1
+	}
+	s := &frequencySketch{
+		seed:   maphash.MakeSeed(),
+		mask:   uint64(width - 1),
+		window: width * 10,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+func (s *frequencySketch) indexes(key string) [sketchDepth]uint64 {
+	h := maphash.String(s.seed, key)
+	lo, hi := h, h>>32|h<<32
+	var idx [sketchDepth]uint64
+	for i := range idx {
+		idx[i] = (lo + uint64(i)*hi) & s.mask
+	}
+	return idx
+}
+
+func (s *frequencySketch) increment(key string) {
+	for i, idx := range s.indexes(key) {
+		if s.rows[i][idx] < sketchMaxCount {
+			s.rows[i][idx]++
+		}
+	}
+	s.additions++
+	if s.additions >= s.window {
+		s.reset()
+	}
+}
+
+func (s *frequencySketch) estimate(key string) uint8 {
+	minCount := uint8(sketchMaxCount)
+	for i, idx := range s.indexes(key) {
+		minCount = min(minCount, s.rows[i][idx])
+	}
+	return minCount
+}
+
+func (s *frequencySketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
@@ -0,0 +1,228 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/logging"
+)
+
+func TestMemoryStoreLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore(MemoryConfig{MaxEntries: 2, Eviction: EvictionLRU})
+	ctx := context.Background()
+	mustSet(t, store, "a", "1")
+	mustSet(t, store, "b", "2")
+	if _, ok, _ := store.Get(ctx, "a"); !ok {
+		t.Fatal("a missing before eviction")
+	}
+	mustSet(t, store, "c", "3")
+
+	assertPresent(t, store, "a", true)
+	assertPresent(t, store, "b", false)
+	assertPresent(t, store, "c", true)
+	if stats := store.Stats(); stats.Entries != 2 || stats.Evictions != 1 {
+		t.Fatalf("Stats = %+v", stats)
+	}
+}
+
+func TestMemoryStoreLFUEvictsLeastFrequentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore(MemoryConfig{MaxEntries: 2, Eviction: EvictionLFU})
+	ctx := context.Background()
+	mustSet(t, store, "hot", "1")
+	mustSet(t, store, "cold", "2")
+	for range 3 {
+		if _, ok, _ := store.Get(ctx, "hot"); !ok {
+			t.Fatal("hot missing")
+		}
+	}
+	// cold was touched most recently, but hot has the higher frequency.
+	if _, ok, _ := store.Get(ctx, "cold"); !ok {
+		t.Fatal("cold missing")
+	}
+	mustSet(t, store, "new", "3")
+
+	assertPresent(t, store, "hot", true)
+	assertPresent(t, store, "cold", false)
+	assertPresent(t, store, "new", true)
+}
+
+func TestMemoryStoreLFUTieBreaksByRecency(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore(MemoryConfig{MaxEntries: 3, Eviction: EvictionLFU})
+	mustSet(t, store, "a", "1")
+	mustSet(t, store, "b", "2")
+	mustSet(t, store, "c", "3")
+	mustSet(t, store, "d", "4")
+
+	assertPresent(t, store, "a", false)
+	assertPresent(t, store, "b", true)
+}
+
+func TestMemoryStoreTinyLFURejectsColdCandidates(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore(MemoryConfig{MaxEntries: 2, Eviction: EvictionTinyLFU})
+	ctx := context.Background()
+	mustSet(t, store, "a", "1")
+	mustSet(t, store, "b", "2")
+	for range 4 {
+		_, _, _ = store.Get(ctx, "a")
+		_, _, _ = store.Get(ctx, "b")
+	}
+
+	mustSet(t, store, "scan", "x")
+	assertPresent(t, store, "scan", false)
+	assertPresent(t, store, "a", true)
+	assertPresent(t, store, "b", true)
+	if stats := store.Stats(); stats.Rejections != 1 || stats.Evictions != 0 {
+		t.Fatalf("Stats after rejected admission = %+v", stats)
+	}
+
+	// Repeated attempts build frequency until the candidate beats the LRU victim.
+	for range 8 {
+		mustSet(t, store, "scan", "x")
+	}
+	assertPresent(t, store, "scan", true)
+}
+
+func TestMemoryStoreMaxBytes(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore(MemoryConfig{MaxBytes: 10})
+	ctx := context.Background()
+	mustSet(t, store, "a", "1234") // 5 bytes
+	mustSet(t, store, "b", "1234") // 10 bytes
+	mustSet(t, store, "c", "12")   // 13 bytes -> evict a
+
+	assertPresent(t, store, "a", false)
+	if stats := store.Stats(); stats.Bytes != 8 || stats.Entries != 2 {
+		t.Fatalf("Stats = %+v", stats)
+	}
+
+	// Growing an existing key evicts others but never the key itself.
+	mustSet(t, store, "c", "123456789")
+	assertPresent(t, store, "b", false)
+	assertPresent(t, store, "c", true)
+
+	// A value that can never fit is declined and drops the stale copy.
+	if err := store.Set(ctx, "c", make([]byte, 11), 0); err != nil {
+		t.Fatalf("Set oversized: %v", err)
+	}
+	assertPresent(t, store, "c", false)
+	if stats := store.Stats(); stats.Bytes != 0 || stats.Rejections != 1 {
+		t.Fatalf("Stats after oversized = %+v", stats)
+	}
+}
+
+func TestMemoryStoreEvictsExpiredAsExpirations(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(100, 0)
+	store := newMemoryStore(MemoryConfig{MaxEntries: 1}, func() time.Time { return now })
+	ctx := context.Background()
+	if err := store.Set(ctx, "old", []byte("v"), time.Second); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	now = now.Add(time.Second)
+	mustSet(t, store, "new", "v")
+
+	if stats := store.Stats(); stats.Expirations != 1 || stats.Evictions != 0 {
+		t.Fatalf("Stats = %+v", stats)
+	}
+}
+
+func TestMemoryStoreBoundedDeleteAndOverwrite(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range []EvictionPolicy{EvictionLRU, EvictionLFU, EvictionTinyLFU} {
+		t.Run(string(policy), func(t *testing.T) {
+			t.Parallel()
+
+			store := NewMemoryStore(MemoryConfig{MaxEntries: 4, Eviction: policy})
+			ctx := context.Background()
+			for i := range 4 {
+				mustSet(t, store, fmt.Sprintf("k%d", i), "v")
+			}
+			mustSet(t, store, "k1", "updated")
+			if err := store.Delete(ctx, "k2"); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if stats := store.Stats(); stats.Entries != 3 || stats.Bytes != 2+1+2+7+2+1 {
+				t.Fatalf("Stats = %+v", stats)
+			}
+			got, ok, err := store.Get(ctx, "k1")
+			if err != nil || !ok || string(got) != "updated" {
+				t.Fatalf("Get k1 = %q, %v, %v", got, ok, err)
+			}
+		})
+	}
+}
+
+func TestMemoryConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := MemoryConfig{MaxEntries: 10, MaxBytes: 1 << 20, Eviction: EvictionTinyLFU}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate valid config: %v", err)
+	}
+	for name, cfg := range map[string]MemoryConfig{
+		"negative entries": {MaxEntries: -1},
+		"negative bytes":   {MaxBytes: -1},
+		"negative ttl":     {DefaultTTL: -1},
+		"unknown policy":   {MaxEntries: 1, Eviction: "fifo"},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("%s: Validate accepted %+v", name, cfg)
+		}
+	}
+
+	reg := NewFactoryRegistry()
+	if err := RegisterMemory(reg); err != nil {
+		t.Fatalf("RegisterMemory: %v", err)
+	}
+	if _, err := New(reg, Config{Provider: ProviderMemory}, &MemoryConfig{Eviction: "fifo"}, logging.NewDefault("test")); err == nil {
+		t.Fatal("New accepted unknown eviction policy")
+	}
+}
+
+func TestEvictionPolicyText(t *testing.T) {
+	t.Parallel()
+
+	var policy EvictionPolicy
+	if err := policy.UnmarshalText(nil); err != nil || policy != EvictionLRU {
+		t.Fatalf("UnmarshalText empty = %q, %v", policy, err)
+	}
+	if err := policy.UnmarshalText([]byte("lfu")); err != nil || policy != EvictionLFU {
+		t.Fatalf("UnmarshalText lfu = %q, %v", policy, err)
+	}
+	if err := policy.UnmarshalText([]byte("random")); err == nil {
+		t.Fatal("UnmarshalText accepted unknown policy")
+	}
+	if text, err := EvictionPolicy("").MarshalText(); err != nil || string(text) != "lru" {
+		t.Fatalf("MarshalText empty = %q, %v", text, err)
+	}
+}
+
+func mustSet(t *testing.T, store *MemoryStore, key, value string) {
+	t.Helper()
+	if err := store.Set(context.Background(), key, []byte(value), 0); err != nil {
+		t.Fatalf("Set %s: %v", key, err)
+	}
+}
+
+func assertPresent(t *testing.T, store *MemoryStore, key string, want bool) {
+	t.Helper()
+	store.mu.RLock()
+	_, ok := store.items[key]
+	store.mu.RUnlock()
+	if ok != want {
+		t.Fatalf("%s present = %v, want %v", key, ok, want)
+	}
+}
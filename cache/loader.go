@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCachedLoadFailure wraps a loader error that GetOrLoad served from the
+// negative cache instead of calling the loader again.
+var ErrCachedLoadFailure = errors.New("cache: cached loader failure")
+
+// Loader computes the value for key on a cache miss. Returning (nil, nil)
+// reports "no value": nothing is cached and GetOrLoad returns (nil, nil).
+type Loader[C any] func(ctx context.Context, key string) (*C, error)
+
+// LoadOption configures a GetOrLoad call.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	softTTL        time.Duration
+	negativeTTL    time.Duration
+	refreshTimeout time.Duration
+	tags           []string
+}
+
+// WithSoftTTL marks loaded values stale after d while keeping them stored for
+// the full ttl. A stale hit is returned immediately and triggers one
+// background reload (stale-while-revalidate). d must be shorter than ttl to
+// have any effect when ttl is non-zero.
+func WithSoftTTL(d time.Duration) LoadOption {
+	return func(o *loadOptions) { o.softTTL = d }
+}
+
+// WithNegativeTTL caches loader errors for d, so callers within that window
+// get ErrCachedLoadFailure without invoking the loader. Loads that end because
+// the caller's context was canceled or timed out are not cached.
+func WithNegativeTTL(d time.Duration) LoadOption {
+	return func(o *loadOptions) { o.negativeTTL = d }
+}
+
+// WithRefreshTimeout bounds background refreshes started by a stale hit.
+// Refreshes are detached from the caller's cancellation; without a timeout
+// they run until the loader returns.
+func WithRefreshTimeout(d time.Duration) LoadOption {
+	return func(o *loadOptions) { o.refreshTimeout = d }
+}
+
+// WithTags attaches tags to loaded values; see TypedStore.SaveWithTags.
+func WithTags(tags ...string) LoadOption {
+	return func(o *loadOptions) { o.tags = tags }
+}
+
+// envelopePrefix marks values written with freshness metadata. A leading NUL
+// byte can never start a JSON document, so plain Save payloads are never
+// mistaken for envelopes.
+var envelopePrefix = []byte("\x00gkc1")
+
+// loadEnvelope carries soft-TTL and negative-cache metadata around a value.
+type loadEnvelope struct {
+	Value      json.RawMessage `json:"v,omitempty"`
+	FreshUntil int64           `json:"fresh_until,omitempty"`
+	Err        string          `json:"err,omitempty"`
+}
+
+// flight is one in-progress loader call shared by every caller for its key.
+type flight[C any] struct {
+	done chan struct{}
+	val  *C
+	err  error
+}
+
+// GetOrLoad returns the cached value for key, calling loader on a miss.
+// Concurrent misses for the same key share one loader call. The loaded value
+// is stored with ttl (0 uses the store default).
+//
+// The cache is an optimization here: read and write failures of the
+// underlying Store degrade to calling loader, and only loader errors are
+// returned. A leader's ctx cancellation aborts the shared load for all
+// waiters on that key; each waiter still honors its own ctx while waiting.
+func (s *TypedStore[C]) GetOrLoad(ctx context.Context, key string, loader Loader[C], ttl time.Duration, opts ...LoadOption) (*C, error) {
+	var o loadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	raw, ok, err := s.store.Get(ctx, s.fullKey(key))
+	if err == nil && ok {
+		env, decodeErr := decodeEntry(raw)
+		if decodeErr == nil {
+			if env.Err != "" {
+				return nil, fmt.Errorf("typed cache load %q: %w: %s", key, ErrCachedLoadFailure, env.Err)
+			}
+			var val C
+			if err := json.Unmarshal(env.Value, &val); err == nil {
+				if env.FreshUntil != 0 && !s.now().Before(time.Unix(0, env.FreshUntil)) {
+					s.refresh(ctx, key, loader, ttl, o)
+				}
+				return &val, nil
+			}
+		}
+	}
+
+	f, leader := s.join(key)
+	if leader {
+		s.load(ctx, key, loader, ttl, o, f, true)
+	}
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// refresh reloads key in the background unless a load is already in flight.
+func (s *TypedStore[C]) refresh(ctx context.Context, key string, loader Loader[C], ttl time.Duration, o loadOptions) {
+	f, leader := s.join(key)
+	if !leader {
+		return
+	}
+	refreshCtx := context.WithoutCancel(ctx)
+	go func() {
+		ctx, cancel := refreshCtx, context.CancelFunc(func() {})
+		if o.refreshTimeout > 0 {
+			ctx, cancel = context.WithTimeout(refreshCtx, o.refreshTimeout)
+		}
+		defer cancel()
+		// A failed refresh keeps serving the stale value until the hard TTL.
+		s.load(ctx, key, loader, ttl, o, f, false)
+	}()
+}
+
+// join returns the in-flight load for key, registering a new one when none
+// exists. leader reports whether the caller must run it.
+func (s *TypedStore[C]) join(key string) (f *flight[C], leader bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f, ok := s.flights[key]; ok {
+		return f, false
+	}
+	if s.flights == nil {
+		s.flights = make(map[string]*flight[C])
+	}
+	f = &flight[C]{done: make(chan struct{})}
+	s.flights[key] = f
+	return f, true
+}
+
+func (s *TypedStore[C]) load(ctx context.Context, key string, loader Loader[C], ttl time.Duration, o loadOptions, f *flight[C], cacheErr bool) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.flights, key)
+		s.mu.Unlock()
+		close(f.done)
+	}()
+
+	val, err := loader(ctx, key)
+	f.val, f.err = val, err
+	switch {
+	case err != nil:
+		// A load cut short by the leader's own context says nothing about
+		// the source, so it must not fail other callers.
+		aborted := errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil
+		if cacheErr && o.negativeTTL > 0 && !aborted {
+			_ = s.setEnvelope(ctx, key, loadEnvelope{Err: err.Error()}, o.negativeTTL, o.tags)
+		}
+	case val != nil:
+		_ = s.saveLoaded(ctx, key, val, ttl, o)
+	}
+}
+
+func (s *TypedStore[C]) saveLoaded(ctx context.Context, key string, val *C, ttl time.Duration, o loadOptions) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("typed cache marshal %q: %w", key, err)
+	}
+	if o.softTTL <= 0 {
+		return s.set(ctx, key, data, ttl, o.tags)
+	}
+	return s.setEnvelope(ctx, key, loadEnvelope{Value: data, FreshUntil: s.now().Add(o.softTTL).UnixNano()}, ttl, o.tags)
+}
+
+func (s *TypedStore[C]) setEnvelope(ctx context.Context, key string, env loadEnvelope, ttl time.Duration, tags []string) error {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("typed cache marshal %q: %w", key, err)
+	}
+	return s.set(ctx, key, append(bytes.Clone(envelopePrefix), data...), ttl, tags)
+}
+
+func (s *TypedStore[C]) now() time.Time {
+	if s.clock != nil {
+		return s.clock()
+	}
+	return time.Now()
+}
+
+// decodeEntry unwraps an envelope, or treats raw as a plain JSON value.
+func decodeEntry(raw []byte) (loadEnvelope, error) {
+	if !bytes.HasPrefix(raw, envelopePrefix) {
+		return loadEnvelope{Value: raw}, nil
+	}
+	var env loadEnvelope
+	if err := json.Unmarshal(raw[len(envelopePrefix):], &env); err != nil {
+		return loadEnvelope{}, err
+	}
+	return env, nil
+}
@@ -0,0 +1,241 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoadCoalescesConcurrentMisses(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	typed := NewTypedStore[testState](NewMemoryStore(MemoryConfig{}), "p")
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(context.Context, string) (*testState, error) {
+		calls.Add(1)
+		<-release
+		return &testState{Count: 7}, nil
+	}
+
+	const callers = 16
+	var wg sync.WaitGroup
+	results := make(chan *testState, callers)
+	for range callers {
+		wg.Go(func() {
+			got, err := typed.GetOrLoad(ctx, "k", loader, 0)
+			if err != nil {
+				t.Errorf("GetOrLoad: %v", err)
+			}
+			results <- got
+		})
+	}
+	waitFor(t, func() bool {
+		typed.mu.Lock()
+		defer typed.mu.Unlock()
+		return typed.flights["k"] != nil
+	})
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("loader calls = %d, want 1", n)
+	}
+	for got := range results {
+		if got == nil || got.Count != 7 {
+			t.Fatalf("result = %+v", got)
+		}
+	}
+	if got, err := typed.Load(ctx, "k"); err != nil || got == nil || got.Count != 7 {
+		t.Fatalf("Load after GetOrLoad = %+v, %v", got, err)
+	}
+	if _, err := typed.GetOrLoad(ctx, "k", loader, 0); err != nil || calls.Load() != 1 {
+		t.Fatalf("cached hit called loader: calls=%d err=%v", calls.Load(), err)
+	}
+}
+
+func TestGetOrLoadServesStaleWhileRevalidating(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Unix(100, 0)
+	var clockMu sync.Mutex
+	clock := func() time.Time {
+		clockMu.Lock()
+		defer clockMu.Unlock()
+		return now
+	}
+	typed := NewTypedStore[testState](newMemoryStore(MemoryConfig{}, clock), "")
+	typed.clock = clock
+
+	var calls atomic.Int32
+	loader := func(context.Context, string) (*testState, error) {
+		return &testState{Count: int(calls.Add(1))}, nil
+	}
+	opts := []LoadOption{WithSoftTTL(time.Second), WithRefreshTimeout(time.Second)}
+
+	if got, err := typed.GetOrLoad(ctx, "k", loader, time.Minute, opts...); err != nil || got.Count != 1 {
+		t.Fatalf("first GetOrLoad = %+v, %v", got, err)
+	}
+	if got, _ := typed.GetOrLoad(ctx, "k", loader, time.Minute, opts...); got.Count != 1 || calls.Load() != 1 {
+		t.Fatalf("fresh hit = %+v, calls = %d", got, calls.Load())
+	}
+
+	clockMu.Lock()
+	now = now.Add(2 * time.Second)
+	clockMu.Unlock()
+	if got, _ := typed.GetOrLoad(ctx, "k", loader, time.Minute, opts...); got.Count != 1 {
+		t.Fatalf("stale hit = %+v, want the stale value", got)
+	}
+	waitFor(t, func() bool {
+		got, err := typed.Load(ctx, "k")
+		return err == nil && got != nil && got.Count == 2
+	})
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("loader calls = %d, want 2", n)
+	}
+}
+
+func TestGetOrLoadNegativeCaching(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Unix(100, 0)
+	clock := func() time.Time { return now }
+	typed := NewTypedStore[testState](newMemoryStore(MemoryConfig{}, clock), "")
+	typed.clock = clock
+
+	loadErr := errors.New("backend down")
+	var calls atomic.Int32
+	failing := func(context.Context, string) (*testState, error) {
+		calls.Add(1)
+		return nil, loadErr
+	}
+
+	if _, err := typed.GetOrLoad(ctx, "k", failing, 0, WithNegativeTTL(time.Second)); !errors.Is(err, loadErr) {
+		t.Fatalf("first error = %v, want %v", err, loadErr)
+	}
+	if _, err := typed.GetOrLoad(ctx, "k", failing, 0, WithNegativeTTL(time.Second)); !errors.Is(err, ErrCachedLoadFailure) {
+		t.Fatalf("cached error = %v, want ErrCachedLoadFailure", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("loader calls = %d, want 1", calls.Load())
+	}
+	if got, err := typed.Load(ctx, "k"); err != nil || got != nil {
+		t.Fatalf("Load of negative entry = %+v, %v; want a miss", got, err)
+	}
+
+	now = now.Add(time.Second)
+	if _, err := typed.GetOrLoad(ctx, "k", failing, 0, WithNegativeTTL(time.Second)); !errors.Is(err, loadErr) {
+		t.Fatalf("error after negative TTL = %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("loader calls = %d, want 2", calls.Load())
+	}
+
+	// Without WithNegativeTTL errors are never cached.
+	if _, err := typed.GetOrLoad(ctx, "other", failing, 0); !errors.Is(err, loadErr) {
+		t.Fatalf("uncached error = %v", err)
+	}
+	if ok, _ := typed.store.Exists(ctx, "other"); ok {
+		t.Fatal("loader error cached without WithNegativeTTL")
+	}
+}
+
+func TestGetOrLoadDoesNotNegativeCacheCanceledLoads(t *testing.T) {
+	t.Parallel()
+
+	typed := NewTypedStore[testState](NewMemoryStore(MemoryConfig{}), "")
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	_, err := typed.GetOrLoad(ctx, "k", func(ctx context.Context, _ string) (*testState, error) {
+		close(started)
+		cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, 0, WithNegativeTTL(time.Minute))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled load error = %v, want context.Canceled", err)
+	}
+	<-started
+
+	var calls atomic.Int32
+	got, err := typed.GetOrLoad(context.Background(), "k", func(context.Context, string) (*testState, error) {
+		calls.Add(1)
+		return &testState{Count: 2}, nil
+	}, 0, WithNegativeTTL(time.Minute))
+	if err != nil || got == nil || got.Count != 2 {
+		t.Fatalf("GetOrLoad after canceled load = %+v, %v; want a fresh load", got, err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("loader calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestGetOrLoadDegradesOnStoreFailureAndNilValues(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	broken := NewTypedStore[testState](&typedFailingStore{getErr: errors.New("read"), setErr: errors.New("write")}, "")
+	got, err := broken.GetOrLoad(ctx, "k", func(context.Context, string) (*testState, error) {
+		return &testState{Count: 1}, nil
+	}, 0)
+	if err != nil || got.Count != 1 {
+		t.Fatalf("GetOrLoad on failing store = %+v, %v", got, err)
+	}
+
+	typed := NewTypedStore[testState](NewMemoryStore(MemoryConfig{}), "")
+	got, err = typed.GetOrLoad(ctx, "none", func(context.Context, string) (*testState, error) {
+		return nil, nil
+	}, 0)
+	if err != nil || got != nil {
+		t.Fatalf("nil loader result = %+v, %v", got, err)
+	}
+	if ok, _ := typed.store.Exists(ctx, "none"); ok {
+		t.Fatal("nil loader result was cached")
+	}
+}
+
+func TestGetOrLoadWaiterHonorsOwnContext(t *testing.T) {
+	t.Parallel()
+
+	typed := NewTypedStore[testState](NewMemoryStore(MemoryConfig{}), "")
+	release := make(chan struct{})
+	defer close(release)
+	go func() {
+		_, _ = typed.GetOrLoad(context.Background(), "k", func(context.Context, string) (*testState, error) {
+			<-release
+			return &testState{}, nil
+		}, 0)
+	}()
+	waitFor(t, func() bool {
+		typed.mu.Lock()
+		defer typed.mu.Unlock()
+		return typed.flights["k"] != nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := typed.GetOrLoad(ctx, "k", func(context.Context, string) (*testState, error) {
+		t.Error("waiter ran its own loader")
+		return nil, nil
+	}, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("waiter error = %v, want context.Canceled", err)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
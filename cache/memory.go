@@ -1,42 +1,102 @@
 package cache
 
 import (
+	"container/list"
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kbukum/gokit/logging"
 )
 
 // MemoryConfig configures the in-memory cache backend.
+//
+// MaxEntries and MaxBytes bound the store; zero leaves that dimension
+// unbounded. When either budget is set, Eviction picks the entry to drop.
+// The byte budget counts key and value lengths, not Go allocation overhead.
 type MemoryConfig struct {
-	DefaultTTL time.Duration `mapstructure:"default_ttl" json:"default_ttl" yaml:"default_ttl"`
+	DefaultTTL time.Duration  `mapstructure:"default_ttl" json:"default_ttl" yaml:"default_ttl"`
+	MaxEntries int            `mapstructure:"max_entries" json:"max_entries" yaml:"max_entries"`
+	MaxBytes   int64          `mapstructure:"max_bytes" json:"max_bytes" yaml:"max_bytes"`
+	Eviction   EvictionPolicy `mapstructure:"eviction" json:"eviction" yaml:"eviction"`
 }
 
-// MemoryStore is a thread-safe in-memory cache with TTL expiration.
+// Bounded reports whether an entry or byte budget is configured.
+func (c *MemoryConfig) Bounded() bool {
+	return c.MaxEntries > 0 || c.MaxBytes > 0
+}
+
+// Validate checks budget and eviction settings.
+func (c *MemoryConfig) Validate() error {
+	if c.DefaultTTL < 0 {
+		return fmt.Errorf("cache: default_ttl must be >= 0")
+	}
+	if c.MaxEntries < 0 {
+		return fmt.Errorf("cache: max_entries must be >= 0")
+	}
+	if c.MaxBytes < 0 {
+		return fmt.Errorf("cache: max_bytes must be >= 0")
+	}
+	if c.Eviction != "" && !c.Eviction.valid() {
+		return invalidEvictionPolicy(c.Eviction)
+	}
+	return nil
+}
+
+// MemoryStore is a thread-safe in-memory cache with TTL expiration and
+// optional entry/byte budgets enforced by an eviction policy.
 type MemoryStore struct {
 	mu         sync.RWMutex
 	clock      func() time.Time
 	defaultTTL time.Duration
-	items      map[string]memoryItem
+	maxEntries int
+	maxBytes   int64
+	evictor    evictor
+	items      map[string]*memoryEntry
+	tags       map[string]map[string]struct{}
+	bytes      int64
+
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	evictions   atomic.Uint64
+	expirations atomic.Uint64
+	rejections  atomic.Uint64
 }
 
-type memoryItem struct {
+type memoryEntry struct {
+	key       string
 	value     []byte
 	expiresAt time.Time
+	tags      []string
+
+	// elem and bucket are owned by the store's evictor.
+	elem   *list.Element
+	bucket *list.Element
 }
 
-// NewMemoryStore creates an in-memory cache.
+// NewMemoryStore creates an in-memory cache. An unrecognized Eviction falls
+// back to LRU; call MemoryConfig.Validate to reject it instead.
 func NewMemoryStore(cfg MemoryConfig) *MemoryStore {
 	return newMemoryStore(cfg, time.Now)
 }
 
 func newMemoryStore(cfg MemoryConfig, clock func() time.Time) *MemoryStore {
-	return &MemoryStore{
+	s := &MemoryStore{
 		clock:      clock,
 		defaultTTL: cfg.DefaultTTL,
-		items:      make(map[string]memoryItem),
+		maxEntries: cfg.MaxEntries,
+		maxBytes:   cfg.MaxBytes,
+		items:      make(map[string]*memoryEntry),
+		tags:       make(map[string]map[string]struct{}),
+	}
+	if cfg.Bounded() {
+		s.evictor = newEvictor(cfg.Eviction, cfg.MaxEntries)
 	}
+	return s
 }
 
 // RegisterMemory registers the core memory backend into an explicit registry.
@@ -53,45 +113,156 @@ func RegisterMemory(reg *FactoryRegistry) error {
 		if memCfg.DefaultTTL == 0 {
 			memCfg.DefaultTTL = cfg.DefaultTTL
 		}
+		if err := memCfg.Validate(); err != nil {
+			return nil, err
+		}
 		return NewMemoryStore(memCfg), nil
 	})
 }
 
 // Get returns a copy of the cached bytes when present and not expired.
-func (s *MemoryStore) Get(ctx context.Context, key string) (value []byte, found bool, err error) {
-	s.mu.RLock()
-	item, ok := s.items[key]
-	s.mu.RUnlock()
-	if !ok {
+func (s *MemoryStore) Get(_ context.Context, key string) (value []byte, found bool, err error) {
+	if s.evictor == nil {
+		s.mu.RLock()
+		e, ok := s.items[key]
+		live := ok && !e.expired(s.clock())
+		if live {
+			value = cloneBytes(e.value)
+		}
+		s.mu.RUnlock()
+		if live {
+			s.hits.Add(1)
+			return value, true, nil
+		}
+		if ok {
+			s.expire(key)
+		}
+		s.misses.Add(1)
 		return nil, false, nil
 	}
-	if item.expired(s.clock()) {
-		_ = s.Delete(ctx, key)
+
+	// Bounded stores reorder on every read, so lookups take the write lock.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.items[key]
+	if ok && e.expired(s.clock()) {
+		s.removeLocked(e)
+		s.expirations.Add(1)
+		ok = false
+	}
+	if !ok {
+		s.misses.Add(1)
 		return nil, false, nil
 	}
-	return cloneBytes(item.value), true, nil
+	s.evictor.accessed(e)
+	s.hits.Add(1)
+	return cloneBytes(e.value), true, nil
 }
 
 // Set stores a copy of value with the given TTL. ttl=0 uses the store default;
 // a resulting zero TTL means no expiration.
+//
+// On a bounded store, Set evicts entries until the new value fits. A value
+// larger than MaxBytes, or a new key refused by TinyLFU admission, is not
+// stored; neither case is an error, since a cache may always decline to keep
+// a value. Both are counted in Stats.Rejections.
 func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.set(key, value, ttl, nil)
+	return nil
+}
+
+// SetWithTags stores value like Set and attaches tags to key, replacing any
+// tags from an earlier write. A plain Set drops the key's tags.
+func (s *MemoryStore) SetWithTags(_ context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	s.set(key, value, ttl, tags)
+	return nil
+}
+
+// InvalidateTag removes every key currently tagged with tag.
+func (s *MemoryStore) InvalidateTag(_ context.Context, tag string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.tags[tag] {
+		if e, ok := s.items[key]; ok {
+			s.removeLocked(e)
+		}
+	}
+	return nil
+}
+
+// DeletePrefix removes every key starting with prefix. It scans the whole
+// store under the write lock.
+func (s *MemoryStore) DeletePrefix(_ context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, e := range s.items {
+		if strings.HasPrefix(key, prefix) {
+			s.removeLocked(e)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) set(key string, value []byte, ttl time.Duration, tags []string) {
 	if ttl == 0 {
 		ttl = s.defaultTTL
 	}
-	item := memoryItem{value: cloneBytes(value)}
+	var expiresAt time.Time
 	if ttl > 0 {
-		item.expiresAt = s.clock().Add(ttl)
+		expiresAt = s.clock().Add(ttl)
 	}
+	size := entrySize(key, value)
+
 	s.mu.Lock()
-	s.items[key] = item
-	s.mu.Unlock()
-	return nil
+	defer s.mu.Unlock()
+	existing := s.items[key]
+	if s.maxBytes > 0 && size > s.maxBytes {
+		if existing != nil {
+			s.removeLocked(existing)
+		}
+		s.rejections.Add(1)
+		return
+	}
+
+	if existing != nil {
+		s.bytes += size - entrySize(key, existing.value)
+		existing.value = cloneBytes(value)
+		existing.expiresAt = expiresAt
+		s.untagLocked(existing)
+		s.tagLocked(existing, tags)
+		if s.evictor != nil {
+			s.evictor.accessed(existing)
+			s.evictLocked(existing, 0, 0)
+		}
+		return
+	}
+
+	if s.evictor != nil {
+		var victim *memoryEntry
+		if s.overBudget(1, size) {
+			victim = s.evictor.victim(nil)
+		}
+		if !s.evictor.admit(key, admissionVictim(victim, s.clock())) {
+			s.rejections.Add(1)
+			return
+		}
+		s.evictLocked(nil, 1, size)
+	}
+	e := &memoryEntry{key: key, value: cloneBytes(value), expiresAt: expiresAt}
+	s.items[key] = e
+	s.bytes += size
+	s.tagLocked(e, tags)
+	if s.evictor != nil {
+		s.evictor.added(e)
+	}
 }
 
 // Delete removes a key.
 func (s *MemoryStore) Delete(_ context.Context, key string) error {
 	s.mu.Lock()
-	delete(s.items, key)
+	if e, ok := s.items[key]; ok {
+		s.removeLocked(e)
+	}
 	s.mu.Unlock()
 	return nil
 }
@@ -117,8 +288,107 @@ func (s *MemoryStore) GetMany(ctx context.Context, keys []string) (map[string][]
 	return out, nil
 }
 
-func (i memoryItem) expired(now time.Time) bool {
-	return !i.expiresAt.IsZero() && !now.Before(i.expiresAt)
+// Stats returns a snapshot of occupancy and lifetime counters.
+func (s *MemoryStore) Stats() Stats {
+	s.mu.RLock()
+	entries, bytes := len(s.items), s.bytes
+	s.mu.RUnlock()
+	return Stats{
+		Entries:     entries,
+		Bytes:       bytes,
+		Hits:        s.hits.Load(),
+		Misses:      s.misses.Load(),
+		Evictions:   s.evictions.Load(),
+		Expirations: s.expirations.Load(),
+		Rejections:  s.rejections.Load(),
+	}
+}
+
+// expire removes key if it is still expired once the write lock is held.
+func (s *MemoryStore) expire(key string) {
+	s.mu.Lock()
+	if e, ok := s.items[key]; ok && e.expired(s.clock()) {
+		s.removeLocked(e)
+		s.expirations.Add(1)
+	}
+	s.mu.Unlock()
+}
+
+// overBudget reports whether adding entries and bytes would exceed a budget.
+func (s *MemoryStore) overBudget(entries int, bytes int64) bool {
+	if s.maxEntries > 0 && len(s.items)+entries > s.maxEntries {
+		return true
+	}
+	return s.maxBytes > 0 && s.bytes+bytes > s.maxBytes
+}
+
+// evictLocked drops victims, never keep, until entries and bytes fit.
+func (s *MemoryStore) evictLocked(keep *memoryEntry, entries int, bytes int64) {
+	now := s.clock()
+	for s.overBudget(entries, bytes) {
+		victim := s.evictor.victim(keep)
+		if victim == nil {
+			return
+		}
+		s.removeLocked(victim)
+		if victim.expired(now) {
+			s.expirations.Add(1)
+		} else {
+			s.evictions.Add(1)
+		}
+	}
+}
+
+func (s *MemoryStore) removeLocked(e *memoryEntry) {
+	delete(s.items, e.key)
+	s.bytes -= entrySize(e.key, e.value)
+	s.untagLocked(e)
+	if s.evictor != nil {
+		s.evictor.removed(e)
+	}
+}
+
+func (s *MemoryStore) tagLocked(e *memoryEntry, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	e.tags = slices.Clone(tags)
+	for _, tag := range tags {
+		keys, ok := s.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			s.tags[tag] = keys
+		}
+		keys[e.key] = struct{}{}
+	}
+}
+
+func (s *MemoryStore) untagLocked(e *memoryEntry) {
+	for _, tag := range e.tags {
+		keys := s.tags[tag]
+		delete(keys, e.key)
+		if len(keys) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+	e.tags = nil
+}
+
+// admissionVictim hides expired victims from admission: displacing a dead
+// entry costs nothing, so the candidate is always admitted.
+func admissionVictim(victim *memoryEntry, now time.Time) *memoryEntry {
+	if victim == nil || victim.expired(now) {
+		return nil
+	}
+	return victim
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+func entrySize(key string, value []byte) int64 {
+	return int64(len(key) + len(value))
 }
 
 func cloneBytes(in []byte) []byte {
@@ -131,6 +401,8 @@ func cloneBytes(in []byte) []byte {
 }
 
 var (
-	_ Store      = (*MemoryStore)(nil)
-	_ BatchStore = (*MemoryStore)(nil)
+	_ Store       = (*MemoryStore)(nil)
+	_ BatchStore  = (*MemoryStore)(nil)
+	_ StatsStore  = (*MemoryStore)(nil)
+	_ TaggedStore = (*MemoryStore)(nil)
 )
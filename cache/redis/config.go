@@ -58,6 +58,9 @@ type Config struct {
 
 	// ConnMaxLifetime is the maximum time a connection may be reused (e.g. "30m"). 0 means no limit.
 	ConnMaxLifetime string `yaml:"max_conn_age" mapstructure:"max_conn_age"`
+
+	// TagPrefix namespaces the sets that track tag membership (default "gokit:tag:").
+	TagPrefix string `yaml:"tag_prefix" mapstructure:"tag_prefix"`
 }
 
 // ApplyDefaults sets sensible defaults for zero-valued fields.
@@ -86,6 +89,9 @@ func (c *Config) ApplyDefaults() {
 	if c.WriteTimeout == "" {
 		c.WriteTimeout = "3s"
 	}
+	if c.TagPrefix == "" {
+		c.TagPrefix = DefaultTagPrefix
+	}
 }
 
 // Validate checks that required fields are present and parseable.
@@ -19,6 +19,9 @@ func TestConfigDefaultsAndValidation(t *testing.T) {
 	if cfg.DialTimeout != "5s" || cfg.ReadTimeout != "3s" || cfg.WriteTimeout != "3s" {
 		t.Fatalf("timeout defaults = %q %q %q", cfg.DialTimeout, cfg.ReadTimeout, cfg.WriteTimeout)
 	}
+	if cfg.TagPrefix != DefaultTagPrefix {
+		t.Fatalf("tag prefix default = %q", cfg.TagPrefix)
+	}
 	if err := cfg.Validate(); err != nil {
 		t.Fatalf("Validate defaulted config: %v", err)
 	}
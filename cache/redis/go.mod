@@ -6,8 +6,10 @@ toolchain go1.26.6
 
 require (
 	github.com/alicebob/miniredis/v2 v2.38.0
+	github.com/google/uuid v1.6.0
 	github.com/kbukum/gokit v0.2.0
 	github.com/kbukum/gokit/cache v0.2.0
+	github.com/kbukum/gokit/stateful v0.2.0
 	github.com/redis/go-redis/v9 v9.22.0
 )
 
@@ -17,7 +19,6 @@ require (
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/go-logr/logr v1.4.4 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
-	github.com/google/uuid v1.6.0 // indirect
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.29.0 // indirect
 	github.com/mattn/go-colorable v0.1.15 // indirect
 	github.com/mattn/go-isatty v0.0.22 // indirect
@@ -55,4 +56,5 @@ require (
 replace (
 	github.com/kbukum/gokit => ../../
 	github.com/kbukum/gokit/cache => ../
+	github.com/kbukum/gokit/stateful => ../../stateful
 )
@@ -0,0 +1,104 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/kbukum/gokit/cache"
+)
+
+// DefaultInvalidationChannel is the pub/sub channel used when none is configured.
+const DefaultInvalidationChannel = "gokit:cache:invalidate"
+
+// Invalidator broadcasts cache.Invalidations over Redis pub/sub. Delivery is
+// at-most-once: instances that are disconnected when a message is published
+// miss it and fall back to the local tier's TTL.
+type Invalidator struct {
+	rdb     *goredis.Client
+	channel string
+
+	mu     sync.Mutex
+	pubsub *goredis.PubSub
+	done   chan struct{}
+}
+
+// NewInvalidator creates a pub/sub invalidator on client's connection pool.
+func NewInvalidator(client *Client, channel string) *Invalidator {
+	if channel == "" {
+		channel = DefaultInvalidationChannel
+	}
+	return &Invalidator{rdb: client.rdb, channel: channel}
+}
+
+// InvalidatorFactory returns a cache.InvalidatorFactory for cache.TieredConfig
+// that reuses the remote tier's connection when it is a *Client.
+func InvalidatorFactory(channel string) cache.InvalidatorFactory {
+	return func(remote cache.Store) (cache.Invalidator, error) {
+		client, ok := remote.(*Client)
+		if !ok {
+			return nil, fmt.Errorf("redis invalidator: remote store is %T, not *redis.Client", remote)
+		}
+		return NewInvalidator(client, channel), nil
+	}
+}
+
+// Publish sends inv to every subscriber of the channel.
+func (i *Invalidator) Publish(ctx context.Context, inv cache.Invalidation) error {
+	payload, err := json.Marshal(inv)
+	if err != nil {
+		return fmt.Errorf("redis invalidator marshal: %w", err)
+	}
+	return i.rdb.Publish(ctx, i.channel, payload).Err()
+}
+
+// Subscribe starts delivering invalidations to handler. It returns once Redis
+// has confirmed the subscription.
+func (i *Invalidator) Subscribe(handler func(cache.Invalidation)) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.pubsub != nil {
+		return fmt.Errorf("redis invalidator: already subscribed to %q", i.channel)
+	}
+	ctx := context.Background()
+	pubsub := i.rdb.Subscribe(ctx, i.channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return fmt.Errorf("redis invalidator subscribe %q: %w", i.channel, err)
+	}
+	i.pubsub = pubsub
+	i.done = make(chan struct{})
+	go i.deliver(pubsub.Channel(), handler, i.done)
+	return nil
+}
+
+// Close unsubscribes and waits for in-flight delivery to finish. The shared
+// client is left open.
+func (i *Invalidator) Close() error {
+	i.mu.Lock()
+	pubsub, done := i.pubsub, i.done
+	i.pubsub, i.done = nil, nil
+	i.mu.Unlock()
+	if pubsub == nil {
+		return nil
+	}
+	err := pubsub.Close()
+	<-done
+	return err
+}
+
+func (i *Invalidator) deliver(messages <-chan *goredis.Message, handler func(cache.Invalidation), done chan struct{}) {
+	defer close(done)
+	for msg := range messages {
+		var inv cache.Invalidation
+		if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+			continue
+		}
+		handler(inv)
+	}
+}
+
+var _ cache.Invalidator = (*Invalidator)(nil)
@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/cache"
+	"github.com/kbukum/gokit/logging"
+)
+
+func TestInvalidatorPublishSubscribe(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTestClient(t)
+	inv := NewInvalidator(client, "")
+	received := make(chan cache.Invalidation, 1)
+	if err := inv.Subscribe(func(i cache.Invalidation) { received <- i }); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if err := inv.Subscribe(func(cache.Invalidation) {}); err == nil {
+		t.Fatal("second Subscribe succeeded")
+	}
+
+	want := cache.Invalidation{Origin: "node-a", Keys: []string{"k1", "k2"}}
+	if err := inv.Publish(context.Background(), want); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	select {
+	case got := <-received:
+		if got.Origin != want.Origin || len(got.Keys) != 2 || got.Keys[1] != "k2" {
+			t.Fatalf("received %+v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("invalidation not delivered")
+	}
+	if err := inv.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := inv.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestTieredStoreOverRedis(t *testing.T) {
+	t.Parallel()
+
+	_, mini := newTestClient(t)
+	reg := cache.NewFactoryRegistry()
+	if err := Register(reg); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := cache.RegisterTiered(reg); err != nil {
+		t.Fatalf("RegisterTiered: %v", err)
+	}
+	newTiered := func() *cache.TieredStore {
+		store, err := cache.New(reg, cache.Config{Provider: cache.ProviderTiered}, &cache.TieredConfig{
+			RemoteProvider: cache.ProviderRedis,
+			Remote:         &Config{Enabled: true, Addr: mini.Addr()},
+			Invalidator:    InvalidatorFactory("test:invalidate"),
+		}, logging.NewDefault("test"))
+		if err != nil {
+			t.Fatalf("New tiered: %v", err)
+		}
+		t.Cleanup(func() { _ = store.(cache.CloseStore).Close() })
+		return store.(*cache.TieredStore)
+	}
+	a, b := newTiered(), newTiered()
+	ctx := context.Background()
+
+	if err := a.Set(ctx, "k", []byte("v1"), 0); err != nil {
+		t.Fatalf("a.Set: %v", err)
+	}
+	if got, _, _ := b.Get(ctx, "k"); string(got) != "v1" {
+		t.Fatalf("b.Get = %q", got)
+	}
+	if err := a.Set(ctx, "k", []byte("v2"), 0); err != nil {
+		t.Fatalf("a.Set v2: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, cached, _ := b.Local().Get(ctx, "k"); !cached {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("b kept its stale local copy")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got, _, _ := b.Get(ctx, "k"); string(got) != "v2" {
+		t.Fatalf("b.Get after invalidation = %q", got)
+	}
+}
+
+func TestInvalidatorFactoryRejectsForeignStore(t *testing.T) {
+	t.Parallel()
+
+	if _, err := InvalidatorFactory("")(cache.NewMemoryStore(cache.MemoryConfig{})); err == nil {
+		t.Fatal("InvalidatorFactory accepted a non-redis store")
+	}
+}
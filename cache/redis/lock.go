@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/kbukum/gokit/worker"
+)
+
+// DefaultLockPrefix namespaces lease keys when none is configured.
+const DefaultLockPrefix = "gokit:lock:"
+
+// acquireScript sets KEYS[1] to owner ARGV[1] with a TTL of ARGV[2]
+// milliseconds if it is free or already held by owner.
+var acquireScript = goredis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current == ARGV[1] then
+  redis.call('PEXPIRE', KEYS[1], ARGV[2])
+  return 1
+end
+if current then
+  return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return 1
+`)
+
+// releaseScript deletes KEYS[1] only if owner ARGV[1] still holds it.
+var releaseScript = goredis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Locker grants worker leases as Redis keys that expire on their own, so a
+// crashed leader's lease lapses after its TTL.
+type Locker struct {
+	rdb    *goredis.Client
+	prefix string
+}
+
+// NewLocker creates a lease locker on client's connection pool.
+func NewLocker(client *Client, prefix string) *Locker {
+	if prefix == "" {
+		prefix = DefaultLockPrefix
+	}
+	return &Locker{rdb: client.rdb, prefix: prefix}
+}
+
+// Acquire takes or renews the lease key for owner.
+func (l *Locker) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	held, err := acquireScript.Run(ctx, l.rdb, []string{l.prefix + key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("redis lock %q: %w", key, err)
+	}
+	return held == 1, nil
+}
+
+// Release gives up the lease key if owner holds it.
+func (l *Locker) Release(ctx context.Context, key, owner string) error {
+	if err := releaseScript.Run(ctx, l.rdb, []string{l.prefix + key}, owner).Err(); err != nil {
+		return fmt.Errorf("redis unlock %q: %w", key, err)
+	}
+	return nil
+}
+
+var _ worker.Locker = (*Locker)(nil)
@@ -0,0 +1,72 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/worker"
+)
+
+func TestLockerAcquireRenewRelease(t *testing.T) {
+	t.Parallel()
+
+	client, mini := newTestClient(t)
+	locker := NewLocker(client, "")
+	ctx := context.Background()
+
+	if ok, err := locker.Acquire(ctx, "jobs", "a", time.Minute); err != nil || !ok {
+		t.Fatalf("a Acquire = %v, %v", ok, err)
+	}
+	if ok, err := locker.Acquire(ctx, "jobs", "b", time.Minute); err != nil || ok {
+		t.Fatalf("b Acquire while a holds = %v, %v", ok, err)
+	}
+
+	mini.FastForward(50 * time.Second)
+	if ok, err := locker.Acquire(ctx, "jobs", "a", time.Minute); err != nil || !ok {
+		t.Fatalf("a renew = %v, %v", ok, err)
+	}
+	if ttl := mini.TTL(DefaultLockPrefix + "jobs"); ttl != time.Minute {
+		t.Fatalf("TTL after renew = %v, want 1m", ttl)
+	}
+
+	// Release by a non-owner is a no-op.
+	if err := locker.Release(ctx, "jobs", "b"); err != nil {
+		t.Fatalf("b Release: %v", err)
+	}
+	if owner, _ := mini.Get(DefaultLockPrefix + "jobs"); owner != "a" {
+		t.Fatalf("owner after foreign release = %q", owner)
+	}
+	if err := locker.Release(ctx, "jobs", "a"); err != nil {
+		t.Fatalf("a Release: %v", err)
+	}
+	if ok, err := locker.Acquire(ctx, "jobs", "b", time.Minute); err != nil || !ok {
+		t.Fatalf("b Acquire after release = %v, %v", ok, err)
+	}
+
+	// An unrenewed lease expires.
+	mini.FastForward(time.Minute)
+	if ok, _ := locker.Acquire(ctx, "jobs", "a", time.Minute); !ok {
+		t.Fatal("a could not take an expired lease")
+	}
+}
+
+func TestLockerElectsOneLeader(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTestClient(t)
+	locker := NewLocker(client, "svc:")
+	electors := []*worker.LeaderElector{
+		worker.NewLeaderElector("a", worker.LeaderConfig{Locker: locker, Key: "jobs", Owner: "a"}),
+		worker.NewLeaderElector("b", worker.LeaderConfig{Locker: locker, Key: "jobs", Owner: "b"}),
+	}
+	for _, e := range electors {
+		if err := e.Start(context.Background()); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		t.Cleanup(func() { _ = e.Stop(context.Background()) })
+	}
+	if !electors[0].IsLeader() || electors[1].IsLeader() {
+		t.Fatalf("leaders = %v, %v; want only a", electors[0].IsLeader(), electors[1].IsLeader())
+	}
+}
@@ -0,0 +1,257 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+
+	gkerrors "github.com/kbukum/gokit/errors"
+	"github.com/kbukum/gokit/worker"
+)
+
+// DefaultQueuePrefix namespaces queue keys when none is configured.
+const DefaultQueuePrefix = "gokit:queue:"
+
+// The scripts address every key through KEYS, in queueKeys order: ready set,
+// dead set, then the payload, attempts, lease, last_error, and enqueued_at
+// hashes, each keyed by task ID.
+
+// enqueueScript stores task ARGV[1] with payload ARGV[2] and makes it visible
+// at ARGV[3], unless the task already exists.
+var enqueueScript = goredis.NewScript(`
+if redis.call('HEXISTS', KEYS[3], ARGV[1]) == 1 then
+  return 0
+end
+redis.call('HSET', KEYS[3], ARGV[1], ARGV[2])
+redis.call('HSET', KEYS[4], ARGV[1], 0)
+redis.call('HSET', KEYS[5], ARGV[1], '')
+redis.call('HSET', KEYS[6], ARGV[1], '')
+redis.call('HSET', KEYS[7], ARGV[1], ARGV[3])
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[1])
+return 1
+`)
+
+// dequeueScript leases the first task visible at ARGV[1] for ARGV[2]
+// milliseconds under lease ARGV[3].
+var dequeueScript = goredis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 1)
+if #ids == 0 then
+  return false
+end
+local id = ids[1]
+redis.call('ZADD', KEYS[1], tonumber(ARGV[1]) + tonumber(ARGV[2]), id)
+local attempts = redis.call('HINCRBY', KEYS[4], id, 1)
+redis.call('HSET', KEYS[5], id, ARGV[3])
+local payload = redis.call('HGET', KEYS[3], id) or ''
+local last_error = redis.call('HGET', KEYS[6], id) or ''
+local enqueued_at = redis.call('HGET', KEYS[7], id) or ''
+return {id, payload, attempts, last_error, enqueued_at}
+`)
+
+// leaseCheck aborts a script unless task ARGV[2] is leased as ARGV[1].
+const leaseCheck = `
+local lease = redis.call('HGET', KEYS[5], ARGV[2])
+if not lease or lease == '' or lease ~= ARGV[1] then
+  return 0
+end
+`
+
+// extendScript hides task ARGV[2] until ARGV[3].
+var extendScript = goredis.NewScript(leaseCheck + `
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[2])
+return 1
+`)
+
+// ackScript deletes task ARGV[2].
+var ackScript = goredis.NewScript(leaseCheck + `
+redis.call('ZREM', KEYS[1], ARGV[2])
+for i = 3, 7 do
+  redis.call('HDEL', KEYS[i], ARGV[2])
+end
+return 1
+`)
+
+// retryScript makes task ARGV[2] visible again at ARGV[3] with reason ARGV[4].
+var retryScript = goredis.NewScript(leaseCheck + `
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[2])
+redis.call('HSET', KEYS[5], ARGV[2], '')
+redis.call('HSET', KEYS[6], ARGV[2], ARGV[4])
+return 1
+`)
+
+// deadLetterScript moves task ARGV[2] to the dead set at ARGV[3] with reason
+// ARGV[4].
+var deadLetterScript = goredis.NewScript(leaseCheck + `
+redis.call('ZREM', KEYS[1], ARGV[2])
+redis.call('ZADD', KEYS[2], ARGV[3], ARGV[2])
+redis.call('HSET', KEYS[5], ARGV[2], '')
+redis.call('HSET', KEYS[6], ARGV[2], ARGV[4])
+return 1
+`)
+
+// Queue is a worker.QueueBackend on Redis. Waiting and leased tasks share a
+// sorted set scored by the time they become visible, task fields are kept in
+// one hash per field keyed by task ID, and dead letters are kept in a second
+// sorted set. Every script declares the keys it touches and all keys share a
+// hash tag, so the queue works on Redis Cluster. Visibility uses the caller's clock, so
+// replicas' clocks must agree to well within the visibility timeout.
+type Queue struct {
+	rdb        *goredis.Client
+	name       string
+	ready      string
+	dead       string
+	payloads   string
+	attempts   string
+	leases     string
+	lastErrors string
+	enqueuedAt string
+	now        func() time.Time
+}
+
+// NewQueue creates a queue called name on client's connection pool, with keys
+// under prefix (DefaultQueuePrefix if empty).
+func NewQueue(client *Client, name, prefix string) *Queue {
+	if prefix == "" {
+		prefix = DefaultQueuePrefix
+	}
+	base := prefix + "{" + name + "}:"
+	return &Queue{
+		rdb:        client.rdb,
+		name:       name,
+		ready:      base + "ready",
+		dead:       base + "dead",
+		payloads:   base + "payload",
+		attempts:   base + "attempts",
+		leases:     base + "lease",
+		lastErrors: base + "last_error",
+		enqueuedAt: base + "enqueued_at",
+		now:        time.Now,
+	}
+}
+
+// queueKeys returns the keys every script receives, in the order they
+// address them.
+func (q *Queue) queueKeys() []string {
+	return []string{q.ready, q.dead, q.payloads, q.attempts, q.leases, q.lastErrors, q.enqueuedAt}
+}
+
+// Enqueue implements worker.QueueBackend.
+func (q *Queue) Enqueue(ctx context.Context, id string, payload []byte) error {
+	added, err := enqueueScript.Run(ctx, q.rdb, q.queueKeys(), id, payload, q.now().UnixMilli()).Int()
+	if err != nil {
+		return fmt.Errorf("redis queue %q enqueue: %w", q.name, err)
+	}
+	if added == 0 {
+		return gkerrors.AlreadyExists("queued task").WithDetail("id", id)
+	}
+	return nil
+}
+
+// Dequeue implements worker.QueueBackend.
+func (q *Queue) Dequeue(ctx context.Context, visibility time.Duration) (*worker.QueuedTask, error) {
+	lease := uuid.NewString()
+	res, err := dequeueScript.Run(ctx, q.rdb, q.queueKeys(),
+		q.now().UnixMilli(), visibility.Milliseconds(), lease).Slice()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis queue %q dequeue: %w", q.name, err)
+	}
+	task := &worker.QueuedTask{Lease: lease}
+	task.ID, _ = res[0].(string)
+	payload, _ := res[1].(string)
+	task.Payload = []byte(payload)
+	attempts, _ := res[2].(int64)
+	task.Attempts = int(attempts)
+	task.LastError, _ = res[3].(string)
+	if enqueued, _ := res[4].(string); enqueued != "" {
+		ms, _ := strconv.ParseInt(enqueued, 10, 64)
+		task.EnqueuedAt = time.UnixMilli(ms)
+	}
+	return task, nil
+}
+
+// Extend implements worker.QueueBackend.
+func (q *Queue) Extend(ctx context.Context, task *worker.QueuedTask, visibility time.Duration) error {
+	return q.settle(ctx, extendScript, "extend", task, q.now().Add(visibility).UnixMilli())
+}
+
+// Ack implements worker.QueueBackend.
+func (q *Queue) Ack(ctx context.Context, task *worker.QueuedTask) error {
+	return q.settle(ctx, ackScript, "ack", task)
+}
+
+// Retry implements worker.QueueBackend.
+func (q *Queue) Retry(ctx context.Context, task *worker.QueuedTask, at time.Time, reason string) error {
+	return q.settle(ctx, retryScript, "retry", task, at.UnixMilli(), reason)
+}
+
+// DeadLetter implements worker.QueueBackend.
+func (q *Queue) DeadLetter(ctx context.Context, task *worker.QueuedTask, reason string) error {
+	return q.settle(ctx, deadLetterScript, "dead letter", task, q.now().UnixMilli(), reason)
+}
+
+// Len returns the number of waiting and leased tasks, excluding dead letters.
+func (q *Queue) Len(ctx context.Context) (int, error) {
+	n, err := q.rdb.ZCard(ctx, q.ready).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis queue %q len: %w", q.name, err)
+	}
+	return int(n), nil
+}
+
+// DeadLetters returns up to limit dead-lettered tasks, oldest first.
+func (q *Queue) DeadLetters(ctx context.Context, limit int) ([]worker.QueuedTask, error) {
+	ids, err := q.rdb.ZRange(ctx, q.dead, 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis queue %q dead letters: %w", q.name, err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	pipe := q.rdb.Pipeline()
+	payloads := pipe.HMGet(ctx, q.payloads, ids...)
+	attempts := pipe.HMGet(ctx, q.attempts, ids...)
+	lastErrors := pipe.HMGet(ctx, q.lastErrors, ids...)
+	enqueuedAt := pipe.HMGet(ctx, q.enqueuedAt, ids...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("redis queue %q dead letters: %w", q.name, err)
+	}
+	tasks := make([]worker.QueuedTask, len(ids))
+	for i, id := range ids {
+		payload, _ := payloads.Val()[i].(string)
+		n, _ := attempts.Val()[i].(string)
+		lastErr, _ := lastErrors.Val()[i].(string)
+		enqueued, _ := enqueuedAt.Val()[i].(string)
+		count, _ := strconv.Atoi(n)
+		ms, _ := strconv.ParseInt(enqueued, 10, 64)
+		tasks[i] = worker.QueuedTask{
+			ID:         id,
+			Payload:    []byte(payload),
+			Attempts:   count,
+			LastError:  lastErr,
+			EnqueuedAt: time.UnixMilli(ms),
+		}
+	}
+	return tasks, nil
+}
+
+// settle runs a lease-checked script for task with extra arguments.
+func (q *Queue) settle(ctx context.Context, script *goredis.Script, op string, task *worker.QueuedTask, args ...any) error {
+	ok, err := script.Run(ctx, q.rdb, q.queueKeys(), append([]any{task.Lease, task.ID}, args...)...).Int()
+	if err != nil {
+		return fmt.Errorf("redis queue %q %s %s: %w", q.name, op, task.ID, err)
+	}
+	if ok == 0 {
+		return worker.ErrLeaseLost
+	}
+	return nil
+}
+
+var _ worker.QueueBackend = (*Queue)(nil)
@@ -0,0 +1,151 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/worker"
+)
+
+func TestQueueLeaseLifecycle(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTestClient(t)
+	q := NewQueue(client, "jobs", "")
+	now := time.UnixMilli(1_700_000_000_000)
+	q.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b"} {
+		if err := q.Enqueue(ctx, id, []byte("payload-"+id)); err != nil {
+			t.Fatalf("Enqueue %s: %v", id, err)
+		}
+		now = now.Add(time.Millisecond)
+	}
+	if err := q.Enqueue(ctx, "a", nil); err == nil {
+		t.Fatal("duplicate Enqueue should fail")
+	}
+
+	a, err := q.Dequeue(ctx, time.Minute)
+	if err != nil || a == nil || a.ID != "a" || string(a.Payload) != "payload-a" || a.Attempts != 1 {
+		t.Fatalf("Dequeue = %+v, %v", a, err)
+	}
+	b, _ := q.Dequeue(ctx, time.Hour)
+	if b == nil || b.ID != "b" {
+		t.Fatalf("second Dequeue = %+v", b)
+	}
+	if none, err := q.Dequeue(ctx, time.Minute); none != nil || err != nil {
+		t.Fatalf("leased tasks must be hidden, got %+v, %v", none, err)
+	}
+
+	// An expired lease is redelivered and the stale one is rejected.
+	now = now.Add(2 * time.Minute)
+	again, _ := q.Dequeue(ctx, time.Minute)
+	if again == nil || again.ID != "a" || again.Attempts != 2 || again.Lease == a.Lease {
+		t.Fatalf("redelivery = %+v", again)
+	}
+	if err := q.Ack(ctx, a); !errors.Is(err, worker.ErrLeaseLost) {
+		t.Fatalf("stale Ack = %v, want ErrLeaseLost", err)
+	}
+
+	if err := q.Retry(ctx, again, now.Add(time.Second), "transient"); err != nil {
+		t.Fatalf("Retry: %v", err)
+	}
+	if err := q.Extend(ctx, again, time.Minute); !errors.Is(err, worker.ErrLeaseLost) {
+		t.Fatalf("Extend after Retry = %v, want ErrLeaseLost", err)
+	}
+	now = now.Add(time.Second)
+	third, _ := q.Dequeue(ctx, time.Minute)
+	if third == nil || third.ID != "a" || third.LastError != "transient" {
+		t.Fatalf("retried Dequeue = %+v", third)
+	}
+	if err := q.DeadLetter(ctx, third, "poison"); err != nil {
+		t.Fatalf("DeadLetter: %v", err)
+	}
+
+	if none, _ := q.Dequeue(ctx, time.Minute); none != nil {
+		t.Fatalf("dead letter was delivered: %+v", none)
+	}
+	if err := q.Ack(ctx, b); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	if n, _ := q.Len(ctx); n != 0 {
+		t.Fatalf("Len = %d, want 0", n)
+	}
+	dead, err := q.DeadLetters(ctx, 10)
+	if err != nil || len(dead) != 1 || dead[0].ID != "a" || dead[0].Attempts != 3 || dead[0].LastError != "poison" {
+		t.Fatalf("DeadLetters = %+v, %v", dead, err)
+	}
+}
+
+func TestQueueUsesOnlyDeclaredKeys(t *testing.T) {
+	t.Parallel()
+
+	client, mini := newTestClient(t)
+	q := NewQueue(client, "jobs", "")
+	ctx := context.Background()
+	if err := q.Enqueue(ctx, "a", []byte("x")); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	task, err := q.Dequeue(ctx, time.Minute)
+	if err != nil || task == nil {
+		t.Fatalf("Dequeue = %+v, %v", task, err)
+	}
+	if err := q.Retry(ctx, task, time.Now(), "later"); err != nil {
+		t.Fatalf("Retry: %v", err)
+	}
+
+	// Scripts only reach keys passed in KEYS, so Redis Cluster can route them.
+	declared := make(map[string]bool)
+	for _, key := range q.queueKeys() {
+		if !strings.Contains(key, "{jobs}") {
+			t.Fatalf("key %q lacks the queue hash tag", key)
+		}
+		declared[key] = true
+	}
+	for _, key := range mini.Keys() {
+		if !declared[key] {
+			t.Fatalf("queue wrote undeclared key %q", key)
+		}
+	}
+}
+
+func TestQueueBacksDurablePool(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTestClient(t)
+	q := NewQueue(client, "emails", "svc:")
+	var failed atomic.Bool
+	h := worker.HandlerFunc[string, string](func(_ context.Context, to string, emit func(worker.Event[string])) error {
+		if failed.CompareAndSwap(false, true) {
+			return errors.New("smtp unavailable")
+		}
+		emit(worker.PartialEvent("sent to " + to))
+		return nil
+	})
+	pool := worker.NewPool(h, worker.PoolConfig{
+		Name: "emails",
+		Size: 2,
+		Durable: &worker.DurableConfig{
+			Backend:      q,
+			PollInterval: 5 * time.Millisecond,
+			BackoffBase:  time.Millisecond,
+		},
+	})
+	defer func() { _ = pool.Stop(context.Background()) }()
+
+	handle, err := pool.Submit(context.Background(), "ada@example.com")
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if _, err := handle.Result(); err != nil {
+		t.Fatalf("Result: %v", err)
+	}
+	if n, _ := q.Len(context.Background()); n != 0 {
+		t.Fatalf("Len after success = %d, want 0", n)
+	}
+}
@@ -0,0 +1,133 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/kbukum/gokit/resilience"
+)
+
+// DefaultRateLimitPrefix namespaces rate-limit state keys when none is configured.
+const DefaultRateLimitPrefix = "gokit:ratelimit:"
+
+// rateLimitScript applies one resilience.RateLimit to the hash at KEYS[1]
+// using the server clock, so replicas with skewed clocks still agree.
+// ARGV: algorithm, limit, interval in microseconds. It returns allowed (0/1),
+// remaining, retry-after and reset-after in microseconds. Timestamps are
+// stored with %.0f because Lua's default number formatting keeps only 14
+// significant digits.
+var rateLimitScript = goredis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local algorithm = ARGV[1]
+local limit = tonumber(ARGV[2])
+local interval = tonumber(ARGV[3])
+local key = KEYS[1]
+local allowed, remaining, retry, reset, ttl = 0, 0, 0, 0, interval
+
+if algorithm == 'gcra' then
+  local emission = interval / limit
+  local tat = tonumber(redis.call('HGET', key, 'tat') or now)
+  if tat < now then tat = now end
+  local allow_at = tat + emission - interval
+  if now < allow_at then
+    retry = allow_at - now
+  else
+    tat = tat + emission
+    allowed = 1
+    remaining = math.floor((interval - (tat - now)) / emission)
+    redis.call('HSET', key, 'tat', string.format('%.0f', tat))
+  end
+  reset = tat - now
+  ttl = tat - now
+elseif algorithm == 'sliding_window' then
+  local window = now - (now % interval)
+  local state = redis.call('HMGET', key, 'start', 'prev', 'curr')
+  local start = tonumber(state[1] or window)
+  local prev = tonumber(state[2] or 0)
+  local curr = tonumber(state[3] or 0)
+  if start ~= window then
+    if window - start == interval then prev = curr else prev = 0 end
+    curr = 0
+  end
+  local elapsed = now - window
+  local estimate = prev * (1 - elapsed / interval) + curr
+  if estimate + 1 > limit then
+    if curr + 1 > limit then
+      retry = window + interval - now + interval * (1 - (limit - 1) / curr)
+    else
+      retry = interval * (1 - (limit - 1 - curr) / prev) - elapsed
+    end
+    if retry < 1000 then retry = 1000 end
+  else
+    curr = curr + 1
+    allowed = 1
+    remaining = math.max(0, math.floor(limit - estimate - 1))
+  end
+  redis.call('HSET', key, 'start', string.format('%.0f', window), 'prev', prev, 'curr', curr)
+  reset = window + 2 * interval - now
+  ttl = 2 * interval
+else
+  local rate = limit / interval
+  local state = redis.call('HMGET', key, 'tokens', 'ts')
+  local tokens = tonumber(state[1] or limit)
+  local last = tonumber(state[2] or now)
+  tokens = math.min(limit, tokens + (now - last) * rate)
+  if tokens >= 1 then
+    tokens = tokens - 1
+    allowed = 1
+    remaining = math.floor(tokens)
+    reset = (limit - remaining) / rate
+  else
+    retry = (1 - tokens) / rate
+    reset = limit / rate
+  end
+  redis.call('HSET', key, 'tokens', string.format('%.6f', tokens), 'ts', string.format('%.0f', now))
+end
+
+redis.call('PEXPIRE', key, math.ceil(ttl / 1000) + 1)
+return {allowed, remaining, math.ceil(retry), math.ceil(reset)}
+`)
+
+// RateLimitStore keeps resilience.KeyedRateLimiter state in Redis so every
+// replica shares one budget per key. Each Take is a single atomic script call.
+type RateLimitStore struct {
+	rdb    *goredis.Client
+	prefix string
+}
+
+// NewRateLimitStore creates a rate-limit store on client's connection pool.
+func NewRateLimitStore(client *Client, prefix string) *RateLimitStore {
+	if prefix == "" {
+		prefix = DefaultRateLimitPrefix
+	}
+	return &RateLimitStore{rdb: client.rdb, prefix: prefix}
+}
+
+// Take applies limit to key and consumes one request when allowed.
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit resilience.RateLimit) (resilience.RateLimitDecision, error) {
+	algorithm := limit.Algorithm
+	if algorithm == "" {
+		algorithm = resilience.RateLimitTokenBucket
+	}
+	res, err := rateLimitScript.Run(ctx, s.rdb, []string{s.prefix + key},
+		string(algorithm), limit.Limit, limit.Interval.Microseconds()).Int64Slice()
+	if err != nil {
+		return resilience.RateLimitDecision{}, fmt.Errorf("redis rate limit %q: %w", key, err)
+	}
+	if len(res) != 4 {
+		return resilience.RateLimitDecision{}, fmt.Errorf("redis rate limit %q: unexpected reply %v", key, res)
+	}
+	return resilience.RateLimitDecision{
+		Allowed:    res[0] == 1,
+		Limit:      limit.Limit,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Microsecond,
+		ResetAt:    time.Now().Add(time.Duration(res[3]) * time.Microsecond),
+	}, nil
+}
+
+var _ resilience.RateLimitStore = (*RateLimitStore)(nil)
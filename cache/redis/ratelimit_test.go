@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/resilience"
+)
+
+func TestRateLimitStoreAlgorithms(t *testing.T) {
+	t.Parallel()
+
+	base := time.Unix(1_700_000_040, 0)
+	cases := []struct {
+		algorithm resilience.RateLimitAlgorithm
+		retry     time.Duration
+	}{
+		{resilience.RateLimitTokenBucket, 15 * time.Second},
+		{resilience.RateLimitGCRA, 15 * time.Second},
+		// The full window is spent, so it waits into the next one until the
+		// weighted count drops below the limit.
+		{resilience.RateLimitSlidingWindow, 75 * time.Second},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.algorithm), func(t *testing.T) {
+			t.Parallel()
+
+			client, mini := newTestClient(t)
+			mini.SetTime(base)
+			store := NewRateLimitStore(client, "")
+			limit := resilience.RateLimit{Limit: 4, Interval: time.Minute, Algorithm: tc.algorithm}
+			ctx := context.Background()
+
+			for i := range 4 {
+				d, err := store.Take(ctx, "k", limit)
+				if err != nil || !d.Allowed || d.Remaining != 3-i {
+					t.Fatalf("request %d = %+v, %v", i, d, err)
+				}
+			}
+			d, err := store.Take(ctx, "k", limit)
+			if err != nil || d.Allowed {
+				t.Fatalf("over limit = %+v, %v", d, err)
+			}
+			if d.RetryAfter != tc.retry {
+				t.Fatalf("RetryAfter = %v, want %v", d.RetryAfter, tc.retry)
+			}
+			if ttl := mini.TTL(DefaultRateLimitPrefix + "k"); ttl <= 0 {
+				t.Fatalf("state key TTL = %v", ttl)
+			}
+
+			mini.SetTime(base.Add(d.RetryAfter))
+			if d, err := store.Take(ctx, "k", limit); err != nil || !d.Allowed {
+				t.Fatalf("after RetryAfter = %+v, %v", d, err)
+			}
+		})
+	}
+}
+
+func TestRateLimitStoreSharesBudgetAcrossLimiters(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTestClient(t)
+	store := NewRateLimitStore(client, "svc:")
+	replicas := make([]*resilience.KeyedRateLimiter, 2)
+	for i := range replicas {
+		replicas[i] = resilience.NewKeyedRateLimiter(resilience.KeyedRateLimiterConfig{
+			Store:     store,
+			Algorithm: resilience.RateLimitGCRA,
+		})
+		t.Cleanup(replicas[i].Stop)
+	}
+
+	allowed := 0
+	for i := range 10 {
+		if replicas[i%2].Allow("client-1", 4, time.Minute).Allowed {
+			allowed++
+		}
+	}
+	if allowed != 4 {
+		t.Fatalf("allowed %d requests across replicas, want 4", allowed)
+	}
+}
+
+func TestRateLimitStoreReportsErrors(t *testing.T) {
+	t.Parallel()
+
+	client, mini := newTestClient(t)
+	mini.Close()
+	store := NewRateLimitStore(client, "")
+	if _, err := store.Take(context.Background(), "k", resilience.RateLimit{Limit: 1, Interval: time.Second}); err == nil {
+		t.Fatal("Take succeeded against a closed server")
+	}
+}
@@ -0,0 +1,219 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/kbukum/gokit/codec"
+	"github.com/kbukum/gokit/stateful"
+)
+
+// DefaultListStorePrefix namespaces list store keys when none is configured.
+const DefaultListStorePrefix = "gokit:stateful:"
+
+// listAppendScript appends ARGV[1] to list KEYS[1], records activity time
+// ARGV[2] in hash KEYS[2], and trims the list to its newest ARGV[3] values
+// (0 = unbounded), returning the evicted ones. Both keys expire after ARGV[4]
+// milliseconds without activity (0 = never).
+var listAppendScript = goredis.NewScript(`
+redis.call('RPUSH', KEYS[1], ARGV[1])
+redis.call('HSET', KEYS[2], 'last_activity', ARGV[2])
+local evicted = {}
+local max = tonumber(ARGV[3])
+if max > 0 then
+  local n = redis.call('LLEN', KEYS[1])
+  if n > max then
+    evicted = redis.call('LRANGE', KEYS[1], 0, n - max - 1)
+    redis.call('LTRIM', KEYS[1], n - max, -1)
+  end
+end
+if tonumber(ARGV[4]) > 0 then
+  redis.call('PEXPIRE', KEYS[1], ARGV[4])
+  redis.call('PEXPIRE', KEYS[2], ARGV[4])
+end
+return evicted
+`)
+
+// listFlushScript returns and deletes list KEYS[1], recording activity time
+// ARGV[1] in hash KEYS[2], which expires after ARGV[2] milliseconds (0 = never).
+var listFlushScript = goredis.NewScript(`
+local values = redis.call('LRANGE', KEYS[1], 0, -1)
+redis.call('DEL', KEYS[1])
+redis.call('HSET', KEYS[2], 'last_activity', ARGV[1])
+if tonumber(ARGV[2]) > 0 then
+  redis.call('PEXPIRE', KEYS[2], ARGV[2])
+end
+return values
+`)
+
+// ListStoreConfig configures ListStores.
+type ListStoreConfig struct {
+	// Prefix namespaces keys. Default: DefaultListStorePrefix.
+	Prefix string
+	// Codec encodes values. Default: codec.CompactJSON().
+	Codec codec.Codec
+	// Expire deletes a key's values and activity time after this long
+	// without activity, so abandoned keys do not pile up (0 = keep).
+	Expire time.Duration
+}
+
+// ListStores opens a stateful.Store per key on Redis, each a list of encoded
+// values plus a hash holding its last activity time. Every operation is a
+// single command or script, so replicas can share keys: a value is returned
+// by exactly one Flush and evicted by exactly one AppendFIFO. Activity uses
+// the caller's clock. Keys share a hash tag so the scripts work on Redis
+// Cluster, but Keys only scans the node the client is connected to.
+type ListStores[V any] struct {
+	rdb    *goredis.Client
+	prefix string
+	codec  codec.Codec
+	expire time.Duration
+	now    func() time.Time
+}
+
+// NewListStores creates list stores on client's connection pool.
+func NewListStores[V any](client *Client, cfg ListStoreConfig) *ListStores[V] {
+	if cfg.Prefix == "" {
+		cfg.Prefix = DefaultListStorePrefix
+	}
+	if cfg.Codec == nil {
+		cfg.Codec = codec.CompactJSON()
+	}
+	return &ListStores[V]{rdb: client.rdb, prefix: cfg.Prefix, codec: cfg.Codec, expire: cfg.Expire, now: time.Now}
+}
+
+// Store returns the store for key. It holds no resources of its own.
+func (s *ListStores[V]) Store(key string) *ListStore[V] {
+	base := s.prefix + "{" + key + "}:"
+	return &ListStore[V]{stores: s, key: key, values: base + "values", meta: base + "meta"}
+}
+
+// Keys implements stateful.KeyLister, returning the keys holding values.
+func (s *ListStores[V]) Keys(ctx context.Context) ([]string, error) {
+	var keys []string
+	iter := s.rdb.Scan(ctx, 0, s.prefix+"{*}:values", 0).Iterator()
+	for iter.Next(ctx) {
+		name := strings.TrimPrefix(iter.Val(), s.prefix+"{")
+		keys = append(keys, strings.TrimSuffix(name, "}:values"))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("redis list stores keys: %w", err)
+	}
+	return keys, nil
+}
+
+// ListStore is the stateful.Store for one key of ListStores.
+type ListStore[V any] struct {
+	stores *ListStores[V]
+	key    string
+	values string
+	meta   string
+}
+
+// Append implements stateful.Store.
+func (s *ListStore[V]) Append(ctx context.Context, value V) error {
+	_, err := s.AppendFIFO(ctx, value, 0)
+	return err
+}
+
+// AppendFIFO implements stateful.Store. Appending and evicting are one
+// atomic script.
+func (s *ListStore[V]) AppendFIFO(ctx context.Context, value V, maxSize int) ([]V, error) {
+	encoded, err := codec.Encode(s.stores.codec, value)
+	if err != nil {
+		return nil, fmt.Errorf("redis list store %q encode: %w", s.key, err)
+	}
+	evicted, err := listAppendScript.Run(ctx, s.stores.rdb, []string{s.values, s.meta},
+		encoded, s.stores.now().UnixMilli(), max(maxSize, 0), s.stores.expire.Milliseconds()).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("redis list store %q append: %w", s.key, err)
+	}
+	if len(evicted) == 0 {
+		return nil, nil
+	}
+	return s.decode(evicted)
+}
+
+// Get implements stateful.Store.
+func (s *ListStore[V]) Get(ctx context.Context) ([]V, error) {
+	raw, err := s.stores.rdb.LRange(ctx, s.values, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis list store %q get: %w", s.key, err)
+	}
+	return s.decode(raw)
+}
+
+// Flush implements stateful.Store.
+func (s *ListStore[V]) Flush(ctx context.Context) ([]V, error) {
+	raw, err := listFlushScript.Run(ctx, s.stores.rdb, []string{s.values, s.meta},
+		s.stores.now().UnixMilli(), s.stores.expire.Milliseconds()).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("redis list store %q flush: %w", s.key, err)
+	}
+	return s.decode(raw)
+}
+
+// Size implements stateful.Store.
+func (s *ListStore[V]) Size(ctx context.Context) (int, error) {
+	n, err := s.stores.rdb.LLen(ctx, s.values).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis list store %q size: %w", s.key, err)
+	}
+	return int(n), nil
+}
+
+// Touch implements stateful.Store.
+func (s *ListStore[V]) Touch(ctx context.Context) error {
+	pipe := s.stores.rdb.TxPipeline()
+	pipe.HSet(ctx, s.meta, "last_activity", s.stores.now().UnixMilli())
+	if s.stores.expire > 0 {
+		pipe.PExpire(ctx, s.values, s.stores.expire)
+		pipe.PExpire(ctx, s.meta, s.stores.expire)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis list store %q touch: %w", s.key, err)
+	}
+	return nil
+}
+
+// LastActivity implements stateful.Store.
+func (s *ListStore[V]) LastActivity(ctx context.Context) (time.Time, error) {
+	raw, err := s.stores.rdb.HGet(ctx, s.meta, "last_activity").Result()
+	if errors.Is(err, goredis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("redis list store %q last activity: %w", s.key, err)
+	}
+	ms, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("redis list store %q last activity: %w", s.key, err)
+	}
+	return time.UnixMilli(ms), nil
+}
+
+// Close implements stateful.Store. Values stay in Redis.
+func (s *ListStore[V]) Close() error { return nil }
+
+func (s *ListStore[V]) decode(raw []string) ([]V, error) {
+	values := make([]V, len(raw))
+	for i, r := range raw {
+		v, err := codec.Decode[V](s.stores.codec, r)
+		if err != nil {
+			return nil, fmt.Errorf("redis list store %q decode: %w", s.key, err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+var (
+	_ stateful.Store[int] = (*ListStore[int])(nil)
+	_ stateful.KeyLister  = (*ListStores[int])(nil)
+)
@@ -0,0 +1,135 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/stateful"
+)
+
+type chatTurn struct {
+	Role string `json:"role"`
+	Text string `json:"text"`
+}
+
+func TestListStoreAppendFlush(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTestClient(t)
+	stores := NewListStores[chatTurn](client, ListStoreConfig{})
+	now := time.UnixMilli(1_700_000_000_000)
+	stores.now = func() time.Time { return now }
+	store := stores.Store("session-1")
+	ctx := context.Background()
+
+	if last, err := store.LastActivity(ctx); err != nil || !last.IsZero() {
+		t.Fatalf("LastActivity on new store = %v, %v; want zero", last, err)
+	}
+	turns := []chatTurn{{"user", "hi"}, {"assistant", "hello"}}
+	for _, turn := range turns {
+		if err := store.Append(ctx, turn); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if n, _ := store.Size(ctx); n != 2 {
+		t.Fatalf("Size = %d, want 2", n)
+	}
+	if got, _ := store.Get(ctx); !reflect.DeepEqual(got, turns) {
+		t.Fatalf("Get = %v, want %v", got, turns)
+	}
+	if last, _ := store.LastActivity(ctx); !last.Equal(now) {
+		t.Fatalf("LastActivity = %v, want %v", last, now)
+	}
+
+	// Another replica sees the same values through its own store.
+	other := NewListStores[chatTurn](client, ListStoreConfig{}).Store("session-1")
+	got, err := other.Flush(ctx)
+	if err != nil || !reflect.DeepEqual(got, turns) {
+		t.Fatalf("Flush = %v, %v; want %v", got, err, turns)
+	}
+	if got, _ := store.Flush(ctx); len(got) != 0 {
+		t.Fatalf("second Flush = %v, want empty", got)
+	}
+}
+
+func TestListStoreAppendFIFO(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTestClient(t)
+	store := NewListStores[int](client, ListStoreConfig{}).Store("k")
+	ctx := context.Background()
+
+	var evicted []int
+	for i := range 5 {
+		ev, err := store.AppendFIFO(ctx, i, 3)
+		if err != nil {
+			t.Fatalf("AppendFIFO: %v", err)
+		}
+		evicted = append(evicted, ev...)
+	}
+	if !reflect.DeepEqual(evicted, []int{0, 1}) {
+		t.Fatalf("evicted = %v, want [0 1]", evicted)
+	}
+	if got, _ := store.Get(ctx); !reflect.DeepEqual(got, []int{2, 3, 4}) {
+		t.Fatalf("Get = %v, want [2 3 4]", got)
+	}
+}
+
+func TestListStoresKeysAndExpire(t *testing.T) {
+	t.Parallel()
+
+	client, mini := newTestClient(t)
+	stores := NewListStores[int](client, ListStoreConfig{Prefix: "chat:", Expire: time.Minute})
+	ctx := context.Background()
+	_ = stores.Store("a").Append(ctx, 1)
+	_ = stores.Store("b").Append(ctx, 2)
+	_, _ = stores.Store("c").Flush(ctx)
+
+	keys, err := stores.Keys(ctx)
+	if err != nil {
+		t.Fatalf("Keys: %v", err)
+	}
+	slices.Sort(keys)
+	if !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Fatalf("Keys = %v, want [a b]", keys)
+	}
+
+	mini.FastForward(2 * time.Minute)
+	if keys, _ := stores.Keys(ctx); len(keys) != 0 {
+		t.Fatalf("Keys after expiry = %v, want none", keys)
+	}
+}
+
+func TestListStoresRecoverWithManager(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTestClient(t)
+	stores := NewListStores[int](client, ListStoreConfig{})
+	ctx := context.Background()
+	stores.now = func() time.Time { return time.Now().Add(-time.Hour) }
+	_ = stores.Store("stale").Append(ctx, 7)
+	stores.now = time.Now
+
+	flushed := make(chan []int, 1)
+	mgr := stateful.NewManager(func(key string) *stateful.Accumulator[int] {
+		return stateful.NewAccumulator[int](stores.Store(key), stateful.Config[int]{
+			TTL:       time.Minute,
+			KeepAlive: true,
+			OnFlush: func(_ context.Context, values []int) error {
+				flushed <- values
+				return nil
+			},
+		})
+	}, 0)
+	defer mgr.Close()
+
+	if _, err := mgr.Recover(ctx, stores, func(s string) (string, error) { return s, nil }); err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if got := <-flushed; !reflect.DeepEqual(got, []int{7}) {
+		t.Fatalf("flushed %v, want [7]", got)
+	}
+}
@@ -0,0 +1,156 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/kbukum/gokit/cache"
+)
+
+// DefaultTagPrefix namespaces tag membership sets when Config.TagPrefix is empty.
+const DefaultTagPrefix = "gokit:tag:"
+
+// scanBatch is the SCAN/SSCAN page size and the DEL batch size for bulk
+// invalidation.
+const scanBatch = 500
+
+// setWithTagsScript writes the value and adds the key to each tag set. A tag
+// set expires with the longest-lived member it has seen; a member without TTL
+// makes the set persistent.
+var setWithTagsScript = goredis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+  redis.call('SET', KEYS[1], ARGV[1], 'PX', ttl)
+else
+  redis.call('SET', KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+  local current = redis.call('PTTL', KEYS[i])
+  redis.call('SADD', KEYS[i], KEYS[1])
+  if ttl == 0 then
+    redis.call('PERSIST', KEYS[i])
+  elseif current == -2 or (current >= 0 and current < ttl) then
+    redis.call('PEXPIRE', KEYS[i], ttl)
+  end
+end
+return 1
+`)
+
+// claimTagScript renames a tag set away for invalidation and reports 0 when
+// the tag has no set, without relying on the server's error wording.
+var claimTagScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+  return 0
+end
+redis.call('RENAME', KEYS[1], KEYS[2])
+return 1
+`)
+
+// SetWithTags stores value and records key in one Redis set per tag, in a
+// single script call. All keys must hash to the same slot on Redis Cluster.
+//
+// Tag sets only grow: re-writing key without a tag leaves it in that tag's
+// set until InvalidateTag runs or the set expires.
+func (c *Client) SetWithTags(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	if len(tags) == 0 {
+		return c.Set(ctx, key, value, ttl)
+	}
+	keys := make([]string, 0, len(tags)+1)
+	keys = append(keys, key)
+	for _, tag := range tags {
+		keys = append(keys, c.tagKey(tag))
+	}
+	ms := ttl.Milliseconds()
+	if ttl > 0 && ms == 0 {
+		ms = 1
+	}
+	if ms < 0 {
+		ms = 0
+	}
+	if err := setWithTagsScript.Run(ctx, c.rdb, keys, value, ms).Err(); err != nil {
+		return fmt.Errorf("redis set with tags %q: %w", key, err)
+	}
+	return nil
+}
+
+// InvalidateTag deletes every key recorded under tag. The tag set is renamed
+// away first, so keys tagged while the invalidation runs land in a fresh set
+// and survive.
+func (c *Client) InvalidateTag(ctx context.Context, tag string) error {
+	tagKey := c.tagKey(tag)
+	pending := tagKey + ":invalidating:" + rand.Text()
+	claimed, err := claimTagScript.Run(ctx, c.rdb, []string{tagKey, pending}).Int()
+	if err != nil {
+		return fmt.Errorf("redis invalidate tag %q: %w", tag, err)
+	}
+	if claimed == 0 {
+		return nil
+	}
+	iter := c.rdb.SScan(ctx, pending, 0, "", scanBatch).Iterator()
+	if err := c.deleteAll(ctx, iter); err != nil {
+		return fmt.Errorf("redis invalidate tag %q: %w", tag, err)
+	}
+	if err := c.rdb.Del(ctx, pending).Err(); err != nil {
+		return fmt.Errorf("redis invalidate tag %q: %w", tag, err)
+	}
+	return nil
+}
+
+// DeletePrefix deletes every key starting with prefix using SCAN, so it does
+// not block the server but is not atomic: keys written during the scan may
+// survive.
+func (c *Client) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := c.rdb.Scan(ctx, 0, escapeGlob(prefix)+"*", scanBatch).Iterator()
+	if err := c.deleteAll(ctx, iter); err != nil {
+		return fmt.Errorf("redis delete prefix %q: %w", prefix, err)
+	}
+	return nil
+}
+
+func (c *Client) deleteAll(ctx context.Context, iter *goredis.ScanIterator) error {
+	batch := make([]string, 0, scanBatch)
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == scanBatch {
+			if err := c.rdb.Del(ctx, batch...).Err(); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return c.rdb.Del(ctx, batch...).Err()
+}
+
+func (c *Client) tagKey(tag string) string {
+	prefix := c.cfg.TagPrefix
+	if prefix == "" {
+		prefix = DefaultTagPrefix
+	}
+	return prefix + tag
+}
+
+// escapeGlob quotes the SCAN MATCH metacharacters in s.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var _ cache.TaggedStore = (*Client)(nil)
@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestClientSetWithTagsAndInvalidateTag(t *testing.T) {
+	t.Parallel()
+
+	client, mini := newTestClient(t)
+	ctx := context.Background()
+	for _, key := range []string{"a", "b"} {
+		if err := client.SetWithTags(ctx, key, []byte("v"), time.Minute, []string{"tenant:1"}); err != nil {
+			t.Fatalf("SetWithTags %s: %v", key, err)
+		}
+	}
+	if err := client.SetWithTags(ctx, "c", []byte("v"), 0, []string{"tenant:2"}); err != nil {
+		t.Fatalf("SetWithTags c: %v", err)
+	}
+	if ttl := mini.TTL(DefaultTagPrefix + "tenant:1"); ttl != time.Minute {
+		t.Fatalf("tag set TTL = %v, want %v", ttl, time.Minute)
+	}
+	if ttl := mini.TTL(DefaultTagPrefix + "tenant:2"); ttl != 0 {
+		t.Fatalf("tag set of persistent key has TTL %v", ttl)
+	}
+
+	if err := client.InvalidateTag(ctx, "tenant:1"); err != nil {
+		t.Fatalf("InvalidateTag: %v", err)
+	}
+	for key, want := range map[string]bool{"a": false, "b": false, "c": true} {
+		if ok, _ := client.Exists(ctx, key); ok != want {
+			t.Fatalf("Exists(%s) = %v, want %v", key, ok, want)
+		}
+	}
+	if mini.Exists(DefaultTagPrefix + "tenant:1") {
+		t.Fatal("tag set survived invalidation")
+	}
+	if err := client.InvalidateTag(ctx, "unknown"); err != nil {
+		t.Fatalf("InvalidateTag unknown: %v", err)
+	}
+}
+
+func TestClientTagSetKeepsLongestTTL(t *testing.T) {
+	t.Parallel()
+
+	client, mini := newTestClient(t)
+	ctx := context.Background()
+	tags := []string{"t"}
+	if err := client.SetWithTags(ctx, "long", []byte("v"), time.Hour, tags); err != nil {
+		t.Fatalf("SetWithTags long: %v", err)
+	}
+	if err := client.SetWithTags(ctx, "short", []byte("v"), time.Second, tags); err != nil {
+		t.Fatalf("SetWithTags short: %v", err)
+	}
+	if ttl := mini.TTL(DefaultTagPrefix + "t"); ttl != time.Hour {
+		t.Fatalf("tag set TTL = %v, want %v", ttl, time.Hour)
+	}
+}
+
+func TestClientDeletePrefix(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+	for i := range scanBatch + 10 {
+		if err := client.Set(ctx, fmt.Sprintf("user:%d", i), []byte("v"), 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+	for _, key := range []string{"order:1", "user*literal", "userX"} {
+		if err := client.Set(ctx, key, []byte("v"), 0); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+
+	if err := client.DeletePrefix(ctx, "user:"); err != nil {
+		t.Fatalf("DeletePrefix: %v", err)
+	}
+	if ok, _ := client.Exists(ctx, "user:0"); ok {
+		t.Fatal("prefixed key survived")
+	}
+	if err := client.DeletePrefix(ctx, "user*"); err != nil {
+		t.Fatalf("DeletePrefix glob: %v", err)
+	}
+	for key, want := range map[string]bool{"order:1": true, "user*literal": false, "userX": true} {
+		if ok, _ := client.Exists(ctx, key); ok != want {
+			t.Fatalf("Exists(%s) = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestEscapeGlob(t *testing.T) {
+	t.Parallel()
+
+	if got := escapeGlob(`a*b?[c]\`); got != `a\*b\?\[c\]\\` {
+		t.Fatalf("escapeGlob = %q", got)
+	}
+}
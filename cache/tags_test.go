@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTagsAndPrefixes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewMemoryStore(MemoryConfig{})
+	for key, tags := range map[string][]string{
+		"t1:a": {"tenant:1"},
+		"t1:b": {"tenant:1", "hot"},
+		"t2:a": {"tenant:2", "hot"},
+	} {
+		if err := store.SetWithTags(ctx, key, []byte("v"), 0, tags); err != nil {
+			t.Fatalf("SetWithTags %s: %v", key, err)
+		}
+	}
+
+	if err := store.InvalidateTag(ctx, "hot"); err != nil {
+		t.Fatalf("InvalidateTag: %v", err)
+	}
+	assertKeys(t, store, map[string]bool{"t1:a": true, "t1:b": false, "t2:a": false})
+
+	// A plain Set drops the key's earlier tags.
+	if err := store.Set(ctx, "t1:a", []byte("v2"), 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := store.InvalidateTag(ctx, "tenant:1"); err != nil {
+		t.Fatalf("InvalidateTag: %v", err)
+	}
+	assertKeys(t, store, map[string]bool{"t1:a": true})
+	if len(store.tags) != 0 {
+		t.Fatalf("tag index leaked: %v", store.tags)
+	}
+
+	for _, key := range []string{"p:1", "p:2", "q:1"} {
+		if err := store.Set(ctx, key, []byte("v"), 0); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+	if err := store.DeletePrefix(ctx, "p:"); err != nil {
+		t.Fatalf("DeletePrefix: %v", err)
+	}
+	assertKeys(t, store, map[string]bool{"p:1": false, "p:2": false, "q:1": true})
+	if got := store.Stats().Bytes; got != entrySize("q:1", []byte("v"))+entrySize("t1:a", []byte("v2")) {
+		t.Fatalf("bytes after bulk delete = %d", got)
+	}
+}
+
+func TestMemoryStoreEvictionDropsTags(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewMemoryStore(MemoryConfig{MaxEntries: 1})
+	if err := store.SetWithTags(ctx, "a", []byte("v"), 0, []string{"t"}); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+	if err := store.Set(ctx, "b", []byte("v"), 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if len(store.tags) != 0 {
+		t.Fatalf("evicted key left tag index %v", store.tags)
+	}
+}
+
+func TestTypedStoreTagsAndPrefixes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewMemoryStore(MemoryConfig{})
+	users := NewTypedStore[testState](store, "user")
+	orders := NewTypedStore[testState](store, "order")
+
+	if err := users.SaveWithTags(ctx, "1", &testState{Count: 1}, 0, "tenant:1"); err != nil {
+		t.Fatalf("SaveWithTags: %v", err)
+	}
+	if _, err := orders.GetOrLoad(ctx, "9", func(context.Context, string) (*testState, error) {
+		return &testState{Count: 9}, nil
+	}, time.Minute, WithTags("tenant:1"), WithSoftTTL(time.Second)); err != nil {
+		t.Fatalf("GetOrLoad: %v", err)
+	}
+	if err := users.Save(ctx, "2", &testState{Count: 2}, 0); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	if err := orders.InvalidateTag(ctx, "tenant:1"); err != nil {
+		t.Fatalf("InvalidateTag: %v", err)
+	}
+	assertKeys(t, store, map[string]bool{"user:1": false, "order:9": false, "user:2": true})
+
+	if err := orders.Save(ctx, "1", &testState{}, 0); err != nil {
+		t.Fatalf("Save order: %v", err)
+	}
+	if err := users.DeletePrefix(ctx, ""); err != nil {
+		t.Fatalf("DeletePrefix: %v", err)
+	}
+	assertKeys(t, store, map[string]bool{"user:2": false, "order:1": true})
+}
+
+func TestTypedStoreTagsRequireTaggedStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	typed := NewTypedStore[testState](&typedFailingStore{}, "")
+	if err := typed.SaveWithTags(ctx, "k", &testState{}, 0, "t"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("SaveWithTags error = %v", err)
+	}
+	if err := typed.InvalidateTag(ctx, "t"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("InvalidateTag error = %v", err)
+	}
+	if err := typed.DeletePrefix(ctx, "p"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("DeletePrefix error = %v", err)
+	}
+	if err := typed.SaveWithTags(ctx, "k", &testState{}, 0); err != nil {
+		t.Fatalf("SaveWithTags without tags: %v", err)
+	}
+}
+
+func assertKeys(t *testing.T, store Store, want map[string]bool) {
+	t.Helper()
+	for key, present := range want {
+		if ok, err := store.Exists(context.Background(), key); err != nil || ok != present {
+			t.Fatalf("Exists(%s) = %v, %v; want %v", key, ok, err, present)
+		}
+	}
+}
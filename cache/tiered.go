@@ -0,0 +1,446 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kbukum/gokit/logging"
+)
+
+// ProviderTiered layers an in-process MemoryStore over a remote provider.
+const ProviderTiered = "tiered"
+
+var (
+	_ encoding.TextMarshaler   = WriteMode("")
+	_ encoding.TextUnmarshaler = (*WriteMode)(nil)
+)
+
+// WriteMode controls how TieredStore writes reach the local tier.
+type WriteMode string
+
+const (
+	// WriteThrough writes the remote tier, then caches the value locally.
+	WriteThrough WriteMode = "write_through"
+	// WriteAround writes only the remote tier and drops the local copy; the
+	// next read repopulates it. Suits write-heavy keys that are rarely re-read.
+	WriteAround WriteMode = "write_around"
+)
+
+// DefaultLocalTTL bounds how long a value may be served from the local tier
+// when no invalidation arrives.
+const DefaultLocalTTL = time.Minute
+
+// MarshalText serializes a write mode for config encoders.
+func (m WriteMode) MarshalText() ([]byte, error) {
+	mode := m
+	if mode == "" {
+		mode = WriteThrough
+	}
+	if !mode.valid() {
+		return nil, invalidWriteMode(mode)
+	}
+	return []byte(mode), nil
+}
+
+// UnmarshalText parses a write mode from config text.
+func (m *WriteMode) UnmarshalText(text []byte) error {
+	mode := WriteMode(text)
+	if mode == "" {
+		*m = WriteThrough
+		return nil
+	}
+	if !mode.valid() {
+		return invalidWriteMode(mode)
+	}
+	*m = mode
+	return nil
+}
+
+func (m WriteMode) valid() bool {
+	return m == WriteThrough || m == WriteAround
+}
+
+func invalidWriteMode(mode WriteMode) error {
+	return fmt.Errorf("cache: write mode must be one of write_through, write_around, got %q", string(mode))
+}
+
+// Invalidation announces keys whose local copies are stale. Origin identifies
+// the publishing TieredStore so it can ignore its own announcements.
+type Invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+// Invalidator carries Invalidations between TieredStore instances that share
+// a remote tier. Implementations live in transport modules (cache/redis
+// pub/sub, messaging/bridge).
+type Invalidator interface {
+	// Publish broadcasts inv to every subscribed instance.
+	Publish(ctx context.Context, inv Invalidation) error
+	// Subscribe starts delivering broadcasts to handler until Close. It returns
+	// once the subscription is active.
+	Subscribe(handler func(Invalidation)) error
+	// Close stops delivery and releases transport resources.
+	Close() error
+}
+
+// InvalidatorFactory builds an Invalidator once the remote store exists, so
+// adapters can reuse the remote connection.
+type InvalidatorFactory func(remote Store) (Invalidator, error)
+
+// TieredConfig configures the tiered provider.
+type TieredConfig struct {
+	// Local configures the in-process tier; bound it with MaxEntries/MaxBytes.
+	Local MemoryConfig `mapstructure:"local" json:"local" yaml:"local"`
+	// LocalTTL caps how long a value lives in the local tier. Defaults to DefaultLocalTTL.
+	LocalTTL time.Duration `mapstructure:"local_ttl" json:"local_ttl" yaml:"local_ttl"`
+	// Mode selects write-through (default) or write-around.
+	Mode WriteMode `mapstructure:"mode" json:"mode" yaml:"mode"`
+	// RemoteProvider names the registered provider for the remote tier.
+	RemoteProvider string `mapstructure:"remote_provider" json:"remote_provider" yaml:"remote_provider"`
+	// Remote is the provider-specific config passed to the remote factory.
+	Remote any `mapstructure:"-" json:"-" yaml:"-"`
+	// Invalidator builds the cross-instance invalidation channel. When nil,
+	// local copies are only refreshed by LocalTTL expiry.
+	Invalidator InvalidatorFactory `mapstructure:"-" json:"-" yaml:"-"`
+}
+
+// ApplyDefaults fills zero-valued fields.
+func (c *TieredConfig) ApplyDefaults() {
+	if c.LocalTTL == 0 {
+		c.LocalTTL = DefaultLocalTTL
+	}
+	if c.Mode == "" {
+		c.Mode = WriteThrough
+	}
+}
+
+// Validate checks tier settings.
+func (c *TieredConfig) Validate() error {
+	if c.LocalTTL < 0 {
+		return fmt.Errorf("cache: local_ttl must be >= 0")
+	}
+	if !c.Mode.valid() {
+		return invalidWriteMode(c.Mode)
+	}
+	if c.RemoteProvider == "" {
+		return fmt.Errorf("cache: tiered remote_provider is required")
+	}
+	if c.RemoteProvider == ProviderTiered {
+		return fmt.Errorf("cache: tiered remote_provider cannot be %q", ProviderTiered)
+	}
+	return c.Local.Validate()
+}
+
+// TieredStore is a near-cache: reads are served from a local MemoryStore when
+// possible and fall back to the remote Store, populating the local tier.
+// Writes and deletes go to the remote tier first and are then announced to
+// peer instances through the Invalidator.
+//
+// A key changed while its remote read is in flight is not cached locally:
+// each read that populates the local tier records the key's generation, which
+// invalidations, writes, and deletes bump, and skips the fill if it moved.
+type TieredStore struct {
+	local       *MemoryStore
+	remote      Store
+	localTTL    time.Duration
+	mode        WriteMode
+	invalidator Invalidator
+	origin      string
+	log         *logging.Logger
+
+	mu    sync.Mutex
+	fills map[string]*pendingFill // keys with remote reads in flight
+}
+
+// pendingFill tracks the generation of a key while remote reads of it are in
+// flight.
+type pendingFill struct {
+	readers    int
+	generation uint64
+}
+
+// NewTieredStore layers local over remote and subscribes to invalidator,
+// which may be nil. The store takes ownership of remote and invalidator.
+func NewTieredStore(local *MemoryStore, remote Store, cfg TieredConfig, invalidator Invalidator, log *logging.Logger) (*TieredStore, error) {
+	if local == nil || remote == nil {
+		return nil, fmt.Errorf("cache: tiered store requires local and remote stores")
+	}
+	cfg.ApplyDefaults()
+	if cfg.LocalTTL < 0 {
+		return nil, fmt.Errorf("cache: local_ttl must be >= 0")
+	}
+	if !cfg.Mode.valid() {
+		return nil, invalidWriteMode(cfg.Mode)
+	}
+	s := &TieredStore{
+		local:       local,
+		remote:      remote,
+		localTTL:    cfg.LocalTTL,
+		mode:        cfg.Mode,
+		invalidator: invalidator,
+		origin:      rand.Text(),
+		log:         log,
+		fills:       make(map[string]*pendingFill),
+	}
+	if invalidator != nil {
+		if err := invalidator.Subscribe(s.onInvalidation); err != nil {
+			return nil, fmt.Errorf("cache: tiered subscribe: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// RegisterTiered registers the tiered provider. The remote tier is built from
+// the same registry, so its provider must be registered as well.
+func RegisterTiered(reg *FactoryRegistry) error {
+	return reg.Register(ProviderTiered, func(cfg Config, providerCfg any, log *logging.Logger) (Store, error) {
+		tc, ok := providerCfg.(*TieredConfig)
+		if !ok {
+			return nil, &ConfigTypeError{Provider: ProviderTiered, Expected: "*cache.TieredConfig", Actual: providerCfg}
+		}
+		tieredCfg := *tc
+		tieredCfg.ApplyDefaults()
+		if err := tieredCfg.Validate(); err != nil {
+			return nil, err
+		}
+		remoteCfg := cfg
+		remoteCfg.Provider = tieredCfg.RemoteProvider
+		remote, err := New(reg, remoteCfg, tieredCfg.Remote, log)
+		if err != nil {
+			return nil, fmt.Errorf("cache: tiered remote: %w", err)
+		}
+		var invalidator Invalidator
+		if tieredCfg.Invalidator != nil {
+			if invalidator, err = tieredCfg.Invalidator(remote); err != nil {
+				closeStore(remote)
+				return nil, fmt.Errorf("cache: tiered invalidator: %w", err)
+			}
+		}
+		if tieredCfg.Local.DefaultTTL == 0 {
+			tieredCfg.Local.DefaultTTL = cfg.DefaultTTL
+		}
+		store, err := NewTieredStore(NewMemoryStore(tieredCfg.Local), remote, tieredCfg, invalidator, log)
+		if err != nil {
+			if invalidator != nil {
+				_ = invalidator.Close()
+			}
+			closeStore(remote)
+			return nil, err
+		}
+		return store, nil
+	})
+}
+
+// Get returns the local copy when present, otherwise reads the remote tier
+// and caches the result locally.
+func (s *TieredStore) Get(ctx context.Context, key string) (value []byte, found bool, err error) {
+	if value, found, _ = s.local.Get(ctx, key); found {
+		return value, true, nil
+	}
+	gen := s.startFill(key)
+	value, found, err = s.remote.Get(ctx, key)
+	if err != nil || !found {
+		s.finishFill(ctx, key, gen, nil)
+		return nil, false, err
+	}
+	s.finishFill(ctx, key, gen, value)
+	return value, true, nil
+}
+
+// Set writes the remote tier, updates or drops the local copy according to
+// the write mode, and tells peers to drop theirs.
+func (s *TieredStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := s.remote.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	s.bump(key)
+	if s.mode == WriteThrough {
+		_ = s.local.Set(ctx, key, value, s.localTTLFor(ttl))
+	} else {
+		_ = s.local.Delete(ctx, key)
+	}
+	return s.publish(ctx, key)
+}
+
+// Delete removes key from both tiers and tells peers to drop their copies.
+func (s *TieredStore) Delete(ctx context.Context, key string) error {
+	if err := s.remote.Delete(ctx, key); err != nil {
+		return err
+	}
+	s.bump(key)
+	_ = s.local.Delete(ctx, key)
+	return s.publish(ctx, key)
+}
+
+// Exists checks the local tier, then the remote tier.
+func (s *TieredStore) Exists(ctx context.Context, key string) (bool, error) {
+	if ok, _ := s.local.Exists(ctx, key); ok {
+		return true, nil
+	}
+	return s.remote.Exists(ctx, key)
+}
+
+// GetMany serves local hits and fetches the remainder from the remote tier,
+// in one round-trip when the remote implements BatchStore.
+func (s *TieredStore) GetMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	out, _ := s.local.GetMany(ctx, keys)
+	missing := make([]string, 0, len(keys)-len(out))
+	for _, key := range keys {
+		if _, ok := out[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return out, nil
+	}
+	gens := make([]uint64, len(missing))
+	for i, key := range missing {
+		gens[i] = s.startFill(key)
+	}
+	remote, err := getMany(ctx, s.remote, missing)
+	for i, key := range missing {
+		value, ok := remote[key]
+		if err != nil || !ok {
+			value = nil
+		}
+		s.finishFill(ctx, key, gens[i], value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range remote {
+		out[key] = value
+	}
+	return out, nil
+}
+
+// Local returns the in-process tier.
+func (s *TieredStore) Local() *MemoryStore { return s.local }
+
+// Remote returns the shared tier.
+func (s *TieredStore) Remote() Store { return s.remote }
+
+// Stats reports the local tier's counters.
+func (s *TieredStore) Stats() Stats { return s.local.Stats() }
+
+// Close stops invalidation delivery and closes the remote tier.
+func (s *TieredStore) Close() error {
+	var errs []error
+	if s.invalidator != nil {
+		if err := s.invalidator.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("cache: tiered invalidator close: %w", err))
+		}
+	}
+	if closer, ok := s.remote.(CloseStore); ok {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *TieredStore) publish(ctx context.Context, keys ...string) error {
+	if s.invalidator == nil {
+		return nil
+	}
+	if err := s.invalidator.Publish(ctx, Invalidation{Origin: s.origin, Keys: keys}); err != nil {
+		return fmt.Errorf("cache: tiered publish invalidation: %w", err)
+	}
+	return nil
+}
+
+func (s *TieredStore) onInvalidation(inv Invalidation) {
+	if inv.Origin == s.origin {
+		return
+	}
+	ctx := context.Background()
+	for _, key := range inv.Keys {
+		s.bump(key)
+		_ = s.local.Delete(ctx, key)
+	}
+	if s.log != nil {
+		s.log.Debug("cache: tiered invalidation applied", map[string]any{"keys": len(inv.Keys)})
+	}
+}
+
+// startFill registers a remote read of key and returns its generation.
+func (s *TieredStore) startFill(key string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.fills[key]
+	if !ok {
+		f = &pendingFill{}
+		s.fills[key] = f
+	}
+	f.readers++
+	return f.generation
+}
+
+// finishFill ends a remote read of key and caches value locally unless it is
+// nil or the key changed since startFill. The check and the local write share
+// the lock bump takes, so an invalidation either stops the fill or deletes
+// what it wrote.
+func (s *TieredStore) finishFill(ctx context.Context, key string, gen uint64, value []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.fills[key]
+	if value != nil && f.generation == gen {
+		_ = s.local.Set(ctx, key, value, s.localTTL)
+	}
+	if f.readers--; f.readers == 0 {
+		delete(s.fills, key)
+	}
+}
+
+// bump marks key changed for the remote reads in flight.
+func (s *TieredStore) bump(key string) {
+	s.mu.Lock()
+	if f, ok := s.fills[key]; ok {
+		f.generation++
+	}
+	s.mu.Unlock()
+}
+
+// localTTLFor never lets the local copy outlive the remote one.
+func (s *TieredStore) localTTLFor(ttl time.Duration) time.Duration {
+	if ttl > 0 && ttl < s.localTTL {
+		return ttl
+	}
+	return s.localTTL
+}
+
+func getMany(ctx context.Context, store Store, keys []string) (map[string][]byte, error) {
+	if batch, ok := store.(BatchStore); ok {
+		return batch.GetMany(ctx, keys)
+	}
+	out := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		value, ok, err := store.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out[key] = value
+		}
+	}
+	return out, nil
+}
+
+func closeStore(store Store) {
+	if closer, ok := store.(CloseStore); ok {
+		_ = closer.Close()
+	}
+}
+
+var (
+	_ Store      = (*TieredStore)(nil)
+	_ BatchStore = (*TieredStore)(nil)
+	_ CloseStore = (*TieredStore)(nil)
+	_ StatsStore = (*TieredStore)(nil)
+)
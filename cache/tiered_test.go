@@ -0,0 +1,339 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/logging"
+)
+
+func TestTieredStoreReadsThroughAndCachesLocally(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	remote := &countingStore{Store: NewMemoryStore(MemoryConfig{})}
+	if err := remote.Store.Set(ctx, "k", []byte("v"), 0); err != nil {
+		t.Fatalf("remote Set: %v", err)
+	}
+	store := newTestTieredStore(t, remote, TieredConfig{}, nil)
+
+	for range 3 {
+		got, ok, err := store.Get(ctx, "k")
+		if err != nil || !ok || string(got) != "v" {
+			t.Fatalf("Get = %q, %v, %v", got, ok, err)
+		}
+	}
+	if remote.gets != 1 {
+		t.Fatalf("remote gets = %d, want 1", remote.gets)
+	}
+	if _, ok, _ := store.Get(ctx, "missing"); ok {
+		t.Fatal("Get missing found a value")
+	}
+}
+
+func TestTieredStoreWriteModes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	for _, mode := range []WriteMode{WriteThrough, WriteAround} {
+		t.Run(string(mode), func(t *testing.T) {
+			t.Parallel()
+
+			remote := NewMemoryStore(MemoryConfig{})
+			store := newTestTieredStore(t, remote, TieredConfig{Mode: mode}, nil)
+			if err := store.Set(ctx, "k", []byte("v"), 0); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			if got, ok, _ := remote.Get(ctx, "k"); !ok || string(got) != "v" {
+				t.Fatalf("remote = %q, %v", got, ok)
+			}
+			_, cached, _ := store.Local().Get(ctx, "k")
+			if cached != (mode == WriteThrough) {
+				t.Fatalf("local cached = %v for mode %s", cached, mode)
+			}
+		})
+	}
+}
+
+func TestTieredStoreLocalTTLNeverOutlivesRemote(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(100, 0)
+	clock := func() time.Time { return now }
+	local := newMemoryStore(MemoryConfig{}, clock)
+	remote := newMemoryStore(MemoryConfig{}, clock)
+	store, err := NewTieredStore(local, remote, TieredConfig{LocalTTL: time.Hour}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewTieredStore: %v", err)
+	}
+	ctx := context.Background()
+	if err := store.Set(ctx, "k", []byte("v"), time.Second); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	now = now.Add(time.Second)
+	if _, ok, _ := local.Get(ctx, "k"); ok {
+		t.Fatal("local copy outlived the remote TTL")
+	}
+}
+
+func TestTieredStoreCrossInstanceInvalidation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	remote := NewMemoryStore(MemoryConfig{})
+	bus := &testInvalidationBus{}
+	a := newTestTieredStore(t, remote, TieredConfig{}, bus.invalidator())
+	b := newTestTieredStore(t, remote, TieredConfig{}, bus.invalidator())
+
+	if err := a.Set(ctx, "k", []byte("v1"), 0); err != nil {
+		t.Fatalf("a.Set: %v", err)
+	}
+	if got, _, _ := b.Get(ctx, "k"); string(got) != "v1" {
+		t.Fatalf("b.Get = %q", got)
+	}
+
+	if err := a.Set(ctx, "k", []byte("v2"), 0); err != nil {
+		t.Fatalf("a.Set v2: %v", err)
+	}
+	if got, _, _ := b.Get(ctx, "k"); string(got) != "v2" {
+		t.Fatalf("b.Get after invalidation = %q", got)
+	}
+	if got, _, _ := a.Local().Get(ctx, "k"); string(got) != "v2" {
+		t.Fatalf("a dropped its own write-through copy: %q", got)
+	}
+
+	if err := b.Delete(ctx, "k"); err != nil {
+		t.Fatalf("b.Delete: %v", err)
+	}
+	if _, ok, _ := a.Get(ctx, "k"); ok {
+		t.Fatal("a still serves a deleted key")
+	}
+}
+
+func TestTieredStoreSkipsFillInvalidatedDuringRemoteRead(t *testing.T) {
+	t.Parallel()
+
+	reads := map[string]func(*TieredStore) ([]byte, error){
+		"Get": func(s *TieredStore) ([]byte, error) {
+			v, _, err := s.Get(context.Background(), "k")
+			return v, err
+		},
+		"GetMany": func(s *TieredStore) ([]byte, error) {
+			m, err := s.GetMany(context.Background(), []string{"k"})
+			return m["k"], err
+		},
+	}
+	for name, read := range reads {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			remote := NewMemoryStore(MemoryConfig{})
+			if err := remote.Set(ctx, "k", []byte("v1"), 0); err != nil {
+				t.Fatalf("remote Set: %v", err)
+			}
+			bus := &testInvalidationBus{}
+			a := newTestTieredStore(t, remote, TieredConfig{}, bus.invalidator())
+			racing := &racingStore{Store: remote}
+			b := newTestTieredStore(t, racing, TieredConfig{}, bus.invalidator())
+			// a writes v2 after b has read v1 but before b fills its local tier.
+			racing.afterGet = func() {
+				if err := a.Set(ctx, "k", []byte("v2"), 0); err != nil {
+					t.Errorf("a.Set: %v", err)
+				}
+			}
+
+			if got, err := read(b); err != nil || string(got) != "v1" {
+				t.Fatalf("racing read = %q, %v; want the value read before the write", got, err)
+			}
+			if got, ok, _ := b.Local().Get(ctx, "k"); ok {
+				t.Fatalf("local tier kept %q invalidated during the remote read", got)
+			}
+			if got, err := read(b); err != nil || string(got) != "v2" {
+				t.Fatalf("read after invalidation = %q, %v; want v2", got, err)
+			}
+		})
+	}
+}
+
+func TestTieredStoreGetManyAndExists(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	remote := NewMemoryStore(MemoryConfig{})
+	store := newTestTieredStore(t, remote, TieredConfig{}, nil)
+	if err := store.Set(ctx, "local", []byte("L"), 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := remote.Set(ctx, "remote", []byte("R"), 0); err != nil {
+		t.Fatalf("remote Set: %v", err)
+	}
+
+	got, err := store.GetMany(ctx, []string{"local", "remote", "missing"})
+	if err != nil {
+		t.Fatalf("GetMany: %v", err)
+	}
+	if len(got) != 2 || string(got["local"]) != "L" || string(got["remote"]) != "R" {
+		t.Fatalf("GetMany = %q", got)
+	}
+	if ok, _ := store.Local().Exists(ctx, "remote"); !ok {
+		t.Fatal("GetMany did not populate the local tier")
+	}
+	if ok, err := store.Exists(ctx, "missing"); err != nil || ok {
+		t.Fatalf("Exists missing = %v, %v", ok, err)
+	}
+}
+
+func TestTieredStorePropagatesRemoteErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	remoteErr := errors.New("remote down")
+	store := newTestTieredStore(t, &failingStore{err: remoteErr}, TieredConfig{}, nil)
+	if err := store.Set(ctx, "k", []byte("v"), 0); !errors.Is(err, remoteErr) {
+		t.Fatalf("Set error = %v", err)
+	}
+	if _, ok, _ := store.Local().Get(ctx, "k"); ok {
+		t.Fatal("failed remote write populated the local tier")
+	}
+	if _, _, err := store.Get(ctx, "k"); !errors.Is(err, remoteErr) {
+		t.Fatalf("Get error = %v", err)
+	}
+}
+
+func TestRegisterTieredBuildsRemoteFromRegistry(t *testing.T) {
+	t.Parallel()
+
+	reg := NewFactoryRegistry()
+	if err := RegisterMemory(reg); err != nil {
+		t.Fatalf("RegisterMemory: %v", err)
+	}
+	if err := RegisterTiered(reg); err != nil {
+		t.Fatalf("RegisterTiered: %v", err)
+	}
+	bus := &testInvalidationBus{}
+	var gotRemote Store
+	store, err := New(reg, Config{Provider: ProviderTiered}, &TieredConfig{
+		Local:          MemoryConfig{MaxEntries: 10},
+		RemoteProvider: ProviderMemory,
+		Invalidator: func(remote Store) (Invalidator, error) {
+			gotRemote = remote
+			return bus.invalidator(), nil
+		},
+	}, logging.NewDefault("test"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	tiered, ok := store.(*TieredStore)
+	if !ok {
+		t.Fatalf("store = %T", store)
+	}
+	if tiered.Remote() != gotRemote {
+		t.Fatal("invalidator factory did not receive the remote store")
+	}
+	if err := tiered.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !bus.closed() {
+		t.Fatal("Close did not close the invalidator")
+	}
+
+	for name, cfg := range map[string]any{
+		"wrong type":       &MemoryConfig{},
+		"missing remote":   &TieredConfig{},
+		"recursive remote": &TieredConfig{RemoteProvider: ProviderTiered},
+		"bad mode":         &TieredConfig{RemoteProvider: ProviderMemory, Mode: "write_back"},
+	} {
+		if _, err := New(reg, Config{Provider: ProviderTiered}, cfg, logging.NewDefault("test")); err == nil {
+			t.Fatalf("%s: New accepted %+v", name, cfg)
+		}
+	}
+}
+
+func newTestTieredStore(t *testing.T, remote Store, cfg TieredConfig, inv Invalidator) *TieredStore {
+	t.Helper()
+	store, err := NewTieredStore(NewMemoryStore(MemoryConfig{}), remote, cfg, inv, nil)
+	if err != nil {
+		t.Fatalf("NewTieredStore: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+type countingStore struct {
+	Store
+	gets int
+}
+
+func (s *countingStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.gets++
+	return s.Store.Get(ctx, key)
+}
+
+// racingStore runs afterGet once, after its first remote read.
+type racingStore struct {
+	Store
+	afterGet func()
+}
+
+func (s *racingStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, found, err := s.Store.Get(ctx, key)
+	if fn := s.afterGet; fn != nil {
+		s.afterGet = nil
+		fn()
+	}
+	return value, found, err
+}
+
+type failingStore struct{ err error }
+
+func (s *failingStore) Get(context.Context, string) ([]byte, bool, error) { return nil, false, s.err }
+func (s *failingStore) Set(context.Context, string, []byte, time.Duration) error {
+	return s.err
+}
+func (s *failingStore) Delete(context.Context, string) error         { return s.err }
+func (s *failingStore) Exists(context.Context, string) (bool, error) { return false, s.err }
+
+// testInvalidationBus delivers invalidations synchronously to every subscriber.
+type testInvalidationBus struct {
+	mu       sync.Mutex
+	handlers []func(Invalidation)
+	closes   int
+}
+
+func (b *testInvalidationBus) invalidator() Invalidator { return &testInvalidator{bus: b} }
+
+func (b *testInvalidationBus) closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closes > 0
+}
+
+type testInvalidator struct{ bus *testInvalidationBus }
+
+func (i *testInvalidator) Publish(_ context.Context, inv Invalidation) error {
+	i.bus.mu.Lock()
+	handlers := append([]func(Invalidation){}, i.bus.handlers...)
+	i.bus.mu.Unlock()
+	for _, h := range handlers {
+		h(inv)
+	}
+	return nil
+}
+
+func (i *testInvalidator) Subscribe(handler func(Invalidation)) error {
+	i.bus.mu.Lock()
+	i.bus.handlers = append(i.bus.handlers, handler)
+	i.bus.mu.Unlock()
+	return nil
+}
+
+func (i *testInvalidator) Close() error {
+	i.bus.mu.Lock()
+	i.bus.closes++
+	i.bus.mu.Unlock()
+	return nil
+}
@@ -3,7 +3,9 @@
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/kbukum/gokit/provider"
@@ -13,6 +15,10 @@
 type TypedStore[C any] struct {
 	store     Store
 	keyPrefix string
+	clock     func() time.Time
+
+	mu      sync.Mutex
+	flights map[string]*flight[C]
 }
 
 // NewTypedStore creates a typed cache store.
@@ -28,6 +34,8 @@ func (s *TypedStore[C]) fullKey(key string) string {
 }
 
 // Load deserializes JSON from cache. It returns (nil, nil) for a miss.
+// Values written by GetOrLoad are unwrapped; stale values are returned as-is
+// and negative-cache entries read as a miss.
 //
 //nolint:nilnil // ContextStore uses nil pointer to represent missing state.
 func (s *TypedStore[C]) Load(ctx context.Context, key string) (*C, error) {
@@ -38,8 +46,15 @@ func (s *TypedStore[C]) Load(ctx context.Context, key string) (*C, error) {
 	if !ok {
 		return nil, nil
 	}
+	env, err := decodeEntry(raw)
+	if err != nil {
+		return nil, fmt.Errorf("typed cache unmarshal %q: %w", key, err)
+	}
+	if env.Err != "" {
+		return nil, nil
+	}
 	var val C
-	if err := json.Unmarshal(raw, &val); err != nil {
+	if err := json.Unmarshal(env.Value, &val); err != nil {
 		return nil, fmt.Errorf("typed cache unmarshal %q: %w", key, err)
 	}
 	return &val, nil
@@ -57,6 +72,65 @@ func (s *TypedStore[C]) Save(ctx context.Context, key string, val *C, ttl time.D
 	return nil
 }
 
+// SaveWithTags stores val like Save and attaches tags for bulk invalidation.
+// Tags are not namespaced by the key prefix, so one tag can span several
+// typed stores. The underlying Store must implement TaggedStore.
+func (s *TypedStore[C]) SaveWithTags(ctx context.Context, key string, val *C, ttl time.Duration, tags ...string) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("typed cache marshal %q: %w", key, err)
+	}
+	if err := s.set(ctx, key, data, ttl, tags); err != nil {
+		return fmt.Errorf("typed cache save %q: %w", key, err)
+	}
+	return nil
+}
+
+// InvalidateTag removes every key tagged with tag, across all typed stores
+// sharing the underlying Store.
+func (s *TypedStore[C]) InvalidateTag(ctx context.Context, tag string) error {
+	tagged, err := s.tagged()
+	if err != nil {
+		return fmt.Errorf("typed cache invalidate tag %q: %w", tag, err)
+	}
+	if err := tagged.InvalidateTag(ctx, tag); err != nil {
+		return fmt.Errorf("typed cache invalidate tag %q: %w", tag, err)
+	}
+	return nil
+}
+
+// DeletePrefix removes every key of this typed store starting with prefix.
+// An empty prefix clears the whole key prefix namespace.
+func (s *TypedStore[C]) DeletePrefix(ctx context.Context, prefix string) error {
+	tagged, err := s.tagged()
+	if err != nil {
+		return fmt.Errorf("typed cache delete prefix %q: %w", prefix, err)
+	}
+	if err := tagged.DeletePrefix(ctx, s.fullKey(prefix)); err != nil {
+		return fmt.Errorf("typed cache delete prefix %q: %w", prefix, err)
+	}
+	return nil
+}
+
+func (s *TypedStore[C]) set(ctx context.Context, key string, data []byte, ttl time.Duration, tags []string) error {
+	if len(tags) == 0 {
+		return s.store.Set(ctx, s.fullKey(key), data, ttl)
+	}
+	tagged, err := s.tagged()
+	if err != nil {
+		return err
+	}
+	return tagged.SetWithTags(ctx, s.fullKey(key), data, ttl, tags)
+}
+
+func (s *TypedStore[C]) tagged() (TaggedStore, error) {
+	tagged, ok := s.store.(TaggedStore)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a cache.TaggedStore: %w", s.store, errors.ErrUnsupported)
+	}
+	return tagged, nil
+}
+
 // Delete removes key.
 func (s *TypedStore[C]) Delete(ctx context.Context, key string) error {
 	if err := s.store.Delete(ctx, s.fullKey(key)); err != nil {
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kbukum/gokit/worker"
+)
+
+// DefaultLockTable is the table leases are stored in when none is configured.
+const DefaultLockTable = "gokit_locks"
+
+// lockRow is one lease in the lock table.
+type lockRow struct {
+	Name      string    `gorm:"column:name;primaryKey;size:255"`
+	Owner     string    `gorm:"column:owner;size:255;not null"`
+	ExpiresAt time.Time `gorm:"column:expires_at;not null"`
+}
+
+// Locker grants worker leases as rows in a table, for deployments that
+// already share a database but not Redis. Expiry uses the caller's clock, so
+// replicas' clocks must agree to well within the lease TTL.
+type Locker struct {
+	db    *gorm.DB
+	table string
+	now   func() time.Time
+}
+
+// NewLocker creates a lease locker storing leases in table (DefaultLockTable
+// if empty). Call EnsureSchema or a migration to create the table.
+func NewLocker(db *DB, table string) *Locker {
+	if table == "" {
+		table = DefaultLockTable
+	}
+	return &Locker{db: db.GormDB, table: table, now: time.Now}
+}
+
+// EnsureSchema creates the lock table if it does not exist.
+func (l *Locker) EnsureSchema(ctx context.Context) error {
+	if err := l.db.WithContext(ctx).Table(l.table).AutoMigrate(&lockRow{}); err != nil {
+		return fmt.Errorf("lock table %s: %w", l.table, err)
+	}
+	return nil
+}
+
+// Acquire takes or renews the lease key for owner. It first tries to claim
+// an existing row that owner holds or that has expired, then to insert a new
+// one; the primary key ensures only one concurrent insert wins.
+func (l *Locker) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	now := l.now().UTC()
+	db := l.db.WithContext(ctx).Table(l.table)
+
+	res := db.Where("name = ? AND (owner = ? OR expires_at <= ?)", key, owner, now).
+		Updates(map[string]any{"owner": owner, "expires_at": now.Add(ttl)})
+	if res.Error != nil {
+		return false, fmt.Errorf("lock %q: %w", key, res.Error)
+	}
+	if res.RowsAffected > 0 {
+		return true, nil
+	}
+
+	res = l.db.WithContext(ctx).Table(l.table).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&lockRow{Name: key, Owner: owner, ExpiresAt: now.Add(ttl)})
+	if res.Error != nil {
+		return false, fmt.Errorf("lock %q: %w", key, res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// Release gives up the lease key if owner holds it.
+func (l *Locker) Release(ctx context.Context, key, owner string) error {
+	err := l.db.WithContext(ctx).Table(l.table).
+		Where("name = ? AND owner = ?", key, owner).Delete(&lockRow{}).Error
+	if err != nil {
+		return fmt.Errorf("unlock %q: %w", key, err)
+	}
+	return nil
+}
+
+var _ worker.Locker = (*Locker)(nil)
@@ -0,0 +1,302 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/worker"
+)
+
+// DefaultOutboxTable is the table outbox messages are stored in when none is
+// configured.
+const DefaultOutboxTable = "gokit_outbox"
+
+// DefaultOutboxBatchSize is how many messages a relay pass publishes when no
+// batch size is configured.
+const DefaultOutboxBatchSize = 100
+
+// DefaultOutboxMaxAttempts is how many failed publish attempts park a message
+// when no limit is configured.
+const DefaultOutboxMaxAttempts = 10
+
+// outboxRow is one message in the outbox table. IDs increase with insertion,
+// so they order each key's messages.
+type outboxRow struct {
+	ID        int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	Topic     string     `gorm:"column:topic;size:255;not null"`
+	Key       string     `gorm:"column:msg_key;size:255;not null;default:''"`
+	Value     []byte     `gorm:"column:value"`
+	Headers   string     `gorm:"column:headers"`
+	CreatedAt time.Time  `gorm:"column:created_at;not null"`
+	SentAt    *time.Time `gorm:"column:sent_at;index"`
+	Attempts  int        `gorm:"column:attempts;not null;default:0"`
+	LastError string     `gorm:"column:last_error"`
+	ParkedAt  *time.Time `gorm:"column:parked_at;index"`
+}
+
+func (r *outboxRow) message() (messaging.Message, error) {
+	msg := messaging.Message{Topic: r.Topic, Key: r.Key, Value: r.Value, Timestamp: r.CreatedAt}
+	if r.Headers != "" {
+		if err := json.Unmarshal([]byte(r.Headers), &msg.Headers); err != nil {
+			return msg, fmt.Errorf("decode headers: %w", err)
+		}
+	}
+	return msg, nil
+}
+
+// OutboxConfig configures an Outbox.
+type OutboxConfig struct {
+	// Table holds the messages. Default: DefaultOutboxTable.
+	Table string
+	// BatchSize caps the messages one Relay call publishes.
+	// Default: DefaultOutboxBatchSize.
+	BatchSize int
+	// MaxAttempts parks a message after this many failed publish attempts, so
+	// it stops holding back its key. Default: DefaultOutboxMaxAttempts.
+	MaxAttempts int
+	// Retention deletes sent messages this long after they were sent when the
+	// relay worker runs (0 = keep; call Cleanup yourself).
+	Retention time.Duration
+}
+
+// Outbox implements the transactional outbox pattern: Add writes messages in
+// the same transaction as the business data they describe, and a relay
+// publishes them afterwards, so a message is published if and only if its
+// transaction commits. Delivery is at least once: a relay that dies between
+// publishing a message and marking it sent publishes it again.
+//
+// Messages sharing a key are published in the order they were added; a
+// message that fails to publish holds back the later ones with its key until
+// it succeeds or is parked after MaxAttempts failures. Parked messages stay in
+// the table with their last error until Unpark returns them to the relay.
+// Messages without a key are not ordered. Run a single relay,
+// for example a Scheduler with leader election, or replicas may publish the
+// same message concurrently and out of order.
+type Outbox struct {
+	db    *gorm.DB
+	table string
+	cfg   OutboxConfig
+	now   func() time.Time
+}
+
+// NewOutbox creates an outbox on db. Call EnsureSchema or a migration to
+// create the table.
+func NewOutbox(db *DB, cfg OutboxConfig) *Outbox {
+	if cfg.Table == "" {
+		cfg.Table = DefaultOutboxTable
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultOutboxBatchSize
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultOutboxMaxAttempts
+	}
+	return &Outbox{db: db.GormDB, table: cfg.Table, cfg: cfg, now: time.Now}
+}
+
+// EnsureSchema creates the outbox table if it does not exist.
+func (o *Outbox) EnsureSchema(ctx context.Context) error {
+	if err := o.db.WithContext(ctx).Table(o.table).AutoMigrate(&outboxRow{}); err != nil {
+		return fmt.Errorf("outbox table %s: %w", o.table, err)
+	}
+	return nil
+}
+
+// Add writes msgs to the outbox within tx, the transaction passed to a
+// TransactionFunc. Each message needs a topic.
+//
+//	err := db.WithTransaction(ctx, func(tx *gorm.DB) error {
+//	    if err := tx.Create(&order).Error; err != nil {
+//	        return err
+//	    }
+//	    return outbox.Add(tx, messaging.NewMessage("orders", order.ID, payload, nil))
+//	})
+func (o *Outbox) Add(tx *gorm.DB, msgs ...messaging.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	now := o.now().UTC()
+	rows := make([]outboxRow, len(msgs))
+	for i, msg := range msgs {
+		if msg.Topic == "" {
+			return fmt.Errorf("outbox add: message %d has no topic", i)
+		}
+		rows[i] = outboxRow{Topic: msg.Topic, Key: msg.Key, Value: msg.Value, CreatedAt: now}
+		if len(msg.Headers) > 0 {
+			headers, err := json.Marshal(msg.Headers)
+			if err != nil {
+				return fmt.Errorf("outbox add: encode headers: %w", err)
+			}
+			rows[i].Headers = string(headers)
+		}
+	}
+	if err := tx.Table(o.table).Create(&rows).Error; err != nil {
+		return fmt.Errorf("outbox add: %w", err)
+	}
+	return nil
+}
+
+// Relay publishes up to BatchSize unsent messages through p, oldest first,
+// marking each sent as soon as it is published. A failed message records its
+// error and holds back the later messages with its key; other keys continue,
+// because messages queued behind a failed one are left out of the batch.
+// Returns the number of messages published; errors are joined.
+func (o *Outbox) Relay(ctx context.Context, p messaging.Producer) (int, error) {
+	sent, _, err := o.relay(ctx, p)
+	return sent, err
+}
+
+// relay is Relay that also reports whether a message that had failed before
+// was published, releasing the messages queued behind it.
+func (o *Outbox) relay(ctx context.Context, p messaging.Producer) (sent int, recovered bool, err error) {
+	// A message queued behind a failed one with its key must wait for it.
+	notBehindFailure := "o.msg_key = '' OR NOT EXISTS (SELECT 1 FROM " + o.table + " AS f WHERE " +
+		"f.msg_key = o.msg_key AND f.id < o.id AND f.sent_at IS NULL AND f.parked_at IS NULL AND f.attempts > 0)"
+	var rows []outboxRow
+	err = o.db.WithContext(ctx).Table(o.table + " AS o").Select("o.*").
+		Where("o.sent_at IS NULL AND o.parked_at IS NULL").Where(notBehindFailure).
+		Order("o.id").Limit(o.cfg.BatchSize).Find(&rows).Error
+	if err != nil {
+		return 0, false, fmt.Errorf("outbox relay: %w", err)
+	}
+
+	var errs []error
+	blocked := make(map[string]bool)
+	for i := range rows {
+		row := &rows[i]
+		if row.Key != "" && blocked[row.Key] {
+			continue
+		}
+		msg, err := row.message()
+		if err == nil {
+			err = p.Send(ctx, msg)
+		}
+		if err != nil {
+			if row.Key != "" {
+				blocked[row.Key] = true
+			}
+			errs = append(errs, fmt.Errorf("outbox relay message %d: %w", row.ID, err))
+			if ferr := o.recordFailure(ctx, row, err); ferr != nil {
+				errs = append(errs, ferr)
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		if err := o.db.WithContext(ctx).Table(o.table).Where("id = ?", row.ID).
+			Update("sent_at", o.now().UTC()).Error; err != nil {
+			// Published but not marked: stop so this key's later messages do
+			// not overtake its next attempt.
+			errs = append(errs, fmt.Errorf("outbox relay mark message %d sent: %w", row.ID, err))
+			break
+		}
+		sent++
+		recovered = recovered || row.Attempts > 0
+	}
+	return sent, recovered, errors.Join(errs...)
+}
+
+// recordFailure counts a failed attempt of row and parks it once it reaches
+// MaxAttempts.
+func (o *Outbox) recordFailure(ctx context.Context, row *outboxRow, cause error) error {
+	updates := map[string]any{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": cause.Error(),
+	}
+	parked := row.Attempts+1 >= o.cfg.MaxAttempts
+	if parked {
+		updates["parked_at"] = o.now().UTC()
+	}
+	err := o.db.WithContext(context.WithoutCancel(ctx)).Table(o.table).Where("id = ?", row.ID).Updates(updates).Error
+	if err != nil {
+		return fmt.Errorf("outbox relay record failure of message %d: %w", row.ID, err)
+	}
+	if parked {
+		return fmt.Errorf("outbox relay parked message %d after %d attempts", row.ID, row.Attempts+1)
+	}
+	return nil
+}
+
+// Pending returns the number of unsent messages the relay will still try, a
+// measure of relay lag. Parked messages are not included.
+func (o *Outbox) Pending(ctx context.Context) (int64, error) {
+	var n int64
+	if err := o.db.WithContext(ctx).Table(o.table).Where("sent_at IS NULL AND parked_at IS NULL").Count(&n).Error; err != nil {
+		return 0, fmt.Errorf("outbox pending: %w", err)
+	}
+	return n, nil
+}
+
+// Parked returns the number of messages parked after MaxAttempts failures.
+func (o *Outbox) Parked(ctx context.Context) (int64, error) {
+	var n int64
+	if err := o.db.WithContext(ctx).Table(o.table).Where("sent_at IS NULL AND parked_at IS NOT NULL").Count(&n).Error; err != nil {
+		return 0, fmt.Errorf("outbox parked: %w", err)
+	}
+	return n, nil
+}
+
+// Unpark returns every parked message to the relay with a fresh attempt count
+// and returns how many it unparked. A message unparked after later messages
+// with its key were published is delivered out of order.
+func (o *Outbox) Unpark(ctx context.Context) (int64, error) {
+	res := o.db.WithContext(ctx).Table(o.table).Where("sent_at IS NULL AND parked_at IS NOT NULL").
+		Updates(map[string]any{"parked_at": nil, "attempts": 0})
+	if res.Error != nil {
+		return 0, fmt.Errorf("outbox unpark: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// Cleanup deletes messages sent more than olderThan ago and returns how many
+// it deleted.
+func (o *Outbox) Cleanup(ctx context.Context, olderThan time.Duration) (int64, error) {
+	res := o.db.WithContext(ctx).Table(o.table).
+		Where("sent_at IS NOT NULL AND sent_at < ?", o.now().UTC().Add(-olderThan)).Delete(&outboxRow{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("outbox cleanup: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// RelayFunc returns a worker.TickerFunc that calls Relay until a batch comes
+// back short without releasing messages held back by an earlier failure, so a
+// backlog drains within one run, and then Cleanup if
+// Retention is set. Use it as the Fn of a singleton Scheduler job when
+// several replicas share the outbox:
+//
+//	s := worker.NewScheduler("outbox",
+//	    worker.Job{Name: "relay", Interval: time.Second, Singleton: true, Fn: outbox.RelayFunc(producer)},
+//	).WithLeaderElection(worker.LeaderConfig{Locker: database.NewLocker(db, "")})
+func (o *Outbox) RelayFunc(p messaging.Producer) worker.TickerFunc {
+	return func(ctx context.Context) error {
+		for {
+			n, recovered, err := o.relay(ctx, p)
+			if err != nil {
+				return err
+			}
+			if (n < o.cfg.BatchSize && !recovered) || ctx.Err() != nil {
+				break
+			}
+		}
+		if o.cfg.Retention > 0 {
+			if _, err := o.Cleanup(ctx, o.cfg.Retention); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// NewRelay returns a worker running RelayFunc(p) every interval, for a
+// deployment with a single relaying instance.
+func (o *Outbox) NewRelay(name string, interval time.Duration, p messaging.Producer, opts ...worker.TickerOption) *worker.TickerWorker {
+	return worker.NewTickerWorker(name, interval, o.RelayFunc(p), opts...)
+}
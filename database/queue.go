@@ -0,0 +1,214 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	gkerrors "github.com/kbukum/gokit/errors"
+	"github.com/kbukum/gokit/worker"
+)
+
+// DefaultQueueTable is the table queued tasks are stored in when none is
+// configured.
+const DefaultQueueTable = "gokit_queue_tasks"
+
+// Queue task states. Waiting and leased tasks are both pending; a lease only
+// moves visible_at into the future.
+const (
+	queueStatusPending = "pending"
+	queueStatusDead    = "dead"
+)
+
+// dequeueCandidates is how many visible tasks Dequeue tries to claim before
+// concluding that other consumers took them all.
+const dequeueCandidates = 5
+
+// queueRow is one task in the queue table.
+type queueRow struct {
+	ID         string    `gorm:"column:id;primaryKey;size:64"`
+	Queue      string    `gorm:"column:queue;size:255;not null;index:,composite:ready,priority:1"`
+	Status     string    `gorm:"column:status;size:16;not null;index:,composite:ready,priority:2"`
+	VisibleAt  time.Time `gorm:"column:visible_at;not null;index:,composite:ready,priority:3"`
+	Payload    []byte    `gorm:"column:payload"`
+	Attempts   int       `gorm:"column:attempts;not null;default:0"`
+	Lease      string    `gorm:"column:lease;size:64;not null;default:''"`
+	LastError  string    `gorm:"column:last_error"`
+	EnqueuedAt time.Time `gorm:"column:enqueued_at;not null"`
+}
+
+func (r *queueRow) task() worker.QueuedTask {
+	return worker.QueuedTask{
+		ID:         r.ID,
+		Payload:    r.Payload,
+		Attempts:   r.Attempts,
+		Lease:      r.Lease,
+		LastError:  r.LastError,
+		EnqueuedAt: r.EnqueuedAt,
+	}
+}
+
+// Queue is a worker.QueueBackend storing tasks as rows in a table, for
+// deployments that already share a database but not Redis. Several queues
+// can share one table. A delivery is claimed with a conditional update, so
+// concurrent consumers never lease the same task twice. Visibility uses the
+// caller's clock, so replicas' clocks must agree to well within the
+// visibility timeout.
+type Queue struct {
+	db    *gorm.DB
+	name  string
+	table string
+	now   func() time.Time
+}
+
+// NewQueue creates a queue called name storing tasks in table
+// (DefaultQueueTable if empty). Call EnsureSchema or a migration to create
+// the table.
+func NewQueue(db *DB, name, table string) *Queue {
+	if table == "" {
+		table = DefaultQueueTable
+	}
+	return &Queue{db: db.GormDB, name: name, table: table, now: time.Now}
+}
+
+// EnsureSchema creates the queue table if it does not exist.
+func (q *Queue) EnsureSchema(ctx context.Context) error {
+	if err := q.db.WithContext(ctx).Table(q.table).AutoMigrate(&queueRow{}); err != nil {
+		return fmt.Errorf("queue table %s: %w", q.table, err)
+	}
+	return nil
+}
+
+// Enqueue implements worker.QueueBackend.
+func (q *Queue) Enqueue(ctx context.Context, id string, payload []byte) error {
+	now := q.now().UTC()
+	res := q.db.WithContext(ctx).Table(q.table).Clauses(clause.OnConflict{DoNothing: true}).Create(&queueRow{
+		ID:         id,
+		Queue:      q.name,
+		Status:     queueStatusPending,
+		VisibleAt:  now,
+		Payload:    payload,
+		EnqueuedAt: now,
+	})
+	if res.Error != nil {
+		return fmt.Errorf("queue %q enqueue: %w", q.name, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return gkerrors.AlreadyExists("queued task").WithDetail("id", id)
+	}
+	return nil
+}
+
+// Dequeue implements worker.QueueBackend. It reads a few of the oldest
+// visible tasks and claims the first one no other consumer claimed since;
+// attempts acts as the row version because every claim increments it.
+func (q *Queue) Dequeue(ctx context.Context, visibility time.Duration) (*worker.QueuedTask, error) {
+	now := q.now().UTC()
+	var rows []queueRow
+	err := q.db.WithContext(ctx).Table(q.table).
+		Where("queue = ? AND status = ? AND visible_at <= ?", q.name, queueStatusPending, now).
+		Order("visible_at, enqueued_at").Limit(dequeueCandidates).Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("queue %q dequeue: %w", q.name, err)
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		lease := uuid.NewString()
+		res := q.db.WithContext(ctx).Table(q.table).
+			Where("id = ? AND status = ? AND visible_at <= ? AND attempts = ?", row.ID, queueStatusPending, now, row.Attempts).
+			Updates(map[string]any{
+				"lease":      lease,
+				"attempts":   gorm.Expr("attempts + 1"),
+				"visible_at": now.Add(visibility),
+			})
+		if res.Error != nil {
+			return nil, fmt.Errorf("queue %q dequeue: %w", q.name, res.Error)
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		row.Lease = lease
+		row.Attempts++
+		task := row.task()
+		return &task, nil
+	}
+	return nil, nil
+}
+
+// Extend implements worker.QueueBackend.
+func (q *Queue) Extend(ctx context.Context, task *worker.QueuedTask, visibility time.Duration) error {
+	return q.settle(ctx, "extend", task, func(db *gorm.DB) *gorm.DB {
+		return db.Update("visible_at", q.now().UTC().Add(visibility))
+	})
+}
+
+// Ack implements worker.QueueBackend.
+func (q *Queue) Ack(ctx context.Context, task *worker.QueuedTask) error {
+	return q.settle(ctx, "ack", task, func(db *gorm.DB) *gorm.DB {
+		return db.Delete(&queueRow{})
+	})
+}
+
+// Retry implements worker.QueueBackend.
+func (q *Queue) Retry(ctx context.Context, task *worker.QueuedTask, at time.Time, reason string) error {
+	return q.settle(ctx, "retry", task, func(db *gorm.DB) *gorm.DB {
+		return db.Updates(map[string]any{"visible_at": at.UTC(), "lease": "", "last_error": reason})
+	})
+}
+
+// DeadLetter implements worker.QueueBackend.
+func (q *Queue) DeadLetter(ctx context.Context, task *worker.QueuedTask, reason string) error {
+	return q.settle(ctx, "dead letter", task, func(db *gorm.DB) *gorm.DB {
+		return db.Updates(map[string]any{"status": queueStatusDead, "lease": "", "last_error": reason})
+	})
+}
+
+// Len returns the number of waiting and leased tasks, excluding dead letters.
+func (q *Queue) Len(ctx context.Context) (int, error) {
+	var n int64
+	err := q.db.WithContext(ctx).Table(q.table).
+		Where("queue = ? AND status = ?", q.name, queueStatusPending).Count(&n).Error
+	if err != nil {
+		return 0, fmt.Errorf("queue %q len: %w", q.name, err)
+	}
+	return int(n), nil
+}
+
+// DeadLetters returns up to limit dead-lettered tasks, oldest first.
+func (q *Queue) DeadLetters(ctx context.Context, limit int) ([]worker.QueuedTask, error) {
+	var rows []queueRow
+	err := q.db.WithContext(ctx).Table(q.table).
+		Where("queue = ? AND status = ?", q.name, queueStatusDead).
+		Order("enqueued_at").Limit(limit).Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("queue %q dead letters: %w", q.name, err)
+	}
+	tasks := make([]worker.QueuedTask, len(rows))
+	for i := range rows {
+		tasks[i] = rows[i].task()
+	}
+	return tasks, nil
+}
+
+// settle applies op to task's row if its lease is still current.
+func (q *Queue) settle(ctx context.Context, op string, task *worker.QueuedTask, apply func(*gorm.DB) *gorm.DB) error {
+	if task.Lease == "" {
+		return worker.ErrLeaseLost
+	}
+	res := apply(q.db.WithContext(ctx).Table(q.table).
+		Where("id = ? AND queue = ? AND status = ? AND lease = ?", task.ID, q.name, queueStatusPending, task.Lease))
+	if res.Error != nil {
+		return fmt.Errorf("queue %q %s %s: %w", q.name, op, task.ID, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return worker.ErrLeaseLost
+	}
+	return nil
+}
+
+var _ worker.QueueBackend = (*Queue)(nil)
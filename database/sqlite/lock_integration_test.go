@@ -0,0 +1,97 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/kbukum/gokit/database"
+	"github.com/kbukum/gokit/database/sqlite"
+	"github.com/kbukum/gokit/logging"
+	"github.com/kbukum/gokit/worker"
+)
+
+func newLocker(t *testing.T) *Locker {
+	t.Helper()
+	cfg := Config{Enabled: true, DSN: ":memory:"}
+	cfg.ApplyDefaults()
+	db, err := NewWithContext(context.Background(), sqlite.Open(cfg.DSN), cfg, logging.NewDefault("test"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	// A single connection keeps every query on the same in-memory database.
+	sqlDB, _ := db.GormDB.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	locker := NewLocker(db, "")
+	if err := locker.EnsureSchema(context.Background()); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+	return locker
+}
+
+func TestLockerAcquireRenewRelease(t *testing.T) {
+	t.Parallel()
+
+	locker := newLocker(t)
+	ctx := context.Background()
+
+	if ok, err := locker.Acquire(ctx, "jobs", "a", time.Minute); err != nil || !ok {
+		t.Fatalf("a Acquire = %v, %v", ok, err)
+	}
+	if ok, err := locker.Acquire(ctx, "jobs", "b", time.Minute); err != nil || ok {
+		t.Fatalf("b Acquire while a holds = %v, %v", ok, err)
+	}
+	if ok, err := locker.Acquire(ctx, "jobs", "a", time.Minute); err != nil || !ok {
+		t.Fatalf("a renew = %v, %v", ok, err)
+	}
+
+	if err := locker.Release(ctx, "jobs", "b"); err != nil {
+		t.Fatalf("b Release: %v", err)
+	}
+	if ok, _ := locker.Acquire(ctx, "jobs", "b", time.Minute); ok {
+		t.Fatal("foreign release freed the lease")
+	}
+	if err := locker.Release(ctx, "jobs", "a"); err != nil {
+		t.Fatalf("a Release: %v", err)
+	}
+	if ok, err := locker.Acquire(ctx, "jobs", "b", time.Millisecond); err != nil || !ok {
+		t.Fatalf("b Acquire after release = %v, %v", ok, err)
+	}
+
+	// An unrenewed lease expires.
+	time.Sleep(5 * time.Millisecond)
+	if ok, err := locker.Acquire(ctx, "jobs", "a", time.Minute); err != nil || !ok {
+		t.Fatalf("a Acquire of expired lease = %v, %v", ok, err)
+	}
+}
+
+func TestLockerDrivesSingletonJobs(t *testing.T) {
+	t.Parallel()
+
+	locker := newLocker(t)
+	runs := make([]int, 2)
+	schedulers := make([]*worker.Scheduler, 2)
+	for i := range schedulers {
+		schedulers[i] = worker.NewScheduler("jobs", worker.Job{
+			Name:       "cleanup",
+			Interval:   time.Hour,
+			RunOnStart: true,
+			Singleton:  true,
+			Fn: func(context.Context) error {
+				runs[i]++
+				return nil
+			},
+		}).WithLeaderElection(worker.LeaderConfig{Locker: locker})
+		if err := schedulers[i].Start(context.Background()); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+	}
+	for _, s := range schedulers {
+		_ = s.Stop(context.Background())
+	}
+	if runs[0]+runs[1] != 1 {
+		t.Fatalf("singleton runs = %v, want exactly one", runs)
+	}
+}
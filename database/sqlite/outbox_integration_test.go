@@ -0,0 +1,293 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	. "github.com/kbukum/gokit/database"
+	"github.com/kbukum/gokit/database/sqlite"
+	"github.com/kbukum/gokit/logging"
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/messaging/memory"
+)
+
+type orderRow struct {
+	ID string `gorm:"primaryKey"`
+}
+
+func newOutbox(t *testing.T, cfg OutboxConfig) (*DB, *Outbox) {
+	t.Helper()
+	dbCfg := Config{Enabled: true, DSN: ":memory:"}
+	dbCfg.ApplyDefaults()
+	db, err := NewWithContext(context.Background(), sqlite.Open(dbCfg.DSN), dbCfg, logging.NewDefault("test"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	// A single connection keeps every query on the same in-memory database.
+	sqlDB, _ := db.GormDB.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	outbox := NewOutbox(db, cfg)
+	if err := outbox.EnsureSchema(context.Background()); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+	if err := db.AutoMigrate(&orderRow{}); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+	return db, outbox
+}
+
+// flakyProducer fails to send messages with key failKey until healed.
+type flakyProducer struct {
+	*memory.InMemoryProducer
+	failKey string
+
+	mu     sync.Mutex
+	healed bool
+}
+
+func (p *flakyProducer) Send(ctx context.Context, msg messaging.Message) error {
+	p.mu.Lock()
+	fail := msg.Key == p.failKey && !p.healed
+	p.mu.Unlock()
+	if fail {
+		return errors.New("broker unavailable")
+	}
+	return p.InMemoryProducer.Send(ctx, msg)
+}
+
+func (p *flakyProducer) heal() {
+	p.mu.Lock()
+	p.healed = true
+	p.mu.Unlock()
+}
+
+func TestOutboxPublishesOnlyCommittedMessages(t *testing.T) {
+	t.Parallel()
+
+	db, outbox := newOutbox(t, OutboxConfig{})
+	ctx := context.Background()
+
+	err := db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(&orderRow{ID: "o-1"}).Error; err != nil {
+			return err
+		}
+		return outbox.Add(tx, messaging.NewMessage("orders", "o-1", []byte("created"), map[string]string{"type": "created"}))
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	boom := errors.New("boom")
+	err = db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := outbox.Add(tx, messaging.NewMessage("orders", "o-2", []byte("created"), nil)); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("rollback: %v", err)
+	}
+
+	broker := memory.NewBroker()
+	defer broker.Close()
+	n, err := outbox.Relay(ctx, broker.Producer())
+	if err != nil || n != 1 {
+		t.Fatalf("Relay = %d, %v; want 1, nil", n, err)
+	}
+	msgs := broker.Messages("orders")
+	if len(msgs) != 1 || msgs[0].Key != "o-1" || msgs[0].Headers["type"] != "created" {
+		t.Fatalf("published %+v", msgs)
+	}
+	if pending, _ := outbox.Pending(ctx); pending != 0 {
+		t.Errorf("Pending = %d, want 0", pending)
+	}
+	if n, _ := outbox.Relay(ctx, broker.Producer()); n != 0 {
+		t.Errorf("second Relay published %d messages, want 0", n)
+	}
+}
+
+func TestOutboxRelayKeepsPerKeyOrder(t *testing.T) {
+	t.Parallel()
+
+	db, outbox := newOutbox(t, OutboxConfig{})
+	ctx := context.Background()
+	err := db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		return outbox.Add(tx,
+			messaging.Message{Topic: "events", Key: "a", Value: []byte("a1")},
+			messaging.Message{Topic: "events", Key: "b", Value: []byte("b1")},
+			messaging.Message{Topic: "events", Key: "a", Value: []byte("a2")},
+			messaging.Message{Topic: "events", Key: "b", Value: []byte("b2")},
+		)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := memory.NewBroker()
+	defer broker.Close()
+	producer := &flakyProducer{InMemoryProducer: broker.Producer(), failKey: "a"}
+
+	// a1 fails, so a2 waits for it while b's messages go out.
+	n, err := outbox.Relay(ctx, producer)
+	if err == nil || n != 2 {
+		t.Fatalf("Relay = %d, %v; want 2 and an error", n, err)
+	}
+	if pending, _ := outbox.Pending(ctx); pending != 2 {
+		t.Fatalf("Pending = %d, want 2", pending)
+	}
+
+	// a2 stays out of the batch until a1 is published; one relay run
+	// publishes both.
+	producer.heal()
+	if err := outbox.RelayFunc(producer)(ctx); err != nil {
+		t.Fatalf("RelayFunc after heal: %v", err)
+	}
+	var order []string
+	for _, msg := range broker.Messages("events") {
+		order = append(order, string(msg.Value))
+	}
+	want := []string{"b1", "b2", "a1", "a2"}
+	if len(order) != len(want) {
+		t.Fatalf("published %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("published %v, want %v", order, want)
+		}
+	}
+}
+
+// poisonProducer fails to send the message with value poison.
+type poisonProducer struct {
+	*memory.InMemoryProducer
+	poison string
+}
+
+func (p *poisonProducer) Send(ctx context.Context, msg messaging.Message) error {
+	if string(msg.Value) == p.poison {
+		return errors.New("message too large")
+	}
+	return p.InMemoryProducer.Send(ctx, msg)
+}
+
+func TestOutboxRelayFailingKeyDoesNotStarveOthers(t *testing.T) {
+	t.Parallel()
+
+	db, outbox := newOutbox(t, OutboxConfig{BatchSize: 2})
+	ctx := context.Background()
+	err := db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		return outbox.Add(tx,
+			messaging.Message{Topic: "events", Key: "a", Value: []byte("a1")},
+			messaging.Message{Topic: "events", Key: "a", Value: []byte("a2")},
+			messaging.Message{Topic: "events", Key: "b", Value: []byte("b1")},
+		)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := memory.NewBroker()
+	defer broker.Close()
+	producer := &flakyProducer{InMemoryProducer: broker.Producer(), failKey: "a"}
+
+	// The first batch is a1 and a2; once a1 has failed, a2 waits outside the
+	// batch and b1 takes its place.
+	if n, err := outbox.Relay(ctx, producer); err == nil || n != 0 {
+		t.Fatalf("first Relay = %d, %v; want 0 and an error", n, err)
+	}
+	if n, err := outbox.Relay(ctx, producer); err == nil || n != 1 {
+		t.Fatalf("second Relay = %d, %v; want 1 and an error", n, err)
+	}
+	msgs := broker.Messages("events")
+	if len(msgs) != 1 || string(msgs[0].Value) != "b1" {
+		t.Fatalf("published %+v, want b1", msgs)
+	}
+}
+
+func TestOutboxRelayParksPoisonMessage(t *testing.T) {
+	t.Parallel()
+
+	db, outbox := newOutbox(t, OutboxConfig{MaxAttempts: 2})
+	ctx := context.Background()
+	err := db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		return outbox.Add(tx,
+			messaging.Message{Topic: "events", Key: "a", Value: []byte("a1")},
+			messaging.Message{Topic: "events", Key: "a", Value: []byte("a2")},
+		)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker := memory.NewBroker()
+	defer broker.Close()
+	producer := &poisonProducer{InMemoryProducer: broker.Producer(), poison: "a1"}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		if n, err := outbox.Relay(ctx, producer); err == nil || n != 0 {
+			t.Fatalf("Relay %d = %d, %v; want 0 and an error", attempt, n, err)
+		}
+	}
+	if parked, _ := outbox.Parked(ctx); parked != 1 {
+		t.Fatalf("Parked = %d, want 1", parked)
+	}
+	if n, err := outbox.Relay(ctx, producer); err != nil || n != 1 {
+		t.Fatalf("Relay after parking = %d, %v; want a2 published", n, err)
+	}
+	if pending, _ := outbox.Pending(ctx); pending != 0 {
+		t.Fatalf("Pending = %d, want 0", pending)
+	}
+
+	if n, err := outbox.Unpark(ctx); err != nil || n != 1 {
+		t.Fatalf("Unpark = %d, %v; want 1", n, err)
+	}
+	if pending, _ := outbox.Pending(ctx); pending != 1 {
+		t.Fatalf("Pending after Unpark = %d, want 1", pending)
+	}
+}
+
+func TestOutboxRelayWorkerDrainsAndCleansUp(t *testing.T) {
+	t.Parallel()
+
+	db, outbox := newOutbox(t, OutboxConfig{BatchSize: 2, Retention: time.Nanosecond})
+	ctx := context.Background()
+	err := db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		for _, key := range []string{"1", "2", "3", "4", "5"} {
+			if err := outbox.Add(tx, messaging.Message{Topic: "events", Key: key}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Add(db.GormDB, messaging.Message{Key: "no-topic"}); err == nil {
+		t.Fatal("Add without a topic should fail")
+	}
+
+	broker := memory.NewBroker()
+	defer broker.Close()
+	relay := outbox.NewRelay("outbox-relay", time.Hour, broker.Producer())
+	if err := outbox.RelayFunc(broker.Producer())(ctx); err != nil {
+		t.Fatalf("RelayFunc: %v", err)
+	}
+	if got := broker.MessageCount("events"); got != 5 {
+		t.Fatalf("published %d messages in one run, want 5", got)
+	}
+	if relay.Name() != "outbox-relay" {
+		t.Errorf("relay name = %q", relay.Name())
+	}
+
+	var rows int64
+	db.GormDB.Table(DefaultOutboxTable).Count(&rows)
+	if rows != 0 {
+		t.Errorf("%d rows left after cleanup, want 0", rows)
+	}
+}
@@ -0,0 +1,136 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/kbukum/gokit/database"
+	"github.com/kbukum/gokit/database/sqlite"
+	"github.com/kbukum/gokit/logging"
+	"github.com/kbukum/gokit/worker"
+)
+
+func newQueue(t *testing.T, name string) *Queue {
+	t.Helper()
+	cfg := Config{Enabled: true, DSN: ":memory:"}
+	cfg.ApplyDefaults()
+	db, err := NewWithContext(context.Background(), sqlite.Open(cfg.DSN), cfg, logging.NewDefault("test"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	// A single connection keeps every query on the same in-memory database.
+	sqlDB, _ := db.GormDB.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	queue := NewQueue(db, name, "")
+	if err := queue.EnsureSchema(context.Background()); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+	return queue
+}
+
+func TestQueueLeaseLifecycle(t *testing.T) {
+	t.Parallel()
+
+	q := newQueue(t, "jobs")
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b"} {
+		if err := q.Enqueue(ctx, id, []byte("payload-"+id)); err != nil {
+			t.Fatalf("Enqueue %s: %v", id, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := q.Enqueue(ctx, "a", nil); err == nil {
+		t.Fatal("duplicate Enqueue should fail")
+	}
+
+	a, err := q.Dequeue(ctx, 30*time.Millisecond)
+	if err != nil || a == nil || a.ID != "a" || string(a.Payload) != "payload-a" || a.Attempts != 1 {
+		t.Fatalf("Dequeue = %+v, %v", a, err)
+	}
+	b, _ := q.Dequeue(ctx, time.Minute)
+	if b == nil || b.ID != "b" {
+		t.Fatalf("second Dequeue = %+v", b)
+	}
+	if none, err := q.Dequeue(ctx, time.Minute); none != nil || err != nil {
+		t.Fatalf("leased tasks must be hidden, got %+v, %v", none, err)
+	}
+
+	// An expired lease is redelivered and the stale one is rejected.
+	time.Sleep(50 * time.Millisecond)
+	again, _ := q.Dequeue(ctx, time.Minute)
+	if again == nil || again.ID != "a" || again.Attempts != 2 {
+		t.Fatalf("redelivery = %+v", again)
+	}
+	if err := q.Ack(ctx, a); !errors.Is(err, worker.ErrLeaseLost) {
+		t.Fatalf("stale Ack = %v, want ErrLeaseLost", err)
+	}
+	if err := q.Extend(ctx, again, time.Minute); err != nil {
+		t.Fatalf("Extend: %v", err)
+	}
+
+	if err := q.Retry(ctx, again, time.Now(), "transient"); err != nil {
+		t.Fatalf("Retry: %v", err)
+	}
+	third, _ := q.Dequeue(ctx, time.Minute)
+	if third == nil || third.ID != "a" || third.Attempts != 3 || third.LastError != "transient" {
+		t.Fatalf("retried Dequeue = %+v", third)
+	}
+	if err := q.DeadLetter(ctx, third, "poison"); err != nil {
+		t.Fatalf("DeadLetter: %v", err)
+	}
+	if err := q.Ack(ctx, b); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+
+	if n, _ := q.Len(ctx); n != 0 {
+		t.Fatalf("Len = %d, want 0", n)
+	}
+	dead, err := q.DeadLetters(ctx, 10)
+	if err != nil || len(dead) != 1 || dead[0].ID != "a" || dead[0].LastError != "poison" {
+		t.Fatalf("DeadLetters = %+v, %v", dead, err)
+	}
+}
+
+func TestQueueBacksDurablePool(t *testing.T) {
+	t.Parallel()
+
+	q := newQueue(t, "reports")
+	var calls atomic.Int32
+	h := worker.HandlerFunc[int, int](func(_ context.Context, n int, emit func(worker.Event[int])) error {
+		if calls.Add(1) == 1 {
+			return errors.New("transient")
+		}
+		emit(worker.PartialEvent(n * n))
+		return nil
+	})
+	pool := worker.NewPool(h, worker.PoolConfig{
+		Name: "reports",
+		Size: 2,
+		Durable: &worker.DurableConfig{
+			Backend:      q,
+			PollInterval: 5 * time.Millisecond,
+			BackoffBase:  time.Millisecond,
+		},
+	})
+	defer func() { _ = pool.Stop(context.Background()) }()
+
+	handle, err := pool.Submit(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if _, err := handle.Result(); err != nil {
+		t.Fatalf("Result: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("handler calls = %d, want 2", calls.Load())
+	}
+	if n, _ := q.Len(context.Background()); n != 0 {
+		t.Fatalf("Len after success = %d, want 0", n)
+	}
+}
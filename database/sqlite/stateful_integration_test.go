@@ -0,0 +1,122 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/kbukum/gokit/database"
+	"github.com/kbukum/gokit/database/sqlite"
+	"github.com/kbukum/gokit/logging"
+	"github.com/kbukum/gokit/stateful"
+)
+
+type chatTurn struct {
+	Role string `json:"role"`
+	Text string `json:"text"`
+}
+
+func newListStores[V any](t *testing.T) *ListStores[V] {
+	t.Helper()
+	cfg := Config{Enabled: true, DSN: ":memory:"}
+	cfg.ApplyDefaults()
+	db, err := NewWithContext(context.Background(), sqlite.Open(cfg.DSN), cfg, logging.NewDefault("test"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	sqlDB, _ := db.GormDB.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	stores := NewListStores[V](db, ListStoreConfig{})
+	if err := stores.EnsureSchema(context.Background()); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+	return stores
+}
+
+func TestListStoreAppendFlush(t *testing.T) {
+	t.Parallel()
+
+	stores := newListStores[chatTurn](t)
+	store := stores.Store("session-1")
+	ctx := context.Background()
+
+	if last, err := store.LastActivity(ctx); err != nil || !last.IsZero() {
+		t.Fatalf("LastActivity on new store = %v, %v; want zero", last, err)
+	}
+	turns := []chatTurn{{"user", "hi"}, {"assistant", "hello"}}
+	for _, turn := range turns {
+		if err := store.Append(ctx, turn); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if n, _ := store.Size(ctx); n != 2 {
+		t.Fatalf("Size = %d, want 2", n)
+	}
+	if got, _ := store.Get(ctx); !reflect.DeepEqual(got, turns) {
+		t.Fatalf("Get = %v, want %v", got, turns)
+	}
+	if last, _ := store.LastActivity(ctx); time.Since(last) > time.Minute {
+		t.Fatalf("LastActivity = %v, want recent", last)
+	}
+	if keys, _ := stores.Keys(ctx); !reflect.DeepEqual(keys, []string{"session-1"}) {
+		t.Fatalf("Keys = %v, want [session-1]", keys)
+	}
+
+	got, err := store.Flush(ctx)
+	if err != nil || !reflect.DeepEqual(got, turns) {
+		t.Fatalf("Flush = %v, %v; want %v", got, err, turns)
+	}
+	if got, _ := store.Flush(ctx); len(got) != 0 {
+		t.Fatalf("second Flush = %v, want empty", got)
+	}
+	if keys, _ := stores.Keys(ctx); len(keys) != 0 {
+		t.Fatalf("Keys after Flush = %v, want none", keys)
+	}
+}
+
+func TestListStoreAppendFIFO(t *testing.T) {
+	t.Parallel()
+
+	store := newListStores[int](t).Store("k")
+	ctx := context.Background()
+
+	var evicted []int
+	for i := range 5 {
+		ev, err := store.AppendFIFO(ctx, i, 3)
+		if err != nil {
+			t.Fatalf("AppendFIFO: %v", err)
+		}
+		evicted = append(evicted, ev...)
+	}
+	if !reflect.DeepEqual(evicted, []int{0, 1}) {
+		t.Fatalf("evicted = %v, want [0 1]", evicted)
+	}
+	if got, _ := store.Get(ctx); !reflect.DeepEqual(got, []int{2, 3, 4}) {
+		t.Fatalf("Get = %v, want [2 3 4]", got)
+	}
+}
+
+func TestListStoresRecoverWithManager(t *testing.T) {
+	t.Parallel()
+
+	stores := newListStores[int](t)
+	ctx := context.Background()
+	_ = stores.Store("a").Append(ctx, 1)
+	_ = stores.Store("b").Append(ctx, 2)
+
+	mgr := stateful.NewManager(func(key string) *stateful.Accumulator[int] {
+		return stateful.NewAccumulator[int](stores.Store(key), stateful.Config[int]{TTL: time.Hour, KeepAlive: true})
+	}, 0)
+	defer mgr.Close()
+
+	n, err := mgr.Recover(ctx, stores, func(s string) (string, error) { return s, nil })
+	if err != nil || n != 2 {
+		t.Fatalf("Recover = %d, %v; want 2, nil", n, err)
+	}
+	if size, _ := mgr.Size(ctx, "b"); size != 1 {
+		t.Fatalf("recovered size = %d, want 1", size)
+	}
+}
@@ -0,0 +1,241 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kbukum/gokit/codec"
+	"github.com/kbukum/gokit/stateful"
+)
+
+// DefaultListStoreTable is the table list store values are kept in when none
+// is configured. Activity times live in the same name suffixed with "_keys".
+const DefaultListStoreTable = "gokit_stateful_values"
+
+// listValueRow is one stored value. IDs increase with insertion, so they
+// order each key's values.
+type listValueRow struct {
+	ID    int64  `gorm:"column:id;primaryKey;autoIncrement"`
+	Key   string `gorm:"column:store_key;size:255;not null;index"`
+	Value string `gorm:"column:value;not null"`
+}
+
+// listKeyRow holds a key's last activity time.
+type listKeyRow struct {
+	Key          string    `gorm:"column:store_key;primaryKey;size:255"`
+	LastActivity time.Time `gorm:"column:last_activity;not null"`
+}
+
+// ListStoreConfig configures ListStores.
+type ListStoreConfig struct {
+	// Table holds the values. Default: DefaultListStoreTable.
+	Table string
+	// Codec encodes values. Default: codec.CompactJSON().
+	Codec codec.Codec
+}
+
+// ListStores opens a stateful.Store per key on a database, keeping values as
+// rows in one table and activity times in a second. Writes run in a
+// transaction that first upserts the key's activity row, which serializes
+// concurrent writers to a key on databases with row locks, so replicas can
+// share keys: a value is returned by exactly one Flush and evicted by exactly
+// one AppendFIFO. Activity uses the caller's clock.
+type ListStores[V any] struct {
+	db     *gorm.DB
+	values string
+	keys   string
+	codec  codec.Codec
+	now    func() time.Time
+}
+
+// NewListStores creates list stores on db. Call EnsureSchema or a migration
+// to create the tables.
+func NewListStores[V any](db *DB, cfg ListStoreConfig) *ListStores[V] {
+	if cfg.Table == "" {
+		cfg.Table = DefaultListStoreTable
+	}
+	if cfg.Codec == nil {
+		cfg.Codec = codec.CompactJSON()
+	}
+	return &ListStores[V]{db: db.GormDB, values: cfg.Table, keys: cfg.Table + "_keys", codec: cfg.Codec, now: time.Now}
+}
+
+// EnsureSchema creates the value and activity tables if they do not exist.
+func (s *ListStores[V]) EnsureSchema(ctx context.Context) error {
+	if err := s.db.WithContext(ctx).Table(s.values).AutoMigrate(&listValueRow{}); err != nil {
+		return fmt.Errorf("list store table %s: %w", s.values, err)
+	}
+	if err := s.db.WithContext(ctx).Table(s.keys).AutoMigrate(&listKeyRow{}); err != nil {
+		return fmt.Errorf("list store table %s: %w", s.keys, err)
+	}
+	return nil
+}
+
+// Store returns the store for key. It holds no resources of its own.
+func (s *ListStores[V]) Store(key string) *ListStore[V] {
+	return &ListStore[V]{stores: s, key: key}
+}
+
+// Keys implements stateful.KeyLister, returning the keys holding values.
+func (s *ListStores[V]) Keys(ctx context.Context) ([]string, error) {
+	var keys []string
+	err := s.db.WithContext(ctx).Table(s.values).Distinct("store_key").Order("store_key").Pluck("store_key", &keys).Error
+	if err != nil {
+		return nil, fmt.Errorf("list stores keys: %w", err)
+	}
+	return keys, nil
+}
+
+// ListStore is the stateful.Store for one key of ListStores.
+type ListStore[V any] struct {
+	stores *ListStores[V]
+	key    string
+}
+
+// Append implements stateful.Store.
+func (s *ListStore[V]) Append(ctx context.Context, value V) error {
+	_, err := s.AppendFIFO(ctx, value, 0)
+	return err
+}
+
+// AppendFIFO implements stateful.Store. Appending and evicting share one
+// transaction.
+func (s *ListStore[V]) AppendFIFO(ctx context.Context, value V, maxSize int) ([]V, error) {
+	encoded, err := codec.Encode(s.stores.codec, value)
+	if err != nil {
+		return nil, fmt.Errorf("list store %q encode: %w", s.key, err)
+	}
+	var evicted []listValueRow
+	err = s.stores.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.touch(tx); err != nil {
+			return err
+		}
+		if err := tx.Table(s.stores.values).Create(&listValueRow{Key: s.key, Value: encoded}).Error; err != nil {
+			return err
+		}
+		if maxSize <= 0 {
+			return nil
+		}
+		var n int64
+		if err := tx.Table(s.stores.values).Where("store_key = ?", s.key).Count(&n).Error; err != nil {
+			return err
+		}
+		if int(n) <= maxSize {
+			return nil
+		}
+		if err := tx.Table(s.stores.values).Where("store_key = ?", s.key).
+			Order("id").Limit(int(n) - maxSize).Find(&evicted).Error; err != nil {
+			return err
+		}
+		return tx.Table(s.stores.values).Delete(&listValueRow{}, rowIDs(evicted)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list store %q append: %w", s.key, err)
+	}
+	if len(evicted) == 0 {
+		return nil, nil
+	}
+	return s.decode(evicted)
+}
+
+// Get implements stateful.Store.
+func (s *ListStore[V]) Get(ctx context.Context) ([]V, error) {
+	var rows []listValueRow
+	err := s.stores.db.WithContext(ctx).Table(s.stores.values).Where("store_key = ?", s.key).Order("id").Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("list store %q get: %w", s.key, err)
+	}
+	return s.decode(rows)
+}
+
+// Flush implements stateful.Store.
+func (s *ListStore[V]) Flush(ctx context.Context) ([]V, error) {
+	var rows []listValueRow
+	err := s.stores.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.touch(tx); err != nil {
+			return err
+		}
+		if err := tx.Table(s.stores.values).Where("store_key = ?", s.key).Order("id").Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Table(s.stores.values).Delete(&listValueRow{}, rowIDs(rows)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list store %q flush: %w", s.key, err)
+	}
+	return s.decode(rows)
+}
+
+// Size implements stateful.Store.
+func (s *ListStore[V]) Size(ctx context.Context) (int, error) {
+	var n int64
+	if err := s.stores.db.WithContext(ctx).Table(s.stores.values).Where("store_key = ?", s.key).Count(&n).Error; err != nil {
+		return 0, fmt.Errorf("list store %q size: %w", s.key, err)
+	}
+	return int(n), nil
+}
+
+// Touch implements stateful.Store.
+func (s *ListStore[V]) Touch(ctx context.Context) error {
+	if err := s.touch(s.stores.db.WithContext(ctx)); err != nil {
+		return fmt.Errorf("list store %q touch: %w", s.key, err)
+	}
+	return nil
+}
+
+// LastActivity implements stateful.Store.
+func (s *ListStore[V]) LastActivity(ctx context.Context) (time.Time, error) {
+	var row listKeyRow
+	err := s.stores.db.WithContext(ctx).Table(s.stores.keys).Where("store_key = ?", s.key).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("list store %q last activity: %w", s.key, err)
+	}
+	return row.LastActivity, nil
+}
+
+// Close implements stateful.Store. Values stay in the database.
+func (s *ListStore[V]) Close() error { return nil }
+
+// touch upserts the key's activity row.
+func (s *ListStore[V]) touch(db *gorm.DB) error {
+	return db.Table(s.stores.keys).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "store_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_activity"}),
+	}).Create(&listKeyRow{Key: s.key, LastActivity: s.stores.now().UTC()}).Error
+}
+
+func (s *ListStore[V]) decode(rows []listValueRow) ([]V, error) {
+	values := make([]V, len(rows))
+	for i := range rows {
+		v, err := codec.Decode[V](s.stores.codec, rows[i].Value)
+		if err != nil {
+			return nil, fmt.Errorf("list store %q decode: %w", s.key, err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func rowIDs(rows []listValueRow) []int64 {
+	ids := make([]int64, len(rows))
+	for i := range rows {
+		ids[i] = rows[i].ID
+	}
+	return ids
+}
+
+var (
+	_ stateful.Store[int] = (*ListStore[int])(nil)
+	_ stateful.KeyLister  = (*ListStores[int])(nil)
+)
@@ -3,7 +3,8 @@
 //
 // Readers stream bounded file reads through [github.com/kbukum/gokit/fs]
 // and emit [github.com/kbukum/gokit/stream] pipelines; they fail closed on malformed
-// or oversized input. Writers drain a pipeline to an injected io.Writer.
+// or oversized input. Writers drain a pipeline to an injected io.Writer. Reader pipelines
+// support [stream.Checkpointed], so a checkpointed job resumes at the next unread record.
 //
 // A record field [Value] is a decoded-JSON leaf; its any element type is a deliberate,
 // documented exception to the no-any rule, matching [github.com/kbukum/gokit/codec] Value
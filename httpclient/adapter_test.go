@@ -344,6 +344,39 @@ func TestClient_Do_CircuitBreaker(t *testing.T) {
 	}
 }
 
+func TestClient_Do_CircuitBreakerFailureRate(t *testing.T) {
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.Add(1)%2 == 0 {
+			w.WriteHeader(503)
+			return
+		}
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	cbCfg := resilience.DefaultCircuitBreakerConfig("rate")
+	cbCfg.Window = resilience.CircuitWindowTime
+	cbCfg.MinimumCalls = 6
+	cbCfg.FailureRateThreshold = 0.5
+
+	c, err := New(Config{BaseURL: srv.URL, CircuitBreaker: &cbCfg})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 6; i++ {
+		c.Do(ctx, Request{Method: http.MethodGet, Path: "/"})
+	}
+	if _, err := c.Do(ctx, Request{Method: http.MethodGet, Path: "/"}); !errors.Is(err, resilience.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen at a 50%% failure rate, got %v", err)
+	}
+	if c.IsAvailable(ctx) {
+		t.Error("IsAvailable = true while the circuit is open")
+	}
+}
+
 func TestClient_DoStream_SSE(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/event-stream")
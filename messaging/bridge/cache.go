@@ -0,0 +1,162 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kbukum/gokit/cache"
+	"github.com/kbukum/gokit/messaging"
+)
+
+// --- CacheInvalidator ---
+
+// DefaultSubscribeWait is how long Subscribe watches a new consume loop for
+// startup failures when no WithSubscribeWait option is given.
+const DefaultSubscribeWait = 100 * time.Millisecond
+
+// CacheInvalidatorOption configures a CacheInvalidator.
+type CacheInvalidatorOption func(*cacheInvalidator)
+
+// WithInvalidationErrorHandler sets a hook called when the consume loop ends
+// with an error after Subscribe returned. Without it the error is only
+// reported by later Publish and Close calls.
+func WithInvalidationErrorHandler(fn func(error)) CacheInvalidatorOption {
+	return func(i *cacheInvalidator) { i.onError = fn }
+}
+
+// WithSubscribeWait sets how long Subscribe watches the consume loop for a
+// startup failure before reporting the subscription active. Default:
+// DefaultSubscribeWait.
+func WithSubscribeWait(d time.Duration) CacheInvalidatorOption {
+	return func(i *cacheInvalidator) {
+		if d > 0 {
+			i.wait = d
+		}
+	}
+}
+
+// CacheInvalidator adapts a Producer/Consumer pair on one topic into a
+// cache.Invalidator for cache.TieredStore. The consumer must see every
+// message published to the topic (fan-out, not a shared consumer group),
+// otherwise only one instance per group drops its local copy.
+//
+// messaging.Consumer exposes no readiness signal, so Subscribe cannot confirm
+// that the broker subscription is established: it runs the consume loop and
+// returns its error if the loop fails within the subscribe wait, and
+// otherwise reports the subscription active. Announcements published before
+// the broker has registered the consumer may be missed; LocalTTL bounds the
+// resulting staleness. If the loop ends later, the error goes to the
+// WithInvalidationErrorHandler hook and every following Publish and Close
+// returns it, so writes through the TieredStore fail instead of the near
+// cache silently going stale.
+func CacheInvalidator(p messaging.Producer, c messaging.Consumer, topic string, opts ...CacheInvalidatorOption) cache.Invalidator {
+	i := &cacheInvalidator{producer: p, consumer: c, topic: topic, wait: DefaultSubscribeWait}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+type cacheInvalidator struct {
+	producer messaging.Producer
+	consumer messaging.Consumer
+	topic    string
+	wait     time.Duration
+	onError  func(error)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error // why the consume loop ended
+}
+
+func (i *cacheInvalidator) Publish(ctx context.Context, inv cache.Invalidation) error {
+	if err := i.loopErr(); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(inv)
+	if err != nil {
+		return fmt.Errorf("cache invalidator marshal: %w", err)
+	}
+	return i.producer.Send(ctx, messaging.NewMessage(i.topic, inv.Origin, payload, map[string]string{
+		"content-type": "application/json",
+	}))
+}
+
+func (i *cacheInvalidator) Subscribe(handler func(cache.Invalidation)) error {
+	i.mu.Lock()
+	if i.cancel != nil {
+		i.mu.Unlock()
+		return fmt.Errorf("cache invalidator: already subscribed to %q", i.topic)
+	}
+	ctx, cancel := context.WithCancel(context.Background()) //nolint:gosec // G118: cancel is retained and invoked in Close
+	done := make(chan struct{})
+	i.cancel, i.done, i.err = cancel, done, nil
+	i.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		err := i.consumer.Consume(ctx, func(_ context.Context, msg messaging.Message) error {
+			var inv cache.Invalidation
+			if err := msg.UnmarshalValueJSON(&inv); err != nil {
+				return nil // a malformed announcement must not stall the topic
+			}
+			handler(inv)
+			return nil
+		})
+		if ctx.Err() != nil {
+			return // stopped by Close
+		}
+		if err == nil {
+			err = messaging.ErrClosed
+		}
+		i.mu.Lock()
+		i.err = fmt.Errorf("cache invalidator: consume %q ended: %w", i.topic, err)
+		i.mu.Unlock()
+	}()
+
+	timer := time.NewTimer(i.wait)
+	defer timer.Stop()
+	select {
+	case <-done:
+		i.mu.Lock()
+		err := i.err
+		i.cancel, i.done, i.err = nil, nil, nil
+		i.mu.Unlock()
+		cancel()
+		return err
+	case <-timer.C:
+	}
+	if i.onError != nil {
+		go func() {
+			<-done
+			if err := i.loopErr(); err != nil {
+				i.onError(err)
+			}
+		}()
+	}
+	return nil
+}
+
+func (i *cacheInvalidator) loopErr() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.err
+}
+
+func (i *cacheInvalidator) Close() error {
+	i.mu.Lock()
+	cancel, done := i.cancel, i.done
+	i.cancel, i.done = nil, nil
+	i.mu.Unlock()
+	if cancel == nil {
+		return i.loopErr()
+	}
+	cancel()
+	<-done
+	return errors.Join(i.loopErr(), i.consumer.Close())
+}
@@ -0,0 +1,138 @@
+package bridge_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/cache"
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/messaging/bridge"
+	"github.com/kbukum/gokit/messaging/memory"
+)
+
+func TestCacheInvalidatorDeliversToTieredStores(t *testing.T) {
+	t.Parallel()
+
+	broker := memory.NewBroker()
+	defer broker.Close()
+	remote := cache.NewMemoryStore(cache.MemoryConfig{})
+	newTiered := func() *cache.TieredStore {
+		inv := bridge.CacheInvalidator(broker.Producer(), broker.Consumer("cache.invalidate"), "cache.invalidate")
+		store, err := cache.NewTieredStore(cache.NewMemoryStore(cache.MemoryConfig{}), remote, cache.TieredConfig{}, inv, nil)
+		if err != nil {
+			t.Fatalf("NewTieredStore: %v", err)
+		}
+		t.Cleanup(func() { _ = store.Close() })
+		return store
+	}
+	a, b := newTiered(), newTiered()
+	ctx := context.Background()
+
+	if err := a.Set(ctx, "k", []byte("v1"), 0); err != nil {
+		t.Fatalf("a.Set: %v", err)
+	}
+	if got, _, _ := b.Get(ctx, "k"); string(got) != "v1" {
+		t.Fatalf("b.Get = %q", got)
+	}
+	if err := a.Delete(ctx, "k"); err != nil {
+		t.Fatalf("a.Delete: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, cached, _ := b.Local().Get(ctx, "k"); !cached {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("b kept its stale local copy")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	msgs := broker.Messages("cache.invalidate")
+	if len(msgs) != 2 || msgs[0].Headers["content-type"] != "application/json" {
+		t.Fatalf("published %d invalidations: %+v", len(msgs), msgs)
+	}
+}
+
+func TestCacheInvalidatorRejectsDoubleSubscribe(t *testing.T) {
+	t.Parallel()
+
+	broker := memory.NewBroker()
+	defer broker.Close()
+	inv := bridge.CacheInvalidator(broker.Producer(), broker.Consumer("t"), "t")
+	if err := inv.Subscribe(func(cache.Invalidation) {}); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if err := inv.Subscribe(func(cache.Invalidation) {}); err == nil {
+		t.Fatal("second Subscribe succeeded")
+	}
+	if err := inv.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+// failingConsumer fails its consume loop with err once release is closed.
+type failingConsumer struct {
+	err     error
+	release chan struct{}
+}
+
+func (c *failingConsumer) Consume(ctx context.Context, _ messaging.MessageHandler) error {
+	select {
+	case <-c.release:
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *failingConsumer) Topic() string { return "t" }
+func (c *failingConsumer) Close() error  { return nil }
+
+func TestCacheInvalidatorSubscribeReportsStartupFailure(t *testing.T) {
+	t.Parallel()
+
+	broker := memory.NewBroker()
+	defer broker.Close()
+	down := errors.New("broker unreachable")
+	release := make(chan struct{})
+	close(release)
+	inv := bridge.CacheInvalidator(broker.Producer(), &failingConsumer{err: down, release: release}, "t")
+	if err := inv.Subscribe(func(cache.Invalidation) {}); !errors.Is(err, down) {
+		t.Fatalf("Subscribe = %v, want %v", err, down)
+	}
+}
+
+func TestCacheInvalidatorSurfacesLaterConsumeFailure(t *testing.T) {
+	t.Parallel()
+
+	broker := memory.NewBroker()
+	defer broker.Close()
+	down := errors.New("connection lost")
+	consumer := &failingConsumer{err: down, release: make(chan struct{})}
+	reported := make(chan error, 1)
+	inv := bridge.CacheInvalidator(broker.Producer(), consumer, "t",
+		bridge.WithSubscribeWait(10*time.Millisecond),
+		bridge.WithInvalidationErrorHandler(func(err error) { reported <- err }))
+	if err := inv.Subscribe(func(cache.Invalidation) {}); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	close(consumer.release)
+
+	select {
+	case err := <-reported:
+		if !errors.Is(err, down) {
+			t.Fatalf("reported %v, want %v", err, down)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("consume failure was not reported")
+	}
+	if err := inv.Publish(context.Background(), cache.Invalidation{Keys: []string{"k"}}); !errors.Is(err, down) {
+		t.Fatalf("Publish = %v, want %v", err, down)
+	}
+	if err := inv.Close(); !errors.Is(err, down) {
+		t.Fatalf("Close = %v, want %v", err, down)
+	}
+}
@@ -3,6 +3,9 @@
 // ProducerAsSink wraps a Producer as a provider.Sink[Message].
 // EventProducerAsSink wraps a Producer as a provider.Sink[Event].
 // ConsumerAsStream wraps a Consumer as a provider.Stream returning messages.
+// ConsumerSource, ProducerSink, and DrainToBatch connect a Consumer and a Producer
+// directly to stream pipelines, committing consumed messages only after processing.
+// CacheInvalidator carries cache.TieredStore invalidations over a Producer/Consumer pair.
 //
 // Once messaging components are expressed as providers,
 // they compose naturally with all other kit patterns that accept providers:
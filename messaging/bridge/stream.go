@@ -0,0 +1,199 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/stream"
+)
+
+// errNotProcessed is returned to the consumer for a message the pipeline
+// stopped before processing, so the message is not committed.
+var errNotProcessed = errors.New("bridge: pipeline stopped before the message was processed")
+
+// ConsumerSourceConfig configures ConsumerSource.
+type ConsumerSourceConfig struct {
+	// Buffer lets the consumer run up to this many messages ahead of the
+	// pipeline. Buffered messages are committed on receipt, so buffering suits
+	// transports that commit automatically. When the buffer fills, a consumer
+	// implementing messaging.PausableConsumer is paused until the pipeline has
+	// drained half of it. Default: 0, commit after processing.
+	Buffer int
+}
+
+// ConsumerSource turns c into a stream source. Each run of the pipeline
+// starts c's consume loop and stops it when the pipeline's iterator closes.
+//
+// Unbuffered, the consumer's handler for a message returns only when the
+// pipeline pulls the next one — which, with synchronous operators, means the
+// sink has handled it — so consumers using messaging.CommitAfterHandlerSuccess
+// commit a message only after downstream processing succeeded. A message still
+// in flight when the pipeline stops fails its handler and is redelivered.
+// Operators that hold values, such as Batch, Buffer or windows, pull ahead and
+// commit the values they hold.
+//
+// Errors from the consume loop end the pipeline with that error.
+func ConsumerSource(c messaging.Consumer, cfg ConsumerSourceConfig) *stream.Pipeline[messaging.Message] {
+	return stream.FromFunc(func(ctx context.Context) stream.Iterator[messaging.Message] {
+		it := &consumerSource{
+			consumer:   c,
+			deliveries: make(chan delivery, max(cfg.Buffer, 0)),
+			done:       make(chan struct{}),
+		}
+		if cfg.Buffer > 0 {
+			it.pausable, _ = c.(messaging.PausableConsumer)
+		}
+		it.start(ctx)
+		return it
+	})
+}
+
+// delivery is a received message. ack is nil for buffered messages, which
+// are already committed.
+type delivery struct {
+	msg messaging.Message
+	ack chan error
+}
+
+type consumerSource struct {
+	consumer   messaging.Consumer
+	deliveries chan delivery
+	done       chan struct{}
+	cancel     context.CancelFunc
+	err        error // set before deliveries is closed
+	once       sync.Once
+
+	pending chan error // ack of the last message returned by Next
+
+	pausable messaging.PausableConsumer
+	pauseMu  sync.Mutex
+	paused   bool
+}
+
+func (it *consumerSource) start(ctx context.Context) {
+	ctx, it.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(it.done)
+		defer close(it.deliveries)
+		err := it.consumer.Consume(ctx, it.handle)
+		if err != nil && ctx.Err() == nil && !errors.Is(err, errNotProcessed) {
+			it.err = err
+		}
+	}()
+}
+
+func (it *consumerSource) handle(ctx context.Context, msg messaging.Message) error {
+	d := delivery{msg: msg}
+	if cap(it.deliveries) == 0 {
+		d.ack = make(chan error, 1)
+	}
+	select {
+	case it.deliveries <- d:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if d.ack == nil {
+		it.pauseIfFull(ctx)
+		return nil
+	}
+	select {
+	case err := <-d.ack:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (it *consumerSource) Next(ctx context.Context) (messaging.Message, bool, error) {
+	it.settle(nil)
+	if err := it.resumeIfDrained(ctx); err != nil {
+		return messaging.Message{}, false, err
+	}
+	select {
+	case d, ok := <-it.deliveries:
+		if !ok {
+			return messaging.Message{}, false, it.err
+		}
+		it.pending = d.ack
+		return d.msg, true, nil
+	case <-ctx.Done():
+		return messaging.Message{}, false, ctx.Err()
+	}
+}
+
+// Close fails the in-flight message, if any, and stops the consume loop.
+func (it *consumerSource) Close() error {
+	it.once.Do(func() {
+		it.settle(errNotProcessed)
+		it.cancel()
+		<-it.done
+	})
+	return nil
+}
+
+// settle answers the handler waiting on the last returned message.
+func (it *consumerSource) settle(err error) {
+	if it.pending != nil {
+		it.pending <- err
+		it.pending = nil
+	}
+}
+
+func (it *consumerSource) pauseIfFull(ctx context.Context) {
+	if it.pausable == nil {
+		return
+	}
+	it.pauseMu.Lock()
+	defer it.pauseMu.Unlock()
+	if it.paused || len(it.deliveries) < cap(it.deliveries) {
+		return
+	}
+	// If pausing fails, the handler blocking on the full buffer still holds
+	// the consumer back.
+	it.paused = it.pausable.Pause(ctx) == nil
+}
+
+func (it *consumerSource) resumeIfDrained(ctx context.Context) error {
+	if it.pausable == nil {
+		return nil
+	}
+	it.pauseMu.Lock()
+	defer it.pauseMu.Unlock()
+	if !it.paused || len(it.deliveries) > cap(it.deliveries)/2 {
+		return nil
+	}
+	if err := it.pausable.Resume(ctx); err != nil {
+		return fmt.Errorf("bridge: resume consumer: %w", err)
+	}
+	it.paused = false
+	return nil
+}
+
+// ProducerSink returns a stream sink that sends each message through p,
+// applying topic to messages without one. Use it with stream.Drain.
+func ProducerSink(p messaging.Producer, topic string) func(context.Context, messaging.Message) error {
+	return func(ctx context.Context, msg messaging.Message) error {
+		if msg.Topic == "" {
+			msg.Topic = topic
+		}
+		return p.Send(ctx, msg)
+	}
+}
+
+// DrainToBatch sends every message of pipe through b, which publishes them in
+// batches, and flushes b when the pipeline ends so no message stays buffered.
+// Flush errors are joined with the pipeline's error.
+//
+// Messages waiting in b are not yet published, so a ConsumerSource upstream
+// may already have committed them; use ProducerSink where every message must
+// be published before its source message is committed.
+func DrainToBatch(ctx context.Context, pipe *stream.Pipeline[messaging.Message], b *messaging.BatchProducer) error {
+	err := stream.ForEach(ctx, pipe, b.Send)
+	if ferr := b.Flush(context.WithoutCancel(ctx)); ferr != nil {
+		err = errors.Join(err, fmt.Errorf("bridge: flush batch: %w", ferr))
+	}
+	return err
+}
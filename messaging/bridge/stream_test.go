@@ -0,0 +1,194 @@
+package bridge_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/messaging/bridge"
+	"github.com/kbukum/gokit/messaging/memory"
+	"github.com/kbukum/gokit/stream"
+)
+
+// scriptedConsumer delivers msgs in order, records which ones were committed
+// (handler returned nil), and stops at the first handler error, like a
+// consumer using messaging.CommitAfterHandlerSuccess.
+type scriptedConsumer struct {
+	msgs []messaging.Message
+	err  error // returned once all msgs are delivered
+
+	mu        sync.Mutex
+	committed []string
+	failed    []string
+	pauses    int
+	resumes   int
+}
+
+func newScriptedConsumer(n int) *scriptedConsumer {
+	c := &scriptedConsumer{}
+	for i := range n {
+		c.msgs = append(c.msgs, messaging.Message{Key: fmt.Sprint(i), Topic: "in"})
+	}
+	return c
+}
+
+func (c *scriptedConsumer) Consume(ctx context.Context, handler messaging.MessageHandler) error {
+	for _, msg := range c.msgs {
+		if err := handler(ctx, msg); err != nil {
+			c.mu.Lock()
+			c.failed = append(c.failed, msg.Key)
+			c.mu.Unlock()
+			return err
+		}
+		c.mu.Lock()
+		c.committed = append(c.committed, msg.Key)
+		c.mu.Unlock()
+	}
+	return c.err
+}
+
+func (c *scriptedConsumer) Topic() string { return "in" }
+func (c *scriptedConsumer) Close() error  { return nil }
+
+func (c *scriptedConsumer) commits() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.committed...)
+}
+
+// pausableConsumer counts Pause and Resume calls.
+type pausableConsumer struct{ *scriptedConsumer }
+
+func (c pausableConsumer) Pause(context.Context) error {
+	c.mu.Lock()
+	c.pauses++
+	c.mu.Unlock()
+	return nil
+}
+
+func (c pausableConsumer) Resume(context.Context) error {
+	c.mu.Lock()
+	c.resumes++
+	c.mu.Unlock()
+	return nil
+}
+
+func TestConsumerSource_CommitsAfterProcessing(t *testing.T) {
+	t.Parallel()
+
+	c := newScriptedConsumer(3)
+	var seen int
+	err := stream.ForEach(context.Background(), bridge.ConsumerSource(c, bridge.ConsumerSourceConfig{}),
+		func(_ context.Context, msg messaging.Message) error {
+			// Every earlier message is committed; this one is not yet.
+			if got := len(c.commits()); got != seen {
+				t.Errorf("processing %s: %d commits, want %d", msg.Key, got, seen)
+			}
+			seen++
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.commits(); len(got) != 3 {
+		t.Errorf("committed = %v, want all 3", got)
+	}
+}
+
+func TestConsumerSource_FailedMessageNotCommitted(t *testing.T) {
+	t.Parallel()
+
+	c := newScriptedConsumer(4)
+	boom := errors.New("boom")
+	err := stream.ForEach(context.Background(), bridge.ConsumerSource(c, bridge.ConsumerSourceConfig{}),
+		func(_ context.Context, msg messaging.Message) error {
+			if msg.Key == "2" {
+				return boom
+			}
+			return nil
+		})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected sink error, got %v", err)
+	}
+	if got := c.commits(); len(got) != 2 {
+		t.Errorf("committed = %v, want [0 1]", got)
+	}
+	if len(c.failed) != 1 || c.failed[0] != "2" {
+		t.Errorf("failed = %v, want [2]", c.failed)
+	}
+}
+
+func TestConsumerSource_ConsumeError(t *testing.T) {
+	t.Parallel()
+
+	c := newScriptedConsumer(1)
+	c.err = errors.New("connection lost")
+	got, err := stream.Collect(context.Background(), bridge.ConsumerSource(c, bridge.ConsumerSourceConfig{}))
+	if !errors.Is(err, c.err) {
+		t.Fatalf("expected consume error, got %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("got %d messages, want 1", len(got))
+	}
+}
+
+func TestConsumerSource_BufferPausesConsumer(t *testing.T) {
+	t.Parallel()
+
+	c := pausableConsumer{newScriptedConsumer(20)}
+	src := bridge.ConsumerSource(c, bridge.ConsumerSourceConfig{Buffer: 4})
+	var n int
+	err := stream.ForEach(context.Background(), src, func(context.Context, messaging.Message) error {
+		n++
+		time.Sleep(time.Millisecond) // let the buffer fill
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 20 {
+		t.Errorf("processed %d messages, want 20", n)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pauses == 0 || c.resumes == 0 {
+		t.Errorf("pauses=%d resumes=%d, want both > 0", c.pauses, c.resumes)
+	}
+}
+
+func TestProducerSink_DefaultsTopic(t *testing.T) {
+	t.Parallel()
+
+	broker := memory.NewBroker()
+	defer broker.Close()
+
+	msgs := []messaging.Message{{Key: "a", Value: []byte("1")}, {Key: "b", Value: []byte("2"), Topic: "other"}}
+	err := stream.Drain(stream.FromSlice(msgs), bridge.ProducerSink(broker.Producer(), "out")).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if broker.MessageCount("out") != 1 || broker.MessageCount("other") != 1 {
+		t.Errorf("out=%d other=%d, want 1 each", broker.MessageCount("out"), broker.MessageCount("other"))
+	}
+}
+
+func TestDrainToBatch_FlushesRemainder(t *testing.T) {
+	t.Parallel()
+
+	broker := memory.NewBroker()
+	defer broker.Close()
+	batch := messaging.NewBatchProducer(broker.Producer(), "out", messaging.BatchConfig{MaxSize: 2, MaxWait: time.Hour})
+	defer func() { _ = batch.Close(context.Background()) }()
+
+	msgs := []messaging.Message{{Key: "a"}, {Key: "b"}, {Key: "c"}}
+	if err := bridge.DrainToBatch(context.Background(), stream.FromSlice(msgs), batch); err != nil {
+		t.Fatal(err)
+	}
+	if got := broker.MessageCount("out"); got != 3 {
+		t.Errorf("published %d messages, want 3", got)
+	}
+}
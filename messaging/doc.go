@@ -16,6 +16,18 @@
 // Collect messages and flush in batches via [BatchProducer]. Supports size-triggered,
 // time-triggered, and byte-triggered flushing with graceful shutdown.
 //
+// # Request-Reply
+//
+// [Requester] sends requests with a correlation ID and a reply-to header and
+// awaits the matching reply from its own reply topic; [ReplyHandler] wraps a
+// [RequestHandler] on the serving side to send its result back. Both use
+// ordinary headers, so they work with every provider:
+//
+//	r, err := messaging.NewRequester(producer, replyConsumer, messaging.RequesterConfig{ReplyTopic: "quotes.replies.host-1"})
+//	q, err := messaging.RequestJSON[Quote](ctx, r, "quotes", symbol, QuoteRequest{Symbol: symbol})
+//
+//	consumer.Consume(ctx, messaging.ReplyHandler(producer, handleQuote))
+//
 // # Sub-packages
 //
 //   - messaging/kafka:      Kafka implementation using segmentio/kafka-go
@@ -7,6 +7,7 @@ toolchain go1.26.6
 require (
 	github.com/google/uuid v1.6.0
 	github.com/kbukum/gokit v0.2.0
+	github.com/kbukum/gokit/cache v0.2.0
 	github.com/kbukum/gokit/testutil v0.2.0
 	go.opentelemetry.io/otel v1.45.0
 	go.opentelemetry.io/otel/sdk v1.45.0
@@ -14,7 +15,10 @@ require (
 	go.opentelemetry.io/otel/trace v1.45.0
 )
 
-replace github.com/kbukum/gokit => ../
+replace (
+	github.com/kbukum/gokit => ../
+	github.com/kbukum/gokit/cache => ../cache
+)
 
 require (
 	github.com/beorn7/perks v1.0.1 // indirect
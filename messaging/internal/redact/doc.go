@@ -0,0 +1,7 @@
+// Package redact detects sensitive material in messaging headers, payloads,
+// and error text.
+//
+// [Contains] and [ContainsBytes] report whether a value mentions one of the
+// shared markers, such as "token" or "authorization", so the request-reply
+// and dead-letter paths redact the same things.
+package redact
@@ -0,0 +1,63 @@
+package redact
+
+import "strings"
+
+// markers are matched case-insensitively anywhere in a value.
+var markers = []string{"authorization", "cookie", "token", "secret", "password", "credential", "api-key", "apikey"}
+
+// Contains reports whether value mentions a sensitive marker.
+func Contains(value string) bool {
+	lower := strings.ToLower(value)
+	for _, marker := range markers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsBytes is Contains for a payload, folding only ASCII case so the
+// payload is not copied.
+func ContainsBytes(value []byte) bool {
+	for _, marker := range markers {
+		if containsASCIIFold(value, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsASCIIFold(value []byte, marker string) bool {
+	if marker == "" {
+		return true
+	}
+	if len(value) < len(marker) {
+		return false
+	}
+	first := marker[0]
+	for offset := 0; offset <= len(value)-len(marker); offset++ {
+		if lowerASCII(value[offset]) != first {
+			continue
+		}
+		if hasASCIIFoldPrefix(value[offset:], marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasASCIIFoldPrefix(value []byte, marker string) bool {
+	for i := range marker {
+		if lowerASCII(value[i]) != marker[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func lowerASCII(value byte) byte {
+	if value >= 'A' && value <= 'Z' {
+		return value + ('a' - 'A')
+	}
+	return value
+}
@@ -0,0 +1,26 @@
+package redact
+
+import "testing"
+
+func TestContains(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "Authorization", want: true},
+		{value: "upstream rejected X-API-KEY", want: true},
+		{value: `{"Password":"hunter2"}`, want: true},
+		{value: "session-Token expired", want: true},
+		{value: "order 42 not found", want: false},
+		{value: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := Contains(tt.value); got != tt.want {
+			t.Errorf("Contains(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+		if got := ContainsBytes([]byte(tt.value)); got != tt.want {
+			t.Errorf("ContainsBytes(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
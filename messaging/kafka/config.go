@@ -37,10 +37,25 @@ type Config struct {
 	BatchTimeout string `yaml:"batch_timeout" mapstructure:"batch_timeout"`
 	RequiredAcks int    `yaml:"required_acks" mapstructure:"required_acks"`
 
+	// Transaction settings
+	// TransactionalID identifies a TransactionalProducer across restarts; a new
+	// producer with the same ID fences off the old one. Required for
+	// exactly-once delivery.
+	TransactionalID    string `yaml:"transactional_id" mapstructure:"transactional_id"`
+	TransactionTimeout string `yaml:"transaction_timeout" mapstructure:"transaction_timeout"`
+
 	// Consumer protocol settings
+	// IsolationLevel is read_uncommitted or read_committed. Exactly-once
+	// consumers always read committed.
+	IsolationLevel    string `yaml:"isolation_level" mapstructure:"isolation_level"`
 	SessionTimeout    string `yaml:"session_timeout" mapstructure:"session_timeout"`
 	HeartbeatInterval string `yaml:"heartbeat_interval" mapstructure:"heartbeat_interval"`
 	RebalanceTimeout  string `yaml:"rebalance_timeout" mapstructure:"rebalance_timeout"`
+	// Partition pins a consumer to one partition of its topic instead of
+	// sharing the topic through consumer group rebalancing. Offsets are still
+	// read from and committed to the consumer group, by the caller. Required
+	// for exactly-once delivery.
+	Partition *int `yaml:"partition" mapstructure:"partition"`
 
 	// Connection protocol settings
 	DialTimeout string `yaml:"dial_timeout" mapstructure:"dial_timeout"`
@@ -68,6 +83,12 @@ func (c *Config) ApplyDefaults() {
 	if c.RequiredAcks <= 0 {
 		c.RequiredAcks = -1 // all replicas
 	}
+	if c.TransactionTimeout == "" {
+		c.TransactionTimeout = "60s"
+	}
+	if c.IsolationLevel == "" {
+		c.IsolationLevel = "read_uncommitted"
+	}
 	if c.SessionTimeout == "" {
 		c.SessionTimeout = "30s"
 	}
@@ -111,6 +132,7 @@ func (c Config) Validate() error {
 		name, val string
 	}{
 		{"batch_timeout", c.BatchTimeout},
+		{"transaction_timeout", c.TransactionTimeout},
 		{"session_timeout", c.SessionTimeout},
 		{"heartbeat_interval", c.HeartbeatInterval},
 		{"rebalance_timeout", c.RebalanceTimeout},
@@ -135,13 +157,21 @@ func (c Config) Validate() error {
 	if c.BatchSize <= 0 {
 		return fmt.Errorf("batch_size must be > 0")
 	}
+	switch c.IsolationLevel {
+	case "read_uncommitted", "read_committed":
+	default:
+		return fmt.Errorf("unsupported isolation_level: %s", c.IsolationLevel)
+	}
+	if c.Partition != nil && *c.Partition < 0 {
+		return fmt.Errorf("partition must be >= 0")
+	}
 	return nil
 }
 
 // ValidateCommonProducer checks Kafka support for core producer semantics.
 func ValidateCommonProducer(cfg messaging.Config) error {
 	if cfg.DeliveryGuarantee == messaging.DeliveryExactlyOnce {
-		return fmt.Errorf("kafka producer: exactly-once delivery requires transactions and is not supported")
+		return fmt.Errorf("kafka producer: exactly-once delivery requires a transactional producer")
 	}
 	if cfg.DLQ.Enabled {
 		return fmt.Errorf("kafka producer: adapter-managed DLQ is not supported; use messaging middleware")
@@ -149,6 +179,18 @@ func ValidateCommonProducer(cfg messaging.Config) error {
 	return nil
 }
 
+// ValidateCommonTransactionalProducer checks Kafka support for core producer
+// semantics on a transactional producer, which needs a transactional ID.
+func ValidateCommonTransactionalProducer(common messaging.Config, cfg Config) error {
+	if common.DLQ.Enabled {
+		return fmt.Errorf("kafka producer: adapter-managed DLQ is not supported; use messaging middleware")
+	}
+	if cfg.TransactionalID == "" {
+		return fmt.Errorf("kafka producer: transactional_id is required for transactions")
+	}
+	return nil
+}
+
 // ValidateCommonConsumer checks Kafka support for core consumer semantics.
 func ValidateCommonConsumer(cfg messaging.Config) error {
 	if cfg.DLQ.Enabled {
@@ -167,7 +209,33 @@ func ValidateCommonConsumer(cfg messaging.Config) error {
 			return fmt.Errorf("kafka consumer: at-most-once delivery requires %s commits", messaging.CommitAuto)
 		}
 	case messaging.DeliveryExactlyOnce:
-		return fmt.Errorf("kafka consumer: exactly-once delivery requires transactions and is not supported")
+		// Offsets are committed by the producer's transaction.
+		if cfg.CommitStrategy != messaging.CommitManual {
+			return fmt.Errorf("kafka consumer: exactly-once delivery requires %s commits", messaging.CommitManual)
+		}
+		if cfg.ConsumerGroup == "" {
+			return fmt.Errorf("kafka consumer: exactly-once delivery requires a consumer_group")
+		}
+	}
+	return nil
+}
+
+// ValidateConsumerAssignment checks how a consumer is assigned its partitions.
+// Transactional offset commits carry no group generation, so the broker
+// cannot fence a rebalanced-away group member; exactly-once consumers
+// therefore read one statically assigned partition each, paired with a
+// transactional ID of their own. A statically assigned consumer is not a
+// group member and cannot commit through the reader, so it needs manual
+// commits.
+func ValidateConsumerAssignment(common messaging.Config, cfg Config) error {
+	if cfg.Partition == nil {
+		if common.DeliveryGuarantee == messaging.DeliveryExactlyOnce {
+			return fmt.Errorf("kafka consumer: exactly-once delivery requires a statically assigned partition")
+		}
+		return nil
+	}
+	if common.CommitStrategy != messaging.CommitManual {
+		return fmt.Errorf("kafka consumer: a statically assigned partition requires %s commits", messaging.CommitManual)
 	}
 	return nil
 }
@@ -86,6 +86,47 @@ func TestValidateCommonRejectsAdapterManagedDLQ(t *testing.T) {
 	}
 }
 
+func TestValidateCommonExactlyOnce(t *testing.T) {
+	t.Parallel()
+
+	cfg := messaging.Config{Adapter: "kafka", DeliveryGuarantee: messaging.DeliveryExactlyOnce}
+	cfg.ApplyDefaults()
+	if err := ValidateCommonProducer(cfg); err == nil {
+		t.Fatal("expected plain producer to reject exactly-once delivery")
+	}
+	if err := ValidateCommonTransactionalProducer(cfg, Config{}); err == nil {
+		t.Fatal("expected transactional producer to require transactional_id")
+	}
+	if err := ValidateCommonTransactionalProducer(cfg, Config{TransactionalID: "orders-enricher"}); err != nil {
+		t.Fatalf("transactional producer: %v", err)
+	}
+
+	if err := ValidateCommonConsumer(cfg); err == nil {
+		t.Fatal("expected exactly-once consumer to require manual commits")
+	}
+	cfg.CommitStrategy = messaging.CommitManual
+	if err := ValidateCommonConsumer(cfg); err == nil {
+		t.Fatal("expected exactly-once consumer to require a consumer group")
+	}
+	cfg.ConsumerGroup = "enricher"
+	if err := ValidateCommonConsumer(cfg); err != nil {
+		t.Fatalf("exactly-once consumer: %v", err)
+	}
+	if err := ValidateConsumerAssignment(cfg, Config{}); err == nil {
+		t.Fatal("expected exactly-once consumer to require a statically assigned partition")
+	}
+	partition := 2
+	if err := ValidateConsumerAssignment(cfg, Config{Partition: &partition}); err != nil {
+		t.Fatalf("statically assigned exactly-once consumer: %v", err)
+	}
+
+	atLeastOnce := messaging.Config{Adapter: "kafka"}
+	atLeastOnce.ApplyDefaults()
+	if err := ValidateConsumerAssignment(atLeastOnce, Config{Partition: &partition}); err == nil {
+		t.Fatal("expected a statically assigned partition to require manual commits")
+	}
+}
+
 func TestConfig_ApplyDefaults_NoOverwrite(t *testing.T) {
 	cfg := Config{
 		Brokers:     []string{"broker1:9092", "broker2:9092"},
@@ -162,6 +203,22 @@ func TestConfig_Validate_InvalidBatchSize(t *testing.T) {
 	}
 }
 
+func TestConfig_Validate_IsolationLevel(t *testing.T) {
+	cfg := Config{Brokers: []string{"localhost:9092"}, TLS: &security.TLSConfig{ServerName: "kafka.test"}}
+	cfg.ApplyDefaults()
+	if cfg.IsolationLevel != "read_uncommitted" {
+		t.Errorf("IsolationLevel = %q, want read_uncommitted", cfg.IsolationLevel)
+	}
+	cfg.IsolationLevel = "read_committed"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() read_committed: %v", err)
+	}
+	cfg.IsolationLevel = "serializable"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should fail with unsupported isolation_level")
+	}
+}
+
 func TestConfig_Validate_Valid(t *testing.T) {
 	cfg := Config{Brokers: []string{"localhost:9092"}, TLS: &security.TLSConfig{ServerName: "kafka.test"}}
 	cfg.ApplyDefaults()
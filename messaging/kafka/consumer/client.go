@@ -10,6 +10,12 @@ type kafkaReader interface {
 	ReadMessage(context.Context) (kafkago.Message, error)
 	FetchMessage(context.Context) (kafkago.Message, error)
 	CommitMessages(context.Context, ...kafkago.Message) error
+	SetOffset(offset int64) error
 	Stats() kafkago.ReaderStats
 	Close() error
 }
+
+// offsetFetcher reads a consumer group's committed offsets.
+type offsetFetcher interface {
+	OffsetFetch(context.Context, *kafkago.OffsetFetchRequest) (*kafkago.OffsetFetchResponse, error)
+}
@@ -19,6 +19,9 @@ type Consumer struct {
 	reader         kafkaReader
 	topic          string
 	groupID        string
+	static         bool // reads partition directly, outside group rebalancing
+	partition      int
+	offsets        offsetFetcher // committed group offsets of a static partition
 	log            *logging.Logger
 	failures       int
 	errCount       *atomic.Int64 // tracks kafka-go internal error count for rate-limiting
@@ -42,6 +45,9 @@ func NewConsumer(common messaging.Config, cfg kafka.Config, topic string, log *l
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("kafka consumer config: %w", err)
 	}
+	if err := kafka.ValidateConsumerAssignment(common, cfg); err != nil {
+		return nil, err
+	}
 	if err := messaging.ValidateTopic(topic); err != nil {
 		return nil, err
 	}
@@ -68,7 +74,16 @@ func NewConsumer(common messaging.Config, cfg kafka.Config, topic string, log *l
 		}
 	})
 
-	reader := kafkago.NewReader(kafkago.ReaderConfig{
+	isolationLevel := cfg.IsolationLevel
+	if common.DeliveryGuarantee == messaging.DeliveryExactlyOnce {
+		isolationLevel = "read_committed"
+	}
+	isolation := kafkago.ReadUncommitted
+	if isolationLevel == "read_committed" {
+		isolation = kafkago.ReadCommitted
+	}
+
+	readerCfg := kafkago.ReaderConfig{
 		Brokers:           cfg.Brokers,
 		Topic:             topic,
 		GroupID:           common.ConsumerGroup,
@@ -79,23 +94,41 @@ func NewConsumer(common messaging.Config, cfg kafka.Config, topic string, log *l
 		SessionTimeout:    kafka.ParseDuration(cfg.SessionTimeout),
 		HeartbeatInterval: kafka.ParseDuration(cfg.HeartbeatInterval),
 		RebalanceTimeout:  kafka.ParseDuration(cfg.RebalanceTimeout),
+		IsolationLevel:    isolation,
 		ErrorLogger:       rateLimitedErrLogger,
-	})
-
-	clog.Debug("Kafka consumer initialized", map[string]any{
-		"topic":   topic,
-		"groupID": common.ConsumerGroup,
-		"brokers": cfg.Brokers,
-	})
-
-	return &Consumer{
-		reader:         reader,
+	}
+	c := &Consumer{
 		topic:          topic,
 		groupID:        common.ConsumerGroup,
 		log:            clog,
 		errCount:       &errCount,
 		commitStrategy: common.CommitStrategy,
-	}, nil
+	}
+	if cfg.Partition != nil {
+		// A static reader must not join the group; the group only stores
+		// its offsets.
+		transport, err := kafka.CreateTransport(&cfg)
+		if err != nil {
+			return nil, fmt.Errorf("kafka consumer transport: %w", err)
+		}
+		readerCfg.GroupID = ""
+		readerCfg.Partition = *cfg.Partition
+		c.static, c.partition = true, *cfg.Partition
+		c.offsets = &kafkago.Client{Addr: kafkago.TCP(cfg.Brokers...), Transport: transport}
+	}
+	c.reader = kafkago.NewReader(readerCfg)
+
+	fields := map[string]any{
+		"topic":     topic,
+		"groupID":   common.ConsumerGroup,
+		"brokers":   cfg.Brokers,
+		"isolation": isolationLevel,
+	}
+	if c.static {
+		fields["partition"] = c.partition
+	}
+	clog.Debug("Kafka consumer initialized", fields)
+	return c, nil
 }
 
 // Consume reads messages in a loop, calling handler for each one. It blocks until ctx is canceled
@@ -105,6 +138,9 @@ func (c *Consumer) Consume(ctx context.Context, handler messaging.MessageHandler
 		"topic":   c.topic,
 		"groupID": c.groupID,
 	})
+	if err := c.resume(ctx); err != nil {
+		return err
+	}
 
 	for {
 		select {
@@ -113,24 +149,12 @@ func (c *Consumer) Consume(ctx context.Context, handler messaging.MessageHandler
 		default:
 		}
 
-		msg, err := c.read(ctx)
+		msg, ok, err := c.next(ctx)
 		if err != nil {
-			if ctx.Err() != nil {
-				return ctx.Err()
-			}
-			if retryErr := c.handleFailure(ctx, err); retryErr != nil {
-				return retryErr
-			}
-			continue
+			return err
 		}
-
-		c.failures = 0
-		if c.errCount.Load() > 0 {
-			c.log.InfoCtx(ctx, "Kafka connection recovered", map[string]any{
-				"topic":   c.topic,
-				"groupID": c.groupID,
-			})
-			c.errCount.Store(0)
+		if !ok {
+			continue
 		}
 
 		domainMsg := kafka.FromKafkaMessage(msg)
@@ -150,8 +174,66 @@ func (c *Consumer) Consume(ctx context.Context, handler messaging.MessageHandler
 	}
 }
 
+// resume moves a static reader to its partition's committed group offset,
+// or to the first offset when the group has none. Group readers resume on
+// their own.
+func (c *Consumer) resume(ctx context.Context) error {
+	if !c.static || c.groupID == "" {
+		return nil
+	}
+	resp, err := c.offsets.OffsetFetch(ctx, &kafkago.OffsetFetchRequest{
+		GroupID: c.groupID,
+		Topics:  map[string][]int{c.topic: {c.partition}},
+	})
+	if err == nil {
+		err = resp.Error
+	}
+	if err != nil {
+		return fmt.Errorf("kafka fetch offset of group %s for %s/%d: %w", c.groupID, c.topic, c.partition, err)
+	}
+	offset := kafkago.FirstOffset
+	for _, p := range resp.Topics[c.topic] {
+		if p.Partition != c.partition {
+			continue
+		}
+		if p.Error != nil {
+			return fmt.Errorf("kafka fetch offset of group %s for %s/%d: %w", c.groupID, c.topic, c.partition, p.Error)
+		}
+		if p.CommittedOffset >= 0 {
+			offset = p.CommittedOffset
+		}
+	}
+	if err := c.reader.SetOffset(offset); err != nil {
+		return fmt.Errorf("kafka seek %s/%d to %d: %w", c.topic, c.partition, offset, err)
+	}
+	return nil
+}
+
+// next reads the next message, backing off after read failures. It reports
+// false when the read failed and should be retried.
+func (c *Consumer) next(ctx context.Context) (kafkago.Message, bool, error) {
+	msg, err := c.read(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return msg, false, ctx.Err()
+		}
+		return msg, false, c.handleFailure(ctx, err)
+	}
+
+	c.failures = 0
+	if c.errCount.Load() > 0 {
+		c.log.InfoCtx(ctx, "Kafka connection recovered", map[string]any{
+			"topic":   c.topic,
+			"groupID": c.groupID,
+		})
+		c.errCount.Store(0)
+	}
+	return msg, true, nil
+}
+
+// read fetches without committing unless commits are automatic.
 func (c *Consumer) read(ctx context.Context) (kafkago.Message, error) {
-	if c.commitStrategy == messaging.CommitAfterHandlerSuccess {
+	if c.commitStrategy != messaging.CommitAuto {
 		return c.reader.FetchMessage(ctx)
 	}
 	return c.reader.ReadMessage(ctx)
@@ -0,0 +1,94 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/messaging/kafka"
+)
+
+// Transactor runs the transactions of a consume-transform-produce loop.
+// producer.TransactionalProducer implements it.
+type Transactor interface {
+	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	SendBatch(ctx context.Context, messages []messaging.Message) error
+	SendOffsets(ctx context.Context, groupID string, consumed ...messaging.Message) error
+}
+
+// TransformFunc maps a consumed message to the messages to produce for it.
+type TransformFunc func(ctx context.Context, msg messaging.Message) ([]messaging.Message, error)
+
+// ConsumeTransformProduce reads messages like Consume, and for each one runs
+// fn and produces its output through tx in a transaction that also commits
+// the message's offset to the consumer group. It blocks until ctx is
+// canceled or a transaction fails.
+//
+// The offsets carry no group generation, so the broker cannot fence a group
+// member that lost its partitions in a rebalance. Output is therefore
+// published exactly once for readers of committed data only when each input
+// partition is read by one consumer with a statically assigned partition
+// (kafka.Config.Partition) and tx uses a transactional ID owned by that
+// partition, such as "enricher-orders-3": a restarted instance then fences
+// its predecessor through the transactional ID. Consumers without a static
+// partition are rejected.
+//
+// Before reading, an empty transaction registers tx's transactional ID, which
+// fences the predecessor and settles its open transaction, and the reader
+// moves to the group's committed offset. A failed transaction is aborted and
+// its error returned; calling ConsumeTransformProduce again resumes from the
+// last committed offset.
+func (c *Consumer) ConsumeTransformProduce(ctx context.Context, tx Transactor, fn TransformFunc) error {
+	if c.groupID == "" {
+		return fmt.Errorf("kafka consumer: consume-transform-produce requires a consumer group")
+	}
+	if c.commitStrategy != messaging.CommitManual {
+		return fmt.Errorf("kafka consumer: consume-transform-produce requires %s commits", messaging.CommitManual)
+	}
+	if !c.static {
+		return fmt.Errorf("kafka consumer: consume-transform-produce requires a statically assigned partition")
+	}
+	if err := tx.InTransaction(ctx, func(context.Context) error { return nil }); err != nil {
+		return fmt.Errorf("kafka consume-transform-produce: register transactional ID: %w", err)
+	}
+	if err := c.resume(ctx); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		msg, ok, err := c.next(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		consumed := kafka.FromKafkaMessage(msg)
+		err = tx.InTransaction(ctx, func(ctx context.Context) error {
+			out, err := fn(ctx, consumed)
+			if err != nil {
+				return err
+			}
+			if len(out) > 0 {
+				if err := tx.SendBatch(ctx, out); err != nil {
+					return err
+				}
+			}
+			return tx.SendOffsets(ctx, c.groupID, consumed)
+		})
+		if err != nil {
+			c.log.ErrorCtx(ctx, "Transaction failed", map[string]any{
+				"error":  err.Error(),
+				"topic":  msg.Topic,
+				"offset": msg.Offset,
+			})
+			return fmt.Errorf("kafka consume-transform-produce at %s/%d@%d: %w", msg.Topic, msg.Partition, msg.Offset, err)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"github.com/kbukum/gokit/logging"
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/messaging/kafka"
+)
+
+type fakeReader struct {
+	messages  []kafkago.Message
+	reads     int
+	committed int
+	seeks     []int64
+}
+
+func (r *fakeReader) ReadMessage(ctx context.Context) (kafkago.Message, error) {
+	return kafkago.Message{}, errors.New("unexpected ReadMessage")
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafkago.Message, error) {
+	if r.reads == len(r.messages) {
+		<-ctx.Done()
+		return kafkago.Message{}, ctx.Err()
+	}
+	r.reads++
+	return r.messages[r.reads-1], nil
+}
+
+func (r *fakeReader) CommitMessages(context.Context, ...kafkago.Message) error {
+	r.committed++
+	return nil
+}
+
+func (r *fakeReader) SetOffset(offset int64) error {
+	r.seeks = append(r.seeks, offset)
+	return nil
+}
+
+func (r *fakeReader) Stats() kafkago.ReaderStats { return kafkago.ReaderStats{} }
+
+func (r *fakeReader) Close() error { return nil }
+
+// fakeOffsets serves a group's committed offset and records when it was read.
+type fakeOffsets struct {
+	committed int64
+	events    *[]string
+}
+
+func (o *fakeOffsets) OffsetFetch(_ context.Context, req *kafkago.OffsetFetchRequest) (*kafkago.OffsetFetchResponse, error) {
+	*o.events = append(*o.events, "fetch "+req.GroupID)
+	resp := &kafkago.OffsetFetchResponse{Topics: map[string][]kafkago.OffsetFetchPartition{}}
+	for topic, partitions := range req.Topics {
+		for _, p := range partitions {
+			resp.Topics[topic] = append(resp.Topics[topic], kafkago.OffsetFetchPartition{Partition: p, CommittedOffset: o.committed})
+		}
+	}
+	return resp, nil
+}
+
+type fakeTransactor struct {
+	events  *[]string
+	txns    int
+	sent    []messaging.Message
+	offsets []messaging.Message
+	groups  []string
+	cancel  func()
+}
+
+func (t *fakeTransactor) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.txns++
+	if t.events != nil {
+		*t.events = append(*t.events, "transaction")
+	}
+	return fn(ctx)
+}
+
+func (t *fakeTransactor) SendBatch(_ context.Context, messages []messaging.Message) error {
+	t.sent = append(t.sent, messages...)
+	return nil
+}
+
+func (t *fakeTransactor) SendOffsets(_ context.Context, groupID string, consumed ...messaging.Message) error {
+	t.groups = append(t.groups, groupID)
+	t.offsets = append(t.offsets, consumed...)
+	if len(t.offsets) == 2 && t.cancel != nil {
+		t.cancel()
+	}
+	return nil
+}
+
+func newTransformConsumer(reader *fakeReader) *Consumer {
+	return &Consumer{
+		reader:         reader,
+		topic:          "orders",
+		groupID:        "enricher",
+		static:         true,
+		partition:      1,
+		offsets:        &fakeOffsets{committed: kafkago.FirstOffset, events: new([]string)},
+		log:            logging.New(&logging.Config{Level: "error"}, "test"),
+		errCount:       &atomic.Int64{},
+		commitStrategy: messaging.CommitManual,
+	}
+}
+
+func TestConsumeTransformProduce_SendsOutputWithOffsets(t *testing.T) {
+	reader := &fakeReader{messages: []kafkago.Message{
+		{Topic: "orders", Partition: 1, Offset: 7, Key: []byte("a"), Value: []byte("one")},
+		{Topic: "orders", Partition: 1, Offset: 8, Key: []byte("b"), Value: []byte("skip")},
+	}}
+	c := newTransformConsumer(reader)
+	var events []string
+	c.offsets = &fakeOffsets{committed: 7, events: &events}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tx := &fakeTransactor{events: &events, cancel: cancel}
+
+	err := c.ConsumeTransformProduce(ctx, tx, func(_ context.Context, msg messaging.Message) ([]messaging.Message, error) {
+		if string(msg.Value) == "skip" {
+			return nil, nil
+		}
+		return []messaging.Message{{Topic: "enriched", Key: msg.Key, Value: []byte(strings.ToUpper(string(msg.Value)))}}, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ConsumeTransformProduce() = %v, want context.Canceled", err)
+	}
+	// The first, empty transaction fences the previous owner of the
+	// transactional ID before the committed offset is read.
+	if len(events) < 2 || events[0] != "transaction" || events[1] != "fetch enricher" {
+		t.Fatalf("events = %v, want an empty transaction before the offset fetch", events)
+	}
+	if len(reader.seeks) != 1 || reader.seeks[0] != 7 {
+		t.Fatalf("seeks = %v, want the committed offset 7", reader.seeks)
+	}
+	if tx.txns != 3 {
+		t.Fatalf("transactions = %d, want 3", tx.txns)
+	}
+	if len(tx.sent) != 1 || tx.sent[0].Topic != "enriched" || string(tx.sent[0].Value) != "ONE" {
+		t.Fatalf("sent = %+v, want one enriched message", tx.sent)
+	}
+	if len(tx.offsets) != 2 || tx.offsets[0].Offset != 7 || tx.offsets[1].Offset != 8 || tx.offsets[1].Partition != 1 {
+		t.Fatalf("offsets = %+v, want offsets 7 and 8 of partition 1", tx.offsets)
+	}
+	for _, g := range tx.groups {
+		if g != "enricher" {
+			t.Fatalf("offsets sent for group %q, want enricher", g)
+		}
+	}
+	if reader.committed != 0 {
+		t.Fatalf("reader committed %d times, want offsets committed only through the transaction", reader.committed)
+	}
+}
+
+func TestConsumeTransformProduce_ReturnsTransformError(t *testing.T) {
+	reader := &fakeReader{messages: []kafkago.Message{{Topic: "orders", Partition: 0, Offset: 3}}}
+	c := newTransformConsumer(reader)
+	tx := &fakeTransactor{}
+	boom := errors.New("boom")
+
+	err := c.ConsumeTransformProduce(context.Background(), tx, func(context.Context, messaging.Message) ([]messaging.Message, error) {
+		return nil, boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("ConsumeTransformProduce() = %v, want %v", err, boom)
+	}
+	if !strings.Contains(err.Error(), "orders/0@3") {
+		t.Fatalf("error %q does not name the failed message", err)
+	}
+	if len(tx.offsets) != 0 {
+		t.Fatalf("offsets = %+v, want none after a failed transform", tx.offsets)
+	}
+}
+
+func TestConsumeTransformProduce_StartsAtFirstOffsetWithoutCommit(t *testing.T) {
+	reader := &fakeReader{}
+	c := newTransformConsumer(reader)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := c.ConsumeTransformProduce(ctx, &fakeTransactor{}, func(context.Context, messaging.Message) ([]messaging.Message, error) {
+		return nil, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ConsumeTransformProduce() = %v, want context.Canceled", err)
+	}
+	if len(reader.seeks) != 1 || reader.seeks[0] != kafkago.FirstOffset {
+		t.Fatalf("seeks = %v, want the first offset", reader.seeks)
+	}
+}
+
+func TestConsumeTransformProduce_RequiresGroupAndManualCommits(t *testing.T) {
+	fn := func(context.Context, messaging.Message) ([]messaging.Message, error) { return nil, nil }
+
+	c := newTransformConsumer(&fakeReader{})
+	c.groupID = ""
+	if err := c.ConsumeTransformProduce(context.Background(), &fakeTransactor{}, fn); err == nil {
+		t.Fatal("expected an error without a consumer group")
+	}
+
+	c = newTransformConsumer(&fakeReader{})
+	c.commitStrategy = messaging.CommitAfterHandlerSuccess
+	if err := c.ConsumeTransformProduce(context.Background(), &fakeTransactor{}, fn); err == nil {
+		t.Fatal("expected an error without manual commits")
+	}
+
+	// Group-managed partitions could move to another member mid-transaction.
+	c = newTransformConsumer(&fakeReader{})
+	c.static = false
+	tx := &fakeTransactor{}
+	if err := c.ConsumeTransformProduce(context.Background(), tx, fn); err == nil {
+		t.Fatal("expected an error without a statically assigned partition")
+	}
+	if tx.txns != 0 {
+		t.Fatalf("transactions = %d, want none for a rejected consumer", tx.txns)
+	}
+}
+
+func TestNewConsumer_ExactlyOnceNeedsStaticPartition(t *testing.T) {
+	common := messaging.Config{
+		Adapter:           "kafka",
+		ConsumerGroup:     "enricher",
+		DeliveryGuarantee: messaging.DeliveryExactlyOnce,
+		CommitStrategy:    messaging.CommitManual,
+	}
+	cfg := kafka.Config{Brokers: []string{"127.0.0.1:1"}, AllowInsecureDev: true}
+	if _, err := NewConsumer(common, cfg, "orders", nil); err == nil {
+		t.Fatal("expected exactly-once consumer without a partition to be rejected")
+	}
+
+	partition := 3
+	cfg.Partition = &partition
+	c, err := NewConsumer(common, cfg, "orders", nil)
+	if err != nil {
+		t.Fatalf("NewConsumer() error: %v", err)
+	}
+	defer c.Close()
+	if !c.static || c.partition != 3 || c.offsets == nil {
+		t.Fatalf("consumer static=%v partition=%d, want a static reader of partition 3", c.static, c.partition)
+	}
+	if c.GroupID() != "enricher" {
+		t.Fatalf("group = %q, want enricher for offset commits", c.GroupID())
+	}
+}
@@ -9,6 +9,16 @@
 //   - kafka/producer: Message publishing with delivery guarantees
 //   - kafka/consumer: Message consumption with managed consumer groups
 //
+// # Transactions
+//
+// Exactly-once delivery uses Kafka transactions: producer.TransactionalProducer
+// publishes atomically under Config.TransactionalID and can commit consumed
+// offsets in the same transaction, and consumer.Consumer.ConsumeTransformProduce
+// runs a read-committed consume-transform-produce loop on top of it. Offsets
+// are committed without a group generation, so exactly-once consumers read a
+// statically assigned Config.Partition instead of joining group rebalancing,
+// each paired with a transactional ID of its own.
+//
 // # Configuration
 //
 // Kafka-specific connection/protocol settings are provided via Config with ApplyDefaults()/Validate().
@@ -253,24 +253,44 @@ func (p *Producer) SendBatch(ctx context.Context, messages []messaging.Message)
 
 // PublishJSON marshals value as JSON and publishes it to the given topic.
 func (p *Producer) PublishJSON(ctx context.Context, topic, key string, value any) error {
+	msg, err := jsonMessage(topic, key, value)
+	if err != nil {
+		return err
+	}
+	return p.WriteMessages(ctx, msg)
+}
+
+// PublishBinary publishes raw bytes to the given topic (e.g. protobuf, avro).
+func (p *Producer) PublishBinary(ctx context.Context, topic, key string, data []byte) error {
+	return p.WriteMessages(ctx, binaryMessage(topic, key, data))
+}
+
+// Publish sends a structured gokit Event to Kafka with event metadata headers.
+func (p *Producer) Publish(ctx context.Context, topic string, event messaging.Event, key ...string) error {
+	msg, err := eventMessage(topic, event, key...)
+	if err != nil {
+		return err
+	}
+	return p.WriteMessages(ctx, msg)
+}
+
+func jsonMessage(topic, key string, value any) (kafkago.Message, error) {
 	data, err := json.Marshal(value)
 	if err != nil {
-		return fmt.Errorf("marshal JSON: %w", err)
+		return kafkago.Message{}, fmt.Errorf("marshal JSON: %w", err)
 	}
-	msg := kafkago.Message{
+	return kafkago.Message{
 		Topic: topic,
 		Key:   []byte(key),
 		Value: data,
 		Headers: []kafkago.Header{
 			{Key: "content-type", Value: []byte("application/json")},
 		},
-	}
-	return p.WriteMessages(ctx, msg)
+	}, nil
 }
 
-// PublishBinary publishes raw bytes to the given topic (e.g. protobuf, avro).
-func (p *Producer) PublishBinary(ctx context.Context, topic, key string, data []byte) error {
-	msg := kafkago.Message{
+func binaryMessage(topic, key string, data []byte) kafkago.Message {
+	return kafkago.Message{
 		Topic: topic,
 		Key:   []byte(key),
 		Value: data,
@@ -278,14 +298,12 @@ func (p *Producer) PublishBinary(ctx context.Context, topic, key string, data []
 			{Key: "content-type", Value: []byte("application/octet-stream")},
 		},
 	}
-	return p.WriteMessages(ctx, msg)
 }
 
-// Publish sends a structured gokit Event to Kafka with event metadata headers.
-func (p *Producer) Publish(ctx context.Context, topic string, event messaging.Event, key ...string) error {
+func eventMessage(topic string, event messaging.Event, key ...string) (kafkago.Message, error) {
 	data, err := event.ToJSON()
 	if err != nil {
-		return fmt.Errorf("marshal event: %w", err)
+		return kafkago.Message{}, fmt.Errorf("marshal event: %w", err)
 	}
 
 	partitionKey := event.Subject
@@ -296,7 +314,7 @@ func (p *Producer) Publish(ctx context.Context, topic string, event messaging.Ev
 		partitionKey = event.ID
 	}
 
-	msg := kafkago.Message{
+	return kafkago.Message{
 		Topic: topic,
 		Key:   []byte(partitionKey),
 		Value: data,
@@ -307,9 +325,7 @@ func (p *Producer) Publish(ctx context.Context, topic string, event messaging.Ev
 			{Key: "content-type", Value: []byte("application/json")},
 		},
 		Time: event.Timestamp,
-	}
-
-	return p.WriteMessages(ctx, msg)
+	}, nil
 }
 
 // Verify Producer implements messaging.Producer at compile time.
@@ -11,12 +11,16 @@
 
 const adapterName = "kafka"
 
-// Register adds a typed lazy Kafka producer factory to registry.
+// Register adds a typed lazy Kafka producer factory to registry. Configs
+// requesting exactly-once delivery get a TransactionalProducer.
 func Register(registry *messaging.Registry, cfg kafka.Config) error {
 	if registry == nil {
 		return fmt.Errorf("kafka producer: messaging registry is nil")
 	}
 	return registry.RegisterProducer(adapterName, func(_ context.Context, common messaging.Config, log *logging.Logger) (messaging.Producer, error) {
+		if common.DeliveryGuarantee == messaging.DeliveryExactlyOnce {
+			return NewTransactionalProducer(common, cfg, log) //nolint:contextcheck // transactional producer connects on first transaction
+		}
 		return NewLazyProducer(common, cfg, log) //nolint:contextcheck // lazy producer construction does not perform request-scoped I/O
 	})
 }
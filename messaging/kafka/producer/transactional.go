@@ -0,0 +1,646 @@
+package producer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"sync"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+
+	"github.com/kbukum/gokit/logging"
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/messaging/kafka"
+)
+
+// txnClient is the subset of kafkago.Client a TransactionalProducer uses. The
+// client routes transactional requests to the transaction coordinator, offset
+// commits to the group coordinator, and produce requests to partition leaders.
+type txnClient interface {
+	Metadata(context.Context, *kafkago.MetadataRequest) (*kafkago.MetadataResponse, error)
+	InitProducerID(context.Context, *kafkago.InitProducerIDRequest) (*kafkago.InitProducerIDResponse, error)
+	AddPartitionsToTxn(context.Context, *kafkago.AddPartitionsToTxnRequest) (*kafkago.AddPartitionsToTxnResponse, error)
+	RawProduce(context.Context, *kafkago.RawProduceRequest) (*kafkago.ProduceResponse, error)
+	AddOffsetsToTxn(context.Context, *kafkago.AddOffsetsToTxnRequest) (*kafkago.AddOffsetsToTxnResponse, error)
+	TxnOffsetCommit(context.Context, *kafkago.TxnOffsetCommitRequest) (*kafkago.TxnOffsetCommitResponse, error)
+	EndTxn(context.Context, *kafkago.EndTxnRequest) (*kafkago.EndTxnResponse, error)
+}
+
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+type cachedPartitions struct {
+	ids     []int
+	expires time.Time
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// errNoTransaction is returned by calls that need an open transaction.
+var errNoTransaction = errors.New("kafka producer: no open transaction")
+
+// ErrProducerFenced is returned once a newer producer with the same
+// transactional ID has taken over. The fenced producer closes itself: it must
+// not re-initialize, since that would fence the instance that replaced it.
+var ErrProducerFenced = errors.New("kafka producer: fenced by a newer producer with the same transactional ID")
+
+// TransactionalProducer writes messages to Kafka inside transactions, so
+// consumers reading committed data see all of a transaction's messages or
+// none of them. SendOffsets commits a consumer group's offsets in the same
+// transaction, which makes consume-transform-produce exactly-once when each
+// input partition is read by one statically assigned consumer paired with a
+// transactional ID of its own (see consumer.Consumer.ConsumeTransformProduce).
+//
+// One transaction is open at a time. Begin opens it explicitly, and Send,
+// SendBatch and the Publish methods called while it is open join it;
+// otherwise each call runs in a transaction of its own. Keyed messages are
+// partitioned by key hash. After a failed or aborted transaction the
+// producer re-initializes, bumping its epoch, before the next one. A producer
+// the broker reports as fenced closes instead, and every later call returns
+// ErrProducerFenced.
+type TransactionalProducer struct {
+	client          txnClient
+	name            string
+	transactionalID string
+	txnTimeout      time.Duration
+	compression     kafkago.Compression
+	metadataTTL     time.Duration
+	balancer        *kafkago.Hash
+	log             *logging.Logger
+	now             func() time.Time
+
+	mu           sync.Mutex
+	closed       bool
+	fenced       bool
+	initialized  bool
+	producerID   int
+	epoch        int
+	sequences    map[topicPartition]int32
+	partitions   map[string]cachedPartitions
+	inTxn        bool
+	failed       error
+	added        map[topicPartition]bool
+	offsetGroups map[string]bool
+}
+
+// NewTransactionalProducer creates a transactional Kafka producer. It connects
+// when the first transaction begins. cfg.TransactionalID must be set.
+func NewTransactionalProducer(common messaging.Config, cfg kafka.Config, log *logging.Logger) (*TransactionalProducer, error) {
+	common.ApplyDefaults()
+	if err := common.Validate(); err != nil {
+		return nil, fmt.Errorf("kafka producer common config: %w", err)
+	}
+	if !common.IsEnabled() {
+		return nil, fmt.Errorf("kafka producer: messaging is disabled")
+	}
+	if err := kafka.ValidateCommonTransactionalProducer(common, cfg); err != nil {
+		return nil, err
+	}
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("kafka producer config: %w", err)
+	}
+
+	requestTimeout, err := time.ParseDuration(common.RequestTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("kafka producer request_timeout: %w", err)
+	}
+	transport, err := kafka.CreateTransport(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("kafka producer transport: %w", err)
+	}
+	name := common.Name
+	if name == "" {
+		name = defaultProviderName
+	}
+	if log == nil {
+		log = logging.NewDefault("messaging")
+	}
+	return &TransactionalProducer{
+		client: &kafkago.Client{
+			Addr:      kafkago.TCP(cfg.Brokers...),
+			Timeout:   requestTimeout,
+			Transport: transport,
+		},
+		name:            name,
+		transactionalID: cfg.TransactionalID,
+		txnTimeout:      kafka.ParseDuration(cfg.TransactionTimeout),
+		compression:     kafka.ResolveCompression(cfg.Compression),
+		metadataTTL:     kafka.ParseDuration(cfg.MetadataTTL),
+		balancer:        &kafkago.Hash{},
+		log:             log.WithComponent("kafka.producer"),
+		now:             time.Now,
+		partitions:      make(map[string]cachedPartitions),
+	}, nil
+}
+
+// Name returns the producer name.
+func (p *TransactionalProducer) Name() string { return p.name }
+
+// IsAvailable reports whether the producer is open.
+func (p *TransactionalProducer) IsAvailable(_ context.Context) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.closed
+}
+
+// Begin opens a transaction. The first call registers the transactional ID
+// with its coordinator, fencing off any older producer using it.
+func (p *TransactionalProducer) Begin(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.beginLocked(ctx)
+}
+
+func (p *TransactionalProducer) beginLocked(ctx context.Context) error {
+	if p.fenced {
+		return ErrProducerFenced
+	}
+	if p.closed {
+		return messaging.ErrClosed
+	}
+	if p.inTxn {
+		return fmt.Errorf("kafka producer: transaction already open")
+	}
+	if err := p.initLocked(ctx); err != nil {
+		return err
+	}
+	p.inTxn = true
+	p.failed = nil
+	p.added = make(map[topicPartition]bool)
+	p.offsetGroups = make(map[string]bool)
+	return nil
+}
+
+func (p *TransactionalProducer) initLocked(ctx context.Context) error {
+	if p.initialized {
+		return nil
+	}
+	resp, err := p.client.InitProducerID(ctx, &kafkago.InitProducerIDRequest{
+		TransactionalID:      p.transactionalID,
+		TransactionTimeoutMs: int(p.txnTimeout.Milliseconds()),
+	})
+	if err == nil {
+		err = resp.Error
+	}
+	if err != nil {
+		return fmt.Errorf("kafka init producer id %q: %w", p.transactionalID, p.fenceLocked(err))
+	}
+	p.producerID = resp.Producer.ProducerID
+	p.epoch = resp.Producer.ProducerEpoch
+	p.sequences = make(map[topicPartition]int32)
+	p.initialized = true
+	p.log.Debug("Kafka transactional producer initialized", map[string]any{
+		"transactional_id": p.transactionalID,
+		"producer_id":      p.producerID,
+		"epoch":            p.epoch,
+	})
+	return nil
+}
+
+// Commit commits the open transaction. If any write or offset commit in it
+// failed, the transaction is aborted instead and the failure returned.
+func (p *TransactionalProducer) Commit(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fenced {
+		return ErrProducerFenced
+	}
+	if !p.inTxn {
+		return errNoTransaction
+	}
+	if failed := p.failed; failed != nil {
+		return errors.Join(fmt.Errorf("kafka transaction aborted: %w", failed), p.endLocked(ctx, false))
+	}
+	return p.endLocked(ctx, true)
+}
+
+// Abort aborts the open transaction, discarding its messages and offsets.
+// It is a no-op without one.
+func (p *TransactionalProducer) Abort(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.inTxn {
+		return nil
+	}
+	return p.endLocked(ctx, false)
+}
+
+// endLocked ends the open transaction. A transaction nothing was written to
+// exists only on this side and needs no request.
+func (p *TransactionalProducer) endLocked(ctx context.Context, commit bool) error {
+	p.inTxn = false
+	if len(p.added) == 0 && len(p.offsetGroups) == 0 {
+		return nil
+	}
+	if !commit {
+		// Abort even when the caller's context is done; the client timeout
+		// still bounds the request.
+		ctx = context.WithoutCancel(ctx)
+	}
+	resp, err := p.client.EndTxn(ctx, &kafkago.EndTxnRequest{
+		TransactionalID: p.transactionalID,
+		ProducerID:      p.producerID,
+		ProducerEpoch:   p.epoch,
+		Committed:       commit,
+	})
+	if err == nil {
+		err = resp.Error
+	}
+	err = p.fenceLocked(err)
+	if (err != nil || !commit) && !p.fenced {
+		// Sequence numbers are uncertain after a failure; a fresh epoch
+		// resets them and aborts anything the coordinator still holds.
+		p.initialized = false
+	}
+	if err != nil {
+		if commit {
+			return fmt.Errorf("kafka commit transaction: %w", err)
+		}
+		return fmt.Errorf("kafka abort transaction: %w", err)
+	}
+	return nil
+}
+
+// fenceLocked closes the producer when err reports that a newer producer
+// with the same transactional ID has fenced it, and returns err wrapped with
+// ErrProducerFenced. Other errors are returned unchanged.
+func (p *TransactionalProducer) fenceLocked(err error) error {
+	if err == nil || errors.Is(err, ErrProducerFenced) {
+		return err
+	}
+	if !errors.Is(err, kafkago.ProducerFenced) && !errors.Is(err, kafkago.InvalidProducerEpoch) {
+		return err
+	}
+	if !p.fenced {
+		p.log.Warn("Kafka transactional producer fenced", map[string]any{
+			"transactional_id": p.transactionalID,
+			"producer_id":      p.producerID,
+			"epoch":            p.epoch,
+		})
+	}
+	p.fenced, p.closed, p.inTxn = true, true, false
+	return fmt.Errorf("%w: %w", ErrProducerFenced, err)
+}
+
+// InTransaction runs fn in a transaction, committing it when fn returns nil
+// and aborting it otherwise.
+func (p *TransactionalProducer) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := p.Begin(ctx); err != nil {
+		return err
+	}
+	if err := fn(ctx); err != nil {
+		return errors.Join(err, p.Abort(ctx))
+	}
+	return p.Commit(ctx)
+}
+
+// SendOffsets commits consumed messages' offsets for groupID as part of the
+// open transaction: the group resumes after the latest message of each
+// partition once the transaction commits. Offsets are committed without a
+// group generation or member ID, so the broker cannot tell a current group
+// member from one that lost its partitions in a rebalance: fencing relies
+// entirely on the transactional ID, which must therefore belong to the input
+// partition rather than the process.
+func (p *TransactionalProducer) SendOffsets(ctx context.Context, groupID string, consumed ...messaging.Message) error {
+	if groupID == "" {
+		return fmt.Errorf("kafka send offsets: consumer group is required")
+	}
+	next := make(map[topicPartition]int64)
+	for _, msg := range consumed {
+		tp := topicPartition{topic: msg.Topic, partition: msg.Partition}
+		if offset, ok := next[tp]; !ok || msg.Offset+1 > offset {
+			next[tp] = msg.Offset + 1
+		}
+	}
+	if len(next) == 0 {
+		return nil
+	}
+	topics := make(map[string][]kafkago.TxnOffsetCommit)
+	for tp, offset := range next {
+		topics[tp.topic] = append(topics[tp.topic], kafkago.TxnOffsetCommit{Partition: tp.partition, Offset: offset})
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fenced {
+		return ErrProducerFenced
+	}
+	if p.closed {
+		return messaging.ErrClosed
+	}
+	if !p.inTxn {
+		return errNoTransaction
+	}
+	err := p.fenceLocked(p.sendOffsetsLocked(ctx, groupID, topics))
+	if err != nil && p.failed == nil {
+		p.failed = err
+	}
+	return err
+}
+
+func (p *TransactionalProducer) sendOffsetsLocked(ctx context.Context, groupID string, topics map[string][]kafkago.TxnOffsetCommit) error {
+	if !p.offsetGroups[groupID] {
+		resp, err := p.client.AddOffsetsToTxn(ctx, &kafkago.AddOffsetsToTxnRequest{
+			TransactionalID: p.transactionalID,
+			ProducerID:      p.producerID,
+			ProducerEpoch:   p.epoch,
+			GroupID:         groupID,
+		})
+		if err == nil {
+			err = resp.Error
+		}
+		if err != nil {
+			return fmt.Errorf("kafka add offsets of group %s to transaction: %w", groupID, err)
+		}
+		p.offsetGroups[groupID] = true
+	}
+	resp, err := p.client.TxnOffsetCommit(ctx, &kafkago.TxnOffsetCommitRequest{
+		TransactionalID: p.transactionalID,
+		GroupID:         groupID,
+		ProducerID:      p.producerID,
+		ProducerEpoch:   p.epoch,
+		GenerationID:    -1,
+		Topics:          topics,
+	})
+	if err != nil {
+		return fmt.Errorf("kafka commit offsets of group %s: %w", groupID, err)
+	}
+	for topic, partitions := range resp.Topics {
+		for _, partition := range partitions {
+			if partition.Error != nil {
+				return fmt.Errorf("kafka commit offset of group %s for %s/%d: %w", groupID, topic, partition.Partition, partition.Error)
+			}
+		}
+	}
+	return nil
+}
+
+// WriteMessages writes msgs in the open transaction, or in one of their own.
+func (p *TransactionalProducer) WriteMessages(ctx context.Context, msgs ...kafkago.Message) error {
+	for i := range msgs {
+		if err := messaging.ValidateTopic(msgs[i].Topic); err != nil {
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.inTxn {
+		err := p.fenceLocked(p.produceLocked(ctx, msgs))
+		if err != nil && p.failed == nil {
+			p.failed = err
+		}
+		return err
+	}
+	if err := p.beginLocked(ctx); err != nil {
+		return err
+	}
+	if err := p.fenceLocked(p.produceLocked(ctx, msgs)); err != nil {
+		if p.fenced {
+			return err
+		}
+		return errors.Join(err, p.endLocked(ctx, false))
+	}
+	return p.endLocked(ctx, true)
+}
+
+// produceLocked writes msgs as one record batch per partition, in order,
+// adding partitions new to the transaction first.
+func (p *TransactionalProducer) produceLocked(ctx context.Context, msgs []kafkago.Message) error {
+	var order []topicPartition
+	batches := make(map[topicPartition][]kafkago.Record)
+	for i := range msgs {
+		partition, err := p.partitionLocked(ctx, msgs[i])
+		if err != nil {
+			return err
+		}
+		tp := topicPartition{topic: msgs[i].Topic, partition: partition}
+		if _, ok := batches[tp]; !ok {
+			order = append(order, tp)
+		}
+		batches[tp] = append(batches[tp], kafkago.Record{
+			Time:    msgs[i].Time,
+			Key:     recordBytes(msgs[i].Key),
+			Value:   recordBytes(msgs[i].Value),
+			Headers: msgs[i].Headers,
+		})
+	}
+
+	if err := p.addPartitionsLocked(ctx, order); err != nil {
+		return err
+	}
+	for _, tp := range order {
+		records := batches[tp]
+		sequence := p.sequences[tp]
+		raw, err := encodeTransactionalBatch(records, p.compression, p.producerID, p.epoch, sequence)
+		if err != nil {
+			return fmt.Errorf("kafka encode batch for %s/%d: %w", tp.topic, tp.partition, err)
+		}
+		resp, err := p.client.RawProduce(ctx, &kafkago.RawProduceRequest{
+			Topic:           tp.topic,
+			Partition:       tp.partition,
+			RequiredAcks:    kafkago.RequireAll,
+			TransactionalID: p.transactionalID,
+			RawRecords:      raw,
+		})
+		if err == nil {
+			err = resp.Error
+		}
+		if err != nil {
+			return fmt.Errorf("kafka produce to %s/%d: %w", tp.topic, tp.partition, err)
+		}
+		p.sequences[tp] = sequence + int32(len(records))
+	}
+	return nil
+}
+
+func (p *TransactionalProducer) addPartitionsLocked(ctx context.Context, tps []topicPartition) error {
+	topics := make(map[string][]kafkago.AddPartitionToTxn)
+	for _, tp := range tps {
+		if !p.added[tp] {
+			topics[tp.topic] = append(topics[tp.topic], kafkago.AddPartitionToTxn{Partition: tp.partition})
+		}
+	}
+	if len(topics) == 0 {
+		return nil
+	}
+	resp, err := p.client.AddPartitionsToTxn(ctx, &kafkago.AddPartitionsToTxnRequest{
+		TransactionalID: p.transactionalID,
+		ProducerID:      p.producerID,
+		ProducerEpoch:   p.epoch,
+		Topics:          topics,
+	})
+	if err != nil {
+		return fmt.Errorf("kafka add partitions to transaction: %w", err)
+	}
+	for topic, partitions := range resp.Topics {
+		for _, partition := range partitions {
+			if partition.Error != nil {
+				return fmt.Errorf("kafka add %s/%d to transaction: %w", topic, partition.Partition, partition.Error)
+			}
+		}
+	}
+	for topic, partitions := range topics {
+		for _, partition := range partitions {
+			p.added[topicPartition{topic: topic, partition: partition.Partition}] = true
+		}
+	}
+	return nil
+}
+
+// partitionLocked picks msg's partition: by key hash, or round robin for
+// messages without a key.
+func (p *TransactionalProducer) partitionLocked(ctx context.Context, msg kafkago.Message) (int, error) {
+	cached, ok := p.partitions[msg.Topic]
+	if !ok || !p.now().Before(cached.expires) {
+		ids, err := p.fetchPartitions(ctx, msg.Topic)
+		if err != nil {
+			return 0, err
+		}
+		cached = cachedPartitions{ids: ids, expires: p.now().Add(p.metadataTTL)}
+		p.partitions[msg.Topic] = cached
+	}
+	return p.balancer.Balance(msg, cached.ids...), nil
+}
+
+func (p *TransactionalProducer) fetchPartitions(ctx context.Context, topic string) ([]int, error) {
+	resp, err := p.client.Metadata(ctx, &kafkago.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, fmt.Errorf("kafka metadata for %s: %w", topic, err)
+	}
+	for _, t := range resp.Topics {
+		if t.Name != topic {
+			continue
+		}
+		if t.Error != nil {
+			return nil, fmt.Errorf("kafka metadata for %s: %w", topic, t.Error)
+		}
+		ids := make([]int, 0, len(t.Partitions))
+		for _, partition := range t.Partitions {
+			ids = append(ids, partition.ID)
+		}
+		if len(ids) == 0 {
+			break
+		}
+		sort.Ints(ids)
+		return ids, nil
+	}
+	return nil, fmt.Errorf("kafka metadata for %s: %w", topic, kafkago.UnknownTopicOrPartition)
+}
+
+// Send writes a single domain message (implements messaging.Producer).
+func (p *TransactionalProducer) Send(ctx context.Context, msg messaging.Message) error {
+	if err := p.WriteMessages(ctx, kafka.ToKafkaMessage(msg)); err != nil {
+		return fmt.Errorf("kafka producer send: %w", err)
+	}
+	return nil
+}
+
+// SendBatch writes pre-built messages in order. Outside an open transaction
+// the batch is committed atomically.
+func (p *TransactionalProducer) SendBatch(ctx context.Context, messages []messaging.Message) error {
+	msgs := make([]kafkago.Message, 0, len(messages))
+	for _, msg := range messages {
+		msgs = append(msgs, kafka.ToKafkaMessage(msg))
+	}
+	return p.WriteMessages(ctx, msgs...)
+}
+
+// PublishJSON marshals value as JSON and publishes it to the given topic.
+func (p *TransactionalProducer) PublishJSON(ctx context.Context, topic, key string, value any) error {
+	msg, err := jsonMessage(topic, key, value)
+	if err != nil {
+		return err
+	}
+	return p.WriteMessages(ctx, msg)
+}
+
+// PublishBinary publishes raw bytes to the given topic.
+func (p *TransactionalProducer) PublishBinary(ctx context.Context, topic, key string, data []byte) error {
+	return p.WriteMessages(ctx, binaryMessage(topic, key, data))
+}
+
+// Publish sends a structured gokit Event with event metadata headers.
+func (p *TransactionalProducer) Publish(ctx context.Context, topic string, event messaging.Event, key ...string) error {
+	msg, err := eventMessage(topic, event, key...)
+	if err != nil {
+		return err
+	}
+	return p.WriteMessages(ctx, msg)
+}
+
+// Flush is a no-op: every write waits for the partition leaders' acks. It
+// reports cancellation and closed-producer state.
+func (p *TransactionalProducer) Flush(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return messaging.ErrClosed
+	}
+	return nil
+}
+
+// Close aborts an open transaction and closes the producer.
+func (p *TransactionalProducer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	p.log.Debug("Kafka transactional producer closing")
+	if p.inTxn {
+		return p.endLocked(context.Background(), false)
+	}
+	return nil
+}
+
+func recordBytes(b []byte) kafkago.Bytes {
+	if len(b) == 0 {
+		return nil
+	}
+	return kafkago.NewBytes(b)
+}
+
+// encodeTransactionalBatch encodes records as one v2 record batch flagged
+// transactional and stamped with the producer's ID, epoch and first sequence
+// number. kafka-go writes -1 for those three fields, so they are patched into
+// the encoded batch, whose CRC then covers everything from the attributes on.
+func encodeTransactionalBatch(records []kafkago.Record, compression kafkago.Compression, producerID, epoch int, sequence int32) (protocol.RawRecordSet, error) {
+	rs := protocol.RecordSet{
+		Version:    2,
+		Attributes: protocol.Attributes(compression) | protocol.Transactional,
+		Records:    kafkago.NewRecordReader(records...),
+	}
+	var buf bytes.Buffer
+	if _, err := rs.WriteTo(&buf); err != nil {
+		return protocol.RawRecordSet{}, err
+	}
+	// The batch follows a 4-byte size prefix; offsets are those of the
+	// record batch v2 header.
+	b := buf.Bytes()
+	batch := b[4:]
+	binary.BigEndian.PutUint64(batch[43:], uint64(producerID))
+	binary.BigEndian.PutUint16(batch[51:], uint16(epoch))
+	binary.BigEndian.PutUint32(batch[53:], uint32(sequence))
+	binary.BigEndian.PutUint32(batch[17:], crc32.Checksum(batch[21:], castagnoli))
+	return protocol.RawRecordSet{Reader: bytes.NewReader(b)}, nil
+}
+
+// Verify TransactionalProducer implements messaging.Producer at compile time.
+var _ messaging.Producer = (*TransactionalProducer)(nil)
@@ -0,0 +1,396 @@
+package producer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"testing"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+
+	"github.com/kbukum/gokit/logging"
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/messaging/kafka"
+)
+
+type producedBatch struct {
+	topic      string
+	partition  int
+	producerID int64
+	epoch      int16
+	sequence   int32
+	txn        bool
+	keys       []string
+}
+
+// fakeTxnClient records the transactional protocol calls of a producer.
+type fakeTxnClient struct {
+	inits       int
+	epoch       int
+	added       map[string][]int
+	produced    []producedBatch
+	produceErr  error
+	offsetsAdd  []string
+	offsets     []*kafkago.TxnOffsetCommitRequest
+	ends        []bool
+	endErr      error
+	metadataHit int
+}
+
+func (c *fakeTxnClient) Metadata(_ context.Context, req *kafkago.MetadataRequest) (*kafkago.MetadataResponse, error) {
+	c.metadataHit++
+	return &kafkago.MetadataResponse{Topics: []kafkago.Topic{{
+		Name:       req.Topics[0],
+		Partitions: []kafkago.Partition{{ID: 2}, {ID: 0}, {ID: 1}},
+	}}}, nil
+}
+
+func (c *fakeTxnClient) InitProducerID(_ context.Context, req *kafkago.InitProducerIDRequest) (*kafkago.InitProducerIDResponse, error) {
+	c.inits++
+	c.epoch++
+	if req.TransactionalID != "billing-1" || req.TransactionTimeoutMs != 60000 {
+		return nil, errors.New("unexpected init request")
+	}
+	return &kafkago.InitProducerIDResponse{Producer: &kafkago.ProducerSession{ProducerID: 7, ProducerEpoch: c.epoch}}, nil
+}
+
+func (c *fakeTxnClient) AddPartitionsToTxn(_ context.Context, req *kafkago.AddPartitionsToTxnRequest) (*kafkago.AddPartitionsToTxnResponse, error) {
+	if c.added == nil {
+		c.added = make(map[string][]int)
+	}
+	for topic, partitions := range req.Topics {
+		for _, p := range partitions {
+			c.added[topic] = append(c.added[topic], p.Partition)
+		}
+	}
+	return &kafkago.AddPartitionsToTxnResponse{}, nil
+}
+
+func (c *fakeTxnClient) RawProduce(_ context.Context, req *kafkago.RawProduceRequest) (*kafkago.ProduceResponse, error) {
+	if c.produceErr != nil {
+		return nil, c.produceErr
+	}
+	raw, err := io.ReadAll(req.RawRecords.Reader)
+	if err != nil {
+		return nil, err
+	}
+	batch := producedBatch{
+		topic:      req.Topic,
+		partition:  req.Partition,
+		producerID: int64(binary.BigEndian.Uint64(raw[4+43:])),
+		epoch:      int16(binary.BigEndian.Uint16(raw[4+51:])),
+		sequence:   int32(binary.BigEndian.Uint32(raw[4+53:])),
+	}
+	var rs protocol.RecordSet
+	if _, err := rs.ReadFrom(bytes.NewReader(raw)); err != nil {
+		return nil, err
+	}
+	batch.txn = rs.Attributes.Transactional()
+	if err := forEachKey(rs.Records, func(key string) { batch.keys = append(batch.keys, key) }); err != nil {
+		return nil, err
+	}
+	c.produced = append(c.produced, batch)
+	return &kafkago.ProduceResponse{}, nil
+}
+
+func (c *fakeTxnClient) AddOffsetsToTxn(_ context.Context, req *kafkago.AddOffsetsToTxnRequest) (*kafkago.AddOffsetsToTxnResponse, error) {
+	c.offsetsAdd = append(c.offsetsAdd, req.GroupID)
+	return &kafkago.AddOffsetsToTxnResponse{}, nil
+}
+
+func (c *fakeTxnClient) TxnOffsetCommit(_ context.Context, req *kafkago.TxnOffsetCommitRequest) (*kafkago.TxnOffsetCommitResponse, error) {
+	c.offsets = append(c.offsets, req)
+	return &kafkago.TxnOffsetCommitResponse{}, nil
+}
+
+func (c *fakeTxnClient) EndTxn(_ context.Context, req *kafkago.EndTxnRequest) (*kafkago.EndTxnResponse, error) {
+	c.ends = append(c.ends, req.Committed)
+	return &kafkago.EndTxnResponse{Error: c.endErr}, nil
+}
+
+func forEachKey(records protocol.RecordReader, fn func(string)) error {
+	for {
+		r, err := records.ReadRecord()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		key, err := protocol.ReadAll(r.Key)
+		if err != nil {
+			return err
+		}
+		fn(string(key))
+	}
+}
+
+func newTestTransactionalProducer(t *testing.T) (*TransactionalProducer, *fakeTxnClient) {
+	t.Helper()
+	log := logging.New(&logging.Config{Level: "error"}, "test")
+	p, err := NewTransactionalProducer(messaging.Config{Adapter: "kafka"},
+		kafka.Config{Brokers: []string{"localhost:9092"}, AllowInsecureDev: true, TransactionalID: "billing-1", Compression: "none"}, log)
+	if err != nil {
+		t.Fatalf("NewTransactionalProducer() error: %v", err)
+	}
+	client := &fakeTxnClient{}
+	p.client = client
+	return p, client
+}
+
+func TestTransactionalProducer_SendBatchCommitsOwnTransaction(t *testing.T) {
+	p, client := newTestTransactionalProducer(t)
+	ctx := context.Background()
+
+	msgs := []messaging.Message{{Topic: "out", Key: "a"}, {Topic: "out", Key: "b"}, {Topic: "out", Key: "a"}}
+	if err := p.SendBatch(ctx, msgs); err != nil {
+		t.Fatalf("SendBatch() error: %v", err)
+	}
+	if err := p.SendBatch(ctx, msgs[:1]); err != nil {
+		t.Fatalf("second SendBatch() error: %v", err)
+	}
+
+	if client.inits != 1 {
+		t.Errorf("InitProducerID calls = %d, want 1", client.inits)
+	}
+	if len(client.ends) != 2 || !client.ends[0] || !client.ends[1] {
+		t.Fatalf("EndTxn = %v, want two commits", client.ends)
+	}
+	if client.metadataHit != 1 {
+		t.Errorf("metadata requests = %d, want 1 (cached)", client.metadataHit)
+	}
+
+	sizes := make(map[int]int32)
+	for i, b := range client.produced {
+		if b.producerID != 7 || b.epoch != 1 || !b.txn {
+			t.Fatalf("batch header = %+v, want producer 7 epoch 1 transactional", b)
+		}
+		if i < len(client.produced)-1 {
+			sizes[b.partition] += int32(len(b.keys))
+			continue
+		}
+		// The second transaction continues the sequence of key a's partition.
+		if b.keys[0] != "a" || b.sequence != sizes[b.partition] {
+			t.Fatalf("last batch = %+v, want key a at sequence %d", b, sizes[b.partition])
+		}
+	}
+	var total int32
+	for _, n := range sizes {
+		total += n
+	}
+	if total != 3 {
+		t.Fatalf("first transaction wrote %d records, want 3", total)
+	}
+}
+
+func TestTransactionalProducer_FailedWriteAbortsAndReinitializes(t *testing.T) {
+	p, client := newTestTransactionalProducer(t)
+	ctx := context.Background()
+
+	client.produceErr = errors.New("not leader")
+	if err := p.Send(ctx, messaging.Message{Topic: "out", Key: "a"}); !errors.Is(err, client.produceErr) {
+		t.Fatalf("Send() error = %v, want produce error", err)
+	}
+	if len(client.ends) != 1 || client.ends[0] {
+		t.Fatalf("EndTxn = %v, want one abort", client.ends)
+	}
+
+	client.produceErr = nil
+	if err := p.Send(ctx, messaging.Message{Topic: "out", Key: "a"}); err != nil {
+		t.Fatalf("Send() after abort error: %v", err)
+	}
+	if client.inits != 2 {
+		t.Fatalf("InitProducerID calls = %d, want 2 after abort", client.inits)
+	}
+	if b := client.produced[0]; b.epoch != 2 || b.sequence != 0 {
+		t.Fatalf("batch after abort: epoch %d sequence %d, want 2 and 0", b.epoch, b.sequence)
+	}
+}
+
+func TestTransactionalProducer_FencedProducerDoesNotReinitialize(t *testing.T) {
+	ctx := context.Background()
+	send := func(p *TransactionalProducer) error {
+		return p.Send(ctx, messaging.Message{Topic: "out", Key: "a"})
+	}
+	tests := []struct {
+		name     string
+		fence    func(*fakeTxnClient)
+		wantEnds int
+	}{
+		{"produce", func(c *fakeTxnClient) { c.produceErr = kafkago.ProducerFenced }, 0},
+		{"commit", func(c *fakeTxnClient) { c.endErr = kafkago.InvalidProducerEpoch }, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, client := newTestTransactionalProducer(t)
+			tt.fence(client)
+			if err := send(p); !errors.Is(err, ErrProducerFenced) {
+				t.Fatalf("Send() error = %v, want ErrProducerFenced", err)
+			}
+			if len(client.ends) != tt.wantEnds {
+				t.Fatalf("EndTxn = %v, want %d requests", client.ends, tt.wantEnds)
+			}
+
+			client.produceErr, client.endErr = nil, nil
+			if err := send(p); !errors.Is(err, ErrProducerFenced) {
+				t.Fatalf("Send() after fencing = %v, want ErrProducerFenced", err)
+			}
+			if err := p.Begin(ctx); !errors.Is(err, ErrProducerFenced) {
+				t.Fatalf("Begin() after fencing = %v, want ErrProducerFenced", err)
+			}
+			if client.inits != 1 {
+				t.Fatalf("InitProducerID calls = %d, want 1: a fenced producer must not re-initialize", client.inits)
+			}
+			if p.IsAvailable(ctx) {
+				t.Fatal("fenced producer reports itself available")
+			}
+		})
+	}
+}
+
+func TestTransactionalProducer_CommitsOffsetsWithMessages(t *testing.T) {
+	p, client := newTestTransactionalProducer(t)
+	ctx := context.Background()
+
+	if err := p.SendOffsets(ctx, "billing", messaging.Message{Topic: "in"}); !errors.Is(err, errNoTransaction) {
+		t.Fatalf("SendOffsets() outside transaction = %v, want errNoTransaction", err)
+	}
+
+	consumed := []messaging.Message{
+		{Topic: "in", Partition: 0, Offset: 4},
+		{Topic: "in", Partition: 0, Offset: 9},
+		{Topic: "in", Partition: 1, Offset: 2},
+	}
+	err := p.InTransaction(ctx, func(ctx context.Context) error {
+		if err := p.Send(ctx, messaging.Message{Topic: "out", Key: "k"}); err != nil {
+			return err
+		}
+		return p.SendOffsets(ctx, "billing", consumed...)
+	})
+	if err != nil {
+		t.Fatalf("InTransaction() error: %v", err)
+	}
+
+	if len(client.ends) != 1 || !client.ends[0] {
+		t.Fatalf("EndTxn = %v, want one commit", client.ends)
+	}
+	if len(client.offsetsAdd) != 1 || client.offsetsAdd[0] != "billing" {
+		t.Fatalf("AddOffsetsToTxn groups = %v, want [billing]", client.offsetsAdd)
+	}
+	req := client.offsets[0]
+	if req.GenerationID != -1 || req.ProducerID != 7 {
+		t.Fatalf("TxnOffsetCommit generation %d producer %d, want -1 and 7", req.GenerationID, req.ProducerID)
+	}
+	next := map[int]int64{}
+	for _, o := range req.Topics["in"] {
+		next[o.Partition] = o.Offset
+	}
+	if next[0] != 10 || next[1] != 3 {
+		t.Fatalf("committed offsets = %v, want partition 0 at 10 and 1 at 3", next)
+	}
+}
+
+func TestTransactionalProducer_FailureInTransactionAbortsOnCommit(t *testing.T) {
+	p, client := newTestTransactionalProducer(t)
+	ctx := context.Background()
+
+	if err := p.Begin(ctx); err != nil {
+		t.Fatalf("Begin() error: %v", err)
+	}
+	if err := p.Send(ctx, messaging.Message{Topic: "out", Key: "a"}); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	client.produceErr = errors.New("record too large")
+	if err := p.Send(ctx, messaging.Message{Topic: "out", Key: "b"}); err == nil {
+		t.Fatal("expected Send() error")
+	}
+	if err := p.Commit(ctx); !errors.Is(err, client.produceErr) {
+		t.Fatalf("Commit() error = %v, want the failed write", err)
+	}
+	if len(client.ends) != 1 || client.ends[0] {
+		t.Fatalf("EndTxn = %v, want one abort", client.ends)
+	}
+	if err := p.Commit(ctx); !errors.Is(err, errNoTransaction) {
+		t.Fatalf("second Commit() error = %v, want errNoTransaction", err)
+	}
+}
+
+func TestTransactionalProducer_EmptyTransactionSendsNothing(t *testing.T) {
+	p, client := newTestTransactionalProducer(t)
+	if err := p.InTransaction(context.Background(), func(context.Context) error { return nil }); err != nil {
+		t.Fatalf("InTransaction() error: %v", err)
+	}
+	if len(client.ends) != 0 {
+		t.Fatalf("EndTxn = %v, want none for an empty transaction", client.ends)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if err := p.Begin(context.Background()); !errors.Is(err, messaging.ErrClosed) {
+		t.Fatalf("Begin() after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestEncodeTransactionalBatch_ChecksumCoversPatchedHeader(t *testing.T) {
+	records := []kafkago.Record{
+		{Time: time.Unix(1, 0), Key: kafkago.NewBytes([]byte("k")), Value: kafkago.NewBytes([]byte("v"))},
+	}
+	raw, err := encodeTransactionalBatch(records, kafkago.Gzip, 42, 3, 17)
+	if err != nil {
+		t.Fatalf("encodeTransactionalBatch() error: %v", err)
+	}
+	b, err := io.ReadAll(raw.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := b[4:]
+	if got := binary.BigEndian.Uint32(batch[17:]); got != crc32.Checksum(batch[21:], crc32.MakeTable(crc32.Castagnoli)) {
+		t.Fatal("batch CRC does not match its contents")
+	}
+
+	var rs protocol.RecordSet
+	if _, err := rs.ReadFrom(bytes.NewReader(b)); err != nil {
+		t.Fatalf("decode batch: %v", err)
+	}
+	stream, ok := rs.Records.(*protocol.RecordStream)
+	if !ok || len(stream.Records) != 1 {
+		t.Fatalf("records = %T, want a stream of one batch", rs.Records)
+	}
+	rb, ok := stream.Records[0].(*protocol.RecordBatch)
+	if !ok {
+		t.Fatalf("records = %T, want *protocol.RecordBatch", rs.Records)
+	}
+	if rb.ProducerID != 42 || rb.ProducerEpoch != 3 || rb.BaseSequence != 17 {
+		t.Fatalf("batch header = producer %d epoch %d sequence %d, want 42 3 17", rb.ProducerID, rb.ProducerEpoch, rb.BaseSequence)
+	}
+	if !rs.Attributes.Transactional() || rs.Attributes.Compression() != kafkago.Gzip {
+		t.Fatalf("attributes = %v, want transactional gzip", rs.Attributes)
+	}
+}
+
+func TestNewTransactionalProducer_RequiresTransactionalID(t *testing.T) {
+	_, err := NewTransactionalProducer(messaging.Config{Adapter: "kafka"}, kafka.Config{Brokers: []string{"localhost:9092"}, AllowInsecureDev: true}, nil)
+	if err == nil {
+		t.Fatal("expected transactional_id error")
+	}
+}
+
+func TestRegisterExactlyOnceBuildsTransactionalProducer(t *testing.T) {
+	reg := messaging.NewRegistry()
+	if err := Register(reg, kafka.Config{Brokers: []string{"127.0.0.1:1"}, TransactionalID: "billing-1"}); err != nil {
+		t.Fatalf("register kafka producer: %v", err)
+	}
+	producer, err := reg.NewProducer(context.Background(), messaging.Config{Adapter: "kafka", DeliveryGuarantee: messaging.DeliveryExactlyOnce}, nil)
+	if err != nil {
+		t.Fatalf("new exactly-once producer: %v", err)
+	}
+	if _, ok := producer.(*TransactionalProducer); !ok {
+		t.Fatalf("producer type = %T, want *TransactionalProducer", producer)
+	}
+	_ = producer.Close()
+}
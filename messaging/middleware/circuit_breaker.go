@@ -25,6 +25,29 @@ type CircuitBreakerConfig struct {
 
 	// OnStateChange is an optional callback invoked on circuit state transitions.
 	OnStateChange func(name string, from, to resilience.State)
+
+	// Window selects failure counting: consecutive (default, uses Threshold),
+	// or a count- or time-based sliding window that opens on failure or
+	// slow-call rates.
+	Window resilience.CircuitWindow
+
+	// WindowSize is the number of recent messages a count window covers. Default: 100.
+	WindowSize int
+
+	// WindowDuration is the period a time window covers. Default: 60s.
+	WindowDuration time.Duration
+
+	// MinimumCalls is the number of messages a window must hold before rates are evaluated. Default: 10.
+	MinimumCalls int
+
+	// FailureRateThreshold is the failure fraction at which the circuit opens. Default: 0.5.
+	FailureRateThreshold float64
+
+	// SlowCallDuration marks handler calls at least this long as slow. 0 disables slow-call tracking.
+	SlowCallDuration time.Duration
+
+	// SlowCallRateThreshold is the slow-call fraction at which the circuit opens. Default: 1.
+	SlowCallRateThreshold float64
 }
 
 // CircuitBreakerHandler wraps a MessageHandler with circuit breaker logic powered by resilience.CircuitBreaker.
@@ -36,6 +59,14 @@ func CircuitBreakerHandler(handler messaging.MessageHandler, cfg CircuitBreakerC
 		Timeout:          cfg.Timeout,
 		HalfOpenMaxCalls: cfg.HalfOpenMax,
 		OnStateChange:    cfg.OnStateChange,
+
+		Window:                cfg.Window,
+		WindowSize:            cfg.WindowSize,
+		WindowDuration:        cfg.WindowDuration,
+		MinimumCalls:          cfg.MinimumCalls,
+		FailureRateThreshold:  cfg.FailureRateThreshold,
+		SlowCallDuration:      cfg.SlowCallDuration,
+		SlowCallRateThreshold: cfg.SlowCallRateThreshold,
 	}
 
 	cb := resilience.NewCircuitBreaker(rcfg)
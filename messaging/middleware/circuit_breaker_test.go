@@ -157,3 +157,32 @@ func TestCircuitBreakerHandler_OnStateChange(t *testing.T) {
 		t.Errorf("transition = %q, want closed→open", transitions[0])
 	}
 }
+
+func TestCircuitBreakerHandler_FailureRateWindow(t *testing.T) {
+	t.Parallel()
+
+	fail := errors.New("downstream error")
+	var n atomic.Int32
+	wrapped := CircuitBreakerHandler(func(context.Context, messaging.Message) error {
+		// Every third message fails: never three in a row, but a 33% rate.
+		if n.Add(1)%3 == 0 {
+			return fail
+		}
+		return nil
+	}, CircuitBreakerConfig{
+		Name:                 "rate",
+		Threshold:            3,
+		Timeout:              time.Hour,
+		Window:               resilience.CircuitWindowCount,
+		WindowSize:           9,
+		MinimumCalls:         9,
+		FailureRateThreshold: 0.3,
+	})
+
+	for range 9 {
+		_ = wrapped(context.Background(), messaging.Message{})
+	}
+	if err := wrapped(context.Background(), messaging.Message{}); !errors.Is(err, resilience.ErrCircuitOpen) {
+		t.Fatalf("error = %v, want ErrCircuitOpen", err)
+	}
+}
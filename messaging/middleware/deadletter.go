@@ -3,11 +3,11 @@
 import (
 	"context"
 	"strconv"
-	"strings"
 	"time"
 	"unicode/utf8"
 
 	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/messaging/internal/redact"
 )
 
 const (
@@ -18,11 +18,18 @@
 // DeadLetterEnvelope is the JSON payload written to the DLQ topic.
 type DeadLetterEnvelope struct {
 	OriginalTopic string            `json:"original_topic"`
+	Key           string            `json:"key,omitempty"`
 	Error         string            `json:"error"`
 	RetryCount    int               `json:"retry_count"`
 	Timestamp     time.Time         `json:"timestamp"`
 	Headers       map[string]string `json:"headers,omitempty"`
 	Payload       string            `json:"payload"`
+	// PayloadVerbatim reports that Payload holds the message value unchanged:
+	// valid UTF-8, neither redacted nor truncated.
+	PayloadVerbatim bool `json:"payload_verbatim,omitempty"`
+	// RawPayload is the unredacted message value, kept only when the
+	// producer was built with WithRawPayload.
+	RawPayload []byte `json:"raw_payload,omitempty"`
 }
 
 // DLQOption configures a DeadLetterProducer.
@@ -35,10 +42,20 @@ func WithSuffix(suffix string) DLQOption {
 	}
 }
 
+// WithRawPayload keeps the unredacted message value in each envelope so a
+// Redriver can republish it. The DLQ topic then holds payloads as sensitive
+// as the source topic's; enable it only where both are equally protected.
+func WithRawPayload() DLQOption {
+	return func(d *DeadLetterProducer) {
+		d.rawPayload = true
+	}
+}
+
 // DeadLetterProducer sends failed messages to a dead-letter topic.
 type DeadLetterProducer struct {
-	publisher messaging.Producer
-	suffix    string
+	publisher  messaging.Producer
+	suffix     string
+	rawPayload bool
 }
 
 // NewDeadLetterProducer creates a DeadLetterProducer that publishes to "{original_topic}{suffix}" (default suffix is ".dlq").
@@ -55,7 +72,7 @@ func NewDeadLetterProducer(publisher messaging.Producer, opts ...DLQOption) *Dea
 
 // Send publishes a DeadLetterEnvelope to the DLQ topic for the given message.
 // The envelope includes a redacted payload summary, redacted headers, sanitized error summary,
-// retry count (read from the "x-retry-count" header), and a UTC timestamp.
+// retry count (read from the "x-retry-count" header), the message key, and a UTC timestamp.
 func (d *DeadLetterProducer) Send(ctx context.Context, msg messaging.Message, originalErr error) error {
 	retryCount := 0
 	if rc, ok := msg.Headers["x-retry-count"]; ok {
@@ -64,11 +81,15 @@ func (d *DeadLetterProducer) Send(ctx context.Context, msg messaging.Message, or
 
 	envelope := DeadLetterEnvelope{
 		OriginalTopic: msg.Topic,
+		Key:           msg.Key,
 		Error:         sanitizeSummary(originalErr.Error()),
 		RetryCount:    retryCount,
 		Timestamp:     time.Now().UTC(),
 		Headers:       redactHeaders(msg.Headers),
-		Payload:       summarizePayloadBytes(msg.Value),
+	}
+	envelope.Payload, envelope.PayloadVerbatim = summarizePayloadBytes(msg.Value)
+	if d.rawPayload {
+		envelope.RawPayload = msg.Value
 	}
 
 	dlqTopic := msg.Topic + d.suffix
@@ -86,7 +107,7 @@ func redactHeaders(headers map[string]string) map[string]string {
 	}
 	out := make(map[string]string, len(headers))
 	for key, value := range headers {
-		if isSensitiveKey(key) || containsSensitiveMarker(value) {
+		if redact.Contains(key) || redact.Contains(value) {
 			out[key] = redactedValue
 			continue
 		}
@@ -96,20 +117,23 @@ func redactHeaders(headers map[string]string) map[string]string {
 }
 
 func sanitizeSummary(value string) string {
-	if containsSensitiveMarker(value) {
+	if redact.Contains(value) {
 		return redactedValue
 	}
 	return truncateStringBytes(value)
 }
 
-func summarizePayloadBytes(payload []byte) string {
-	if containsSensitiveMarkerBytes(payload) {
-		return redactedValue
+// summarizePayloadBytes returns the payload summary stored in an envelope
+// and whether it is the payload unchanged. Invalid UTF-8 does not survive
+// the JSON string, so such payloads are never verbatim.
+func summarizePayloadBytes(payload []byte) (string, bool) {
+	if redact.ContainsBytes(payload) {
+		return redactedValue, false
 	}
 	if len(payload) <= maxDLQPayloadBytes {
-		return string(payload)
+		return string(payload), utf8.Valid(payload)
 	}
-	return string(payload[:maxDLQPayloadBytes]) + "…"
+	return string(payload[:maxDLQPayloadBytes]) + "…", false
 }
 
 func truncateStringBytes(value string) string {
@@ -125,65 +149,3 @@ func truncateStringBytes(value string) string {
 	}
 	return value[:limit] + "…"
 }
-
-func isSensitiveKey(key string) bool {
-	return containsSensitiveMarker(key)
-}
-
-func containsSensitiveMarker(value string) bool {
-	lower := strings.ToLower(value)
-	for _, part := range []string{"authorization", "cookie", "token", "secret", "password", "credential", "api-key", "apikey"} {
-		if strings.Contains(lower, part) {
-			return true
-		}
-	}
-	return false
-}
-
-func containsSensitiveMarkerBytes(value []byte) bool {
-	for _, marker := range sensitiveMarkers() {
-		if containsASCIIFold(value, marker) {
-			return true
-		}
-	}
-	return false
-}
-
-func containsASCIIFold(value []byte, marker string) bool {
-	if marker == "" {
-		return true
-	}
-	if len(value) < len(marker) {
-		return false
-	}
-	first := marker[0]
-	for offset := 0; offset <= len(value)-len(marker); offset++ {
-		if lowerASCII(value[offset]) != first {
-			continue
-		}
-		if hasASCIIFoldPrefix(value[offset:], marker) {
-			return true
-		}
-	}
-	return false
-}
-
-func hasASCIIFoldPrefix(value []byte, marker string) bool {
-	for i := range marker {
-		if lowerASCII(value[i]) != marker[i] {
-			return false
-		}
-	}
-	return true
-}
-
-func lowerASCII(value byte) byte {
-	if value >= 'A' && value <= 'Z' {
-		return value + ('a' - 'A')
-	}
-	return value
-}
-
-func sensitiveMarkers() []string {
-	return []string{"authorization", "cookie", "token", "secret", "password", "credential", "api-key", "apikey"}
-}
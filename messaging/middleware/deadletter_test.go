@@ -106,8 +106,36 @@ func TestDeadLetterProducer_Send(t *testing.T) {
 	if env.Headers["content-type"] != "application/json" {
 		t.Errorf("Headers[content-type] = %q", env.Headers["content-type"])
 	}
-	if env.Payload != `{"id":"order-123"}` {
-		t.Errorf("Payload = %q", env.Payload)
+	if env.Payload != `{"id":"order-123"}` || !env.PayloadVerbatim {
+		t.Errorf("Payload = %q verbatim=%v, want the value verbatim", env.Payload, env.PayloadVerbatim)
+	}
+	if env.Key != "order-123" {
+		t.Errorf("Key = %q, want order-123", env.Key)
+	}
+	if env.RawPayload != nil {
+		t.Errorf("RawPayload = %q, want none without WithRawPayload", env.RawPayload)
+	}
+}
+
+func TestDeadLetterProducer_Send_WithRawPayload(t *testing.T) {
+	pub := &mockPublisher{}
+	d := NewDeadLetterProducer(pub, WithRawPayload())
+
+	value := []byte(`{"password":"hunter2"}`)
+	if err := d.Send(context.Background(), messaging.Message{Topic: "users", Value: value}, errors.New("boom")); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+
+	data, _ := json.Marshal(pub.lastValue)
+	var env DeadLetterEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		t.Fatalf("unmarshal envelope: %v", err)
+	}
+	if env.Payload != redactedValue {
+		t.Errorf("Payload = %q, want redacted summary", env.Payload)
+	}
+	if string(env.RawPayload) != string(value) {
+		t.Errorf("RawPayload = %q, want %q", env.RawPayload, value)
 	}
 }
 
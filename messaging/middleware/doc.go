@@ -1,7 +1,7 @@
 // Package middleware provides composable middleware for message handlers.
 //
 // Middleware wraps [messaging.MessageHandler] functions to add cross-cutting concerns such as retry logic,
-// dead-letter routing, distributed tracing, metrics collection, deduplication, and circuit breaking
+// dead-letter routing, distributed tracing, metrics collection, deduplication, rate limiting, and circuit breaking
 // — all built on top of existing gokit modules.
 //
 // # Retry
@@ -22,6 +22,18 @@
 //	dlq := middleware.NewDeadLetterProducer(publisher)
 //	dlq.Send(ctx, msg, err)
 //
+// Redrive envelopes back to their original topics, filtered by topic, error
+// and failure time, with optional rate limiting; DryRun only summarizes them.
+// Build the DeadLetterProducer WithRawPayload so redacted or truncated
+// payloads can be replayed:
+//
+//	redriver := middleware.NewRedriver(publisher, middleware.RedriveConfig{
+//	    OriginalTopics: []string{"orders"}, ErrorContains: []string{"timeout"},
+//	    RateLimit: &middleware.RateLimitConfig{Limit: 50, Interval: time.Second},
+//	    IdleTimeout: 10 * time.Second,
+//	})
+//	report, err := redriver.Run(ctx, dlqConsumer)
+//
 // # Tracing
 //
 // Add OpenTelemetry distributed tracing to message processing:
@@ -40,6 +52,17 @@
 //
 //	deduped := middleware.DedupHandler(handler, middleware.DedupConfig{TTL: 5 * time.Minute})
 //
+// # Rate Limiting
+//
+// Throttle consumption; share the budget across replicas with a store-backed limiter:
+//
+//	limiter := resilience.NewKeyedRateLimiter(resilience.KeyedRateLimiterConfig{
+//	    Store: cacheredis.NewRateLimitStore(client, ""), Algorithm: resilience.RateLimitGCRA,
+//	})
+//	throttled := middleware.RateLimitHandler(handler, middleware.RateLimitConfig{
+//	    Limiter: limiter, Limit: 50, Interval: time.Second,
+//	})
+//
 // # Circuit Breaker
 //
 // Fail-fast when downstream is unhealthy (wraps resilience.CircuitBreaker):
@@ -47,4 +70,11 @@
 //	protected := middleware.CircuitBreakerHandler(handler, middleware.CircuitBreakerConfig{
 //	    Threshold: 5, Timeout: 30 * time.Second,
 //	})
+//
+// Set Window to resilience.CircuitWindowCount or CircuitWindowTime to open on a
+// failure or slow-call rate instead of consecutive failures:
+//
+//	protected := middleware.CircuitBreakerHandler(handler, middleware.CircuitBreakerConfig{
+//	    Window: resilience.CircuitWindowTime, FailureRateThreshold: 0.3, MinimumCalls: 20,
+//	})
 package middleware
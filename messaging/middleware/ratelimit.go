@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/resilience"
+)
+
+// RateLimitConfig configures the consumer-side rate limiting middleware.
+type RateLimitConfig struct {
+	// Limiter holds the bucket state. Give it a resilience.RateLimitStore to
+	// share one budget across consumer replicas. Default: a private in-process
+	// token bucket limiter without a background cleanup goroutine, so nothing
+	// needs stopping.
+	Limiter *resilience.KeyedRateLimiter
+
+	// Limit is the number of messages allowed per Interval. Default: 100.
+	Limit int
+
+	// Interval is the window Limit applies to. Default: 1s.
+	Interval time.Duration
+
+	// KeyFunc derives the bucket key from a message. Default: the message topic.
+	KeyFunc func(messaging.Message) string
+}
+
+func (c *RateLimitConfig) applyDefaults() {
+	if c.Limiter == nil {
+		c.Limiter = resilience.NewKeyedRateLimiter(resilience.KeyedRateLimiterConfig{LazyCleanup: true})
+	}
+	if c.Limit <= 0 {
+		c.Limit = 100
+	}
+	if c.Interval <= 0 {
+		c.Interval = time.Second
+	}
+	if c.KeyFunc == nil {
+		c.KeyFunc = func(msg messaging.Message) string { return msg.Topic }
+	}
+}
+
+// RateLimitHandler throttles a MessageHandler. Unlike the HTTP middleware it
+// never drops work: a message over the limit waits for its RetryAfter and is
+// then handled, so the consumer slows down instead of failing. Waiting ends
+// with ctx.Err() when the context is canceled. When the limiter's store fails
+// and the limiter is not FailOpen, the store error is returned at once so the
+// consumer's retry and dead-letter handling can take over.
+func RateLimitHandler(handler messaging.MessageHandler, cfg RateLimitConfig) messaging.MessageHandler {
+	cfg.applyDefaults()
+	return func(ctx context.Context, msg messaging.Message) error {
+		key := cfg.KeyFunc(msg)
+		for {
+			decision, err := cfg.Limiter.Take(ctx, key, cfg.Limit, cfg.Interval)
+			if decision.Allowed {
+				return handler(ctx, msg)
+			}
+			if err != nil {
+				return fmt.Errorf("rate limit %q: %w", key, err)
+			}
+			timer := time.NewTimer(max(decision.RetryAfter, time.Millisecond))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/resilience"
+)
+
+func TestRateLimitHandler_ThrottlesInsteadOfDropping(t *testing.T) {
+	t.Parallel()
+
+	limiter := resilience.NewKeyedRateLimiter(resilience.KeyedRateLimiterConfig{Algorithm: resilience.RateLimitGCRA})
+	t.Cleanup(limiter.Stop)
+	var calls atomic.Int32
+	wrapped := RateLimitHandler(func(context.Context, messaging.Message) error {
+		calls.Add(1)
+		return nil
+	}, RateLimitConfig{Limiter: limiter, Limit: 2, Interval: 100 * time.Millisecond})
+
+	start := time.Now()
+	for range 4 {
+		if err := wrapped(context.Background(), messaging.Message{Topic: "orders"}); err != nil {
+			t.Fatalf("handler error: %v", err)
+		}
+	}
+	if calls.Load() != 4 {
+		t.Fatalf("calls = %d, want 4", calls.Load())
+	}
+	// Two messages fit the burst; the other two wait one emission interval each.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("4 messages at 2/100ms took %v, expected throttling", elapsed)
+	}
+}
+
+func TestRateLimitHandler_KeysAndCancellation(t *testing.T) {
+	t.Parallel()
+
+	limiter := resilience.NewKeyedRateLimiter(resilience.KeyedRateLimiterConfig{})
+	t.Cleanup(limiter.Stop)
+	wrapped := RateLimitHandler(func(context.Context, messaging.Message) error { return nil }, RateLimitConfig{
+		Limiter:  limiter,
+		Limit:    1,
+		Interval: time.Hour,
+		KeyFunc:  func(msg messaging.Message) string { return msg.Key },
+	})
+
+	ctx := context.Background()
+	if err := wrapped(ctx, messaging.Message{Key: "a"}); err != nil {
+		t.Fatalf("first a: %v", err)
+	}
+	if err := wrapped(ctx, messaging.Message{Key: "b"}); err != nil {
+		t.Fatalf("first b: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := wrapped(ctx, messaging.Message{Key: "a"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("throttled message error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestRateLimitConfig_ApplyDefaults(t *testing.T) {
+	t.Parallel()
+
+	cfg := RateLimitConfig{}
+	cfg.applyDefaults()
+	if cfg.Limit != 100 || cfg.Interval != time.Second || cfg.KeyFunc(messaging.Message{Topic: "t"}) != "t" {
+		t.Fatalf("defaults = %+v", cfg)
+	}
+}
+
+type failingRateLimitStore struct{ err error }
+
+func (s failingRateLimitStore) Take(context.Context, string, resilience.RateLimit) (resilience.RateLimitDecision, error) {
+	return resilience.RateLimitDecision{}, s.err
+}
+
+func TestRateLimitHandler_StoreFailure(t *testing.T) {
+	t.Parallel()
+
+	storeErr := errors.New("redis down")
+	for _, failOpen := range []bool{false, true} {
+		limiter := resilience.NewKeyedRateLimiter(resilience.KeyedRateLimiterConfig{
+			Store:    failingRateLimitStore{err: storeErr},
+			FailOpen: failOpen,
+		})
+		t.Cleanup(limiter.Stop)
+		var calls atomic.Int32
+		wrapped := RateLimitHandler(func(context.Context, messaging.Message) error {
+			calls.Add(1)
+			return nil
+		}, RateLimitConfig{Limiter: limiter})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err := wrapped(ctx, messaging.Message{Topic: "orders"})
+		cancel()
+		if failOpen {
+			if err != nil || calls.Load() != 1 {
+				t.Fatalf("fail-open: err = %v, calls = %d", err, calls.Load())
+			}
+			continue
+		}
+		if !errors.Is(err, storeErr) || calls.Load() != 0 {
+			t.Fatalf("fail-closed: err = %v, calls = %d; want the store error without handling", err, calls.Load())
+		}
+	}
+}
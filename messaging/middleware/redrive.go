@@ -0,0 +1,294 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kbukum/gokit/messaging"
+)
+
+// redriveCountHeader counts how often a message has been redriven.
+const redriveCountHeader = "x-redrive-count"
+
+// RedriveConfig configures a Redriver. Envelopes must pass every filter that
+// is set to be redriven.
+type RedriveConfig struct {
+	// OriginalTopics limits the redrive to envelopes from these topics.
+	// Default: all topics.
+	OriginalTopics []string
+
+	// ErrorContains limits the redrive to envelopes whose error contains one
+	// of these substrings. Default: all errors.
+	ErrorContains []string
+
+	// Since and Until bound the time the message failed; a zero value leaves
+	// that side open.
+	Since, Until time.Time
+
+	// Filter is an additional predicate over envelopes.
+	Filter func(DeadLetterEnvelope) bool
+
+	// Transform rewrites a message before it is republished, for example to
+	// repair its payload or route it to another topic. An error stops the
+	// redrive of that message and is returned to the consumer.
+	Transform func(ctx context.Context, msg messaging.Message, env DeadLetterEnvelope) (messaging.Message, error)
+
+	// RateLimit throttles republishing, keyed by the original topic by
+	// default. Nil disables throttling.
+	RateLimit *RateLimitConfig
+
+	// MaxRedrives skips messages already redriven this many times, as
+	// counted by the "x-redrive-count" header. Default: 0 (no limit).
+	MaxRedrives int
+
+	// DryRun inspects and summarizes envelopes without republishing them.
+	DryRun bool
+
+	// IdleTimeout ends Run once no DLQ message has arrived for this long.
+	// Default: 0 (Run consumes until ctx ends).
+	IdleTimeout time.Duration
+}
+
+// RedriveReport summarizes the envelopes a Redriver has read.
+type RedriveReport struct {
+	// Scanned counts DLQ messages read.
+	Scanned int `json:"scanned"`
+	// Invalid counts DLQ messages that are not envelopes.
+	Invalid int `json:"invalid"`
+	// Skipped counts envelopes rejected by the filters or MaxRedrives.
+	Skipped int `json:"skipped"`
+	// Matched counts envelopes that passed the filters.
+	Matched int `json:"matched"`
+	// Unreplayable counts matched envelopes whose payload was not stored
+	// verbatim (redacted, truncated, or not valid UTF-8) and not kept with
+	// WithRawPayload.
+	Unreplayable int `json:"unreplayable"`
+	// Redriven counts republished messages; it stays 0 in dry-run mode.
+	Redriven int `json:"redriven"`
+	// ByError and ByTopic count matched envelopes per error and per
+	// original topic.
+	ByError map[string]int `json:"by_error,omitempty"`
+	ByTopic map[string]int `json:"by_topic,omitempty"`
+	// Oldest and Newest are the failure times of matched envelopes.
+	Oldest time.Time `json:"oldest,omitzero"`
+	Newest time.Time `json:"newest,omitzero"`
+}
+
+// Redriver consumes DeadLetterEnvelopes from a DLQ topic and republishes the
+// original messages to their OriginalTopic. Payloads are taken from the
+// envelope's RawPayload, or from Payload when the envelope marks it
+// PayloadVerbatim; headers redacted by the DeadLetterProducer are dropped.
+// The "x-retry-count" header is cleared so the message gets a fresh retry
+// budget, and "x-redrive-count" is incremented. It is safe for concurrent use.
+//
+// Every DLQ message the Redriver reads is handled successfully unless
+// republishing fails, so the consumer commits skipped and dry-run messages
+// too. Inspect and redrive with separate consumer groups, or a consumer that
+// does not commit, to keep the DLQ intact.
+type Redriver struct {
+	publisher messaging.Producer
+	cfg       RedriveConfig
+	send      messaging.MessageHandler
+
+	mu     sync.Mutex
+	report RedriveReport
+}
+
+// NewRedriver creates a Redriver that republishes through publisher, which
+// may be nil in dry-run mode.
+func NewRedriver(publisher messaging.Producer, cfg RedriveConfig) *Redriver {
+	r := &Redriver{publisher: publisher, cfg: cfg}
+	r.send = func(ctx context.Context, msg messaging.Message) error {
+		return r.publisher.Send(ctx, msg)
+	}
+	if cfg.RateLimit != nil {
+		r.send = RateLimitHandler(r.send, *cfg.RateLimit)
+	}
+	return r
+}
+
+// Handler returns a messaging.MessageHandler that redrives each DLQ message,
+// for use with any consumer.
+func (r *Redriver) Handler() messaging.MessageHandler {
+	return r.handle
+}
+
+// Run consumes the DLQ with consumer until ctx ends or, with IdleTimeout
+// set, the DLQ stays idle, and returns the report. The end of ctx is not an
+// error.
+func (r *Redriver) Run(ctx context.Context, consumer messaging.Consumer) (RedriveReport, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	handler := r.Handler()
+	if r.cfg.IdleTimeout > 0 {
+		var inFlight atomic.Int64
+		activity := make(chan struct{}, 1)
+		touch := func() {
+			select {
+			case activity <- struct{}{}:
+			default:
+			}
+		}
+		inner := handler
+		handler = func(ctx context.Context, msg messaging.Message) error {
+			inFlight.Add(1)
+			defer func() {
+				inFlight.Add(-1)
+				touch()
+			}()
+			touch()
+			return inner(ctx, msg)
+		}
+		go watchIdle(ctx, cancel, r.cfg.IdleTimeout, activity, &inFlight)
+	}
+
+	err := consumer.Consume(ctx, handler)
+	if ctx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		err = nil
+	}
+	return r.Report(), err
+}
+
+// watchIdle cancels the consume loop once no handler has run for idle.
+func watchIdle(ctx context.Context, cancel context.CancelFunc, idle time.Duration, activity <-chan struct{}, inFlight *atomic.Int64) {
+	timer := time.NewTimer(idle)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-activity:
+			timer.Reset(idle)
+		case <-timer.C:
+			if inFlight.Load() > 0 {
+				timer.Reset(idle)
+				continue
+			}
+			cancel()
+			return
+		}
+	}
+}
+
+// Report returns a snapshot of what the Redriver has read so far.
+func (r *Redriver) Report() RedriveReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := r.report
+	report.ByError = maps.Clone(r.report.ByError)
+	report.ByTopic = maps.Clone(r.report.ByTopic)
+	return report
+}
+
+func (r *Redriver) handle(ctx context.Context, dlqMsg messaging.Message) error {
+	var env DeadLetterEnvelope
+	if err := json.Unmarshal(dlqMsg.Value, &env); err != nil || env.OriginalTopic == "" {
+		r.record(func(report *RedriveReport) { report.Scanned++; report.Invalid++ })
+		return nil
+	}
+	if !r.matches(env) {
+		r.record(func(report *RedriveReport) { report.Scanned++; report.Skipped++ })
+		return nil
+	}
+	payload, replayable := env.replayPayload()
+	r.record(func(report *RedriveReport) {
+		report.Scanned++
+		report.Matched++
+		if !replayable {
+			report.Unreplayable++
+		}
+		if report.ByError == nil {
+			report.ByError, report.ByTopic = make(map[string]int), make(map[string]int)
+		}
+		report.ByError[env.Error]++
+		report.ByTopic[env.OriginalTopic]++
+		if report.Oldest.IsZero() || env.Timestamp.Before(report.Oldest) {
+			report.Oldest = env.Timestamp
+		}
+		if env.Timestamp.After(report.Newest) {
+			report.Newest = env.Timestamp
+		}
+	})
+	if !replayable || r.cfg.DryRun {
+		return nil
+	}
+
+	msg := messaging.NewMessage(env.OriginalTopic, env.Key, payload, replayHeaders(env.Headers))
+	if r.cfg.Transform != nil {
+		var err error
+		if msg, err = r.cfg.Transform(ctx, msg, env); err != nil {
+			return fmt.Errorf("redrive transform for %s: %w", env.OriginalTopic, err)
+		}
+	}
+	if err := r.send(ctx, msg); err != nil {
+		return fmt.Errorf("redrive to %s: %w", msg.Topic, err)
+	}
+	r.record(func(report *RedriveReport) { report.Redriven++ })
+	return nil
+}
+
+func (r *Redriver) record(update func(*RedriveReport)) {
+	r.mu.Lock()
+	update(&r.report)
+	r.mu.Unlock()
+}
+
+func (r *Redriver) matches(env DeadLetterEnvelope) bool {
+	cfg := r.cfg
+	if len(cfg.OriginalTopics) > 0 && !slices.Contains(cfg.OriginalTopics, env.OriginalTopic) {
+		return false
+	}
+	if len(cfg.ErrorContains) > 0 && !slices.ContainsFunc(cfg.ErrorContains, func(s string) bool {
+		return strings.Contains(env.Error, s)
+	}) {
+		return false
+	}
+	if !cfg.Since.IsZero() && env.Timestamp.Before(cfg.Since) {
+		return false
+	}
+	if !cfg.Until.IsZero() && env.Timestamp.After(cfg.Until) {
+		return false
+	}
+	if cfg.MaxRedrives > 0 {
+		if n, _ := strconv.Atoi(env.Headers[redriveCountHeader]); n >= cfg.MaxRedrives {
+			return false
+		}
+	}
+	return cfg.Filter == nil || cfg.Filter(env)
+}
+
+// replayPayload returns the original message value, if the envelope kept it.
+func (env DeadLetterEnvelope) replayPayload() ([]byte, bool) {
+	if env.RawPayload != nil {
+		return env.RawPayload, true
+	}
+	if !env.PayloadVerbatim {
+		return nil, false
+	}
+	return []byte(env.Payload), true
+}
+
+// replayHeaders returns the headers to republish: redacted headers are
+// dropped, the retry count is reset, and the redrive count is incremented.
+func replayHeaders(headers map[string]string) map[string]string {
+	out := make(map[string]string, len(headers)+1)
+	for key, value := range headers {
+		if value != redactedValue {
+			out[key] = value
+		}
+	}
+	delete(out, "x-retry-count")
+	n, _ := strconv.Atoi(out[redriveCountHeader])
+	out[redriveCountHeader] = strconv.Itoa(n + 1)
+	return out
+}
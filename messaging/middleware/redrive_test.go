@@ -0,0 +1,243 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/messaging/memory"
+)
+
+func dlqMessage(t *testing.T, env DeadLetterEnvelope) messaging.Message {
+	t.Helper()
+	data, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+	return messaging.NewMessage(env.OriginalTopic+".dlq", env.Key, data, nil)
+}
+
+func TestRedriver_RepublishesOriginalMessage(t *testing.T) {
+	broker := memory.NewBroker()
+	defer broker.Close()
+	dlq := NewDeadLetterProducer(broker.Producer(), WithRawPayload())
+	failed := messaging.NewMessage("orders", "o-1", []byte(`{"id":"o-1"}`), map[string]string{
+		"x-retry-count": "3", "trace-id": "abc", "Authorization": "Bearer x",
+	})
+	if err := dlq.Send(context.Background(), failed, errors.New("db down")); err != nil {
+		t.Fatalf("dlq send: %v", err)
+	}
+
+	r := NewRedriver(broker.Producer(), RedriveConfig{})
+	if err := r.Handler()(context.Background(), broker.Messages("orders.dlq")[0]); err != nil {
+		t.Fatalf("redrive: %v", err)
+	}
+	msgs := broker.Messages("orders")
+	if len(msgs) != 1 {
+		t.Fatalf("republished %d messages, want 1", len(msgs))
+	}
+	got := msgs[0]
+	if got.Key != "o-1" || string(got.Value) != `{"id":"o-1"}` {
+		t.Fatalf("republished key=%q value=%s", got.Key, got.Value)
+	}
+	want := map[string]string{"trace-id": "abc", "x-redrive-count": "1"}
+	if len(got.Headers) != len(want) {
+		t.Fatalf("headers = %v, want %v", got.Headers, want)
+	}
+	for k, v := range want {
+		if got.Headers[k] != v {
+			t.Fatalf("headers = %v, want %v", got.Headers, want)
+		}
+	}
+	if report := r.Report(); report.Redriven != 1 || report.Matched != 1 || report.ByTopic["orders"] != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+}
+
+func TestRedriver_NonUTF8PayloadNeedsRawPayload(t *testing.T) {
+	value := []byte{0xff, 0xfe, 'o', 0x80}
+	for _, tt := range []struct {
+		name string
+		opts []DLQOption
+		want bool
+	}{
+		{"summary only", nil, false},
+		{"raw payload", []DLQOption{WithRawPayload()}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := memory.NewBroker()
+			defer broker.Close()
+			dlq := NewDeadLetterProducer(broker.Producer(), tt.opts...)
+			if err := dlq.Send(context.Background(), messaging.NewMessage("orders", "o-1", value, nil), errors.New("bad")); err != nil {
+				t.Fatalf("dlq send: %v", err)
+			}
+
+			r := NewRedriver(broker.Producer(), RedriveConfig{})
+			if err := r.Handler()(context.Background(), broker.Messages("orders.dlq")[0]); err != nil {
+				t.Fatalf("redrive: %v", err)
+			}
+			msgs := broker.Messages("orders")
+			report := r.Report()
+			if !tt.want {
+				if len(msgs) != 0 || report.Unreplayable != 1 {
+					t.Fatalf("republished %d messages, unreplayable %d; want the mangled summary left in the DLQ", len(msgs), report.Unreplayable)
+				}
+				return
+			}
+			if len(msgs) != 1 || string(msgs[0].Value) != string(value) || report.Unreplayable != 0 {
+				t.Fatalf("republished %v, unreplayable %d; want the original bytes", msgs, report.Unreplayable)
+			}
+		})
+	}
+}
+
+func TestRedriver_Filters(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	envelopes := []DeadLetterEnvelope{
+		{OriginalTopic: "orders", Error: "timeout talking to db", Timestamp: now, Payload: "1", PayloadVerbatim: true},
+		{OriginalTopic: "orders", Error: "validation failed", Timestamp: now, Payload: "2", PayloadVerbatim: true},
+		{OriginalTopic: "payments", Error: "timeout", Timestamp: now, Payload: "3", PayloadVerbatim: true},
+		{OriginalTopic: "orders", Error: "timeout", Timestamp: now.Add(-48 * time.Hour), Payload: "4", PayloadVerbatim: true},
+		{OriginalTopic: "orders", Error: "timeout", Timestamp: now, Payload: "5", PayloadVerbatim: true, Headers: map[string]string{"x-redrive-count": "2"}},
+		{OriginalTopic: "orders", Error: "timeout", Timestamp: now, Payload: "6", PayloadVerbatim: true, Key: "skip-me"},
+	}
+	pub := &recordingPublisher{}
+	r := NewRedriver(pub, RedriveConfig{
+		OriginalTopics: []string{"orders"},
+		ErrorContains:  []string{"timeout"},
+		Since:          now.Add(-time.Hour),
+		Until:          now.Add(time.Hour),
+		MaxRedrives:    2,
+		Filter:         func(env DeadLetterEnvelope) bool { return env.Key != "skip-me" },
+	})
+	for _, env := range envelopes {
+		if err := r.Handler()(context.Background(), dlqMessage(t, env)); err != nil {
+			t.Fatalf("redrive: %v", err)
+		}
+	}
+	if len(pub.sent) != 1 || string(pub.sent[0].Value) != "1" {
+		t.Fatalf("sent = %+v, want only payload 1", pub.sent)
+	}
+	if report := r.Report(); report.Scanned != 6 || report.Skipped != 5 || report.Redriven != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+}
+
+func TestRedriver_DryRunSummarizes(t *testing.T) {
+	t1 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	r := NewRedriver(nil, RedriveConfig{DryRun: true})
+	h := r.Handler()
+	for _, env := range []DeadLetterEnvelope{
+		{OriginalTopic: "orders", Error: "boom", Timestamp: t2, Payload: "a", PayloadVerbatim: true},
+		{OriginalTopic: "orders", Error: "boom", Timestamp: t1, Payload: redactedValue},
+		{OriginalTopic: "users", Error: "bad", Timestamp: t1, Payload: strings.Repeat("x", maxDLQPayloadBytes) + "…"},
+	} {
+		if err := h(context.Background(), dlqMessage(t, env)); err != nil {
+			t.Fatalf("dry run: %v", err)
+		}
+	}
+	if err := h(context.Background(), messaging.NewMessage("orders.dlq", "", []byte("not json"), nil)); err != nil {
+		t.Fatalf("dry run invalid: %v", err)
+	}
+
+	report := r.Report()
+	if report.Scanned != 4 || report.Invalid != 1 || report.Matched != 3 || report.Unreplayable != 2 || report.Redriven != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+	if report.ByError["boom"] != 2 || report.ByError["bad"] != 1 || report.ByTopic["orders"] != 2 || report.ByTopic["users"] != 1 {
+		t.Fatalf("breakdown = %v %v", report.ByError, report.ByTopic)
+	}
+	if !report.Oldest.Equal(t1) || !report.Newest.Equal(t2) {
+		t.Fatalf("range = %v..%v", report.Oldest, report.Newest)
+	}
+	report.ByError["boom"] = 99
+	if r.Report().ByError["boom"] != 2 {
+		t.Fatal("Report must return a copy")
+	}
+}
+
+func TestRedriver_TransformAndPublishErrors(t *testing.T) {
+	env := DeadLetterEnvelope{OriginalTopic: "orders", Error: "boom", Payload: "v1", PayloadVerbatim: true}
+
+	pub := &recordingPublisher{}
+	r := NewRedriver(pub, RedriveConfig{
+		Transform: func(_ context.Context, msg messaging.Message, _ DeadLetterEnvelope) (messaging.Message, error) {
+			msg.Topic = "orders.v2"
+			msg.Value = []byte("v2")
+			return msg, nil
+		},
+	})
+	if err := r.Handler()(context.Background(), dlqMessage(t, env)); err != nil {
+		t.Fatalf("redrive: %v", err)
+	}
+	if len(pub.sent) != 1 || pub.sent[0].Topic != "orders.v2" || string(pub.sent[0].Value) != "v2" {
+		t.Fatalf("sent = %+v", pub.sent)
+	}
+
+	errTransform := errors.New("cannot repair")
+	r = NewRedriver(pub, RedriveConfig{
+		Transform: func(context.Context, messaging.Message, DeadLetterEnvelope) (messaging.Message, error) {
+			return messaging.Message{}, errTransform
+		},
+	})
+	if err := r.Handler()(context.Background(), dlqMessage(t, env)); !errors.Is(err, errTransform) {
+		t.Fatalf("transform error = %v", err)
+	}
+
+	errSend := errors.New("broker down")
+	r = NewRedriver(&recordingPublisher{mockPublisher: mockPublisher{err: errSend}}, RedriveConfig{})
+	if err := r.Handler()(context.Background(), dlqMessage(t, env)); !errors.Is(err, errSend) {
+		t.Fatalf("send error = %v", err)
+	}
+	if report := r.Report(); report.Redriven != 0 || report.Matched != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+}
+
+func TestRedriver_RunStopsWhenIdle(t *testing.T) {
+	broker := memory.NewBroker()
+	defer broker.Close()
+	consumer := broker.Consumer("orders.dlq")
+	for i := range 3 {
+		env := DeadLetterEnvelope{OriginalTopic: "orders", Error: "boom", Payload: string(rune('a' + i)), PayloadVerbatim: true}
+		if err := broker.Producer().Send(context.Background(), dlqMessage(t, env)); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+
+	r := NewRedriver(broker.Producer(), RedriveConfig{
+		IdleTimeout: 50 * time.Millisecond,
+		RateLimit:   &RateLimitConfig{Limit: 1000, Interval: time.Second},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	report, err := r.Run(ctx, consumer)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("Run did not stop on idle")
+	}
+	if report.Redriven != 3 || broker.MessageCount("orders") != 3 {
+		t.Fatalf("report = %+v, republished %d", report, broker.MessageCount("orders"))
+	}
+}
+
+// recordingPublisher records sent messages.
+type recordingPublisher struct {
+	mockPublisher
+	sent []messaging.Message
+}
+
+func (p *recordingPublisher) Send(_ context.Context, msg messaging.Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.sent = append(p.sent, msg)
+	return nil
+}
@@ -8,7 +8,7 @@
 //
 // Middleware is applied in a fixed order regardless of builder call order:
 //
-//	Tracing → Metrics → Dedup → CircuitBreaker → Retry(+DLQ) → Handler
+//	Tracing → Metrics → Dedup → RateLimit → CircuitBreaker → Retry(+DLQ) → Handler
 //
 // The outermost middleware (Tracing) runs first on each message.
 //
@@ -27,6 +27,7 @@ type StackBuilder struct {
 	tracing    bool
 	tracingOpt []TracingOption
 	dedupCfg   *DedupConfig
+	rateCfg    *RateLimitConfig
 	cbCfg      *CircuitBreakerConfig
 }
 
@@ -65,6 +66,12 @@ func (b *StackBuilder) WithDedup(cfg DedupConfig) *StackBuilder {
 	return b
 }
 
+// WithRateLimit adds consumer throttling middleware.
+func (b *StackBuilder) WithRateLimit(cfg RateLimitConfig) *StackBuilder {
+	b.rateCfg = &cfg
+	return b
+}
+
 // WithCircuitBreaker adds circuit breaker middleware.
 func (b *StackBuilder) WithCircuitBreaker(cfg CircuitBreakerConfig) *StackBuilder {
 	b.cbCfg = &cfg
@@ -76,9 +83,10 @@ func (b *StackBuilder) WithCircuitBreaker(cfg CircuitBreakerConfig) *StackBuilde
 // Application order (inner → outer):
 //  1. Retry (+DLQ on exhaustion) — innermost, closest to handler
 //  2. CircuitBreaker — fail-fast before retry
-//  3. Dedup — skip duplicates before processing
-//  4. Metrics — record per-message metrics
-//  5. Tracing — outermost, creates a span for the full pipeline
+//  3. RateLimit — throttle before spending downstream budget
+//  4. Dedup — skip duplicates before processing
+//  5. Metrics — record per-message metrics
+//  6. Tracing — outermost, creates a span for the full pipeline
 func (b *StackBuilder) Build() messaging.MessageHandler {
 	h := b.base
 
@@ -92,17 +100,22 @@ func (b *StackBuilder) Build() messaging.MessageHandler {
 		h = CircuitBreakerHandler(h, *b.cbCfg)
 	}
 
-	// 3. Dedup
+	// 3. Rate limit
+	if b.rateCfg != nil {
+		h = RateLimitHandler(h, *b.rateCfg)
+	}
+
+	// 4. Dedup
 	if b.dedupCfg != nil {
 		h = DedupHandler(h, *b.dedupCfg)
 	}
 
-	// 4. Metrics
+	// 5. Metrics
 	if b.metrics != nil {
 		h = InstrumentHandler(b.metrics.topic, b.metrics.group, h)
 	}
 
-	// 5. Tracing (outermost)
+	// 6. Tracing (outermost)
 	if b.tracing {
 		h = TracingHandler(h, b.tracingOpt...)
 	}
@@ -33,6 +33,7 @@ type Config struct {
 	Password         string              `yaml:"password" mapstructure:"password"`
 	TLS              *security.TLSConfig `yaml:"tls" mapstructure:"tls"`
 	AllowInsecureDev bool                `yaml:"allow_insecure_dev" mapstructure:"allow_insecure_dev"`
+	JetStream        JetStreamConfig     `yaml:"jetstream" mapstructure:"jetstream"`
 }
 
 // ApplyDefaults fills zero-valued fields.
@@ -55,6 +56,9 @@ func (c *Config) ApplyDefaults() {
 	if c.ReconnectWait == "" {
 		c.ReconnectWait = "2s"
 	}
+	if c.JetStream.Enabled {
+		c.JetStream.applyDefaults()
+	}
 }
 
 // Validate checks NATS-specific settings.
@@ -106,6 +110,9 @@ func (c Config) Validate() error {
 	if err := c.TLS.Validate(); err != nil {
 		return fmt.Errorf("nats tls: %w", err)
 	}
+	if c.JetStream.Enabled {
+		return c.JetStream.validate(c)
+	}
 	return nil
 }
 
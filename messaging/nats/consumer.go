@@ -11,18 +11,28 @@
 	"github.com/kbukum/gokit/messaging"
 )
 
-// Consumer consumes messages from a NATS subject.
+// Consumer consumes messages from a NATS subject, or from a durable consumer
+// of a stream when JetStream is enabled.
 type Consumer struct {
-	conn    natsConn
-	sub     natsSubscription
-	connect func(string, ...natsgo.Option) (natsConn, error)
-	cfg     Config
-	topic   string
-	mu      sync.Mutex
-	closed  bool
+	conn      natsConn
+	sub       natsSubscription
+	connect   func(string, ...natsgo.Option) (natsConn, error)
+	jetstream func(natsConn) (jsContext, error)
+	js        jsContext
+	source    jsSource
+	cfg       Config
+	topic     string
+	mu        sync.Mutex
+	closed    bool
+	done      chan struct{}
+	paused    bool
+	resume    chan struct{}
 }
 
-var _ messaging.Consumer = (*Consumer)(nil)
+var (
+	_ messaging.Consumer         = (*Consumer)(nil)
+	_ messaging.PausableConsumer = (*Consumer)(nil)
+)
 
 // NewConsumer creates a lazy NATS consumer for topic. It connects on Consume.
 func NewConsumer(cfg Config, topic string) (*Consumer, error) {
@@ -33,7 +43,7 @@ func NewConsumer(cfg Config, topic string) (*Consumer, error) {
 	if err := messaging.ValidateTopic(topic); err != nil {
 		return nil, err
 	}
-	return &Consumer{cfg: cfg, topic: topic, connect: defaultConnectNATS}, nil
+	return &Consumer{cfg: cfg, topic: topic, connect: defaultConnectNATS, jetstream: defaultJetStream, done: make(chan struct{})}, nil
 }
 
 func (c *Consumer) ensureSubscription() (natsSubscription, error) {
@@ -72,7 +82,15 @@ func (c *Consumer) ensureSubscription() (natsSubscription, error) {
 }
 
 // Consume reads messages until ctx is canceled.
+//
+// With JetStream, a message is acked when handler returns nil and terminated
+// when it returns an error wrapped by Term, and consumption continues. Any
+// other error naks the message for redelivery (after NakDelay) and ends
+// Consume. At-most-once consumers registered through Register never ack.
 func (c *Consumer) Consume(ctx context.Context, handler messaging.MessageHandler) error {
+	if c.cfg.JetStream.Enabled {
+		return c.consumeJetStream(ctx, handler)
+	}
 	sub, err := c.ensureSubscription()
 	if err != nil {
 		return err
@@ -118,6 +136,14 @@ func (c *Consumer) Close() error {
 		return nil
 	}
 	c.closed = true
+	if c.done != nil {
+		close(c.done)
+	}
+	if c.source != nil {
+		c.source.Stop()
+		c.source = nil
+	}
+	c.js = nil
 	var err error
 	if c.sub != nil {
 		err = c.sub.Unsubscribe()
@@ -135,3 +161,140 @@ func (c *Consumer) isClosed() bool {
 	defer c.mu.Unlock()
 	return c.closed
 }
+
+func (c *Consumer) ensureJetStream(ctx context.Context) (jsContext, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, messaging.ErrClosed
+	}
+	if c.js != nil {
+		return c.js, nil
+	}
+	opts, err := c.cfg.connectOptions()
+	if err != nil {
+		return nil, err
+	}
+	if c.connect == nil {
+		c.connect = defaultConnectNATS
+	}
+	if c.jetstream == nil {
+		c.jetstream = defaultJetStream
+	}
+	conn, err := c.connect(c.cfg.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("nats consumer connect %s: %w", c.cfg.RedactedURL(), err)
+	}
+	js, err := c.jetstream(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("nats jetstream: %w", err)
+	}
+	if c.cfg.JetStream.Provision {
+		if _, err := js.CreateOrUpdateStream(ctx, c.cfg.streamConfig()); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("nats jetstream stream %s: %w", c.cfg.JetStream.Stream, err)
+		}
+	}
+	c.conn = conn
+	c.js = js
+	return js, nil
+}
+
+func (c *Consumer) consumeJetStream(ctx context.Context, handler messaging.MessageHandler) error {
+	js, err := c.ensureJetStream(ctx)
+	if err != nil {
+		return err
+	}
+	src, err := openJetStreamSource(ctx, js, c.cfg, c.topic)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		src.Stop()
+		return messaging.ErrClosed
+	}
+	c.source = src
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		owned := c.source == src
+		if owned {
+			c.source = nil
+		}
+		c.mu.Unlock()
+		if owned {
+			src.Stop()
+		}
+	}()
+
+	for {
+		if err := c.waitResumed(ctx); err != nil {
+			return err
+		}
+		msg, err := src.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if c.isClosed() {
+				return messaging.ErrClosed
+			}
+			return fmt.Errorf("nats jetstream receive: %w", err)
+		}
+		if err := c.settle(msg, handler(ctx, jetStreamMessage(msg, c.topic))); err != nil {
+			return err
+		}
+	}
+}
+
+// Pause implements messaging.PausableConsumer for JetStream consumers:
+// Consume stops handing messages to the handler until Resume. Messages the
+// client already holds stay unacknowledged and are redelivered if the pause
+// outlasts AckWait. Core NATS cannot pause and returns messaging.ErrUnsupported.
+func (c *Consumer) Pause(context.Context) error {
+	if !c.cfg.JetStream.Enabled {
+		return fmt.Errorf("nats: pause requires jetstream: %w", messaging.ErrUnsupported)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		c.paused = true
+		c.resume = make(chan struct{})
+	}
+	return nil
+}
+
+// Resume implements messaging.PausableConsumer.
+func (c *Consumer) Resume(context.Context) error {
+	if !c.cfg.JetStream.Enabled {
+		return fmt.Errorf("nats: resume requires jetstream: %w", messaging.ErrUnsupported)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		c.paused = false
+		close(c.resume)
+	}
+	return nil
+}
+
+func (c *Consumer) waitResumed(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.paused {
+		c.mu.Unlock()
+		return nil
+	}
+	resume := c.resume
+	c.mu.Unlock()
+	select {
+	case <-resume:
+		return nil
+	case <-c.done:
+		return messaging.ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,2 +1,6 @@
 // Package nats provides the opt-in NATS messaging adapter.
+//
+// By default it uses core NATS subjects, which deliver at most once. Setting
+// Config.JetStream switches producers and consumers to a JetStream stream with
+// durable consumers, acknowledged according to handler results.
 package nats
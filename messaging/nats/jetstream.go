@@ -0,0 +1,389 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/kbukum/gokit/messaging"
+)
+
+const (
+	jetStreamModePull = "pull"
+	jetStreamModePush = "push"
+)
+
+// JetStreamConfig switches the adapter from core NATS to JetStream, which
+// persists messages in a stream and acknowledges them per consumer.
+type JetStreamConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Stream is the stream messages are published to and consumed from.
+	Stream string `yaml:"stream" mapstructure:"stream"`
+	// Provision creates or updates the stream and consumers from this config.
+	// Otherwise they must already exist.
+	Provision bool `yaml:"provision" mapstructure:"provision"`
+	// Subjects the stream captures when provisioned. Default: "<subject_prefix>.>".
+	Subjects []string `yaml:"subjects" mapstructure:"subjects"`
+	// Storage is "file" (default) or "memory".
+	Storage string `yaml:"storage" mapstructure:"storage"`
+	// Retention is "limits" (default), "interest" or "workqueue".
+	Retention string `yaml:"retention" mapstructure:"retention"`
+	// MaxAge discards stream messages older than this (empty = keep).
+	MaxAge   string `yaml:"max_age" mapstructure:"max_age"`
+	Replicas int    `yaml:"replicas" mapstructure:"replicas"`
+
+	// Durable names the consumer. Default: "<queue_group>_<topic>" with dots
+	// replaced, so each topic gets its own durable consumer per group.
+	Durable string `yaml:"durable" mapstructure:"durable"`
+	// Mode is "pull" (default) or "push".
+	Mode string `yaml:"mode" mapstructure:"mode"`
+	// DeliverPolicy is "all" (default), "new" or "last".
+	DeliverPolicy string `yaml:"deliver_policy" mapstructure:"deliver_policy"`
+	// AckWait is how long the server waits for an ack before redelivering.
+	AckWait string `yaml:"ack_wait" mapstructure:"ack_wait"`
+	// MaxDeliver caps delivery attempts per message (0 = unlimited).
+	MaxDeliver int `yaml:"max_deliver" mapstructure:"max_deliver"`
+	// MaxAckPending caps unacknowledged messages. Default: common max_in_flight.
+	MaxAckPending int `yaml:"max_ack_pending" mapstructure:"max_ack_pending"`
+	// NakDelay delays redelivery of a message whose handler failed (empty = immediate).
+	NakDelay string `yaml:"nak_delay" mapstructure:"nak_delay"`
+
+	// ackNone is set for at-most-once consumers, which never acknowledge.
+	ackNone bool
+}
+
+func (c *JetStreamConfig) applyDefaults() {
+	if c.Storage == "" {
+		c.Storage = "file"
+	}
+	if c.Retention == "" {
+		c.Retention = "limits"
+	}
+	if c.Replicas == 0 {
+		c.Replicas = 1
+	}
+	if c.Mode == "" {
+		c.Mode = jetStreamModePull
+	}
+	if c.DeliverPolicy == "" {
+		c.DeliverPolicy = "all"
+	}
+	if c.AckWait == "" {
+		c.AckWait = "30s"
+	}
+}
+
+func (c JetStreamConfig) validate(cfg Config) error {
+	if err := validateJetStreamName("stream", c.Stream); err != nil {
+		return err
+	}
+	if c.Durable != "" {
+		if err := validateJetStreamName("durable", c.Durable); err != nil {
+			return err
+		}
+	} else if cfg.QueueGroup == "" {
+		return fmt.Errorf("nats: jetstream durable or queue_group is required")
+	}
+	if c.Provision && len(c.Subjects) == 0 && strings.Trim(cfg.SubjectPrefix, ".") == "" {
+		return fmt.Errorf("nats: jetstream subjects or subject_prefix is required to provision a stream")
+	}
+	if _, ok := storageTypes[c.Storage]; !ok {
+		return fmt.Errorf("nats: unsupported jetstream storage %q", c.Storage)
+	}
+	if _, ok := retentionPolicies[c.Retention]; !ok {
+		return fmt.Errorf("nats: unsupported jetstream retention %q", c.Retention)
+	}
+	if _, ok := deliverPolicies[c.DeliverPolicy]; !ok {
+		return fmt.Errorf("nats: unsupported jetstream deliver_policy %q", c.DeliverPolicy)
+	}
+	if c.Mode != jetStreamModePull && c.Mode != jetStreamModePush {
+		return fmt.Errorf("nats: jetstream mode must be %q or %q", jetStreamModePull, jetStreamModePush)
+	}
+	if c.Replicas < 1 {
+		return fmt.Errorf("nats: jetstream replicas must be >= 1")
+	}
+	if c.MaxDeliver < 0 || c.MaxAckPending < 0 {
+		return fmt.Errorf("nats: jetstream max_deliver and max_ack_pending must be >= 0")
+	}
+	if _, err := parsePositiveDuration("nats", "jetstream ack_wait", c.AckWait); err != nil {
+		return err
+	}
+	for _, value := range []struct{ name, val string }{
+		{"jetstream max_age", c.MaxAge},
+		{"jetstream nak_delay", c.NakDelay},
+	} {
+		if value.val == "" {
+			continue
+		}
+		if _, err := parsePositiveDuration("nats", value.name, value.val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	storageTypes = map[string]jetstream.StorageType{
+		"file":   jetstream.FileStorage,
+		"memory": jetstream.MemoryStorage,
+	}
+	retentionPolicies = map[string]jetstream.RetentionPolicy{
+		"limits":    jetstream.LimitsPolicy,
+		"interest":  jetstream.InterestPolicy,
+		"workqueue": jetstream.WorkQueuePolicy,
+	}
+	deliverPolicies = map[string]jetstream.DeliverPolicy{
+		"all":  jetstream.DeliverAllPolicy,
+		"new":  jetstream.DeliverNewPolicy,
+		"last": jetstream.DeliverLastPolicy,
+	}
+)
+
+// validateJetStreamName rejects names the server refuses for streams and
+// consumers.
+func validateJetStreamName(field, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("nats: jetstream %s is required", field)
+	}
+	if strings.ContainsAny(name, ".*> \t\r\n/\\") {
+		return fmt.Errorf("nats: jetstream %s %q must not contain '.', '*', '>', whitespace or path separators", field, name)
+	}
+	return nil
+}
+
+// streamConfig is the stream provisioned for cfg.
+func (c Config) streamConfig() jetstream.StreamConfig {
+	js := c.JetStream
+	subjects := js.Subjects
+	if len(subjects) == 0 {
+		subjects = []string{strings.Trim(c.SubjectPrefix, ".") + ".>"}
+	}
+	return jetstream.StreamConfig{
+		Name:      js.Stream,
+		Subjects:  subjects,
+		Storage:   storageTypes[js.Storage],
+		Retention: retentionPolicies[js.Retention],
+		MaxAge:    mustDuration(js.MaxAge),
+		Replicas:  js.Replicas,
+	}
+}
+
+// consumerConfig is the durable consumer provisioned for topic.
+func (c Config) consumerConfig(topic string) jetstream.ConsumerConfig {
+	js := c.JetStream
+	durable := c.durableName(topic)
+	cfg := jetstream.ConsumerConfig{
+		Durable:       durable,
+		FilterSubject: subject(c, topic),
+		DeliverPolicy: deliverPolicies[js.DeliverPolicy],
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       mustDuration(js.AckWait),
+		MaxDeliver:    js.MaxDeliver,
+		MaxAckPending: js.MaxAckPending,
+	}
+	if js.ackNone {
+		cfg.AckPolicy = jetstream.AckNonePolicy
+	}
+	if js.Mode == jetStreamModePush {
+		cfg.DeliverSubject = "_gokit.deliver." + js.Stream + "." + durable
+		cfg.DeliverGroup = c.QueueGroup
+	}
+	return cfg
+}
+
+func (c Config) durableName(topic string) string {
+	if c.JetStream.Durable != "" {
+		return c.JetStream.Durable
+	}
+	return strings.NewReplacer(".", "_", ":", "_").Replace(c.QueueGroup + "_" + topic)
+}
+
+// jsContext is the subset of jetstream.JetStream the adapter uses.
+type jsContext interface {
+	PublishMsg(ctx context.Context, msg *natsgo.Msg, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error)
+	CreateOrUpdateStream(ctx context.Context, cfg jetstream.StreamConfig) (jetstream.Stream, error)
+	CreateOrUpdateConsumer(ctx context.Context, stream string, cfg jetstream.ConsumerConfig) (jetstream.Consumer, error)
+	Consumer(ctx context.Context, stream, consumer string) (jetstream.Consumer, error)
+	CreateOrUpdatePushConsumer(ctx context.Context, stream string, cfg jetstream.ConsumerConfig) (jetstream.PushConsumer, error)
+	PushConsumer(ctx context.Context, stream, consumer string) (jetstream.PushConsumer, error)
+}
+
+func defaultJetStream(conn natsConn) (jsContext, error) {
+	adapter, ok := conn.(natsConnAdapter)
+	if !ok {
+		return nil, fmt.Errorf("nats: jetstream requires a NATS connection")
+	}
+	return jetstream.New(adapter.conn)
+}
+
+// jsSource yields the messages of one JetStream consumer subscription.
+type jsSource interface {
+	Next(ctx context.Context) (jetstream.Msg, error)
+	Stop()
+}
+
+// openJetStreamSource provisions or binds the consumer for topic and
+// subscribes to it.
+func openJetStreamSource(ctx context.Context, js jsContext, cfg Config, topic string) (jsSource, error) {
+	stream := cfg.JetStream.Stream
+	consumerCfg := cfg.consumerConfig(topic)
+	if cfg.JetStream.Mode == jetStreamModePush {
+		var (
+			consumer jetstream.PushConsumer
+			err      error
+		)
+		if cfg.JetStream.Provision {
+			consumer, err = js.CreateOrUpdatePushConsumer(ctx, stream, consumerCfg)
+		} else {
+			consumer, err = js.PushConsumer(ctx, stream, consumerCfg.Durable)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("nats jetstream push consumer %s/%s: %w", stream, consumerCfg.Durable, err)
+		}
+		return subscribePush(consumer)
+	}
+
+	var (
+		consumer jetstream.Consumer
+		err      error
+	)
+	if cfg.JetStream.Provision {
+		consumer, err = js.CreateOrUpdateConsumer(ctx, stream, consumerCfg)
+	} else {
+		consumer, err = js.Consumer(ctx, stream, consumerCfg.Durable)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("nats jetstream consumer %s/%s: %w", stream, consumerCfg.Durable, err)
+	}
+	var opts []jetstream.PullMessagesOpt
+	if consumerCfg.MaxAckPending > 0 {
+		opts = append(opts, jetstream.PullMaxMessages(consumerCfg.MaxAckPending))
+	}
+	it, err := consumer.Messages(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("nats jetstream pull: %w", err)
+	}
+	return pullSource{it: it}, nil
+}
+
+type pullSource struct{ it jetstream.MessagesContext }
+
+func (s pullSource) Next(ctx context.Context) (jetstream.Msg, error) {
+	return s.it.Next(jetstream.NextContext(ctx))
+}
+
+func (s pullSource) Stop() { s.it.Stop() }
+
+// pushSource hands messages from the push subscription's callback to Next
+// one at a time, so the server's flow control applies while the handler runs.
+type pushSource struct {
+	msgs    chan jetstream.Msg
+	stopped chan struct{}
+	cc      jetstream.ConsumeContext
+}
+
+func subscribePush(consumer jetstream.PushConsumer) (*pushSource, error) {
+	s := &pushSource{msgs: make(chan jetstream.Msg), stopped: make(chan struct{})}
+	cc, err := consumer.Consume(func(msg jetstream.Msg) {
+		select {
+		case s.msgs <- msg:
+		case <-s.stopped:
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("nats jetstream push subscribe: %w", err)
+	}
+	s.cc = cc
+	return s, nil
+}
+
+func (s *pushSource) Next(ctx context.Context) (jetstream.Msg, error) {
+	select {
+	case msg := <-s.msgs:
+		return msg, nil
+	case <-s.cc.Closed():
+		return nil, jetstream.ErrMsgIteratorClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *pushSource) Stop() {
+	close(s.stopped)
+	s.cc.Stop()
+}
+
+// termError marks a handler error as permanent.
+type termError struct{ err error }
+
+func (e termError) Error() string { return e.err.Error() }
+func (e termError) Unwrap() error { return e.err }
+
+// Term marks err as permanent. A JetStream consumer whose handler returns it
+// terminates the message, so the server never redelivers it, and keeps
+// consuming; other handler errors nak the message for redelivery and end
+// Consume. Core NATS consumers treat it like any other error.
+func Term(err error) error {
+	if err == nil {
+		return nil
+	}
+	return termError{err: err}
+}
+
+// settle acknowledges msg according to the handler's result. It returns the
+// error that ends Consume, or nil to continue.
+func (c *Consumer) settle(msg jetstream.Msg, handlerErr error) error {
+	js := c.cfg.JetStream
+	if js.ackNone {
+		return handlerErr
+	}
+	if handlerErr == nil {
+		if err := msg.Ack(); err != nil {
+			return fmt.Errorf("nats jetstream ack: %w", err)
+		}
+		return nil
+	}
+	var term termError
+	if errors.As(handlerErr, &term) {
+		if err := msg.TermWithReason(handlerErr.Error()); err != nil {
+			return fmt.Errorf("nats jetstream term: %w", err)
+		}
+		return nil
+	}
+	var err error
+	if delay := mustDuration(js.NakDelay); delay > 0 {
+		err = msg.NakWithDelay(delay)
+	} else {
+		err = msg.Nak()
+	}
+	if err != nil {
+		return errors.Join(handlerErr, fmt.Errorf("nats jetstream nak: %w", err))
+	}
+	return handlerErr
+}
+
+func jetStreamMessage(msg jetstream.Msg, topic string) messaging.Message {
+	headers := make(map[string]string, len(msg.Headers()))
+	for key, values := range msg.Headers() {
+		if len(values) > 0 {
+			headers[key] = values[0]
+		}
+	}
+	domain := messaging.Message{
+		Key:       headers["message-key"],
+		Value:     msg.Data(),
+		Topic:     topic,
+		Timestamp: time.Now().UTC(),
+		Headers:   headers,
+	}
+	if meta, err := msg.Metadata(); err == nil {
+		domain.Offset = int64(meta.Sequence.Stream)
+		domain.Timestamp = meta.Timestamp.UTC()
+	}
+	return domain
+}
@@ -0,0 +1,383 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/kbukum/gokit/messaging"
+)
+
+// fakeJSMsg records how a message was settled.
+type fakeJSMsg struct {
+	jetstream.Msg
+	data    []byte
+	headers natsgo.Header
+	seq     uint64
+
+	mu      sync.Mutex
+	settled []string
+}
+
+func (m *fakeJSMsg) Data() []byte           { return m.data }
+func (m *fakeJSMsg) Headers() natsgo.Header { return m.headers }
+func (m *fakeJSMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{Sequence: jetstream.SequencePair{Stream: m.seq}, Timestamp: time.Unix(100, 0)}, nil
+}
+func (m *fakeJSMsg) Ack() error                         { return m.settle("ack") }
+func (m *fakeJSMsg) Nak() error                         { return m.settle("nak") }
+func (m *fakeJSMsg) NakWithDelay(d time.Duration) error { return m.settle("nak " + d.String()) }
+func (m *fakeJSMsg) Term() error                        { return m.settle("term") }
+func (m *fakeJSMsg) TermWithReason(reason string) error { return m.settle("term " + reason) }
+
+func (m *fakeJSMsg) settle(how string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settled = append(m.settled, how)
+	return nil
+}
+
+func (m *fakeJSMsg) settlements() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.settled...)
+}
+
+func newFakeJSMsg(key string, seq uint64) *fakeJSMsg {
+	return &fakeJSMsg{data: []byte(key), headers: natsgo.Header{"message-key": {key}}, seq: seq}
+}
+
+// fakeMessages is a pull iterator over a channel.
+type fakeMessages struct {
+	jetstream.MessagesContext
+	msgs     chan jetstream.Msg
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func (m *fakeMessages) Next(...jetstream.NextOpt) (jetstream.Msg, error) {
+	select {
+	case msg := <-m.msgs:
+		return msg, nil
+	case <-m.stop:
+		return nil, jetstream.ErrMsgIteratorClosed
+	}
+}
+
+func (m *fakeMessages) Stop() { m.stopOnce.Do(func() { close(m.stop) }) }
+
+type fakeJSConsumer struct {
+	jetstream.Consumer
+	it *fakeMessages
+}
+
+func (c fakeJSConsumer) Messages(...jetstream.PullMessagesOpt) (jetstream.MessagesContext, error) {
+	return c.it, nil
+}
+
+// fakeJSContext records provisioning and publishes; its one pull consumer
+// yields msgs.
+type fakeJSContext struct {
+	jetstream.JetStream
+	msgs chan jetstream.Msg
+
+	mu        sync.Mutex
+	streams   []jetstream.StreamConfig
+	consumers []jetstream.ConsumerConfig
+	published []*natsgo.Msg
+}
+
+func newFakeJSContext() *fakeJSContext {
+	return &fakeJSContext{msgs: make(chan jetstream.Msg, 8)}
+}
+
+func (c *fakeJSContext) PublishMsg(_ context.Context, msg *natsgo.Msg, _ ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.published = append(c.published, msg)
+	return &jetstream.PubAck{Sequence: uint64(len(c.published))}, nil
+}
+
+func (c *fakeJSContext) CreateOrUpdateStream(_ context.Context, cfg jetstream.StreamConfig) (jetstream.Stream, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.streams = append(c.streams, cfg)
+	return nil, nil
+}
+
+func (c *fakeJSContext) CreateOrUpdateConsumer(_ context.Context, _ string, cfg jetstream.ConsumerConfig) (jetstream.Consumer, error) {
+	c.mu.Lock()
+	c.consumers = append(c.consumers, cfg)
+	c.mu.Unlock()
+	return c.Consumer(context.Background(), "", cfg.Durable)
+}
+
+func (c *fakeJSContext) Consumer(context.Context, string, string) (jetstream.Consumer, error) {
+	return fakeJSConsumer{it: &fakeMessages{msgs: c.msgs, stop: make(chan struct{})}}, nil
+}
+
+func (c *fakeJSContext) consumerConfigs() []jetstream.ConsumerConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]jetstream.ConsumerConfig(nil), c.consumers...)
+}
+
+func jetStreamTestConfig() Config {
+	return Config{
+		URL: "nats://localhost:4222", AllowInsecureDev: true, SubjectPrefix: "svc", QueueGroup: "workers",
+		JetStream: JetStreamConfig{Enabled: true, Stream: "EVENTS", Provision: true, NakDelay: "2s"},
+	}
+}
+
+func newJetStreamTestConsumer(t *testing.T, cfg Config, js *fakeJSContext) *Consumer {
+	t.Helper()
+	c, err := NewConsumer(cfg, "orders.created")
+	if err != nil {
+		t.Fatalf("new jetstream consumer: %v", err)
+	}
+	c.connect = func(string, ...natsgo.Option) (natsConn, error) { return &fakeNATSConn{}, nil }
+	c.jetstream = func(natsConn) (jsContext, error) { return js, nil }
+	return c
+}
+
+func TestJetStreamConfigDefaultsAndValidation(t *testing.T) {
+	t.Parallel()
+
+	cfg := jetStreamTestConfig()
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("validate jetstream config: %v", err)
+	}
+	if got := cfg.streamConfig(); got.Name != "EVENTS" || len(got.Subjects) != 1 || got.Subjects[0] != "svc.>" || got.Storage != jetstream.FileStorage {
+		t.Fatalf("stream config = %+v", got)
+	}
+	if got := cfg.durableName("orders.created"); got != "workers_orders_created" {
+		t.Fatalf("durable name = %q, want workers_orders_created", got)
+	}
+
+	for name, mutate := range map[string]func(*Config){
+		"missing stream":    func(c *Config) { c.JetStream.Stream = "" },
+		"dotted stream":     func(c *Config) { c.JetStream.Stream = "a.b" },
+		"no durable":        func(c *Config) { c.QueueGroup = "" },
+		"no subjects":       func(c *Config) { c.SubjectPrefix = "" },
+		"bad storage":       func(c *Config) { c.JetStream.Storage = "disk" },
+		"bad mode":          func(c *Config) { c.JetStream.Mode = "poll" },
+		"bad deliver":       func(c *Config) { c.JetStream.DeliverPolicy = "first" },
+		"bad ack wait":      func(c *Config) { c.JetStream.AckWait = "-1s" },
+		"negative max":      func(c *Config) { c.JetStream.MaxDeliver = -1 },
+		"unparsed nakdelay": func(c *Config) { c.JetStream.NakDelay = "soon" },
+	} {
+		cfg := jetStreamTestConfig()
+		mutate(&cfg)
+		cfg.ApplyDefaults()
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestJetStreamConsumerSettlesHandlerResults(t *testing.T) {
+	t.Parallel()
+
+	js := newFakeJSContext()
+	c := newJetStreamTestConsumer(t, jetStreamTestConfig(), js)
+	ok, bad, failed := newFakeJSMsg("ok", 1), newFakeJSMsg("bad", 2), newFakeJSMsg("failed", 3)
+	js.msgs <- ok
+	js.msgs <- bad
+	js.msgs <- failed
+
+	handlerErr := errors.New("handler failed")
+	var got []messaging.Message
+	err := c.Consume(context.Background(), func(_ context.Context, msg messaging.Message) error {
+		got = append(got, msg)
+		switch msg.Key {
+		case "bad":
+			return Term(errors.New("malformed"))
+		case "failed":
+			return handlerErr
+		}
+		return nil
+	})
+	if !errors.Is(err, handlerErr) {
+		t.Fatalf("Consume = %v, want handler error", err)
+	}
+	if len(got) != 3 || got[0].Topic != "orders.created" || got[0].Offset != 1 || !got[0].Timestamp.Equal(time.Unix(100, 0)) {
+		t.Fatalf("delivered = %+v", got)
+	}
+	if s := ok.settlements(); len(s) != 1 || s[0] != "ack" {
+		t.Errorf("ok settled %v, want ack", s)
+	}
+	if s := bad.settlements(); len(s) != 1 || s[0] != "term malformed" {
+		t.Errorf("bad settled %v, want term", s)
+	}
+	if s := failed.settlements(); len(s) != 1 || s[0] != "nak 2s" {
+		t.Errorf("failed settled %v, want delayed nak", s)
+	}
+
+	if len(js.streams) != 1 {
+		t.Fatalf("provisioned %d streams, want 1", len(js.streams))
+	}
+	consumers := js.consumerConfigs()
+	if len(consumers) != 1 || consumers[0].Durable != "workers_orders_created" ||
+		consumers[0].AckPolicy != jetstream.AckExplicitPolicy || consumers[0].FilterSubject != "svc.orders.created" {
+		t.Fatalf("consumer config = %+v", consumers)
+	}
+}
+
+func TestJetStreamConsumerAtMostOnceNeverAcks(t *testing.T) {
+	t.Parallel()
+
+	cfg := jetStreamTestConfig()
+	cfg.JetStream.ackNone = true
+	js := newFakeJSContext()
+	c := newJetStreamTestConsumer(t, cfg, js)
+	msg := newFakeJSMsg("k", 1)
+	js.msgs <- msg
+
+	handlerErr := errors.New("handler failed")
+	if err := c.Consume(context.Background(), func(context.Context, messaging.Message) error { return handlerErr }); !errors.Is(err, handlerErr) {
+		t.Fatalf("Consume = %v, want handler error", err)
+	}
+	if s := msg.settlements(); len(s) != 0 {
+		t.Fatalf("settled %v, want nothing", s)
+	}
+	if consumers := js.consumerConfigs(); consumers[0].AckPolicy != jetstream.AckNonePolicy {
+		t.Fatalf("ack policy = %v, want none", consumers[0].AckPolicy)
+	}
+}
+
+func TestJetStreamConsumerPauseAndClose(t *testing.T) {
+	t.Parallel()
+
+	js := newFakeJSContext()
+	c := newJetStreamTestConsumer(t, jetStreamTestConfig(), js)
+	if err := c.Pause(context.Background()); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	js.msgs <- newFakeJSMsg("k", 1)
+
+	delivered := make(chan struct{}, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Consume(context.Background(), func(context.Context, messaging.Message) error {
+			delivered <- struct{}{}
+			return nil
+		})
+	}()
+
+	select {
+	case <-delivered:
+		t.Fatal("message delivered while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := c.Resume(context.Background()); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("message not delivered after resume")
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, messaging.ErrClosed) {
+			t.Fatalf("Consume after close = %v, want ErrClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Consume did not return after Close")
+	}
+}
+
+func TestCoreConsumerCannotPause(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewConsumer(Config{URL: "nats://localhost:4222", AllowInsecureDev: true}, "orders")
+	if err != nil {
+		t.Fatalf("new consumer: %v", err)
+	}
+	if err := c.Pause(context.Background()); !errors.Is(err, messaging.ErrUnsupported) {
+		t.Fatalf("pause = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestJetStreamProducerPublishesWithMessageID(t *testing.T) {
+	t.Parallel()
+
+	js := newFakeJSContext()
+	conn := &fakeNATSConn{}
+	p, err := NewProducer(jetStreamTestConfig())
+	if err != nil {
+		t.Fatalf("new jetstream producer: %v", err)
+	}
+	p.connect = func(string, ...natsgo.Option) (natsConn, error) { return conn, nil }
+	p.jetstream = func(natsConn) (jsContext, error) { return js, nil }
+
+	event, err := messaging.NewEvent("order.created", "orders", map[string]string{"id": "1"}, "order-1")
+	if err != nil {
+		t.Fatalf("new event: %v", err)
+	}
+	if err := p.Publish(context.Background(), "orders.created", event); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if err := p.PublishBinary(context.Background(), "orders.created", "k", []byte("raw")); err != nil {
+		t.Fatalf("publish binary: %v", err)
+	}
+
+	if len(js.streams) != 1 {
+		t.Fatalf("provisioned %d streams, want 1", len(js.streams))
+	}
+	if len(js.published) != 2 || len(conn.published) != 0 {
+		t.Fatalf("jetstream published %d, core published %d; want 2 and 0", len(js.published), len(conn.published))
+	}
+	first := js.published[0]
+	if first.Subject != "svc.orders.created" || first.Header.Get(natsgo.MsgIdHdr) != event.ID || first.Header.Get("message-key") != "order-1" {
+		t.Fatalf("published message = %+v", first)
+	}
+	if id := js.published[1].Header.Get(natsgo.MsgIdHdr); id != "" {
+		t.Fatalf("binary message id = %q, want none", id)
+	}
+}
+
+func TestRegisterJetStreamDeliveryGuarantees(t *testing.T) {
+	t.Parallel()
+
+	reg := messaging.NewRegistry()
+	if err := Register(reg, jetStreamTestConfig()); err != nil {
+		t.Fatalf("register nats: %v", err)
+	}
+
+	consumer, err := reg.NewConsumer(context.Background(), messaging.Config{Adapter: "nats", MaxInFlight: 16}, nil, "orders")
+	if err != nil {
+		t.Fatalf("at-least-once consumer: %v", err)
+	}
+	if js := consumer.(*Consumer).cfg.JetStream; js.ackNone || js.MaxAckPending != 16 {
+		t.Fatalf("at-least-once jetstream config = %+v", js)
+	}
+
+	consumer, err = reg.NewConsumer(context.Background(), messaging.Config{Adapter: "nats", DeliveryGuarantee: messaging.DeliveryAtMostOnce, CommitStrategy: messaging.CommitAuto}, nil, "orders")
+	if err != nil {
+		t.Fatalf("at-most-once consumer: %v", err)
+	}
+	if !consumer.(*Consumer).cfg.JetStream.ackNone {
+		t.Fatal("at-most-once consumer should not ack")
+	}
+
+	for _, common := range []messaging.Config{
+		{Adapter: "nats", DeliveryGuarantee: messaging.DeliveryExactlyOnce},
+		{Adapter: "nats", DeliveryGuarantee: messaging.DeliveryAtLeastOnce, CommitStrategy: messaging.CommitAuto},
+	} {
+		if _, err := reg.NewConsumer(context.Background(), common, nil, "orders"); err == nil || !strings.Contains(err.Error(), "jetstream") {
+			t.Errorf("%s/%s: error = %v, want jetstream rejection", common.DeliveryGuarantee, common.CommitStrategy, err)
+		}
+	}
+}
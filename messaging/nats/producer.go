@@ -13,10 +13,13 @@
 	"github.com/kbukum/gokit/resilience"
 )
 
-// Producer publishes messages to NATS subjects.
+// Producer publishes messages to NATS subjects. With JetStream enabled it
+// waits for the stream to acknowledge each message.
 type Producer struct {
 	conn          natsConn
 	connect       func(string, ...natsgo.Option) (natsConn, error)
+	jetstream     func(natsConn) (jsContext, error)
+	js            jsContext
 	cfg           Config
 	retryAttempts int
 	retryBackoff  time.Duration
@@ -39,7 +42,7 @@ func newProducer(cfg Config, retryAttempts int, retryBackoff time.Duration) (*Pr
 	if retryAttempts <= 0 {
 		retryAttempts = 1
 	}
-	return &Producer{cfg: cfg, retryAttempts: retryAttempts, retryBackoff: retryBackoff, connect: defaultConnectNATS}, nil
+	return &Producer{cfg: cfg, retryAttempts: retryAttempts, retryBackoff: retryBackoff, connect: defaultConnectNATS, jetstream: defaultJetStream}, nil
 }
 
 func (p *Producer) ensureConnLocked() (natsConn, error) {
@@ -61,9 +64,32 @@ func (p *Producer) ensureConnLocked() (natsConn, error) {
 		return nil, fmt.Errorf("nats producer connect %s: %w", p.cfg.RedactedURL(), err)
 	}
 	p.conn = conn
+	p.js = nil
 	return conn, nil
 }
 
+// ensureJetStreamLocked returns the JetStream context on conn, provisioning
+// the stream the first time when configured to.
+func (p *Producer) ensureJetStreamLocked(ctx context.Context, conn natsConn) (jsContext, error) {
+	if p.js != nil {
+		return p.js, nil
+	}
+	if p.jetstream == nil {
+		p.jetstream = defaultJetStream
+	}
+	js, err := p.jetstream(conn)
+	if err != nil {
+		return nil, fmt.Errorf("nats jetstream: %w", err)
+	}
+	if p.cfg.JetStream.Provision {
+		if _, err := js.CreateOrUpdateStream(ctx, p.cfg.streamConfig()); err != nil {
+			return nil, fmt.Errorf("nats jetstream stream %s: %w", p.cfg.JetStream.Stream, err)
+		}
+	}
+	p.js = js
+	return js, nil
+}
+
 // Send writes a pre-built transport-agnostic message.
 func (p *Producer) Send(ctx context.Context, msg messaging.Message) error {
 	return p.publish(ctx, msg.Topic, msg.Value, headersWithMessageKey(msg.Headers, msg.Key))
@@ -145,10 +171,40 @@ func (p *Producer) publish(ctx context.Context, topic string, data []byte, heade
 	for key, value := range headers {
 		msg.Header.Set(key, value)
 	}
-	return p.publishWithRetry(ctx, conn, msg)
+	if !p.cfg.JetStream.Enabled {
+		return p.publishWithRetry(ctx, func() error { return p.publishCore(conn, msg) })
+	}
+	js, err := p.ensureJetStreamLocked(ctx, conn)
+	if err != nil {
+		return err
+	}
+	// The stream drops a republished event within its duplicate window.
+	if id := headers["event-id"]; id != "" {
+		msg.Header.Set(natsgo.MsgIdHdr, id)
+	}
+	return p.publishWithRetry(ctx, func() error { return p.publishJetStream(ctx, js, msg) })
 }
 
-func (p *Producer) publishWithRetry(ctx context.Context, conn natsConn, msg *natsgo.Msg) error {
+func (p *Producer) publishCore(conn natsConn, msg *natsgo.Msg) error {
+	if err := conn.PublishMsg(msg); err != nil {
+		return fmt.Errorf("nats publish: %w", err)
+	}
+	if err := conn.FlushTimeout(mustDuration(p.cfg.PublishTimeout)); err != nil {
+		return fmt.Errorf("nats publish flush: %w", err)
+	}
+	return nil
+}
+
+func (p *Producer) publishJetStream(ctx context.Context, js jsContext, msg *natsgo.Msg) error {
+	ctx, cancel := context.WithTimeout(ctx, mustDuration(p.cfg.PublishTimeout))
+	defer cancel()
+	if _, err := js.PublishMsg(ctx, msg); err != nil {
+		return fmt.Errorf("nats jetstream publish: %w", err)
+	}
+	return nil
+}
+
+func (p *Producer) publishWithRetry(ctx context.Context, send func() error) error {
 	retryCfg := resilience.RetryConfig{
 		MaxAttempts:    p.retryAttempts,
 		InitialBackoff: p.retryBackoff,
@@ -168,11 +224,8 @@ func (p *Producer) publishWithRetry(ctx context.Context, conn natsConn, msg *nat
 		if err := ctx.Err(); err != nil {
 			return err
 		}
-		if err := conn.PublishMsg(msg); err != nil {
-			return fmt.Errorf("nats publish: %w", err)
-		}
-		if err := conn.FlushTimeout(mustDuration(p.cfg.PublishTimeout)); err != nil {
-			return fmt.Errorf("nats publish flush: %w", err)
+		if err := send(); err != nil {
+			return err
 		}
 		return ctx.Err()
 	})
@@ -204,6 +257,7 @@ func (p *Producer) Close() error {
 		return nil
 	}
 	p.closed = true
+	p.js = nil
 	if p.conn == nil {
 		return nil
 	}
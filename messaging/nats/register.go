@@ -20,7 +20,7 @@ func Register(registry *messaging.Registry, configs ...Config) error {
 	}
 	if err := registry.RegisterProducer(adapterName, func(_ context.Context, common messaging.Config, _ *logging.Logger) (messaging.Producer, error) {
 		cfg := cfg
-		if commonErr := validateCommon(common); commonErr != nil {
+		if commonErr := applyCommon(common, &cfg); commonErr != nil {
 			return nil, commonErr
 		}
 		cfg.PublishTimeout = common.RequestTimeout
@@ -34,7 +34,7 @@ func Register(registry *messaging.Registry, configs ...Config) error {
 	}
 	return registry.RegisterConsumer(adapterName, func(_ context.Context, common messaging.Config, _ *logging.Logger, topic string) (messaging.Consumer, error) {
 		cfg := cfg
-		if commonErr := validateCommon(common); commonErr != nil {
+		if commonErr := applyCommon(common, &cfg); commonErr != nil {
 			return nil, commonErr
 		}
 		if common.ConsumerGroup != "" {
@@ -62,6 +62,31 @@ func configFromRegistration(configs ...Config) (Config, error) {
 	return cfg, nil
 }
 
+// applyCommon checks common against what the configured transport supports
+// and carries its delivery settings into cfg.
+func applyCommon(common messaging.Config, cfg *Config) error {
+	if !cfg.JetStream.Enabled {
+		return validateCommon(common)
+	}
+	switch {
+	case common.DeliveryGuarantee == messaging.DeliveryAtLeastOnce && common.CommitStrategy == messaging.CommitAfterHandlerSuccess:
+	case common.DeliveryGuarantee == messaging.DeliveryAtMostOnce && common.CommitStrategy == messaging.CommitAuto:
+		cfg.JetStream.ackNone = true
+	case common.DeliveryGuarantee == messaging.DeliveryExactlyOnce:
+		return fmt.Errorf("nats: jetstream does not support %s delivery", messaging.DeliveryExactlyOnce)
+	default:
+		return fmt.Errorf("nats: jetstream supports %s with %s commits or %s with %s commits",
+			messaging.DeliveryAtLeastOnce, messaging.CommitAfterHandlerSuccess, messaging.DeliveryAtMostOnce, messaging.CommitAuto)
+	}
+	if cfg.JetStream.MaxAckPending == 0 {
+		cfg.JetStream.MaxAckPending = common.MaxInFlight
+	}
+	if common.DLQ.Enabled {
+		return fmt.Errorf("nats: adapter-managed DLQ is not supported; use messaging middleware")
+	}
+	return nil
+}
+
 func validateCommon(cfg messaging.Config) error {
 	if cfg.DeliveryGuarantee != messaging.DeliveryAtMostOnce {
 		return fmt.Errorf("nats: core NATS supports only %s delivery", messaging.DeliveryAtMostOnce)
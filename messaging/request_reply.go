@@ -0,0 +1,305 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/kbukum/gokit/messaging/internal/redact"
+)
+
+// Request-reply headers. They travel as ordinary message headers, so
+// request-reply works on every provider that carries headers.
+const (
+	// CorrelationIDHeader matches a reply to its request.
+	CorrelationIDHeader = "correlation-id"
+	// ReplyToHeader names the topic a request's reply is sent to.
+	ReplyToHeader = "reply-to"
+	// ReplyErrorHeader carries the error a request handler returned.
+	ReplyErrorHeader = "reply-error"
+)
+
+// maxReplyErrorBytes bounds the error text a reply carries.
+const maxReplyErrorBytes = 4096
+
+// ErrNoReplyTopic is returned when a Requester is configured without a reply topic.
+var ErrNoReplyTopic = errors.New("messaging: reply topic is required")
+
+// ReplyError is the error a remote request handler returned, delivered in
+// the reply's ReplyErrorHeader.
+type ReplyError struct {
+	Message string
+}
+
+func (e *ReplyError) Error() string { return "messaging: request failed: " + e.Message }
+
+// RequesterConfig configures a Requester.
+type RequesterConfig struct {
+	// ReplyTopic is the topic the replies consumer reads. Replies to every
+	// request of this Requester are sent there, so no other Requester may
+	// consume it: use a per-instance topic, or a consumer group unique to the
+	// instance. Required.
+	ReplyTopic string
+
+	// Timeout bounds a request whose context has no deadline. Default: 30s.
+	Timeout time.Duration
+}
+
+func (c *RequesterConfig) applyDefaults() {
+	if c.Timeout <= 0 {
+		c.Timeout = 30 * time.Second
+	}
+}
+
+// Requester sends requests and awaits their replies. Each request carries a
+// correlation ID and the reply topic in its headers; a background loop
+// consumes the reply topic and hands each reply to the request waiting for
+// it. Replies nobody waits for, such as late replies to timed-out requests,
+// are dropped. It is safe for concurrent use.
+type Requester struct {
+	producer Producer
+	cfg      RequesterConfig
+
+	mu      sync.Mutex
+	pending map[string]chan Message
+	err     error // why the reply loop ended
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRequester creates a Requester that publishes through producer and reads
+// replies with replies, a consumer of cfg.ReplyTopic, until Close. The
+// producer and consumer stay owned by the caller.
+func NewRequester(producer Producer, replies Consumer, cfg RequesterConfig) (*Requester, error) {
+	if cfg.ReplyTopic == "" {
+		return nil, ErrNoReplyTopic
+	}
+	if producer == nil || replies == nil {
+		return nil, errors.New("messaging: requester needs a producer and a replies consumer")
+	}
+	cfg.applyDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Requester{
+		producer: producer,
+		cfg:      cfg,
+		pending:  make(map[string]chan Message),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go r.consume(ctx, replies)
+	return r, nil
+}
+
+func (r *Requester) consume(ctx context.Context, replies Consumer) {
+	defer close(r.done)
+	err := replies.Consume(ctx, r.dispatch)
+	if err == nil || ctx.Err() != nil {
+		err = ErrClosed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = fmt.Errorf("messaging: reply loop ended: %w", err)
+	for id, ch := range r.pending {
+		close(ch)
+		delete(r.pending, id)
+	}
+}
+
+func (r *Requester) dispatch(_ context.Context, msg Message) error {
+	id := msg.Headers[CorrelationIDHeader]
+	r.mu.Lock()
+	ch, ok := r.pending[id]
+	if ok {
+		delete(r.pending, id)
+	}
+	r.mu.Unlock()
+	if ok {
+		ch <- msg
+	}
+	return nil
+}
+
+// Request sends msg and waits for its reply until ctx ends or, when ctx has
+// no deadline, the configured timeout passes. msg keeps its correlation ID
+// header if it has one; otherwise a new one is generated. A reply carrying
+// ReplyErrorHeader is returned together with a *ReplyError.
+func (r *Requester) Request(ctx context.Context, msg Message) (Message, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.cfg.Timeout)
+		defer cancel()
+	}
+
+	headers := make(map[string]string, len(msg.Headers)+2)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	id := headers[CorrelationIDHeader]
+	if id == "" {
+		id = uuid.NewString()
+		headers[CorrelationIDHeader] = id
+	}
+	headers[ReplyToHeader] = r.cfg.ReplyTopic
+	msg.Headers = headers
+
+	ch := make(chan Message, 1)
+	r.mu.Lock()
+	if r.err != nil {
+		err := r.err
+		r.mu.Unlock()
+		return Message{}, err
+	}
+	if _, dup := r.pending[id]; dup {
+		r.mu.Unlock()
+		return Message{}, fmt.Errorf("messaging: request %s is already pending", id)
+	}
+	r.pending[id] = ch
+	r.mu.Unlock()
+
+	if err := r.producer.Send(ctx, msg); err != nil {
+		r.forget(id)
+		return Message{}, fmt.Errorf("messaging: send request to %s: %w", msg.Topic, err)
+	}
+
+	select {
+	case reply, ok := <-ch:
+		if !ok {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			return Message{}, r.err
+		}
+		if text, failed := reply.Headers[ReplyErrorHeader]; failed {
+			return reply, &ReplyError{Message: text}
+		}
+		return reply, nil
+	case <-ctx.Done():
+		r.forget(id)
+		return Message{}, fmt.Errorf("messaging: await reply from %s: %w", msg.Topic, ctx.Err())
+	}
+}
+
+func (r *Requester) forget(id string) {
+	r.mu.Lock()
+	delete(r.pending, id)
+	r.mu.Unlock()
+}
+
+// Close stops the reply loop and fails pending requests with ErrClosed. It
+// does not close the producer or the replies consumer.
+func (r *Requester) Close() error {
+	r.cancel()
+	<-r.done
+	return nil
+}
+
+// RequestJSON sends v as JSON to topic through r and decodes the reply's
+// value into a T.
+func RequestJSON[T any](ctx context.Context, r *Requester, topic, key string, v any) (T, error) {
+	var out T
+	data, err := json.Marshal(v)
+	if err != nil {
+		return out, fmt.Errorf("messaging: marshal request: %w", err)
+	}
+	reply, err := r.Request(ctx, NewMessage(topic, key, data, map[string]string{"content-type": "application/json"}))
+	if err != nil {
+		return out, err
+	}
+	if err := json.Unmarshal(reply.Value, &out); err != nil {
+		return out, fmt.Errorf("messaging: unmarshal reply: %w", err)
+	}
+	return out, nil
+}
+
+// RequestHandler handles a request and returns the reply to send. Only the
+// reply's Key, Value, and Headers are used.
+type RequestHandler func(ctx context.Context, msg Message) (Message, error)
+
+// ReplyHandlerOption configures a ReplyHandler.
+type ReplyHandlerOption func(*replyHandler)
+
+type replyHandler struct {
+	errorText func(error) string
+}
+
+// WithReplyErrorMapper sets how a handler error becomes the text of the
+// reply's ReplyErrorHeader, for example to send a stable public code instead
+// of the internal error. The text is still truncated to 4 KiB.
+func WithReplyErrorMapper(fn func(error) string) ReplyHandlerOption {
+	return func(h *replyHandler) {
+		if fn != nil {
+			h.errorText = fn
+		}
+	}
+}
+
+// ReplyHandler adapts fn into a MessageHandler that sends fn's reply to the
+// request's ReplyToHeader topic through producer, with the request's
+// correlation ID. When fn fails, the reply carries the error in
+// ReplyErrorHeader and the request counts as handled; only a failure to send
+// the reply is returned. Messages without a reply topic are handled as
+// one-way messages and fn's error is returned as is.
+//
+// The error text crosses to the requester, so by default it is redacted when
+// it mentions credentials, tokens, or secrets and truncated to 4 KiB, as in
+// dead-letter envelopes. WithReplyErrorMapper replaces the redaction.
+func ReplyHandler(producer Producer, fn RequestHandler, opts ...ReplyHandlerOption) MessageHandler {
+	h := &replyHandler{errorText: redactReplyError}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return func(ctx context.Context, msg Message) error {
+		replyTo := msg.Headers[ReplyToHeader]
+		reply, err := fn(ctx, msg)
+		if replyTo == "" {
+			return err
+		}
+
+		headers := make(map[string]string, len(reply.Headers)+2)
+		for k, v := range reply.Headers {
+			headers[k] = v
+		}
+		headers[CorrelationIDHeader] = msg.Headers[CorrelationIDHeader]
+		if err != nil {
+			headers[ReplyErrorHeader] = truncateReplyError(h.errorText(err))
+		}
+		key := reply.Key
+		if key == "" {
+			key = msg.Key
+		}
+		out := NewMessage(replyTo, key, reply.Value, headers)
+		if sendErr := producer.Send(ctx, out); sendErr != nil {
+			return fmt.Errorf("messaging: send reply to %s: %w", replyTo, sendErr)
+		}
+		return nil
+	}
+}
+
+// redactReplyError is the default reply error text: the error, or
+// "<redacted>" when it mentions sensitive material.
+func redactReplyError(err error) string {
+	text := err.Error()
+	if redact.Contains(text) {
+		return "<redacted>"
+	}
+	return text
+}
+
+// truncateReplyError cuts text to maxReplyErrorBytes on a rune boundary.
+func truncateReplyError(text string) string {
+	if len(text) <= maxReplyErrorBytes {
+		return text
+	}
+	limit := maxReplyErrorBytes
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	return text[:limit] + "…"
+}
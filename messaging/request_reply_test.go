@@ -0,0 +1,221 @@
+package messaging_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/messaging/memory"
+)
+
+type quote struct {
+	Symbol string  `json:"symbol"`
+	Price  float64 `json:"price"`
+}
+
+// startServer consumes topic and answers with fn until the test ends.
+func startServer(t *testing.T, broker *memory.InMemoryBroker, topic string, fn messaging.RequestHandler) {
+	t.Helper()
+	consumer := broker.Consumer(topic)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = consumer.Consume(ctx, messaging.ReplyHandler(broker.Producer(), fn))
+	}()
+	t.Cleanup(func() { cancel(); <-done })
+}
+
+func newRequester(t *testing.T, broker *memory.InMemoryBroker, cfg messaging.RequesterConfig) *messaging.Requester {
+	t.Helper()
+	r, err := messaging.NewRequester(broker.Producer(), broker.Consumer(cfg.ReplyTopic), cfg)
+	if err != nil {
+		t.Fatalf("NewRequester: %v", err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+func TestRequestReply_RoundTrip(t *testing.T) {
+	broker := memory.NewBroker()
+	defer broker.Close()
+	startServer(t, broker, "quotes", func(_ context.Context, msg messaging.Message) (messaging.Message, error) {
+		symbol, err := messaging.UnmarshalMessageJSON[string](msg)
+		if err != nil {
+			return messaging.Message{}, err
+		}
+		data, err := json.Marshal(quote{Symbol: symbol, Price: 12.5})
+		return messaging.Message{Value: data}, err
+	})
+	r := newRequester(t, broker, messaging.RequesterConfig{ReplyTopic: "quotes.replies.a"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, symbol := range []string{"ABC", "XYZ", "QRS"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := messaging.RequestJSON[quote](ctx, r, "quotes", symbol, symbol)
+			if err != nil {
+				t.Errorf("RequestJSON(%s): %v", symbol, err)
+				return
+			}
+			if got != (quote{Symbol: symbol, Price: 12.5}) {
+				t.Errorf("reply for %s = %+v", symbol, got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	reply, err := r.Request(ctx, messaging.NewMessage("quotes", "k", []byte(`"ABC"`), map[string]string{
+		messaging.CorrelationIDHeader: "fixed-id",
+	}))
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if reply.Headers[messaging.CorrelationIDHeader] != "fixed-id" || reply.Key != "k" {
+		t.Fatalf("reply = %+v", reply)
+	}
+	request := broker.Messages("quotes")[3]
+	if request.Headers[messaging.ReplyToHeader] != "quotes.replies.a" {
+		t.Fatalf("request headers = %v", request.Headers)
+	}
+}
+
+func TestRequestReply_HandlerError(t *testing.T) {
+	broker := memory.NewBroker()
+	defer broker.Close()
+	startServer(t, broker, "orders", func(context.Context, messaging.Message) (messaging.Message, error) {
+		return messaging.Message{}, errors.New("out of stock")
+	})
+	r := newRequester(t, broker, messaging.RequesterConfig{ReplyTopic: "orders.replies"})
+
+	_, err := r.Request(context.Background(), messaging.NewMessage("orders", "", []byte("x"), nil))
+	var replyErr *messaging.ReplyError
+	if !errors.As(err, &replyErr) || replyErr.Message != "out of stock" {
+		t.Fatalf("Request error = %v, want ReplyError", err)
+	}
+}
+
+func TestRequestReply_TimeoutAndLateReply(t *testing.T) {
+	broker := memory.NewBroker()
+	defer broker.Close()
+	release := make(chan struct{})
+	startServer(t, broker, "slow", func(ctx context.Context, _ messaging.Message) (messaging.Message, error) {
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return messaging.Message{Value: []byte("late")}, nil
+	})
+	r := newRequester(t, broker, messaging.RequesterConfig{ReplyTopic: "slow.replies", Timeout: 20 * time.Millisecond})
+
+	_, err := r.Request(context.Background(), messaging.NewMessage("slow", "", nil, nil))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Request error = %v, want deadline exceeded", err)
+	}
+	close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.Request(ctx, messaging.NewMessage("slow", "", nil, nil)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled Request error = %v", err)
+	}
+}
+
+func TestRequester_CloseFailsPendingRequests(t *testing.T) {
+	broker := memory.NewBroker()
+	defer broker.Close()
+	r, err := messaging.NewRequester(broker.Producer(), broker.Consumer("void.replies"), messaging.RequesterConfig{ReplyTopic: "void.replies"})
+	if err != nil {
+		t.Fatalf("NewRequester: %v", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := r.Request(context.Background(), messaging.NewMessage("void", "", nil, nil))
+		errs <- err
+	}()
+	memory.WaitForMessage(t, broker, "void", time.Second)
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := <-errs; !errors.Is(err, messaging.ErrClosed) {
+		t.Fatalf("pending Request error = %v, want ErrClosed", err)
+	}
+	if _, err := r.Request(context.Background(), messaging.NewMessage("void", "", nil, nil)); !errors.Is(err, messaging.ErrClosed) {
+		t.Fatalf("Request after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestNewRequester_RequiresReplyTopic(t *testing.T) {
+	broker := memory.NewBroker()
+	defer broker.Close()
+	_, err := messaging.NewRequester(broker.Producer(), broker.Consumer("x"), messaging.RequesterConfig{})
+	if !errors.Is(err, messaging.ErrNoReplyTopic) {
+		t.Fatalf("NewRequester = %v, want ErrNoReplyTopic", err)
+	}
+}
+
+func TestReplyHandler_SanitizesErrors(t *testing.T) {
+	long := strings.Repeat("é", 3000)
+	tests := []struct {
+		name string
+		err  error
+		opts []messaging.ReplyHandlerOption
+		want string
+	}{
+		{"plain", errors.New("quote not found"), nil, "quote not found"},
+		{"sensitive", errors.New("dial db: password authentication failed for user app"), nil, "<redacted>"},
+		{"truncated", errors.New(long), nil, long[:4096] + "…"},
+		{"mapped", errors.New("pq: deadlock detected"), []messaging.ReplyHandlerOption{
+			messaging.WithReplyErrorMapper(func(error) string { return "unavailable" }),
+		}, "unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := memory.NewBroker()
+			defer broker.Close()
+			h := messaging.ReplyHandler(broker.Producer(), func(context.Context, messaging.Message) (messaging.Message, error) {
+				return messaging.Message{}, tt.err
+			}, tt.opts...)
+			req := messaging.NewMessage("quotes", "k", nil, map[string]string{messaging.ReplyToHeader: "quotes.replies"})
+			if err := h(context.Background(), req); err != nil {
+				t.Fatalf("handler: %v", err)
+			}
+			replies := broker.Messages("quotes.replies")
+			if len(replies) != 1 {
+				t.Fatalf("replies = %d, want 1", len(replies))
+			}
+			if got := replies[0].Headers[messaging.ReplyErrorHeader]; got != tt.want {
+				t.Fatalf("reply error = %.40q (%d bytes), want %.40q (%d bytes)", got, len(got), tt.want, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestReplyHandler_OneWayMessages(t *testing.T) {
+	broker := memory.NewBroker()
+	defer broker.Close()
+	errFailed := errors.New("failed")
+	calls := 0
+	h := messaging.ReplyHandler(broker.Producer(), func(context.Context, messaging.Message) (messaging.Message, error) {
+		calls++
+		return messaging.Message{Value: []byte("ignored")}, errFailed
+	})
+	if err := h(context.Background(), messaging.NewMessage("events", "", nil, nil)); !errors.Is(err, errFailed) {
+		t.Fatalf("one-way handler error = %v", err)
+	}
+	if calls != 1 || len(broker.AllMessages()) != 0 {
+		t.Fatalf("calls = %d, published = %d", calls, len(broker.AllMessages()))
+	}
+	if !strings.Contains((&messaging.ReplyError{Message: "boom"}).Error(), "boom") {
+		t.Fatal("ReplyError must include the remote message")
+	}
+}
@@ -0,0 +1,545 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Avro type kinds.
+const (
+	avroNull    = "null"
+	avroBoolean = "boolean"
+	avroInt     = "int"
+	avroLong    = "long"
+	avroFloat   = "float"
+	avroDouble  = "double"
+	avroBytes   = "bytes"
+	avroString  = "string"
+	avroRecord  = "record"
+	avroEnum    = "enum"
+	avroArray   = "array"
+	avroMap     = "map"
+	avroUnion   = "union"
+	avroFixed   = "fixed"
+)
+
+// avroType is a parsed Avro schema node. Logical types are read as their
+// underlying type.
+type avroType struct {
+	kind     string
+	name     string   // full name of records, enums, and fixed
+	aliases  []string // full names
+	fields   []*avroField
+	symbols  []string
+	enumDef  string
+	items    *avroType // arrays
+	values   *avroType // maps
+	branches []*avroType
+	size     int
+}
+
+type avroField struct {
+	name       string
+	aliases    []string
+	typ        *avroType
+	def        any
+	hasDefault bool
+}
+
+// describe names t in error messages.
+func (t *avroType) describe() string {
+	if t.name != "" {
+		return t.name
+	}
+	return t.kind
+}
+
+// field returns the field of record t named name or one of aliases.
+func (t *avroType) field(name string, aliases []string) *avroField {
+	for _, f := range t.fields {
+		if f.name == name || slices.Contains(aliases, f.name) {
+			return f
+		}
+	}
+	return nil
+}
+
+// avroSchema is a parsed Avro schema with its named types, including those
+// of referenced schemas.
+type avroSchema struct {
+	root  *avroType
+	names map[string]*avroType
+}
+
+// parseAvro parses s, first parsing its references so their named types
+// resolve.
+func parseAvro(s Schema, resolve referenceResolver) (*avroSchema, error) {
+	names := make(map[string]*avroType)
+	if err := parseAvroReferences(s.References, resolve, names, map[Reference]bool{}); err != nil {
+		return nil, err
+	}
+	root, err := parseAvroText(s.Schema, names)
+	if err != nil {
+		return nil, err
+	}
+	return &avroSchema{root: root, names: names}, nil
+}
+
+func parseAvroReferences(refs []Reference, resolve referenceResolver, names map[string]*avroType, seen map[Reference]bool) error {
+	for _, ref := range refs {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		if resolve == nil {
+			return fmt.Errorf("avro reference %s: no resolver", ref.Name)
+		}
+		s, err := resolve(ref)
+		if err != nil {
+			return fmt.Errorf("avro reference %s: %w", ref.Name, err)
+		}
+		if err := parseAvroReferences(s.References, resolve, names, seen); err != nil {
+			return err
+		}
+		if _, err := parseAvroText(s.Schema, names); err != nil {
+			return fmt.Errorf("avro reference %s: %w", ref.Name, err)
+		}
+	}
+	return nil
+}
+
+func parseAvroText(text string, names map[string]*avroType) (*avroType, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var raw any
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("avro schema: %w", err)
+	}
+	p := avroParser{names: names}
+	return p.parse(raw, "")
+}
+
+type avroParser struct {
+	names map[string]*avroType
+}
+
+func (p avroParser) parse(raw any, namespace string) (*avroType, error) {
+	switch v := raw.(type) {
+	case string:
+		if isAvroPrimitive(v) {
+			return &avroType{kind: v}, nil
+		}
+		if t, ok := p.names[avroFullName(v, namespace)]; ok {
+			return t, nil
+		}
+		if t, ok := p.names[v]; ok {
+			return t, nil
+		}
+		return nil, fmt.Errorf("avro schema: unknown type %q", v)
+	case []any:
+		t := &avroType{kind: avroUnion}
+		for _, b := range v {
+			branch, err := p.parse(b, namespace)
+			if err != nil {
+				return nil, err
+			}
+			if branch.kind == avroUnion {
+				return nil, fmt.Errorf("avro schema: unions cannot contain unions")
+			}
+			if slices.ContainsFunc(t.branches, func(o *avroType) bool { return o.describe() == branch.describe() }) {
+				return nil, fmt.Errorf("avro schema: union repeats %s", branch.describe())
+			}
+			t.branches = append(t.branches, branch)
+		}
+		if len(t.branches) == 0 {
+			return nil, fmt.Errorf("avro schema: empty union")
+		}
+		return t, nil
+	case map[string]any:
+		return p.parseObject(v, namespace)
+	default:
+		return nil, fmt.Errorf("avro schema: unexpected %T", raw)
+	}
+}
+
+func (p avroParser) parseObject(obj map[string]any, namespace string) (*avroType, error) {
+	kind, ok := obj["type"].(string)
+	if !ok {
+		if nested, ok := obj["type"]; ok {
+			return p.parse(nested, namespace)
+		}
+		return nil, fmt.Errorf("avro schema: object without a type")
+	}
+	switch kind {
+	case avroRecord, "error":
+		t, ns, err := p.define(obj, avroRecord, namespace)
+		if err != nil {
+			return nil, err
+		}
+		rawFields, ok := obj["fields"].([]any)
+		if !ok {
+			return nil, fmt.Errorf("avro schema: record %s has no fields", t.name)
+		}
+		for _, rf := range rawFields {
+			fobj, ok := rf.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("avro schema: record %s has a malformed field", t.name)
+			}
+			f := &avroField{}
+			if f.name, ok = fobj["name"].(string); !ok || f.name == "" {
+				return nil, fmt.Errorf("avro schema: record %s has a field without a name", t.name)
+			}
+			if t.field(f.name, nil) != nil {
+				return nil, fmt.Errorf("avro schema: record %s repeats field %s", t.name, f.name)
+			}
+			if f.typ, err = p.parse(fobj["type"], ns); err != nil {
+				return nil, fmt.Errorf("avro schema: field %s.%s: %w", t.name, f.name, err)
+			}
+			f.def, f.hasDefault = fobj["default"]
+			f.aliases = stringList(fobj["aliases"])
+			t.fields = append(t.fields, f)
+		}
+		return t, nil
+	case avroEnum:
+		t, _, err := p.define(obj, avroEnum, namespace)
+		if err != nil {
+			return nil, err
+		}
+		t.symbols = stringList(obj["symbols"])
+		if len(t.symbols) == 0 {
+			return nil, fmt.Errorf("avro schema: enum %s has no symbols", t.name)
+		}
+		if len(slices.Compact(slices.Sorted(slices.Values(t.symbols)))) != len(t.symbols) {
+			return nil, fmt.Errorf("avro schema: enum %s repeats a symbol", t.name)
+		}
+		if def, ok := obj["default"].(string); ok {
+			if !slices.Contains(t.symbols, def) {
+				return nil, fmt.Errorf("avro schema: enum %s default %q is not a symbol", t.name, def)
+			}
+			t.enumDef = def
+		}
+		return t, nil
+	case avroFixed:
+		t, _, err := p.define(obj, avroFixed, namespace)
+		if err != nil {
+			return nil, err
+		}
+		size, err := jsonInt(obj["size"])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("avro schema: fixed %s has an invalid size", t.name)
+		}
+		t.size = int(size)
+		return t, nil
+	case avroArray:
+		items, err := p.parse(obj["items"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &avroType{kind: avroArray, items: items}, nil
+	case avroMap:
+		values, err := p.parse(obj["values"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &avroType{kind: avroMap, values: values}, nil
+	default:
+		return p.parse(kind, namespace)
+	}
+}
+
+// define registers a named type before its body is parsed, so records can
+// refer to themselves. It returns the namespace for the type's members.
+func (p avroParser) define(obj map[string]any, kind, namespace string) (*avroType, string, error) {
+	name, _ := obj["name"].(string)
+	if name == "" {
+		return nil, "", fmt.Errorf("avro schema: %s without a name", kind)
+	}
+	if ns, ok := obj["namespace"].(string); ok {
+		namespace = ns
+	}
+	full := avroFullName(name, namespace)
+	if _, exists := p.names[full]; exists {
+		return nil, "", fmt.Errorf("avro schema: %s is defined twice", full)
+	}
+	ns := ""
+	if i := strings.LastIndexByte(full, '.'); i >= 0 {
+		ns = full[:i]
+	}
+	t := &avroType{kind: kind, name: full}
+	for _, alias := range stringList(obj["aliases"]) {
+		t.aliases = append(t.aliases, avroFullName(alias, ns))
+	}
+	p.names[full] = t
+	return t, ns, nil
+}
+
+func avroFullName(name, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func isAvroPrimitive(kind string) bool {
+	switch kind {
+	case avroNull, avroBoolean, avroInt, avroLong, avroFloat, avroDouble, avroBytes, avroString:
+		return true
+	}
+	return false
+}
+
+func stringList(raw any) []string {
+	list, _ := raw.([]any)
+	out := make([]string, 0, len(list))
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// canRead reports whether data written with writer can be read with s,
+// following Avro schema resolution.
+func (s *avroSchema) canRead(writer parsedSchema) error {
+	w, ok := writer.(*avroSchema)
+	if !ok {
+		return fmt.Errorf("cannot compare an avro schema with %T", writer)
+	}
+	return avroCanRead(s.root, w.root, "", map[[2]*avroType]bool{})
+}
+
+func avroCanRead(r, w *avroType, path string, seen map[[2]*avroType]bool) error {
+	if w.kind == avroUnion {
+		for _, b := range w.branches {
+			if err := avroCanRead(r, b, path, seen); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if r.kind == avroUnion {
+		for _, b := range r.branches {
+			if avroCanRead(b, w, path, seen) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: reader union has no branch for writer type %s", avroPath(path), w.describe())
+	}
+	if !avroPromotable(w.kind, r.kind) {
+		return fmt.Errorf("%s: writer type %s cannot be read as %s", avroPath(path), w.describe(), r.describe())
+	}
+	if r.name != "" && !avroNamesMatch(r, w) {
+		return fmt.Errorf("%s: writer type %s cannot be read as %s", avroPath(path), w.describe(), r.describe())
+	}
+
+	pair := [2]*avroType{r, w}
+	if seen[pair] {
+		return nil
+	}
+	seen[pair] = true
+	err := avroCanReadBody(r, w, path, seen)
+	if err != nil {
+		delete(seen, pair)
+	}
+	return err
+}
+
+func avroCanReadBody(r, w *avroType, path string, seen map[[2]*avroType]bool) error {
+	switch r.kind {
+	case avroRecord:
+		for _, rf := range r.fields {
+			wf := w.field(rf.name, rf.aliases)
+			if wf == nil {
+				if !rf.hasDefault {
+					return fmt.Errorf("%s: reader field %s is missing from the writer and has no default", avroPath(path), rf.name)
+				}
+				continue
+			}
+			if err := avroCanRead(rf.typ, wf.typ, path+"."+rf.name, seen); err != nil {
+				return err
+			}
+		}
+	case avroEnum:
+		if r.enumDef == "" {
+			for _, sym := range w.symbols {
+				if !slices.Contains(r.symbols, sym) {
+					return fmt.Errorf("%s: reader enum %s lacks writer symbol %s", avroPath(path), r.name, sym)
+				}
+			}
+		}
+	case avroFixed:
+		if r.size != w.size {
+			return fmt.Errorf("%s: fixed %s changed size from %d to %d", avroPath(path), r.name, w.size, r.size)
+		}
+	case avroArray:
+		return avroCanRead(r.items, w.items, path+"[]", seen)
+	case avroMap:
+		return avroCanRead(r.values, w.values, path+"{}", seen)
+	}
+	return nil
+}
+
+// avroPromotable reports whether a writer kind can be read as a reader kind.
+func avroPromotable(writer, reader string) bool {
+	if writer == reader {
+		return true
+	}
+	switch writer {
+	case avroInt:
+		return reader == avroLong || reader == avroFloat || reader == avroDouble
+	case avroLong:
+		return reader == avroFloat || reader == avroDouble
+	case avroFloat:
+		return reader == avroDouble
+	case avroString:
+		return reader == avroBytes
+	case avroBytes:
+		return reader == avroString
+	}
+	return false
+}
+
+// avroNamesMatch compares named types by unqualified name or reader alias.
+func avroNamesMatch(r, w *avroType) bool {
+	return unqualified(r.name) == unqualified(w.name) || slices.Contains(r.aliases, w.name)
+}
+
+func unqualified(name string) string {
+	return name[strings.LastIndexByte(name, '.')+1:]
+}
+
+func avroPath(path string) string {
+	if path == "" {
+		return "root"
+	}
+	return strings.TrimPrefix(path, ".")
+}
+
+func jsonInt(raw any) (int64, error) {
+	switch v := raw.(type) {
+	case json.Number:
+		return v.Int64()
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, fmt.Errorf("%v is not an integer", v)
+		}
+		return int64(v), nil
+	default:
+		return 0, fmt.Errorf("%v is not a number", raw)
+	}
+}
+
+// NewAvroSerializer returns a Serializer writing values of T with the Avro
+// schema text. T is converted to Avro through its JSON form, so struct
+// fields map by their json names; []byte values fill bytes and fixed types.
+func NewAvroSerializer[T any](reg Registry, schemaText string, opts ...Option) (*Serializer[T], error) {
+	f, err := newAvroFormat[T](schemaText)
+	if err != nil {
+		return nil, err
+	}
+	return newSerializer[T](reg, f, newOptions(opts))
+}
+
+// NewAvroDeserializer returns a Deserializer reading values of T. With a
+// reader schema, data is resolved from each writer schema into it: missing
+// fields take their defaults and writer-only fields are dropped. With an
+// empty reader schema, data is read as written.
+func NewAvroDeserializer[T any](reg Registry, readerSchema string) (*Deserializer[T], error) {
+	f := &avroFormat[T]{}
+	if readerSchema != "" {
+		var err error
+		if f, err = newAvroFormat[T](readerSchema); err != nil {
+			return nil, err
+		}
+	}
+	return newDeserializer[T](reg, f)
+}
+
+type avroFormat[T any] struct {
+	text   string
+	parsed *avroSchema
+}
+
+func newAvroFormat[T any](text string) (*avroFormat[T], error) {
+	parsed, err := parseAvro(Schema{Type: Avro, Schema: text}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+	return &avroFormat[T]{text: text, parsed: parsed}, nil
+}
+
+func (f *avroFormat[T]) schema() Schema { return Schema{Type: Avro, Schema: f.text} }
+
+func (f *avroFormat[T]) recordName() string { return f.parsed.root.describe() }
+
+func (f *avroFormat[T]) dependencies() []dependency { return nil }
+
+func (f *avroFormat[T]) encode(v T) ([]byte, error) {
+	generic, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	return avroEncode(nil, f.parsed.root, generic, "")
+}
+
+func (f *avroFormat[T]) decoder(writer parsedSchema) (func([]byte) (T, error), error) {
+	w, ok := writer.(*avroSchema)
+	if !ok {
+		return nil, fmt.Errorf("writer schema is not avro")
+	}
+	reader := f.parsed
+	if reader == nil {
+		reader = w
+	} else if err := reader.canRead(w); err != nil {
+		return nil, fmt.Errorf("reader schema cannot read writer schema: %w", err)
+	}
+	return func(body []byte) (T, error) {
+		d := &avroDecoder{data: body}
+		generic, err := d.read(w.root, reader.root)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		if len(d.data) > 0 {
+			var zero T
+			return zero, fmt.Errorf("avro decode: %d trailing bytes", len(d.data))
+		}
+		return fromGeneric[T](generic)
+	}, nil
+}
+
+// toGeneric converts v to its generic JSON form, keeping numbers exact.
+func toGeneric(v any) (any, error) {
+	if m, ok := v.(map[string]any); ok {
+		return m, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// fromGeneric converts a decoded generic value to T through its JSON form.
+func fromGeneric[T any](generic any) (T, error) {
+	if v, ok := generic.(T); ok {
+		return v, nil
+	}
+	var out T
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return out, err
+	}
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
@@ -0,0 +1,600 @@
+package schemaregistry
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+)
+
+// Avro values are handled in their generic form: nil, bool, numbers,
+// string, []byte, []any for arrays, and map[string]any for records and maps.
+// Encoding accepts any Go number kind and json.Number, and base64 strings
+// for bytes and fixed, so values round-tripped through encoding/json encode
+// unchanged. Decoding produces int32, int64, float32, and float64 for the
+// numeric kinds.
+
+func avroEncode(dst []byte, t *avroType, v any, path string) ([]byte, error) {
+	switch t.kind {
+	case avroNull:
+		if v != nil {
+			return nil, avroValueError(path, t, v)
+		}
+		return dst, nil
+	case avroBoolean:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, avroValueError(path, t, v)
+		}
+		if b {
+			return append(dst, 1), nil
+		}
+		return append(dst, 0), nil
+	case avroInt, avroLong:
+		n, ok := avroInteger(v)
+		if !ok || (t.kind == avroInt && (n < math.MinInt32 || n > math.MaxInt32)) {
+			return nil, avroValueError(path, t, v)
+		}
+		return binary.AppendVarint(dst, n), nil
+	case avroFloat:
+		f, ok := avroNumber(v)
+		if !ok {
+			return nil, avroValueError(path, t, v)
+		}
+		return binary.LittleEndian.AppendUint32(dst, math.Float32bits(float32(f))), nil
+	case avroDouble:
+		f, ok := avroNumber(v)
+		if !ok {
+			return nil, avroValueError(path, t, v)
+		}
+		return binary.LittleEndian.AppendUint64(dst, math.Float64bits(f)), nil
+	case avroString:
+		s, ok := v.(string)
+		if !ok {
+			return nil, avroValueError(path, t, v)
+		}
+		dst = binary.AppendVarint(dst, int64(len(s)))
+		return append(dst, s...), nil
+	case avroBytes:
+		b, ok := avroBinary(v)
+		if !ok && v != nil {
+			return nil, avroValueError(path, t, v)
+		}
+		dst = binary.AppendVarint(dst, int64(len(b)))
+		return append(dst, b...), nil
+	case avroFixed:
+		b, ok := avroBinary(v)
+		if !ok || len(b) != t.size {
+			return nil, avroValueError(path, t, v)
+		}
+		return append(dst, b...), nil
+	case avroEnum:
+		s, _ := v.(string)
+		i := slices.Index(t.symbols, s)
+		if i < 0 {
+			return nil, avroValueError(path, t, v)
+		}
+		return binary.AppendVarint(dst, int64(i)), nil
+	case avroRecord:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, avroValueError(path, t, v)
+		}
+		var err error
+		for _, f := range t.fields {
+			fv, present := obj[f.name]
+			if !present {
+				if !f.hasDefault {
+					return nil, fmt.Errorf("avro encode %s: missing field %s", avroPath(path), f.name)
+				}
+				if fv, err = avroDefault(f.typ, f.def); err != nil {
+					return nil, fmt.Errorf("avro encode %s.%s default: %w", avroPath(path), f.name, err)
+				}
+			}
+			if dst, err = avroEncode(dst, f.typ, fv, path+"."+f.name); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case avroArray:
+		// nil is a Go nil slice, which JSON writes as null.
+		list, ok := v.([]any)
+		if !ok && v != nil {
+			return nil, avroValueError(path, t, v)
+		}
+		if len(list) > 0 {
+			dst = binary.AppendVarint(dst, int64(len(list)))
+			var err error
+			for _, item := range list {
+				if dst, err = avroEncode(dst, t.items, item, path+"[]"); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return binary.AppendVarint(dst, 0), nil
+	case avroMap:
+		obj, ok := v.(map[string]any)
+		if !ok && v != nil {
+			return nil, avroValueError(path, t, v)
+		}
+		if len(obj) > 0 {
+			dst = binary.AppendVarint(dst, int64(len(obj)))
+			keys := make([]string, 0, len(obj))
+			for k := range obj {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+			var err error
+			for _, k := range keys {
+				dst = binary.AppendVarint(dst, int64(len(k)))
+				dst = append(dst, k...)
+				if dst, err = avroEncode(dst, t.values, obj[k], path+"{}"); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return binary.AppendVarint(dst, 0), nil
+	case avroUnion:
+		i := avroBranch(t, v)
+		if i < 0 {
+			return nil, avroValueError(path, t, v)
+		}
+		dst = binary.AppendVarint(dst, int64(i))
+		return avroEncode(dst, t.branches[i], v, path)
+	default:
+		return nil, fmt.Errorf("avro encode %s: unsupported type %s", avroPath(path), t.kind)
+	}
+}
+
+// avroBranch picks the first union branch that v matches, preferring exact
+// matches over base64 strings for bytes.
+func avroBranch(t *avroType, v any) int {
+	for i, b := range t.branches {
+		if avroMatches(b, v, false) {
+			return i
+		}
+	}
+	for i, b := range t.branches {
+		if avroMatches(b, v, true) {
+			return i
+		}
+	}
+	return -1
+}
+
+func avroMatches(t *avroType, v any, loose bool) bool {
+	switch t.kind {
+	case avroNull:
+		return v == nil
+	case avroBoolean:
+		_, ok := v.(bool)
+		return ok
+	case avroInt:
+		n, ok := avroInteger(v)
+		return ok && n >= math.MinInt32 && n <= math.MaxInt32
+	case avroLong:
+		_, ok := avroInteger(v)
+		return ok
+	case avroFloat, avroDouble:
+		_, ok := avroNumber(v)
+		return ok
+	case avroString:
+		_, ok := v.(string)
+		return ok
+	case avroEnum:
+		s, ok := v.(string)
+		return ok && slices.Contains(t.symbols, s)
+	case avroBytes, avroFixed:
+		if _, ok := v.(string); ok && !loose {
+			return false
+		}
+		b, ok := avroBinary(v)
+		return ok && (t.kind == avroBytes || len(b) == t.size)
+	case avroRecord:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return false
+		}
+		for _, f := range t.fields {
+			if _, present := obj[f.name]; !present && !f.hasDefault {
+				return false
+			}
+		}
+		return true
+	case avroMap:
+		_, ok := v.(map[string]any)
+		return ok
+	case avroArray:
+		_, ok := v.([]any)
+		return ok
+	}
+	return false
+}
+
+func avroInteger(v any) (int64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case float64:
+		return int64(n), n == math.Trunc(n) && n >= math.MinInt64 && n < math.MaxInt64
+	case float32:
+		return int64(n), float64(n) == math.Trunc(float64(n))
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u := rv.Uint()
+		return int64(u), u <= math.MaxInt64
+	default:
+		return 0, false
+	}
+}
+
+func avroNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+	if i, ok := avroInteger(v); ok {
+		return float64(i), true
+	}
+	return 0, false
+}
+
+func avroBinary(v any) ([]byte, bool) {
+	switch b := v.(type) {
+	case []byte:
+		return b, true
+	case string:
+		decoded, err := base64.StdEncoding.DecodeString(b)
+		return decoded, err == nil
+	}
+	return nil, false
+}
+
+func avroValueError(path string, t *avroType, v any) error {
+	return fmt.Errorf("avro encode %s: %T is not a valid %s", avroPath(path), v, t.describe())
+}
+
+// avroDefault converts a field default from its JSON form to a generic value.
+// A union's default belongs to its first branch.
+func avroDefault(t *avroType, raw any) (any, error) {
+	switch t.kind {
+	case avroUnion:
+		return avroDefault(t.branches[0], raw)
+	case avroNull:
+		if raw != nil {
+			return nil, fmt.Errorf("default %v is not null", raw)
+		}
+		return nil, nil
+	case avroBoolean:
+		b, ok := raw.(bool)
+		if !ok {
+			return nil, fmt.Errorf("default %v is not a boolean", raw)
+		}
+		return b, nil
+	case avroInt:
+		n, err := jsonInt(raw)
+		return int32(n), err
+	case avroLong:
+		return jsonInt(raw)
+	case avroFloat, avroDouble:
+		n, ok := raw.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("default %v is not a number", raw)
+		}
+		f, err := n.Float64()
+		if t.kind == avroFloat {
+			return float32(f), err
+		}
+		return f, err
+	case avroString, avroEnum:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("default %v is not a string", raw)
+		}
+		return s, nil
+	case avroBytes, avroFixed:
+		// JSON defaults encode each byte as one code point.
+		s, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("default %v is not a string", raw)
+		}
+		b := make([]byte, 0, len(s))
+		for _, r := range s {
+			b = append(b, byte(r))
+		}
+		return b, nil
+	case avroArray:
+		list, ok := raw.([]any)
+		if !ok {
+			return nil, fmt.Errorf("default %v is not an array", raw)
+		}
+		out := make([]any, len(list))
+		for i, item := range list {
+			v, err := avroDefault(t.items, item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = v
+		}
+		return out, nil
+	case avroMap, avroRecord:
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("default %v is not an object", raw)
+		}
+		out := make(map[string]any, len(obj))
+		if t.kind == avroMap {
+			for k, item := range obj {
+				v, err := avroDefault(t.values, item)
+				if err != nil {
+					return nil, err
+				}
+				out[k] = v
+			}
+			return out, nil
+		}
+		for _, f := range t.fields {
+			item, present := obj[f.name]
+			if !present {
+				if !f.hasDefault {
+					return nil, fmt.Errorf("default lacks field %s", f.name)
+				}
+				item = f.def
+			}
+			v, err := avroDefault(f.typ, item)
+			if err != nil {
+				return nil, err
+			}
+			out[f.name] = v
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t.kind)
+}
+
+// avroDecoder reads Avro binary data written with one schema as another,
+// following schema resolution.
+type avroDecoder struct {
+	data []byte
+}
+
+func (d *avroDecoder) long() (int64, error) {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		return 0, fmt.Errorf("avro decode: malformed varint")
+	}
+	d.data = d.data[n:]
+	return v, nil
+}
+
+func (d *avroDecoder) bytes(n int64) ([]byte, error) {
+	if n < 0 || n > int64(len(d.data)) {
+		return nil, fmt.Errorf("avro decode: length %d exceeds the remaining %d bytes", n, len(d.data))
+	}
+	b := d.data[:n:n]
+	d.data = d.data[n:]
+	return b, nil
+}
+
+// maxAvroNullItems bounds blocks of nulls, which take no bytes to encode.
+const maxAvroNullItems = 1 << 20
+
+// blockCount reads an array or map block header. Negative counts are
+// followed by the block size in bytes. Counts the remaining data cannot hold
+// are rejected before any item is read.
+func (d *avroDecoder) blockCount(item *avroType) (int64, error) {
+	n, err := d.long()
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		if n == math.MinInt64 {
+			return 0, fmt.Errorf("avro decode: malformed block count")
+		}
+		n = -n
+		if _, err := d.long(); err != nil {
+			return 0, err
+		}
+	}
+	limit := int64(len(d.data))
+	if item.kind == avroNull {
+		limit = maxAvroNullItems
+	}
+	if n > limit {
+		return 0, fmt.Errorf("avro decode: block of %d items exceeds the payload", n)
+	}
+	return n, nil
+}
+
+func (d *avroDecoder) read(w, r *avroType) (any, error) {
+	if w.kind == avroUnion {
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(w.branches)) {
+			return nil, fmt.Errorf("avro decode: union index %d out of range", i)
+		}
+		return d.read(w.branches[i], r)
+	}
+	if r.kind == avroUnion {
+		for _, b := range r.branches {
+			if avroCanRead(b, w, "", map[[2]*avroType]bool{}) == nil {
+				return d.read(w, b)
+			}
+		}
+		return nil, fmt.Errorf("avro decode: reader union has no branch for %s", w.describe())
+	}
+
+	switch w.kind {
+	case avroNull:
+		return nil, nil
+	case avroBoolean:
+		b, err := d.bytes(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0] != 0, nil
+	case avroInt, avroLong:
+		n, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		switch r.kind {
+		case avroInt:
+			return int32(n), nil
+		case avroFloat:
+			return float32(n), nil
+		case avroDouble:
+			return float64(n), nil
+		}
+		return n, nil
+	case avroFloat:
+		b, err := d.bytes(4)
+		if err != nil {
+			return nil, err
+		}
+		f := math.Float32frombits(binary.LittleEndian.Uint32(b))
+		if r.kind == avroDouble {
+			return float64(f), nil
+		}
+		return f, nil
+	case avroDouble:
+		b, err := d.bytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case avroString, avroBytes:
+		n, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.bytes(n)
+		if err != nil {
+			return nil, err
+		}
+		if r.kind == avroString {
+			return string(b), nil
+		}
+		return slices.Clone(b), nil
+	case avroFixed:
+		b, err := d.bytes(int64(w.size))
+		if err != nil {
+			return nil, err
+		}
+		return slices.Clone(b), nil
+	case avroEnum:
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(w.symbols)) {
+			return nil, fmt.Errorf("avro decode: enum index %d out of range", i)
+		}
+		sym := w.symbols[i]
+		if !slices.Contains(r.symbols, sym) {
+			if r.enumDef == "" {
+				return nil, fmt.Errorf("avro decode: enum %s has no symbol %s", r.name, sym)
+			}
+			sym = r.enumDef
+		}
+		return sym, nil
+	case avroArray:
+		out := []any{}
+		for {
+			n, err := d.blockCount(w.items)
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				return out, nil
+			}
+			for range n {
+				item, err := d.read(w.items, r.items)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, item)
+			}
+		}
+	case avroMap:
+		out := map[string]any{}
+		for {
+			n, err := d.blockCount(w.values)
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				return out, nil
+			}
+			for range n {
+				size, err := d.long()
+				if err != nil {
+					return nil, err
+				}
+				key, err := d.bytes(size)
+				if err != nil {
+					return nil, err
+				}
+				if out[string(key)], err = d.read(w.values, r.values); err != nil {
+					return nil, err
+				}
+			}
+		}
+	case avroRecord:
+		return d.readRecord(w, r)
+	}
+	return nil, fmt.Errorf("avro decode: unsupported type %s", w.kind)
+}
+
+func (d *avroDecoder) readRecord(w, r *avroType) (any, error) {
+	out := make(map[string]any, len(r.fields))
+	for _, wf := range w.fields {
+		var rf *avroField
+		for _, f := range r.fields {
+			if f.name == wf.name || slices.Contains(f.aliases, wf.name) {
+				rf = f
+				break
+			}
+		}
+		if rf == nil {
+			if _, err := d.read(wf.typ, wf.typ); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		v, err := d.read(wf.typ, rf.typ)
+		if err != nil {
+			return nil, err
+		}
+		out[rf.name] = v
+	}
+	for _, rf := range r.fields {
+		if _, ok := out[rf.name]; ok {
+			continue
+		}
+		if !rf.hasDefault {
+			return nil, fmt.Errorf("avro decode: field %s.%s is missing and has no default", r.name, rf.name)
+		}
+		v, err := avroDefault(rf.typ, rf.def)
+		if err != nil {
+			return nil, fmt.Errorf("avro decode: field %s.%s default: %w", r.name, rf.name, err)
+		}
+		out[rf.name] = v
+	}
+	return out, nil
+}
@@ -0,0 +1,204 @@
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const userV1 = `{
+  "type": "record", "name": "User", "namespace": "com.example",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "name", "type": "string"},
+    {"name": "score", "type": "int"},
+    {"name": "tags", "type": {"type": "array", "items": "string"}},
+    {"name": "email", "type": ["null", "string"], "default": null},
+    {"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["ACTIVE", "BLOCKED"]}},
+    {"name": "attrs", "type": {"type": "map", "values": "double"}},
+    {"name": "avatar", "type": "bytes"}
+  ]
+}`
+
+// userV2 adds a defaulted field and promotes score from int to long.
+const userV2 = `{
+  "type": "record", "name": "User", "namespace": "com.example",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "name", "type": "string"},
+    {"name": "score", "type": "long"},
+    {"name": "tags", "type": {"type": "array", "items": "string"}},
+    {"name": "email", "type": ["null", "string"], "default": null},
+    {"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["ACTIVE", "BLOCKED"]}},
+    {"name": "attrs", "type": {"type": "map", "values": "double"}},
+    {"name": "avatar", "type": "bytes"},
+    {"name": "region", "type": "string", "default": "eu"}
+  ]
+}`
+
+type avroUser struct {
+	ID     int64              `json:"id"`
+	Name   string             `json:"name"`
+	Score  int64              `json:"score"`
+	Tags   []string           `json:"tags"`
+	Email  *string            `json:"email"`
+	Status string             `json:"status"`
+	Attrs  map[string]float64 `json:"attrs"`
+	Avatar []byte             `json:"avatar"`
+	Region string             `json:"region,omitempty"`
+}
+
+func TestAvroRoundTrip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	reg := NewLocalRegistry()
+
+	ser, err := NewAvroSerializer[avroUser](reg, userV1)
+	if err != nil {
+		t.Fatalf("NewAvroSerializer: %v", err)
+	}
+	de, err := NewAvroDeserializer[avroUser](reg, userV1)
+	if err != nil {
+		t.Fatalf("NewAvroDeserializer: %v", err)
+	}
+	email := "ada@example.com"
+	in := avroUser{
+		ID: 1 << 40, Name: "Ada", Score: -7, Tags: []string{"a", "b"}, Email: &email,
+		Status: "BLOCKED", Attrs: map[string]float64{"x": 1.5, "y": -2}, Avatar: []byte{0, 1, 2},
+	}
+	data, err := ser.Serialize(ctx, "users", in)
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if id, _, _ := ParseHeader(data); id != 1 {
+		t.Fatalf("schema id = %d, want 1", id)
+	}
+	out, err := de.Deserialize(ctx, data)
+	if err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Fatalf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestAvroResolvesWriterIntoReaderSchema(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	reg := NewLocalRegistry()
+
+	ser, err := NewAvroSerializer[avroUser](reg, userV1)
+	if err != nil {
+		t.Fatalf("NewAvroSerializer: %v", err)
+	}
+	data, err := ser.Serialize(ctx, "users", avroUser{ID: 7, Name: "Lin", Score: 3, Status: "ACTIVE"})
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+
+	de, err := NewAvroDeserializer[avroUser](reg, userV2)
+	if err != nil {
+		t.Fatalf("NewAvroDeserializer: %v", err)
+	}
+	out, err := de.Deserialize(ctx, data)
+	if err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	if out.Region != "eu" || out.Score != 3 || out.Name != "Lin" || out.Email != nil {
+		t.Fatalf("resolved = %+v", out)
+	}
+
+	generic, err := NewAvroDeserializer[map[string]any](reg, "")
+	if err != nil {
+		t.Fatalf("NewAvroDeserializer: %v", err)
+	}
+	m, err := generic.Deserialize(ctx, data)
+	if err != nil {
+		t.Fatalf("Deserialize generic: %v", err)
+	}
+	if m["name"] != "Lin" {
+		t.Fatalf("generic = %v", m)
+	}
+	if _, ok := m["region"]; ok {
+		t.Fatalf("writer-schema read has reader-only field: %v", m)
+	}
+}
+
+func TestAvroSerializeRejectsValuesOutsideSchema(t *testing.T) {
+	t.Parallel()
+
+	ser, err := NewAvroSerializer[map[string]any](NewLocalRegistry(), userV1)
+	if err != nil {
+		t.Fatalf("NewAvroSerializer: %v", err)
+	}
+	_, err = ser.Serialize(context.Background(), "users", map[string]any{"id": 1, "name": "x"})
+	if err == nil {
+		t.Fatal("expected error for missing required fields")
+	}
+}
+
+func TestAvroCompatibility(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		reader, writer string
+		wantErr        string
+	}{
+		{name: "added field with default", reader: userV2, writer: userV1},
+		{name: "removed field", reader: `{"type":"record","name":"R","fields":[{"name":"a","type":"int"}]}`,
+			writer: `{"type":"record","name":"R","fields":[{"name":"a","type":"int"},{"name":"b","type":"string"}]}`},
+		{name: "added field without default", reader: `{"type":"record","name":"R","fields":[{"name":"a","type":"int"}]}`,
+			writer: `{"type":"record","name":"R","fields":[]}`, wantErr: "a"},
+		{name: "narrowed type", reader: `{"type":"record","name":"R","fields":[{"name":"a","type":"int"}]}`,
+			writer: `{"type":"record","name":"R","fields":[{"name":"a","type":"long"}]}`, wantErr: "a"},
+		{name: "removed enum symbol", reader: `{"type":"enum","name":"E","symbols":["A"]}`,
+			writer: `{"type":"enum","name":"E","symbols":["A","B"]}`, wantErr: "B"},
+		{name: "removed enum symbol with default", reader: `{"type":"enum","name":"E","symbols":["A"],"default":"A"}`,
+			writer: `{"type":"enum","name":"E","symbols":["A","B"]}`},
+		{name: "widened to union", reader: `["null","string"]`, writer: `"string"`},
+		{name: "recursive record", reader: `{"type":"record","name":"Node","fields":[{"name":"next","type":["null","Node"]}]}`,
+			writer: `{"type":"record","name":"Node","fields":[{"name":"next","type":["null","Node"]}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := parseAvro(Schema{Type: Avro, Schema: tt.reader}, nil)
+			if err != nil {
+				t.Fatalf("parse reader: %v", err)
+			}
+			writer, err := parseAvro(Schema{Type: Avro, Schema: tt.writer}, nil)
+			if err != nil {
+				t.Fatalf("parse writer: %v", err)
+			}
+			err = reader.canRead(writer)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("canRead: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("canRead error = %v, want mention of %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAvroParseRejectsInvalidSchemas(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{
+		`{"type":"record","name":"R","fields":[{"name":"a","type":"Missing"}]}`,
+		`{"type":"enum","name":"E","symbols":["A","A"]}`,
+		`{"type":"fixed","name":"F"}`,
+		`["null","null"]`,
+		`not json`,
+	} {
+		_, err := parseSchema(Schema{Type: Avro, Schema: text}, nil)
+		if !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("parseSchema(%s) = %v, want ErrInvalidSchema", text, err)
+		}
+	}
+}
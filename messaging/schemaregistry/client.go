@@ -0,0 +1,342 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kbukum/gokit/security"
+)
+
+// Config configures a Client of a Confluent-compatible schema registry.
+type Config struct {
+	// URL is the registry endpoint. It must use https unless
+	// AllowInsecureDev is set, and must not include credentials, query, or
+	// fragment.
+	URL string `yaml:"url" mapstructure:"url"`
+	// Username and Password enable HTTP basic authentication.
+	Username string `yaml:"username" mapstructure:"username"`
+	Password string `yaml:"password" mapstructure:"password"`
+	// Timeout bounds each registry request. Default: "10s".
+	Timeout          string              `yaml:"timeout" mapstructure:"timeout"`
+	TLS              *security.TLSConfig `yaml:"tls" mapstructure:"tls"`
+	AllowInsecureDev bool                `yaml:"allow_insecure_dev" mapstructure:"allow_insecure_dev"`
+}
+
+// ApplyDefaults fills zero-valued fields.
+func (c *Config) ApplyDefaults() {
+	if c.Timeout == "" {
+		c.Timeout = "10s"
+	}
+}
+
+// Validate checks the client settings.
+func (c Config) Validate() error {
+	if strings.TrimSpace(c.URL) == "" {
+		return fmt.Errorf("schemaregistry: url is required")
+	}
+	parsed, err := url.Parse(c.URL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return fmt.Errorf("schemaregistry: invalid url %q", c.URL)
+	}
+	if parsed.User != nil || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("schemaregistry: url must not contain credentials, query parameters, or fragments")
+	}
+	if !c.AllowInsecureDev && parsed.Scheme != "https" {
+		return fmt.Errorf("schemaregistry: https url is required unless allow_insecure_dev is true")
+	}
+	if c.Password != "" && c.Username == "" {
+		return fmt.Errorf("schemaregistry: username is required when password is set")
+	}
+	d, err := time.ParseDuration(c.Timeout)
+	if err != nil {
+		return fmt.Errorf("schemaregistry: invalid timeout %q: %w", c.Timeout, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("schemaregistry: timeout must be > 0")
+	}
+	if err := c.TLS.Validate(); err != nil {
+		return fmt.Errorf("schemaregistry tls: %w", err)
+	}
+	return nil
+}
+
+// ClientOption configures a Client.
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the HTTP client used for registry requests, replacing
+// the one built from Config's timeout and TLS settings.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) {
+		if hc != nil {
+			c.http = hc
+		}
+	}
+}
+
+// Client is a Registry backed by a Confluent-compatible schema registry's
+// REST API. Schemas, IDs, and versions are immutable once registered, so it
+// caches them; latest versions and compatibility settings are always fetched.
+// It is safe for concurrent use.
+type Client struct {
+	baseURL  string
+	username string
+	password string
+	http     *http.Client
+
+	mu       sync.RWMutex
+	ids      map[string]int              // subject + schema key → ID
+	lookups  map[string]RegisteredSchema // subject + schema key → version
+	schemas  map[int]Schema
+	versions map[string]RegisteredSchema // subject + version → version
+}
+
+// NewClient returns a Client for the registry described by cfg.
+func NewClient(cfg Config, opts ...ClientOption) (*Client, error) {
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	timeout, _ := time.ParseDuration(cfg.Timeout)
+	c := &Client{
+		baseURL:  strings.TrimRight(cfg.URL, "/"),
+		username: cfg.Username,
+		password: cfg.Password,
+		http:     &http.Client{Timeout: timeout},
+		ids:      make(map[string]int),
+		lookups:  make(map[string]RegisteredSchema),
+		schemas:  make(map[int]Schema),
+		versions: make(map[string]RegisteredSchema),
+	}
+	if cfg.TLS != nil && cfg.TLS.IsEnabled() {
+		tlsCfg, err := cfg.TLS.Build()
+		if err != nil {
+			return nil, fmt.Errorf("schemaregistry tls: %w", err)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsCfg
+		c.http.Transport = transport
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Register registers s under subject, or returns its ID if it is already
+// registered there.
+func (c *Client) Register(ctx context.Context, subject string, s Schema) (int, error) {
+	if err := validateSubject(subject); err != nil {
+		return 0, err
+	}
+	s = withDefaultType(s)
+	cacheKey := subject + "\x00" + s.key()
+	c.mu.RLock()
+	id, ok := c.ids[cacheKey]
+	c.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	var out schemaPayload
+	if err := c.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", newSchemaPayload(s), &out); err != nil {
+		return 0, fmt.Errorf("register under %s: %w", subject, err)
+	}
+	c.mu.Lock()
+	c.ids[cacheKey] = out.ID
+	c.schemas[out.ID] = s
+	c.mu.Unlock()
+	return out.ID, nil
+}
+
+// Lookup returns the version of subject that holds s.
+func (c *Client) Lookup(ctx context.Context, subject string, s Schema) (RegisteredSchema, error) {
+	s = withDefaultType(s)
+	cacheKey := subject + "\x00" + s.key()
+	c.mu.RLock()
+	rs, ok := c.lookups[cacheKey]
+	c.mu.RUnlock()
+	if ok {
+		return rs, nil
+	}
+
+	var out schemaPayload
+	if err := c.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject), newSchemaPayload(s), &out); err != nil {
+		return RegisteredSchema{}, fmt.Errorf("look up under %s: %w", subject, err)
+	}
+	rs = RegisteredSchema{Schema: s, Subject: subject, ID: out.ID, Version: out.Version}
+	c.mu.Lock()
+	c.lookups[cacheKey] = rs
+	c.ids[cacheKey] = rs.ID
+	c.schemas[rs.ID] = s
+	c.mu.Unlock()
+	return rs, nil
+}
+
+// SchemaByID returns the schema with the given ID.
+func (c *Client) SchemaByID(ctx context.Context, id int) (Schema, error) {
+	c.mu.RLock()
+	s, ok := c.schemas[id]
+	c.mu.RUnlock()
+	if ok {
+		return s, nil
+	}
+
+	var out schemaPayload
+	if err := c.do(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id)+"?format=serialized", nil, &out); err != nil {
+		return Schema{}, fmt.Errorf("schema %d: %w", id, err)
+	}
+	s = out.schema()
+	c.mu.Lock()
+	c.schemas[id] = s
+	c.mu.Unlock()
+	return s, nil
+}
+
+// Latest returns the newest version of subject.
+func (c *Client) Latest(ctx context.Context, subject string) (RegisteredSchema, error) {
+	return c.fetchVersion(ctx, subject, "latest")
+}
+
+// Version returns a version of subject.
+func (c *Client) Version(ctx context.Context, subject string, version int) (RegisteredSchema, error) {
+	cacheKey := subject + "\x00" + strconv.Itoa(version)
+	c.mu.RLock()
+	rs, ok := c.versions[cacheKey]
+	c.mu.RUnlock()
+	if ok {
+		return rs, nil
+	}
+	rs, err := c.fetchVersion(ctx, subject, strconv.Itoa(version))
+	if err != nil {
+		return RegisteredSchema{}, err
+	}
+	c.mu.Lock()
+	c.versions[cacheKey] = rs
+	c.mu.Unlock()
+	return rs, nil
+}
+
+func (c *Client) fetchVersion(ctx context.Context, subject, version string) (RegisteredSchema, error) {
+	var out schemaPayload
+	path := "/subjects/" + url.PathEscape(subject) + "/versions/" + version + "?format=serialized"
+	if err := c.do(ctx, http.MethodGet, path, nil, &out); err != nil {
+		return RegisteredSchema{}, fmt.Errorf("version %s of %s: %w", version, subject, err)
+	}
+	return out.registered(), nil
+}
+
+// CheckCompatibility reports whether s could be registered under subject.
+// It returns an error wrapping ErrIncompatible when it could not.
+func (c *Client) CheckCompatibility(ctx context.Context, subject string, s Schema) error {
+	var out compatibilityPayload
+	path := "/compatibility/subjects/" + url.PathEscape(subject) + "/versions/latest?verbose=true"
+	err := c.do(ctx, http.MethodPost, path, newSchemaPayload(withDefaultType(s)), &out)
+	if errors.Is(err, ErrNotFound) {
+		// A subject without versions accepts any schema.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("check compatibility with %s: %w", subject, err)
+	}
+	if !out.IsCompatible {
+		return fmt.Errorf("%w with %s: %s", ErrIncompatible, subject, strings.Join(out.Messages, "; "))
+	}
+	return nil
+}
+
+// Compatibility returns the compatibility mode of subject, falling back to
+// the registry default.
+func (c *Client) Compatibility(ctx context.Context, subject string) (CompatibilityMode, error) {
+	path := "/config"
+	if subject != "" {
+		path += "/" + url.PathEscape(subject) + "?defaultToGlobal=true"
+	}
+	var out configPayload
+	if err := c.do(ctx, http.MethodGet, path, nil, &out); err != nil {
+		return "", fmt.Errorf("compatibility of %s: %w", subject, err)
+	}
+	return out.CompatibilityLevel, nil
+}
+
+// SetCompatibility sets the compatibility mode of subject, or the registry
+// default when subject is empty.
+func (c *Client) SetCompatibility(ctx context.Context, subject string, mode CompatibilityMode) error {
+	if err := mode.Validate(); err != nil {
+		return err
+	}
+	path := "/config"
+	if subject != "" {
+		path += "/" + url.PathEscape(subject)
+	}
+	if err := c.do(ctx, http.MethodPut, path, configPayload{Compatibility: mode}, nil); err != nil {
+		return fmt.Errorf("set compatibility of %s: %w", subject, err)
+	}
+	return nil
+}
+
+// do sends a registry request and decodes the response into out, mapping
+// registry errors to ErrNotFound, ErrIncompatible, and ErrInvalidSchema.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", contentType)
+	if in != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode/100 != 2 {
+		var e errorPayload
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &e) != nil || e.Message == "" {
+			e.Message = strings.TrimSpace(string(data))
+		}
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return fmt.Errorf("%w: %s", ErrNotFound, e.Message)
+		case http.StatusConflict:
+			return fmt.Errorf("%w: %s", ErrIncompatible, e.Message)
+		case http.StatusUnprocessableEntity:
+			if e.ErrorCode == codeInvalidSchema {
+				return fmt.Errorf("%w: %s", ErrInvalidSchema, e.Message)
+			}
+		}
+		return fmt.Errorf("schemaregistry: %s %s: status %d: %s", method, path, resp.StatusCode, e.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("schemaregistry: decode %s response: %w", path, err)
+	}
+	return nil
+}
+
+var _ Registry = (*Client)(nil)
@@ -0,0 +1,163 @@
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/apipb"
+)
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "https", cfg: Config{URL: "https://registry.example.test"}},
+		{name: "plaintext in dev", cfg: Config{URL: "http://localhost:8081", AllowInsecureDev: true}},
+		{name: "plaintext", cfg: Config{URL: "http://localhost:8081"}, wantErr: true},
+		{name: "missing url", cfg: Config{}, wantErr: true},
+		{name: "credentials in url", cfg: Config{URL: "https://u:p@registry.example.test"}, wantErr: true},
+		{name: "query in url", cfg: Config{URL: "https://registry.example.test?x=1"}, wantErr: true},
+		{name: "password without username", cfg: Config{URL: "https://registry.example.test", Password: "p"}, wantErr: true},
+		{name: "invalid timeout", cfg: Config{URL: "https://registry.example.test", Timeout: "soon"}, wantErr: true},
+		{name: "negative timeout", cfg: Config{URL: "https://registry.example.test", Timeout: "-1s"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.ApplyDefaults()
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// newTestClient serves local over HTTP and returns a Client of it with a
+// count of the requests it sent.
+func newTestClient(t *testing.T, local *LocalRegistry) (*Client, *atomic.Int64) {
+	t.Helper()
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if user, pass, ok := r.BasicAuth(); !ok || user != "svc" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		local.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClient(Config{URL: srv.URL, Username: "svc", Password: "secret", AllowInsecureDev: true})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client, &requests
+}
+
+func TestClientAgainstLocalRegistry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	local := NewLocalRegistry()
+	client, requests := newTestClient(t, local)
+
+	id, err := client.Register(ctx, "users-value", Schema{Schema: recordV1})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if again, err := client.Register(ctx, "users-value", Schema{Schema: recordV1}); err != nil || again != id {
+		t.Fatalf("cached Register = %d, %v", again, err)
+	}
+	if s, err := client.SchemaByID(ctx, id); err != nil || s.Type != Avro || s.Schema != recordV1 {
+		t.Fatalf("SchemaByID = %+v, %v", s, err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("requests = %d, want 1 with cached ID and schema", n)
+	}
+
+	if _, err := client.Register(ctx, "users-value", Schema{Schema: recordV3}); !errors.Is(err, ErrIncompatible) {
+		t.Fatalf("Register incompatible = %v, want ErrIncompatible", err)
+	}
+	if err := client.CheckCompatibility(ctx, "users-value", Schema{Schema: recordV3}); !errors.Is(err, ErrIncompatible) {
+		t.Fatalf("CheckCompatibility = %v, want ErrIncompatible", err)
+	}
+	if err := client.CheckCompatibility(ctx, "users-value", Schema{Schema: recordV2}); err != nil {
+		t.Fatalf("CheckCompatibility = %v", err)
+	}
+	if err := client.CheckCompatibility(ctx, "new-value", Schema{Schema: recordV1}); err != nil {
+		t.Fatalf("CheckCompatibility of an empty subject = %v", err)
+	}
+	if _, err := client.Register(ctx, "users-value", Schema{Schema: `{"type":"nope"}`}); !errors.Is(err, ErrInvalidSchema) {
+		t.Fatalf("Register invalid = %v, want ErrInvalidSchema", err)
+	}
+	if _, err := client.SchemaByID(ctx, 99); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("SchemaByID = %v, want ErrNotFound", err)
+	}
+	if _, err := client.Lookup(ctx, "users-value", Schema{Schema: recordV2}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Lookup = %v, want ErrNotFound", err)
+	}
+
+	if err := client.SetCompatibility(ctx, "users-value", CompatibilityForward); err != nil {
+		t.Fatalf("SetCompatibility: %v", err)
+	}
+	if mode, err := client.Compatibility(ctx, "users-value"); err != nil || mode != CompatibilityForward {
+		t.Fatalf("Compatibility = %s, %v", mode, err)
+	}
+	if err := client.SetCompatibility(ctx, "", CompatibilityNone); err != nil {
+		t.Fatalf("SetCompatibility global: %v", err)
+	}
+	if mode, _ := local.Compatibility(ctx, "other"); mode != CompatibilityNone {
+		t.Fatalf("global mode = %s", mode)
+	}
+	if _, err := client.Register(ctx, "users-value", Schema{Schema: recordV3}); err != nil {
+		t.Fatalf("Register forward-compatible: %v", err)
+	}
+	latest, err := client.Latest(ctx, "users-value")
+	if err != nil || latest.Version != 2 || latest.Subject != "users-value" {
+		t.Fatalf("Latest = %+v, %v", latest, err)
+	}
+	if v1, err := client.Version(ctx, "users-value", 1); err != nil || v1.ID != id {
+		t.Fatalf("Version = %+v, %v", v1, err)
+	}
+}
+
+func TestClientProtobufReferencesWithSlashedSubjects(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	local := NewLocalRegistry()
+	client, _ := newTestClient(t, local)
+
+	ser, err := NewProtobufSerializer[*apipb.Api](client)
+	if err != nil {
+		t.Fatalf("NewProtobufSerializer: %v", err)
+	}
+	in := &apipb.Api{Name: "svc", Version: "v1"}
+	data, err := ser.Serialize(ctx, "apis", in)
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if _, err := local.Latest(ctx, "google/protobuf/type.proto"); err != nil {
+		t.Fatalf("reference subject: %v", err)
+	}
+
+	// A fresh client resolves the writer schema and its references by
+	// subject and version, escaping the slashes in import paths.
+	reader, _ := newTestClient(t, local)
+	de, err := NewProtobufDeserializer[*apipb.Api](reader)
+	if err != nil {
+		t.Fatalf("NewProtobufDeserializer: %v", err)
+	}
+	out, err := de.Deserialize(ctx, data)
+	if err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	if !proto.Equal(in, out) {
+		t.Fatalf("round trip = %v, want %v", out, in)
+	}
+}
@@ -0,0 +1,64 @@
+package schemaregistry
+
+import (
+	"fmt"
+)
+
+// parsedSchema is a schema parsed for compatibility checks and decoding.
+type parsedSchema interface {
+	// canRead reports why data written with writer cannot be read with this
+	// schema, or nil when it can.
+	canRead(writer parsedSchema) error
+}
+
+// referenceResolver returns the schema a reference points at.
+type referenceResolver func(ref Reference) (Schema, error)
+
+// parseSchema parses s according to its type. Errors wrap ErrInvalidSchema.
+func parseSchema(s Schema, resolve referenceResolver) (parsedSchema, error) {
+	var (
+		parsed parsedSchema
+		err    error
+	)
+	switch s.Type {
+	case Avro:
+		parsed, err = parseAvro(s, resolve)
+	case JSONSchema:
+		parsed, err = parseJSONSchema(s)
+	case Protobuf:
+		parsed, err = parseProtobuf(s, resolve)
+	default:
+		err = s.Type.Validate()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+	return parsed, nil
+}
+
+// checkCompatibility checks candidate under mode against the earlier versions
+// of a subject, which previous lists oldest first; they are checked newest
+// first. Non-transitive modes only consider the latest version. Errors wrap
+// ErrIncompatible.
+func checkCompatibility(mode CompatibilityMode, candidate parsedSchema, previous []parsedSchema) error {
+	if mode == CompatibilityNone || len(previous) == 0 {
+		return nil
+	}
+	if !mode.transitive() {
+		previous = previous[len(previous)-1:]
+	}
+	for i := len(previous) - 1; i >= 0; i-- {
+		old := previous[i]
+		if mode.backward() {
+			if err := candidate.canRead(old); err != nil {
+				return fmt.Errorf("%w: %s: new schema cannot read data written with an earlier one: %w", ErrIncompatible, mode, err)
+			}
+		}
+		if mode.forward() {
+			if err := old.canRead(candidate); err != nil {
+				return fmt.Errorf("%w: %s: an earlier schema cannot read data written with the new one: %w", ErrIncompatible, mode, err)
+			}
+		}
+	}
+	return nil
+}
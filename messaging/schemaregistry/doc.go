@@ -0,0 +1,36 @@
+// Package schemaregistry provides schema-registry aware serializers for
+// messaging: Avro, Protobuf, and JSON Schema payloads in the Confluent wire
+// format, usable with any messaging provider.
+//
+// A Serializer registers (or looks up) its schema once per subject, caches
+// the ID, and prefixes each payload with a magic byte and the schema ID. A
+// Deserializer fetches the writer schema by ID once, then decodes with it:
+// Avro data is resolved against the reader schema, so fields added with
+// defaults or promoted numeric types decode into older and newer readers.
+//
+// # Architecture
+//
+//   - Registry: the subject/version/ID store the serializers talk to
+//   - Client: Registry backed by a Confluent-compatible registry's REST API
+//   - LocalRegistry: in-process Registry that also serves the REST API, for tests
+//   - Serializer/Deserializer: typed encoders built by NewAvroSerializer,
+//     NewProtobufSerializer, NewJSONSerializer and their Deserializer peers
+//
+// # Compatibility
+//
+// Registering a schema checks it against the subject's compatibility mode
+// (BACKWARD by default, as in the registry): backward modes require the new
+// schema to read data written with older ones, forward modes the reverse,
+// and transitive modes check every version instead of the latest.
+//
+// # Usage
+//
+//	reg, err := schemaregistry.NewClient(schemaregistry.Config{URL: "https://registry:8081"})
+//	ser, err := schemaregistry.NewJSONSerializer[Order](reg)
+//	err = ser.Publish(ctx, producer, "orders", order.ID, order)
+//
+//	de, err := schemaregistry.NewJSONDeserializer[Order](reg)
+//	consumer.Consume(ctx, de.Handler(func(ctx context.Context, o Order, msg messaging.Message) error {
+//	    return process(ctx, o)
+//	}))
+package schemaregistry
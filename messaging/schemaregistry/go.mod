@@ -0,0 +1,28 @@
+module github.com/kbukum/gokit/messaging/schemaregistry
+
+go 1.26.0
+
+toolchain go1.26.6
+
+require (
+	github.com/kbukum/gokit v0.2.0
+	github.com/kbukum/gokit/messaging v0.2.0
+	github.com/kbukum/gokit/schema v0.2.0
+	google.golang.org/protobuf v1.36.12-0.20260120151049-f2248ac996af
+)
+
+require (
+	github.com/bahlo/generic-list-go v0.2.0 // indirect
+	github.com/buger/jsonparser v1.1.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/invopop/jsonschema v0.14.0 // indirect
+	github.com/pb33f/ordered-map/v2 v2.3.1 // indirect
+	go.yaml.in/yaml/v4 v4.0.0-rc.6 // indirect
+)
+
+replace (
+	github.com/kbukum/gokit => ../../
+	github.com/kbukum/gokit/cache => ../../cache
+	github.com/kbukum/gokit/messaging => ../
+	github.com/kbukum/gokit/schema => ../../schema
+)
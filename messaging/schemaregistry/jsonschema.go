@@ -0,0 +1,229 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/kbukum/gokit/schema"
+)
+
+// jsonSchema is a parsed JSON Schema document.
+type jsonSchema struct {
+	doc      schema.JSON
+	compiled *schema.CompiledSchema
+}
+
+func parseJSONSchema(s Schema) (*jsonSchema, error) {
+	if len(s.References) > 0 {
+		return nil, fmt.Errorf("json schema references are not supported")
+	}
+	var doc schema.JSON
+	if err := json.Unmarshal([]byte(s.Schema), &doc); err != nil {
+		return nil, fmt.Errorf("json schema: %w", err)
+	}
+	compiled, err := schema.Compile(doc)
+	if err != nil {
+		return nil, fmt.Errorf("json schema: %w", err)
+	}
+	return &jsonSchema{doc: doc, compiled: compiled}, nil
+}
+
+// canRead reports whether every document valid under writer is valid under
+// s, as far as types, properties, required properties, closed content models,
+// enums, and array items tell.
+func (s *jsonSchema) canRead(writer parsedSchema) error {
+	w, ok := writer.(*jsonSchema)
+	if !ok {
+		return fmt.Errorf("cannot compare a json schema with %T", writer)
+	}
+	return jsonCanRead(s.doc, w.doc, "#")
+}
+
+func jsonCanRead(r, w map[string]any, path string) error {
+	rTypes, wTypes := jsonTypes(r), jsonTypes(w)
+	if rTypes != nil {
+		if wTypes == nil {
+			return fmt.Errorf("%s: type narrowed from any to %v", path, rTypes)
+		}
+		for _, t := range wTypes {
+			if !slices.Contains(rTypes, t) && (t != "integer" || !slices.Contains(rTypes, "number")) {
+				return fmt.Errorf("%s: type %s is no longer accepted", path, t)
+			}
+		}
+	}
+
+	if rEnum, ok := r["enum"].([]any); ok {
+		wEnum, ok := w["enum"].([]any)
+		if !ok {
+			return fmt.Errorf("%s: enum added", path)
+		}
+		for _, v := range wEnum {
+			if !slices.ContainsFunc(rEnum, func(e any) bool { return reflect.DeepEqual(e, v) }) {
+				return fmt.Errorf("%s: enum value %v removed", path, v)
+			}
+		}
+	}
+
+	if closed(r) && !closed(w) && jsonObject(w) {
+		return fmt.Errorf("%s: content model closed", path)
+	}
+	rProps, _ := r["properties"].(map[string]any)
+	wProps, _ := w["properties"].(map[string]any)
+	for name, wp := range wProps {
+		rp, ok := rProps[name]
+		if !ok {
+			if closed(r) {
+				return fmt.Errorf("%s/properties/%s: property removed from a closed content model", path, name)
+			}
+			continue
+		}
+		rObj, rok := rp.(map[string]any)
+		wObj, wok := wp.(map[string]any)
+		if rok && wok {
+			if err := jsonCanRead(rObj, wObj, path+"/properties/"+name); err != nil {
+				return err
+			}
+		}
+	}
+	wRequired := stringList(w["required"])
+	for _, name := range stringList(r["required"]) {
+		if !slices.Contains(wRequired, name) {
+			return fmt.Errorf("%s/properties/%s: property is newly required", path, name)
+		}
+	}
+
+	if rItems, ok := r["items"].(map[string]any); ok {
+		wItems, ok := w["items"].(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s/items: items narrowed from any", path)
+		}
+		if err := jsonCanRead(rItems, wItems, path+"/items"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonTypes returns the types a schema allows, or nil for any type.
+func jsonTypes(s map[string]any) []string {
+	switch t := s["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		return stringList(t)
+	}
+	return nil
+}
+
+// jsonObject reports whether a schema admits objects.
+func jsonObject(s map[string]any) bool {
+	types := jsonTypes(s)
+	return types == nil || slices.Contains(types, "object")
+}
+
+func closed(s map[string]any) bool {
+	additional, ok := s["additionalProperties"].(bool)
+	return ok && !additional
+}
+
+// NewJSONSerializer returns a Serializer writing values of T as JSON, under
+// the JSON Schema generated from T by the schema package or set with
+// WithJSONSchema. Values are validated against it before they are written
+// unless validation is disabled.
+func NewJSONSerializer[T any](reg Registry, opts ...Option) (*Serializer[T], error) {
+	o := newOptions(opts)
+	f, err := newJSONFormat[T](o)
+	if err != nil {
+		return nil, err
+	}
+	return newSerializer[T](reg, f, o)
+}
+
+// NewJSONDeserializer returns a Deserializer reading JSON values of T,
+// validated against the schema they were written with unless validation is
+// disabled.
+func NewJSONDeserializer[T any](reg Registry, opts ...Option) (*Deserializer[T], error) {
+	o := newOptions(opts)
+	return newDeserializer[T](reg, &jsonFormat[T]{validate: o.validate})
+}
+
+type jsonFormat[T any] struct {
+	text     string
+	name     string
+	compiled *schema.CompiledSchema
+	validate bool
+}
+
+func newJSONFormat[T any](o options) (*jsonFormat[T], error) {
+	doc := o.jsonSchema
+	if doc == nil {
+		doc = schema.Generate[T]()
+	}
+	text, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+	parsed, err := parseJSONSchema(Schema{Type: JSONSchema, Schema: string(text)})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+	name, _ := doc["title"].(string)
+	if name == "" {
+		name = reflect.TypeFor[T]().String()
+	}
+	return &jsonFormat[T]{text: string(text), name: name, compiled: parsed.compiled, validate: o.validate}, nil
+}
+
+func (f *jsonFormat[T]) schema() Schema { return Schema{Type: JSONSchema, Schema: f.text} }
+
+func (f *jsonFormat[T]) recordName() string { return f.name }
+
+func (f *jsonFormat[T]) dependencies() []dependency { return nil }
+
+func (f *jsonFormat[T]) encode(v T) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if f.validate {
+		if err := validateJSON(f.compiled, data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (f *jsonFormat[T]) decoder(writer parsedSchema) (func([]byte) (T, error), error) {
+	w, ok := writer.(*jsonSchema)
+	if !ok {
+		return nil, fmt.Errorf("writer schema is not a json schema")
+	}
+	return func(body []byte) (T, error) {
+		var out T
+		if f.validate {
+			if err := validateJSON(w.compiled, body); err != nil {
+				return out, err
+			}
+		}
+		err := json.Unmarshal(body, &out)
+		return out, err
+	}, nil
+}
+
+func validateJSON(compiled *schema.CompiledSchema, data []byte) error {
+	result, err := compiled.TryValidate(json.RawMessage(data))
+	if err != nil {
+		return err
+	}
+	if !result.Valid {
+		errs := make([]error, len(result.Errors))
+		for i, e := range result.Errors {
+			errs[i] = e
+		}
+		return fmt.Errorf("json schema validation: %w", errors.Join(errs...))
+	}
+	return nil
+}
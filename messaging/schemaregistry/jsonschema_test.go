@@ -0,0 +1,141 @@
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kbukum/gokit/schema"
+)
+
+type jsonOrder struct {
+	ID     string  `json:"id" jsonschema:"required"`
+	Amount float64 `json:"amount" jsonschema:"minimum=0"`
+}
+
+func TestJSONRoundTripAndValidation(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	reg := NewLocalRegistry()
+
+	ser, err := NewJSONSerializer[jsonOrder](reg)
+	if err != nil {
+		t.Fatalf("NewJSONSerializer: %v", err)
+	}
+	de, err := NewJSONDeserializer[jsonOrder](reg)
+	if err != nil {
+		t.Fatalf("NewJSONDeserializer: %v", err)
+	}
+	data, err := ser.Serialize(ctx, "orders", jsonOrder{ID: "o-1", Amount: 12.5})
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if body := string(data[headerSize:]); body != `{"id":"o-1","amount":12.5}` {
+		t.Fatalf("body = %s", body)
+	}
+	out, err := de.Deserialize(ctx, data)
+	if err != nil || out != (jsonOrder{ID: "o-1", Amount: 12.5}) {
+		t.Fatalf("Deserialize = %+v, %v", out, err)
+	}
+
+	if _, err := ser.Serialize(ctx, "orders", jsonOrder{ID: "o-2", Amount: -1}); err == nil {
+		t.Fatal("expected validation error for a negative amount")
+	}
+	id, _, _ := ParseHeader(data)
+	invalid := append(AppendHeader(nil, id), `{"amount":-1}`...)
+	if _, err := de.Deserialize(ctx, invalid); err == nil {
+		t.Fatal("expected validation error on deserialize")
+	}
+
+	lenient, err := NewJSONDeserializer[jsonOrder](reg, WithValidation(false))
+	if err != nil {
+		t.Fatalf("NewJSONDeserializer: %v", err)
+	}
+	if out, err := lenient.Deserialize(ctx, invalid); err != nil || out.Amount != -1 {
+		t.Fatalf("Deserialize without validation = %+v, %v", out, err)
+	}
+}
+
+func TestJSONSerializerWithExplicitSchema(t *testing.T) {
+	t.Parallel()
+
+	doc := schema.JSON{
+		"title":      "Order",
+		"type":       "object",
+		"properties": map[string]any{"id": map[string]any{"type": "string"}},
+		"required":   []any{"id"},
+	}
+	ser, err := NewJSONSerializer[map[string]any](NewLocalRegistry(), WithJSONSchema(doc), WithSubjectNameStrategy(RecordNameStrategy))
+	if err != nil {
+		t.Fatalf("NewJSONSerializer: %v", err)
+	}
+	if got := ser.Subject("orders"); got != "Order" {
+		t.Fatalf("Subject = %q, want Order", got)
+	}
+	if _, err := ser.Serialize(context.Background(), "orders", map[string]any{"id": 1}); err == nil {
+		t.Fatal("expected validation error for a numeric id")
+	}
+}
+
+func TestJSONCompatibility(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		reader, writer string
+		wantErr        string
+	}{
+		{name: "added optional property",
+			reader: `{"type":"object","properties":{"a":{"type":"string"},"b":{"type":"integer"}}}`,
+			writer: `{"type":"object","properties":{"a":{"type":"string"}}}`},
+		{name: "added required property",
+			reader: `{"type":"object","properties":{"a":{"type":"string"}},"required":["a"]}`,
+			writer: `{"type":"object","properties":{"a":{"type":"string"}}}`, wantErr: "newly required"},
+		{name: "widened integer to number",
+			reader: `{"type":"object","properties":{"a":{"type":"number"}}}`,
+			writer: `{"type":"object","properties":{"a":{"type":"integer"}}}`},
+		{name: "changed property type",
+			reader: `{"type":"object","properties":{"a":{"type":"string"}}}`,
+			writer: `{"type":"object","properties":{"a":{"type":"integer"}}}`, wantErr: "#/properties/a"},
+		{name: "removed property from closed model",
+			reader: `{"type":"object","properties":{},"additionalProperties":false}`,
+			writer: `{"type":"object","properties":{"a":{"type":"string"}},"additionalProperties":false}`, wantErr: "closed"},
+		{name: "removed enum value",
+			reader: `{"enum":["a"]}`, writer: `{"enum":["a","b"]}`, wantErr: "enum value b"},
+		{name: "narrowed items",
+			reader: `{"type":"array","items":{"type":"string"}}`,
+			writer: `{"type":"array","items":{"type":["string","null"]}}`, wantErr: "#/items"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := parseJSONSchema(Schema{Type: JSONSchema, Schema: tt.reader})
+			if err != nil {
+				t.Fatalf("parse reader: %v", err)
+			}
+			writer, err := parseJSONSchema(Schema{Type: JSONSchema, Schema: tt.writer})
+			if err != nil {
+				t.Fatalf("parse writer: %v", err)
+			}
+			err = reader.canRead(writer)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("canRead: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("canRead error = %v, want mention of %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJSONSchemaRejectsReferences(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseSchema(Schema{Type: JSONSchema, Schema: `{}`, References: []Reference{{Name: "x", Subject: "x", Version: 1}}}, nil)
+	if !errors.Is(err, ErrInvalidSchema) {
+		t.Fatalf("parseSchema = %v, want ErrInvalidSchema", err)
+	}
+}
@@ -0,0 +1,209 @@
+package schemaregistry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// LocalRegistry is an in-process Registry for tests and local development.
+// It parses schemas, enforces compatibility modes, shares IDs between
+// subjects holding the same schema like the registry does, and serves the
+// registry's REST API through ServeHTTP so a Client can talk to it.
+type LocalRegistry struct {
+	mu       sync.RWMutex
+	global   CompatibilityMode
+	modes    map[string]CompatibilityMode
+	subjects map[string][]*localVersion
+	byID     map[int]Schema
+	ids      map[string]int // schema key → ID
+	handler  http.Handler
+}
+
+type localVersion struct {
+	RegisteredSchema
+	parsed parsedSchema
+}
+
+// NewLocalRegistry returns an empty registry with DefaultCompatibility.
+func NewLocalRegistry() *LocalRegistry {
+	l := &LocalRegistry{
+		global:   DefaultCompatibility,
+		modes:    make(map[string]CompatibilityMode),
+		subjects: make(map[string][]*localVersion),
+		byID:     make(map[int]Schema),
+		ids:      make(map[string]int),
+	}
+	l.handler = l.routes()
+	return l
+}
+
+// Register adds s to subject unless it is already registered there.
+func (l *LocalRegistry) Register(_ context.Context, subject string, s Schema) (int, error) {
+	if err := validateSubject(subject); err != nil {
+		return 0, err
+	}
+	s = withDefaultType(s)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if v := l.findLocked(subject, s); v != nil {
+		return v.ID, nil
+	}
+	parsed, err := l.checkLocked(subject, s)
+	if err != nil {
+		return 0, err
+	}
+	key := s.key()
+	id, ok := l.ids[key]
+	if !ok {
+		id = len(l.ids) + 1
+		l.ids[key] = id
+		l.byID[id] = s
+	}
+	versions := l.subjects[subject]
+	l.subjects[subject] = append(versions, &localVersion{
+		RegisteredSchema: RegisteredSchema{Schema: s, Subject: subject, ID: id, Version: len(versions) + 1},
+		parsed:           parsed,
+	})
+	return id, nil
+}
+
+// Lookup returns the version of subject that holds s.
+func (l *LocalRegistry) Lookup(_ context.Context, subject string, s Schema) (RegisteredSchema, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if v := l.findLocked(subject, withDefaultType(s)); v != nil {
+		return v.RegisteredSchema, nil
+	}
+	return RegisteredSchema{}, fmt.Errorf("%w: schema under subject %s", ErrNotFound, subject)
+}
+
+// SchemaByID returns the schema with the given ID.
+func (l *LocalRegistry) SchemaByID(_ context.Context, id int) (Schema, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	s, ok := l.byID[id]
+	if !ok {
+		return Schema{}, fmt.Errorf("%w: schema %d", ErrNotFound, id)
+	}
+	return s, nil
+}
+
+// Latest returns the newest version of subject.
+func (l *LocalRegistry) Latest(_ context.Context, subject string) (RegisteredSchema, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	versions := l.subjects[subject]
+	if len(versions) == 0 {
+		return RegisteredSchema{}, fmt.Errorf("%w: subject %s", ErrNotFound, subject)
+	}
+	return versions[len(versions)-1].RegisteredSchema, nil
+}
+
+// Version returns a version of subject.
+func (l *LocalRegistry) Version(_ context.Context, subject string, version int) (RegisteredSchema, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	versions := l.subjects[subject]
+	if version < 1 || version > len(versions) {
+		return RegisteredSchema{}, fmt.Errorf("%w: version %d of subject %s", ErrNotFound, version, subject)
+	}
+	return versions[version-1].RegisteredSchema, nil
+}
+
+// CheckCompatibility reports whether s could be registered under subject.
+func (l *LocalRegistry) CheckCompatibility(_ context.Context, subject string, s Schema) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, err := l.checkLocked(subject, withDefaultType(s))
+	return err
+}
+
+// Compatibility returns the compatibility mode of subject.
+func (l *LocalRegistry) Compatibility(_ context.Context, subject string) (CompatibilityMode, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.modeLocked(subject), nil
+}
+
+// SetCompatibility sets the compatibility mode of subject, or the default
+// when subject is empty.
+func (l *LocalRegistry) SetCompatibility(_ context.Context, subject string, mode CompatibilityMode) error {
+	if err := mode.Validate(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if subject == "" {
+		l.global = mode
+	} else {
+		l.modes[subject] = mode
+	}
+	return nil
+}
+
+// ServeHTTP serves the subset of the registry REST API that Client uses.
+func (l *LocalRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.handler.ServeHTTP(w, r)
+}
+
+func (l *LocalRegistry) findLocked(subject string, s Schema) *localVersion {
+	key := s.key()
+	for _, v := range l.subjects[subject] {
+		if v.key() == key {
+			return v
+		}
+	}
+	return nil
+}
+
+func (l *LocalRegistry) modeLocked(subject string) CompatibilityMode {
+	if mode, ok := l.modes[subject]; ok {
+		return mode
+	}
+	return l.global
+}
+
+// checkLocked parses s and checks it against subject's versions.
+func (l *LocalRegistry) checkLocked(subject string, s Schema) (parsedSchema, error) {
+	parsed, err := parseSchema(s, l.resolveLocked)
+	if err != nil {
+		return nil, err
+	}
+	versions := l.subjects[subject]
+	previous := make([]parsedSchema, len(versions))
+	for i, v := range versions {
+		previous[i] = v.parsed
+	}
+	if err := checkCompatibility(l.modeLocked(subject), parsed, previous); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+func (l *LocalRegistry) resolveLocked(ref Reference) (Schema, error) {
+	versions := l.subjects[ref.Subject]
+	if ref.Version < 1 || ref.Version > len(versions) {
+		return Schema{}, fmt.Errorf("%w: version %d of subject %s", ErrNotFound, ref.Version, ref.Subject)
+	}
+	return versions[ref.Version-1].Schema, nil
+}
+
+// withDefaultType treats schemas without a type as Avro, as the registry does.
+func withDefaultType(s Schema) Schema {
+	if s.Type == "" {
+		s.Type = Avro
+	}
+	return s
+}
+
+func validateSubject(subject string) error {
+	if subject == "" {
+		return fmt.Errorf("schemaregistry: subject is required")
+	}
+	return nil
+}
+
+var _ Registry = (*LocalRegistry)(nil)
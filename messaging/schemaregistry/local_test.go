@@ -0,0 +1,127 @@
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+const (
+	recordV1 = `{"type":"record","name":"R","fields":[{"name":"a","type":"int"}]}`
+	// recordV2 adds a field with a default: backward and forward compatible.
+	recordV2 = `{"type":"record","name":"R","fields":[{"name":"a","type":"int"},{"name":"b","type":"string","default":""}]}`
+	// recordV3 adds a field without a default: forward compatible only.
+	recordV3 = `{"type":"record","name":"R","fields":[{"name":"a","type":"int"},{"name":"c","type":"string"}]}`
+	// recordV4 drops a, and recordV5 adds it back as a string: backward
+	// compatible with recordV4 but not with recordV1.
+	recordV4 = `{"type":"record","name":"R","fields":[]}`
+	recordV5 = `{"type":"record","name":"R","fields":[{"name":"a","type":"string","default":""}]}`
+)
+
+func TestLocalRegistryVersionsAndIDs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	reg := NewLocalRegistry()
+
+	id1, err := reg.Register(ctx, "a-value", Schema{Schema: recordV1})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	again, err := reg.Register(ctx, "a-value", Schema{Type: Avro, Schema: " " + recordV1})
+	if err != nil || again != id1 {
+		t.Fatalf("re-Register = %d, %v, want %d", again, err, id1)
+	}
+	id2, err := reg.Register(ctx, "a-value", Schema{Schema: recordV2})
+	if err != nil || id2 == id1 {
+		t.Fatalf("Register v2 = %d, %v", id2, err)
+	}
+	shared, err := reg.Register(ctx, "b-value", Schema{Schema: recordV1})
+	if err != nil || shared != id1 {
+		t.Fatalf("Register under another subject = %d, %v, want shared id %d", shared, err, id1)
+	}
+
+	latest, err := reg.Latest(ctx, "a-value")
+	if err != nil || latest.Version != 2 || latest.ID != id2 {
+		t.Fatalf("Latest = %+v, %v", latest, err)
+	}
+	v1, err := reg.Lookup(ctx, "a-value", Schema{Schema: recordV1})
+	if err != nil || v1.Version != 1 || v1.ID != id1 {
+		t.Fatalf("Lookup = %+v, %v", v1, err)
+	}
+	if _, err := reg.Version(ctx, "a-value", 3); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Version 3 = %v, want ErrNotFound", err)
+	}
+	if _, err := reg.SchemaByID(ctx, 99); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("SchemaByID = %v, want ErrNotFound", err)
+	}
+	if _, err := reg.Register(ctx, "c-value", Schema{Schema: `{"type":"nope"}`}); !errors.Is(err, ErrInvalidSchema) {
+		t.Fatalf("Register invalid = %v, want ErrInvalidSchema", err)
+	}
+}
+
+func TestLocalRegistryCompatibilityModes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		mode     CompatibilityMode
+		versions []string
+		wantErr  bool
+	}{
+		{mode: CompatibilityBackward, versions: []string{recordV1, recordV2}},
+		{mode: CompatibilityBackward, versions: []string{recordV1, recordV3}, wantErr: true},
+		{mode: CompatibilityForward, versions: []string{recordV1, recordV3}},
+		{mode: CompatibilityForward, versions: []string{recordV2, recordV1}},
+		{mode: CompatibilityFull, versions: []string{recordV1, recordV2}},
+		{mode: CompatibilityFull, versions: []string{recordV1, recordV3}, wantErr: true},
+		{mode: CompatibilityBackward, versions: []string{recordV1, recordV4, recordV5}},
+		{mode: CompatibilityBackwardTransitive, versions: []string{recordV1, recordV4, recordV5}, wantErr: true},
+		{mode: CompatibilityNone, versions: []string{recordV1, `"string"`}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			ctx := context.Background()
+			reg := NewLocalRegistry()
+			if err := reg.SetCompatibility(ctx, "s", tt.mode); err != nil {
+				t.Fatalf("SetCompatibility: %v", err)
+			}
+			var err error
+			for _, v := range tt.versions {
+				if _, err = reg.Register(ctx, "s", Schema{Schema: v}); err != nil {
+					break
+				}
+			}
+			if tt.wantErr != errors.Is(err, ErrIncompatible) {
+				t.Fatalf("Register = %v, want incompatible %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLocalRegistryCompatibilitySettings(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	reg := NewLocalRegistry()
+
+	if mode, _ := reg.Compatibility(ctx, "s"); mode != DefaultCompatibility {
+		t.Fatalf("default mode = %s", mode)
+	}
+	if err := reg.SetCompatibility(ctx, "", CompatibilityFull); err != nil {
+		t.Fatalf("SetCompatibility global: %v", err)
+	}
+	if mode, _ := reg.Compatibility(ctx, "s"); mode != CompatibilityFull {
+		t.Fatalf("mode after global change = %s", mode)
+	}
+	if err := reg.SetCompatibility(ctx, "s", "SIDEWAYS"); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+
+	if _, err := reg.Register(ctx, "s", Schema{Schema: recordV1}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := reg.CheckCompatibility(ctx, "s", Schema{Schema: recordV3}); !errors.Is(err, ErrIncompatible) {
+		t.Fatalf("CheckCompatibility = %v, want ErrIncompatible", err)
+	}
+	if latest, _ := reg.Latest(ctx, "s"); latest.Version != 1 {
+		t.Fatalf("CheckCompatibility registered a version: %+v", latest)
+	}
+}
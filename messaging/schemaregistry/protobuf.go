@@ -0,0 +1,312 @@
+package schemaregistry
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// protobufSchema is a parsed Protobuf file with its imports resolved.
+type protobufSchema struct {
+	file protoreflect.FileDescriptor
+}
+
+// parseProtobuf decodes a serialized file descriptor and its references.
+// Imports that are not referenced, such as the well-known types, resolve from
+// the files linked into the binary.
+func parseProtobuf(s Schema, resolve referenceResolver) (*protobufSchema, error) {
+	main, err := decodeFileDescriptor(s.Schema)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]*descriptorpb.FileDescriptorProto{main.GetName(): main}
+	if err := collectProtobufReferences(s.References, resolve, files); err != nil {
+		return nil, err
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range files {
+		set.File = append(set.File, fd)
+	}
+	for i := 0; i < len(set.File); i++ {
+		for _, dep := range set.File[i].GetDependency() {
+			if _, ok := files[dep]; ok {
+				continue
+			}
+			linked, err := protoregistry.GlobalFiles.FindFileByPath(dep)
+			if err != nil {
+				return nil, fmt.Errorf("protobuf import %s is neither referenced nor linked: %w", dep, err)
+			}
+			files[dep] = protodesc.ToFileDescriptorProto(linked)
+			set.File = append(set.File, files[dep])
+		}
+	}
+	registry, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("protobuf schema: %w", err)
+	}
+	file, err := registry.FindFileByPath(main.GetName())
+	if err != nil {
+		return nil, fmt.Errorf("protobuf schema: %w", err)
+	}
+	return &protobufSchema{file: file}, nil
+}
+
+func collectProtobufReferences(refs []Reference, resolve referenceResolver, files map[string]*descriptorpb.FileDescriptorProto) error {
+	for _, ref := range refs {
+		if _, ok := files[ref.Name]; ok {
+			continue
+		}
+		if resolve == nil {
+			return fmt.Errorf("protobuf reference %s: no resolver", ref.Name)
+		}
+		s, err := resolve(ref)
+		if err != nil {
+			return fmt.Errorf("protobuf reference %s: %w", ref.Name, err)
+		}
+		fd, err := decodeFileDescriptor(s.Schema)
+		if err != nil {
+			return fmt.Errorf("protobuf reference %s: %w", ref.Name, err)
+		}
+		if fd.GetName() != ref.Name {
+			return fmt.Errorf("protobuf reference %s: schema describes %s", ref.Name, fd.GetName())
+		}
+		files[ref.Name] = fd
+		if err := collectProtobufReferences(s.References, resolve, files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeFileDescriptor(text string) (*descriptorpb.FileDescriptorProto, error) {
+	raw, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		return nil, fmt.Errorf("protobuf schema is not a base64 file descriptor: %w", err)
+	}
+	fd := &descriptorpb.FileDescriptorProto{}
+	if err := proto.Unmarshal(raw, fd); err != nil {
+		return nil, fmt.Errorf("protobuf schema: %w", err)
+	}
+	if fd.GetName() == "" {
+		return nil, fmt.Errorf("protobuf schema: file descriptor has no name")
+	}
+	return fd, nil
+}
+
+// encodeFileDescriptor returns the registry schema text of file.
+func encodeFileDescriptor(file protoreflect.FileDescriptor) (string, error) {
+	raw, err := proto.MarshalOptions{Deterministic: true}.Marshal(protodesc.ToFileDescriptorProto(file))
+	if err != nil {
+		return "", fmt.Errorf("protobuf schema %s: %w", file.Path(), err)
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// canRead reports whether messages written with writer's file decode with
+// s: every writer message must still exist, and fields sharing a number
+// must stay wire compatible.
+func (s *protobufSchema) canRead(writer parsedSchema) error {
+	w, ok := writer.(*protobufSchema)
+	if !ok {
+		return fmt.Errorf("cannot compare a protobuf schema with %T", writer)
+	}
+	seen := map[[2]protoreflect.FullName]bool{}
+	msgs := w.file.Messages()
+	for i := range msgs.Len() {
+		wm := msgs.Get(i)
+		rm := s.file.Messages().ByName(wm.Name())
+		if rm == nil {
+			return fmt.Errorf("message %s removed", wm.FullName())
+		}
+		if err := protobufMessageCanRead(rm, wm, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func protobufMessageCanRead(r, w protoreflect.MessageDescriptor, seen map[[2]protoreflect.FullName]bool) error {
+	pair := [2]protoreflect.FullName{r.FullName(), w.FullName()}
+	if seen[pair] {
+		return nil
+	}
+	seen[pair] = true
+	fields := w.Fields()
+	for i := range fields.Len() {
+		wf := fields.Get(i)
+		rf := r.Fields().ByNumber(wf.Number())
+		if rf == nil {
+			// Unknown fields are skipped by readers.
+			continue
+		}
+		if wf.IsList() != rf.IsList() || wf.IsMap() != rf.IsMap() {
+			return fmt.Errorf("field %s changed cardinality", rf.FullName())
+		}
+		if protobufKindGroup(wf.Kind()) != protobufKindGroup(rf.Kind()) {
+			return fmt.Errorf("field %s changed type from %s to %s", rf.FullName(), wf.Kind(), rf.Kind())
+		}
+		if rf.Message() != nil && wf.Message() != nil {
+			if err := protobufMessageCanRead(rf.Message(), wf.Message(), seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// protobufKindGroup groups kinds that share a wire encoding and read each
+// other's values.
+func protobufKindGroup(k protoreflect.Kind) int {
+	switch k {
+	case protoreflect.Int32Kind, protoreflect.Uint32Kind, protoreflect.Int64Kind, protoreflect.Uint64Kind,
+		protoreflect.BoolKind, protoreflect.EnumKind:
+		return 1
+	case protoreflect.Sint32Kind, protoreflect.Sint64Kind:
+		return 2
+	case protoreflect.Fixed32Kind, protoreflect.Sfixed32Kind:
+		return 3
+	case protoreflect.Fixed64Kind, protoreflect.Sfixed64Kind:
+		return 4
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return 5
+	case protoreflect.MessageKind:
+		return 6
+	case protoreflect.GroupKind:
+		return 7
+	case protoreflect.FloatKind:
+		return 8
+	case protoreflect.DoubleKind:
+		return 9
+	}
+	return 0
+}
+
+// messageIndexes returns the path of md within its file.
+func messageIndexes(md protoreflect.MessageDescriptor) []int {
+	var path []int
+	for d := protoreflect.Descriptor(md); ; {
+		path = append([]int{d.Index()}, path...)
+		parent, ok := d.Parent().(protoreflect.MessageDescriptor)
+		if !ok {
+			return path
+		}
+		d = parent
+	}
+}
+
+// messageAt resolves message indexes within file.
+func messageAt(file protoreflect.FileDescriptor, indexes []int) (protoreflect.MessageDescriptor, error) {
+	msgs := file.Messages()
+	var md protoreflect.MessageDescriptor
+	for _, i := range indexes {
+		if i >= msgs.Len() {
+			return nil, fmt.Errorf("schemaregistry: message index %v not in %s", indexes, file.Path())
+		}
+		md = msgs.Get(i)
+		msgs = md.Messages()
+	}
+	return md, nil
+}
+
+// NewProtobufSerializer returns a Serializer writing messages of T, a
+// generated message type. The schema is T's file; the files it imports are
+// registered under their import paths and referenced.
+func NewProtobufSerializer[T proto.Message](reg Registry, opts ...Option) (*Serializer[T], error) {
+	f, err := newProtobufFormat[T]()
+	if err != nil {
+		return nil, err
+	}
+	return newSerializer[T](reg, f, newOptions(opts))
+}
+
+// NewProtobufDeserializer returns a Deserializer reading messages of T, a
+// generated message type. Payloads naming another message type are rejected.
+func NewProtobufDeserializer[T proto.Message](reg Registry) (*Deserializer[T], error) {
+	f, err := newProtobufFormat[T]()
+	if err != nil {
+		return nil, err
+	}
+	return newDeserializer[T](reg, f)
+}
+
+type protobufFormat[T proto.Message] struct {
+	msgType protoreflect.MessageType
+	text    string
+	indexes []int
+	deps    []dependency
+}
+
+func newProtobufFormat[T proto.Message]() (*protobufFormat[T], error) {
+	var zero T
+	msgType := zero.ProtoReflect().Type()
+	md := msgType.Descriptor()
+	text, err := encodeFileDescriptor(md.ParentFile())
+	if err != nil {
+		return nil, err
+	}
+	deps, err := protobufDependencies(md.ParentFile())
+	if err != nil {
+		return nil, err
+	}
+	return &protobufFormat[T]{msgType: msgType, text: text, indexes: messageIndexes(md), deps: deps}, nil
+}
+
+func protobufDependencies(file protoreflect.FileDescriptor) ([]dependency, error) {
+	imports := file.Imports()
+	deps := make([]dependency, 0, imports.Len())
+	for i := range imports.Len() {
+		imp := imports.Get(i).FileDescriptor
+		text, err := encodeFileDescriptor(imp)
+		if err != nil {
+			return nil, err
+		}
+		nested, err := protobufDependencies(imp)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, dependency{name: imp.Path(), schema: Schema{Type: Protobuf, Schema: text}, deps: nested})
+	}
+	return deps, nil
+}
+
+func (f *protobufFormat[T]) schema() Schema { return Schema{Type: Protobuf, Schema: f.text} }
+
+func (f *protobufFormat[T]) recordName() string { return string(f.msgType.Descriptor().FullName()) }
+
+func (f *protobufFormat[T]) dependencies() []dependency { return f.deps }
+
+func (f *protobufFormat[T]) encode(v T) ([]byte, error) {
+	return proto.MarshalOptions{}.MarshalAppend(appendMessageIndexes(nil, f.indexes), v)
+}
+
+func (f *protobufFormat[T]) decoder(writer parsedSchema) (func([]byte) (T, error), error) {
+	w, ok := writer.(*protobufSchema)
+	if !ok {
+		return nil, fmt.Errorf("writer schema is not protobuf")
+	}
+	want := f.msgType.Descriptor().FullName()
+	return func(body []byte) (T, error) {
+		var zero T
+		indexes, body, err := readMessageIndexes(body)
+		if err != nil {
+			return zero, err
+		}
+		md, err := messageAt(w.file, indexes)
+		if err != nil {
+			return zero, err
+		}
+		if md.FullName() != want {
+			return zero, fmt.Errorf("payload is a %s, not a %s", md.FullName(), want)
+		}
+		msg := f.msgType.New().Interface()
+		if err := proto.Unmarshal(body, msg); err != nil {
+			return zero, err
+		}
+		return msg.(T), nil
+	}, nil
+}
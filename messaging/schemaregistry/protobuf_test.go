@@ -0,0 +1,152 @@
+package schemaregistry
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+func TestProtobufRoundTrip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	reg := NewLocalRegistry()
+
+	ser, err := NewProtobufSerializer[*timestamppb.Timestamp](reg)
+	if err != nil {
+		t.Fatalf("NewProtobufSerializer: %v", err)
+	}
+	de, err := NewProtobufDeserializer[*timestamppb.Timestamp](reg)
+	if err != nil {
+		t.Fatalf("NewProtobufDeserializer: %v", err)
+	}
+	in := &timestamppb.Timestamp{Seconds: 1700000000, Nanos: 42}
+	data, err := ser.Serialize(ctx, "ticks", in)
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if data[headerSize] != 0 {
+		t.Fatalf("message indexes = %d, want 0 for the first message", data[headerSize])
+	}
+	out, err := de.Deserialize(ctx, data)
+	if err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	if !proto.Equal(in, out) {
+		t.Fatalf("round trip = %v, want %v", out, in)
+	}
+}
+
+func TestProtobufRegistersImportsAsReferences(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	reg := NewLocalRegistry()
+
+	// google/protobuf/api.proto imports source_context.proto and type.proto.
+	ser, err := NewProtobufSerializer[*apipb.Api](reg)
+	if err != nil {
+		t.Fatalf("NewProtobufSerializer: %v", err)
+	}
+	in := &apipb.Api{
+		Name:          "svc",
+		Methods:       []*apipb.Method{{Name: "Get", RequestTypeUrl: "req"}},
+		SourceContext: &sourcecontextpb.SourceContext{FileName: "svc.proto"},
+		Syntax:        typepb.Syntax_SYNTAX_PROTO3,
+	}
+	data, err := ser.Serialize(ctx, "apis", in)
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	latest, err := reg.Latest(ctx, "apis-value")
+	if err != nil {
+		t.Fatalf("Latest: %v", err)
+	}
+	names := make([]string, len(latest.References))
+	for i, ref := range latest.References {
+		names[i] = ref.Subject
+	}
+	if got := strings.Join(names, ","); got != "google/protobuf/source_context.proto,google/protobuf/type.proto" {
+		t.Fatalf("references = %s", got)
+	}
+
+	de, err := NewProtobufDeserializer[*apipb.Api](reg)
+	if err != nil {
+		t.Fatalf("NewProtobufDeserializer: %v", err)
+	}
+	out, err := de.Deserialize(ctx, data)
+	if err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	if !proto.Equal(in, out) {
+		t.Fatalf("round trip = %v, want %v", out, in)
+	}
+
+	// Method is the second message of api.proto.
+	methods, err := NewProtobufSerializer[*apipb.Method](reg)
+	if err != nil {
+		t.Fatalf("NewProtobufSerializer: %v", err)
+	}
+	data, err = methods.Serialize(ctx, "apis", &apipb.Method{Name: "List"})
+	if err != nil {
+		t.Fatalf("Serialize method: %v", err)
+	}
+	if _, err := de.Deserialize(ctx, data); err == nil || !strings.Contains(err.Error(), "google.protobuf.Method") {
+		t.Fatalf("Deserialize of another message type = %v", err)
+	}
+}
+
+func TestProtobufCompatibility(t *testing.T) {
+	t.Parallel()
+
+	file := func(fields ...*descriptorpb.FieldDescriptorProto) Schema {
+		fd := &descriptorpb.FileDescriptorProto{
+			Name:        proto.String("order.proto"),
+			Package:     proto.String("shop"),
+			Syntax:      proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("Order"), Field: fields}},
+		}
+		desc, err := protodesc.NewFile(fd, nil)
+		if err != nil {
+			t.Fatalf("NewFile: %v", err)
+		}
+		text, err := encodeFileDescriptor(desc)
+		if err != nil {
+			t.Fatalf("encodeFileDescriptor: %v", err)
+		}
+		return Schema{Type: Protobuf, Schema: text}
+	}
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name: proto.String(name), JsonName: proto.String(name), Number: proto.Int32(number), Type: typ.Enum(),
+			Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+	}
+	parse := func(s Schema) parsedSchema {
+		p, err := parseSchema(s, nil)
+		if err != nil {
+			t.Fatalf("parseSchema: %v", err)
+		}
+		return p
+	}
+
+	v1 := parse(file(field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING)))
+	v2 := parse(file(field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING), field("qty", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64)))
+	changed := parse(file(field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE)))
+
+	if err := v2.canRead(v1); err != nil {
+		t.Fatalf("added field: %v", err)
+	}
+	if err := v1.canRead(v2); err != nil {
+		t.Fatalf("removed field: %v", err)
+	}
+	if err := changed.canRead(v1); err == nil || !strings.Contains(err.Error(), "shop.Order.id") {
+		t.Fatalf("changed field type = %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned when a subject, version, or schema does not exist.
+	ErrNotFound = errors.New("schemaregistry: not found")
+	// ErrIncompatible is returned when a schema breaks its subject's
+	// compatibility mode.
+	ErrIncompatible = errors.New("schemaregistry: incompatible schema")
+	// ErrInvalidSchema is returned when a schema cannot be parsed.
+	ErrInvalidSchema = errors.New("schemaregistry: invalid schema")
+)
+
+// Registry stores schemas under subjects and assigns them IDs. Client talks
+// to a Confluent-compatible schema registry; LocalRegistry is an in-process
+// stand-in for tests and local development.
+type Registry interface {
+	// Register adds s to subject unless it is already registered there and
+	// returns its ID. It fails with ErrIncompatible when s breaks the
+	// subject's compatibility mode.
+	Register(ctx context.Context, subject string, s Schema) (int, error)
+	// Lookup returns the version of subject that holds s.
+	Lookup(ctx context.Context, subject string, s Schema) (RegisteredSchema, error)
+	// SchemaByID returns the schema with the given ID.
+	SchemaByID(ctx context.Context, id int) (Schema, error)
+	// Latest returns the newest version of subject.
+	Latest(ctx context.Context, subject string) (RegisteredSchema, error)
+	// Version returns a version of subject.
+	Version(ctx context.Context, subject string, version int) (RegisteredSchema, error)
+	// CheckCompatibility reports whether s could be registered under subject,
+	// returning an error wrapping ErrIncompatible when it could not.
+	CheckCompatibility(ctx context.Context, subject string, s Schema) error
+	// Compatibility returns the compatibility mode of subject, falling back
+	// to the registry default.
+	Compatibility(ctx context.Context, subject string) (CompatibilityMode, error)
+	// SetCompatibility sets the compatibility mode of subject, or the
+	// registry default when subject is empty.
+	SetCompatibility(ctx context.Context, subject string, mode CompatibilityMode) error
+}
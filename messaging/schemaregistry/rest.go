@@ -0,0 +1,191 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// contentType is the media type of registry REST requests and responses.
+const contentType = "application/vnd.schemaregistry.v1+json"
+
+// schemaPayload is a schema in registry REST requests and responses.
+type schemaPayload struct {
+	Subject    string      `json:"subject,omitempty"`
+	ID         int         `json:"id,omitempty"`
+	Version    int         `json:"version,omitempty"`
+	SchemaType string      `json:"schemaType,omitempty"`
+	Schema     string      `json:"schema,omitempty"`
+	References []Reference `json:"references,omitempty"`
+}
+
+func newSchemaPayload(s Schema) schemaPayload {
+	p := schemaPayload{Schema: s.Schema, References: s.References}
+	if s.Type != Avro {
+		p.SchemaType = string(s.Type)
+	}
+	return p
+}
+
+func (p schemaPayload) schema() Schema {
+	return withDefaultType(Schema{Type: SchemaType(p.SchemaType), Schema: p.Schema, References: p.References})
+}
+
+func (p schemaPayload) registered() RegisteredSchema {
+	return RegisteredSchema{Schema: p.schema(), Subject: p.Subject, ID: p.ID, Version: p.Version}
+}
+
+type configPayload struct {
+	Compatibility      CompatibilityMode `json:"compatibility,omitempty"`
+	CompatibilityLevel CompatibilityMode `json:"compatibilityLevel,omitempty"`
+}
+
+type compatibilityPayload struct {
+	IsCompatible bool     `json:"is_compatible"`
+	Messages     []string `json:"messages,omitempty"`
+}
+
+type errorPayload struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// Registry error codes.
+const (
+	codeSubjectNotFound      = 40401
+	codeSchemaNotFound       = 40403
+	codeIncompatible         = 409
+	codeInvalidSchema        = 42201
+	codeInvalidCompatibility = 42203
+	codeInternal             = 50001
+)
+
+func (l *LocalRegistry) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /subjects/{subject}/versions", func(w http.ResponseWriter, r *http.Request) {
+		var p schemaPayload
+		if !decodeRequest(w, r, &p) {
+			return
+		}
+		id, err := l.Register(r.Context(), r.PathValue("subject"), p.schema())
+		if err != nil {
+			writeError(w, err, codeSubjectNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, schemaPayload{ID: id})
+	})
+	mux.HandleFunc("POST /subjects/{subject}", func(w http.ResponseWriter, r *http.Request) {
+		var p schemaPayload
+		if !decodeRequest(w, r, &p) {
+			return
+		}
+		rs, err := l.Lookup(r.Context(), r.PathValue("subject"), p.schema())
+		if err != nil {
+			writeError(w, err, codeSchemaNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, registeredPayload(rs))
+	})
+	mux.HandleFunc("GET /schemas/ids/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(w, ErrNotFound, codeSchemaNotFound)
+			return
+		}
+		s, err := l.SchemaByID(r.Context(), id)
+		if err != nil {
+			writeError(w, err, codeSchemaNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, newSchemaPayload(s))
+	})
+	mux.HandleFunc("GET /subjects/{subject}/versions/{version}", func(w http.ResponseWriter, r *http.Request) {
+		subject, version := r.PathValue("subject"), r.PathValue("version")
+		var (
+			rs  RegisteredSchema
+			err error
+		)
+		if version == "latest" {
+			rs, err = l.Latest(r.Context(), subject)
+		} else if n, convErr := strconv.Atoi(version); convErr != nil {
+			err = ErrNotFound
+		} else {
+			rs, err = l.Version(r.Context(), subject, n)
+		}
+		if err != nil {
+			writeError(w, err, codeSubjectNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, registeredPayload(rs))
+	})
+	mux.HandleFunc("POST /compatibility/subjects/{subject}/versions/{version}", func(w http.ResponseWriter, r *http.Request) {
+		var p schemaPayload
+		if !decodeRequest(w, r, &p) {
+			return
+		}
+		err := l.CheckCompatibility(r.Context(), r.PathValue("subject"), p.schema())
+		switch {
+		case err == nil:
+			writeJSON(w, http.StatusOK, compatibilityPayload{IsCompatible: true})
+		case errors.Is(err, ErrIncompatible):
+			writeJSON(w, http.StatusOK, compatibilityPayload{Messages: []string{err.Error()}})
+		default:
+			writeError(w, err, codeSubjectNotFound)
+		}
+	})
+	getConfig := func(w http.ResponseWriter, r *http.Request) {
+		mode, _ := l.Compatibility(r.Context(), r.PathValue("subject"))
+		writeJSON(w, http.StatusOK, configPayload{CompatibilityLevel: mode})
+	}
+	putConfig := func(w http.ResponseWriter, r *http.Request) {
+		var p configPayload
+		if !decodeRequest(w, r, &p) {
+			return
+		}
+		if err := l.SetCompatibility(r.Context(), r.PathValue("subject"), p.Compatibility); err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, errorPayload{ErrorCode: codeInvalidCompatibility, Message: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, configPayload{Compatibility: p.Compatibility})
+	}
+	mux.HandleFunc("GET /config", getConfig)
+	mux.HandleFunc("PUT /config", putConfig)
+	mux.HandleFunc("GET /config/{subject}", getConfig)
+	mux.HandleFunc("PUT /config/{subject}", putConfig)
+	return mux
+}
+
+func registeredPayload(rs RegisteredSchema) schemaPayload {
+	p := newSchemaPayload(rs.Schema)
+	p.Subject, p.ID, p.Version = rs.Subject, rs.ID, rs.Version
+	return p
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 16<<20)).Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorPayload{ErrorCode: http.StatusBadRequest, Message: err.Error()})
+		return false
+	}
+	return true
+}
+
+// writeError maps registry errors to their HTTP status and error code.
+func writeError(w http.ResponseWriter, err error, notFoundCode int) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		writeJSON(w, http.StatusNotFound, errorPayload{ErrorCode: notFoundCode, Message: err.Error()})
+	case errors.Is(err, ErrIncompatible):
+		writeJSON(w, http.StatusConflict, errorPayload{ErrorCode: codeIncompatible, Message: err.Error()})
+	case errors.Is(err, ErrInvalidSchema):
+		writeJSON(w, http.StatusUnprocessableEntity, errorPayload{ErrorCode: codeInvalidSchema, Message: err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, errorPayload{ErrorCode: codeInternal, Message: err.Error()})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v) //nolint:errcheck // the status is already written
+}
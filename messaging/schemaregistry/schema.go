@@ -0,0 +1,122 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaType is the format of a registered schema.
+type SchemaType string
+
+const (
+	// Avro schemas are Avro JSON schema declarations.
+	Avro SchemaType = "AVRO"
+	// Protobuf schemas are base64-encoded serialized FileDescriptorProtos,
+	// the registry's "serialized" format.
+	Protobuf SchemaType = "PROTOBUF"
+	// JSONSchema schemas are JSON Schema documents.
+	JSONSchema SchemaType = "JSON"
+)
+
+// Validate checks that t is a supported schema type.
+func (t SchemaType) Validate() error {
+	switch t {
+	case Avro, Protobuf, JSONSchema:
+		return nil
+	default:
+		return fmt.Errorf("schemaregistry: unsupported schema type %q", t)
+	}
+}
+
+// Reference points a schema at a named type or file registered under
+// another subject. For Avro the name is the referenced type's full name; for
+// Protobuf it is the import path.
+type Reference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// Schema is a schema definition as stored in the registry.
+type Schema struct {
+	Type       SchemaType
+	Schema     string
+	References []Reference
+}
+
+// key identifies equal schemas: JSON-based schemas compare after compacting,
+// so formatting differences do not register a new version.
+func (s Schema) key() string {
+	text := s.Schema
+	if s.Type != Protobuf {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, []byte(text)); err == nil {
+			text = buf.String()
+		}
+	}
+	refs, _ := json.Marshal(s.References)
+	return string(s.Type) + "\x00" + text + "\x00" + string(refs)
+}
+
+// RegisteredSchema is a schema registered under a subject.
+type RegisteredSchema struct {
+	Schema
+	Subject string
+	ID      int
+	Version int
+}
+
+// CompatibilityMode controls which schema changes a subject accepts.
+type CompatibilityMode string
+
+const (
+	// CompatibilityNone accepts any change.
+	CompatibilityNone CompatibilityMode = "NONE"
+	// CompatibilityBackward lets readers of the new schema read data written
+	// with the previous one.
+	CompatibilityBackward CompatibilityMode = "BACKWARD"
+	// CompatibilityBackwardTransitive is CompatibilityBackward against every
+	// earlier version.
+	CompatibilityBackwardTransitive CompatibilityMode = "BACKWARD_TRANSITIVE"
+	// CompatibilityForward lets readers of the previous schema read data
+	// written with the new one.
+	CompatibilityForward CompatibilityMode = "FORWARD"
+	// CompatibilityForwardTransitive is CompatibilityForward against every
+	// earlier version.
+	CompatibilityForwardTransitive CompatibilityMode = "FORWARD_TRANSITIVE"
+	// CompatibilityFull is both backward and forward compatible with the
+	// previous version.
+	CompatibilityFull CompatibilityMode = "FULL"
+	// CompatibilityFullTransitive is CompatibilityFull against every earlier
+	// version.
+	CompatibilityFullTransitive CompatibilityMode = "FULL_TRANSITIVE"
+)
+
+// DefaultCompatibility is the registry's compatibility mode for subjects
+// without their own.
+const DefaultCompatibility = CompatibilityBackward
+
+// Validate checks that m is a known compatibility mode.
+func (m CompatibilityMode) Validate() error {
+	switch m {
+	case CompatibilityNone, CompatibilityBackward, CompatibilityBackwardTransitive,
+		CompatibilityForward, CompatibilityForwardTransitive,
+		CompatibilityFull, CompatibilityFullTransitive:
+		return nil
+	default:
+		return fmt.Errorf("schemaregistry: unsupported compatibility mode %q", m)
+	}
+}
+
+func (m CompatibilityMode) backward() bool {
+	return m == CompatibilityBackward || m == CompatibilityBackwardTransitive || m == CompatibilityFull || m == CompatibilityFullTransitive
+}
+
+func (m CompatibilityMode) forward() bool {
+	return m == CompatibilityForward || m == CompatibilityForwardTransitive || m == CompatibilityFull || m == CompatibilityFullTransitive
+}
+
+func (m CompatibilityMode) transitive() bool {
+	return m == CompatibilityBackwardTransitive || m == CompatibilityForwardTransitive || m == CompatibilityFullTransitive
+}
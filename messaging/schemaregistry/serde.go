@@ -0,0 +1,285 @@
+package schemaregistry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/schema"
+)
+
+// SubjectNameStrategy names the subject a schema is registered under, from
+// the topic and the schema's record name: the Avro record's full name, the
+// Protobuf message's full name, or the JSON Schema title.
+type SubjectNameStrategy func(topic, recordName string) string
+
+// TopicNameStrategy registers every schema of a topic under "<topic>-value",
+// so the topic carries one evolving type. It is the default.
+func TopicNameStrategy(topic, _ string) string { return topic + "-value" }
+
+// RecordNameStrategy registers a schema under its record name, so a type
+// evolves the same way on every topic.
+func RecordNameStrategy(_, recordName string) string { return recordName }
+
+// TopicRecordNameStrategy registers a schema under "<topic>-<record name>",
+// so a topic can carry several types.
+func TopicRecordNameStrategy(topic, recordName string) string { return topic + "-" + recordName }
+
+// Option configures a Serializer or Deserializer.
+type Option func(*options)
+
+type options struct {
+	subjectName  SubjectNameStrategy
+	autoRegister bool
+	validate     bool
+	jsonSchema   schema.JSON
+}
+
+func newOptions(opts []Option) options {
+	o := options{subjectName: TopicNameStrategy, autoRegister: true, validate: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithSubjectNameStrategy sets how serializers name subjects.
+// Default: TopicNameStrategy.
+func WithSubjectNameStrategy(strategy SubjectNameStrategy) Option {
+	return func(o *options) {
+		if strategy != nil {
+			o.subjectName = strategy
+		}
+	}
+}
+
+// WithAutoRegister controls whether serializers register their schema when
+// the subject does not hold it yet. With it disabled, serializing fails until
+// the schema is registered, for example by a deployment pipeline.
+// Default: true.
+func WithAutoRegister(enabled bool) Option {
+	return func(o *options) { o.autoRegister = enabled }
+}
+
+// WithValidation controls whether JSON Schema serializers and deserializers
+// validate payloads against their schema. Default: true.
+func WithValidation(enabled bool) Option {
+	return func(o *options) { o.validate = enabled }
+}
+
+// WithJSONSchema sets the schema of a JSON Schema serializer instead of
+// generating it from T.
+func WithJSONSchema(doc schema.JSON) Option {
+	return func(o *options) { o.jsonSchema = doc }
+}
+
+// format encodes and decodes values of T in one schema format.
+type format[T any] interface {
+	schema() Schema
+	recordName() string
+	// dependencies lists the schemas the format's schema imports; they are
+	// registered first and referenced.
+	dependencies() []dependency
+	// encode returns the payload that follows the wire header.
+	encode(v T) ([]byte, error)
+	// decoder returns a function decoding payloads written with writer.
+	decoder(writer parsedSchema) (func(body []byte) (T, error), error)
+}
+
+// dependency is a schema imported by another, registered under its name.
+type dependency struct {
+	name   string
+	schema Schema
+	deps   []dependency
+}
+
+// Serializer encodes values of T in the registry wire format: a magic byte,
+// the schema ID, and the payload. The schema ID is resolved once per subject
+// and cached. It is safe for concurrent use.
+type Serializer[T any] struct {
+	reg    Registry
+	format format[T]
+	opts   options
+
+	mu  sync.RWMutex
+	ids map[string]int
+}
+
+func newSerializer[T any](reg Registry, f format[T], opts options) (*Serializer[T], error) {
+	if reg == nil {
+		return nil, fmt.Errorf("schemaregistry: registry is nil")
+	}
+	return &Serializer[T]{reg: reg, format: f, opts: opts, ids: make(map[string]int)}, nil
+}
+
+// Subject returns the subject the serializer's schema has for topic.
+func (s *Serializer[T]) Subject(topic string) string {
+	return s.opts.subjectName(topic, s.format.recordName())
+}
+
+// Serialize encodes v for topic, registering the schema first if needed.
+func (s *Serializer[T]) Serialize(ctx context.Context, topic string, v T) ([]byte, error) {
+	id, err := s.schemaID(ctx, s.Subject(topic))
+	if err != nil {
+		return nil, err
+	}
+	body, err := s.format.encode(v)
+	if err != nil {
+		return nil, fmt.Errorf("schemaregistry: serialize for %s: %w", topic, err)
+	}
+	return append(AppendHeader(make([]byte, 0, headerSize+len(body)), id), body...), nil
+}
+
+// Message serializes v into a message for topic.
+func (s *Serializer[T]) Message(ctx context.Context, topic, key string, v T) (messaging.Message, error) {
+	value, err := s.Serialize(ctx, topic, v)
+	if err != nil {
+		return messaging.Message{}, err
+	}
+	return messaging.NewMessage(topic, key, value, nil), nil
+}
+
+// Publish serializes v and sends it through p.
+func (s *Serializer[T]) Publish(ctx context.Context, p messaging.Producer, topic, key string, v T) error {
+	msg, err := s.Message(ctx, topic, key, v)
+	if err != nil {
+		return err
+	}
+	return p.Send(ctx, msg)
+}
+
+func (s *Serializer[T]) schemaID(ctx context.Context, subject string) (int, error) {
+	s.mu.RLock()
+	id, ok := s.ids[subject]
+	s.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	sch := s.format.schema()
+	refs, err := s.references(ctx, s.format.dependencies())
+	if err != nil {
+		return 0, err
+	}
+	sch.References = refs
+	if s.opts.autoRegister {
+		id, err = s.reg.Register(ctx, subject, sch)
+	} else {
+		var rs RegisteredSchema
+		rs, err = s.reg.Lookup(ctx, subject, sch)
+		id = rs.ID
+	}
+	if err != nil {
+		return 0, fmt.Errorf("schemaregistry: schema for subject %s: %w", subject, err)
+	}
+
+	s.mu.Lock()
+	s.ids[subject] = id
+	s.mu.Unlock()
+	return id, nil
+}
+
+// references registers or looks up deps, depth first, and returns
+// references to them.
+func (s *Serializer[T]) references(ctx context.Context, deps []dependency) ([]Reference, error) {
+	if len(deps) == 0 {
+		return nil, nil
+	}
+	refs := make([]Reference, 0, len(deps))
+	for _, dep := range deps {
+		sch := dep.schema
+		var err error
+		if sch.References, err = s.references(ctx, dep.deps); err != nil {
+			return nil, err
+		}
+		if s.opts.autoRegister {
+			if _, err := s.reg.Register(ctx, dep.name, sch); err != nil {
+				return nil, fmt.Errorf("schemaregistry: register reference %s: %w", dep.name, err)
+			}
+		}
+		rs, err := s.reg.Lookup(ctx, dep.name, sch)
+		if err != nil {
+			return nil, fmt.Errorf("schemaregistry: reference %s: %w", dep.name, err)
+		}
+		refs = append(refs, Reference{Name: dep.name, Subject: dep.name, Version: rs.Version})
+	}
+	return refs, nil
+}
+
+// Deserializer decodes registry wire-format payloads into values of T,
+// fetching each writer schema once by ID. It is safe for concurrent use.
+type Deserializer[T any] struct {
+	reg    Registry
+	format format[T]
+
+	mu       sync.RWMutex
+	decoders map[int]func(body []byte) (T, error)
+}
+
+func newDeserializer[T any](reg Registry, f format[T]) (*Deserializer[T], error) {
+	if reg == nil {
+		return nil, fmt.Errorf("schemaregistry: registry is nil")
+	}
+	return &Deserializer[T]{reg: reg, format: f, decoders: make(map[int]func([]byte) (T, error))}, nil
+}
+
+// Deserialize decodes data written by a Serializer.
+func (d *Deserializer[T]) Deserialize(ctx context.Context, data []byte) (T, error) {
+	var zero T
+	id, body, err := ParseHeader(data)
+	if err != nil {
+		return zero, err
+	}
+	decode, err := d.decoder(ctx, id)
+	if err != nil {
+		return zero, err
+	}
+	v, err := decode(body)
+	if err != nil {
+		return zero, fmt.Errorf("schemaregistry: deserialize with schema %d: %w", id, err)
+	}
+	return v, nil
+}
+
+// Handler returns a messaging.MessageHandler that deserializes each message
+// value and calls fn. Messages that fail to deserialize return an error
+// without calling fn.
+func (d *Deserializer[T]) Handler(fn func(ctx context.Context, v T, msg messaging.Message) error) messaging.MessageHandler {
+	return func(ctx context.Context, msg messaging.Message) error {
+		v, err := d.Deserialize(ctx, msg.Value)
+		if err != nil {
+			return fmt.Errorf("%s/%d@%d: %w", msg.Topic, msg.Partition, msg.Offset, err)
+		}
+		return fn(ctx, v, msg)
+	}
+}
+
+func (d *Deserializer[T]) decoder(ctx context.Context, id int) (func([]byte) (T, error), error) {
+	d.mu.RLock()
+	decode, ok := d.decoders[id]
+	d.mu.RUnlock()
+	if ok {
+		return decode, nil
+	}
+
+	s, err := d.reg.SchemaByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("schemaregistry: schema %d: %w", id, err)
+	}
+	writer, err := parseSchema(s, func(ref Reference) (Schema, error) {
+		rs, err := d.reg.Version(ctx, ref.Subject, ref.Version)
+		return rs.Schema, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("schemaregistry: schema %d: %w", id, err)
+	}
+	if decode, err = d.format.decoder(writer); err != nil {
+		return nil, fmt.Errorf("schemaregistry: schema %d: %w", id, err)
+	}
+
+	d.mu.Lock()
+	d.decoders[id] = decode
+	d.mu.Unlock()
+	return decode, nil
+}
@@ -0,0 +1,132 @@
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kbukum/gokit/messaging"
+	"github.com/kbukum/gokit/messaging/memory"
+)
+
+func TestSerializerPublishAndHandler(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	reg := NewLocalRegistry()
+	broker := memory.NewBroker()
+	defer broker.Close()
+	consumer := broker.Consumer("orders")
+
+	ser, err := NewJSONSerializer[jsonOrder](reg)
+	if err != nil {
+		t.Fatalf("NewJSONSerializer: %v", err)
+	}
+	if err := ser.Publish(ctx, broker.Producer(), "orders", "o-1", jsonOrder{ID: "o-1", Amount: 3}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := broker.Producer().Send(ctx, messaging.NewMessage("orders", "bad", []byte("{}"), nil)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	de, err := NewJSONDeserializer[jsonOrder](reg)
+	if err != nil {
+		t.Fatalf("NewJSONDeserializer: %v", err)
+	}
+	var got []jsonOrder
+	err = consumer.Consume(ctx, de.Handler(func(_ context.Context, o jsonOrder, msg messaging.Message) error {
+		if msg.Key != o.ID {
+			t.Errorf("key = %q, want %q", msg.Key, o.ID)
+		}
+		got = append(got, o)
+		return nil
+	}))
+	// The second message has no wire header, so the handler stops with an
+	// error naming it.
+	if err == nil || !strings.HasPrefix(err.Error(), "orders/0@0: ") {
+		t.Fatalf("Consume = %v", err)
+	}
+	if len(got) != 1 || got[0] != (jsonOrder{ID: "o-1", Amount: 3}) {
+		t.Fatalf("handled = %+v", got)
+	}
+}
+
+func TestSerializerSubjectNameStrategies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		strategy SubjectNameStrategy
+		want     string
+	}{
+		{strategy: nil, want: "users-value"},
+		{strategy: TopicNameStrategy, want: "users-value"},
+		{strategy: RecordNameStrategy, want: "com.example.User"},
+		{strategy: TopicRecordNameStrategy, want: "users-com.example.User"},
+	}
+	for _, tt := range tests {
+		ser, err := NewAvroSerializer[avroUser](NewLocalRegistry(), userV1, WithSubjectNameStrategy(tt.strategy))
+		if err != nil {
+			t.Fatalf("NewAvroSerializer: %v", err)
+		}
+		if got := ser.Subject("users"); got != tt.want {
+			t.Errorf("Subject = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestSerializerWithoutAutoRegister(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	reg := NewLocalRegistry()
+
+	ser, err := NewAvroSerializer[map[string]any](reg, recordV1, WithAutoRegister(false))
+	if err != nil {
+		t.Fatalf("NewAvroSerializer: %v", err)
+	}
+	if _, err := ser.Serialize(ctx, "r", map[string]any{"a": 1}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Serialize before registration = %v, want ErrNotFound", err)
+	}
+	id, err := reg.Register(ctx, "r-value", Schema{Schema: recordV1})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	data, err := ser.Serialize(ctx, "r", map[string]any{"a": 1})
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if got, _, _ := ParseHeader(data); got != id {
+		t.Fatalf("schema id = %d, want %d", got, id)
+	}
+}
+
+func TestSerializerRejectsIncompatibleSchema(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	reg := NewLocalRegistry()
+
+	if _, err := reg.Register(ctx, "r-value", Schema{Schema: recordV1}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	ser, err := NewAvroSerializer[map[string]any](reg, recordV3)
+	if err != nil {
+		t.Fatalf("NewAvroSerializer: %v", err)
+	}
+	if _, err := ser.Serialize(ctx, "r", map[string]any{"a": 1, "c": "x"}); !errors.Is(err, ErrIncompatible) {
+		t.Fatalf("Serialize = %v, want ErrIncompatible", err)
+	}
+}
+
+func TestDeserializerUnknownSchemaID(t *testing.T) {
+	t.Parallel()
+
+	de, err := NewAvroDeserializer[map[string]any](NewLocalRegistry(), "")
+	if err != nil {
+		t.Fatalf("NewAvroDeserializer: %v", err)
+	}
+	if _, err := de.Deserialize(context.Background(), AppendHeader(nil, 7)); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Deserialize = %v, want ErrNotFound", err)
+	}
+	if _, err := NewJSONSerializer[jsonOrder](nil); err == nil {
+		t.Fatal("expected error for a nil registry")
+	}
+}
@@ -0,0 +1,68 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// magicByte starts every payload in the registry wire format, followed by the
+// big-endian schema ID.
+const (
+	magicByte  = 0x0
+	headerSize = 5
+)
+
+// AppendHeader appends the wire-format header for schema id to dst.
+func AppendHeader(dst []byte, id int) []byte {
+	dst = append(dst, magicByte)
+	return binary.BigEndian.AppendUint32(dst, uint32(id))
+}
+
+// ParseHeader splits a wire-format payload into its schema ID and body.
+func ParseHeader(data []byte) (int, []byte, error) {
+	if len(data) < headerSize {
+		return 0, nil, fmt.Errorf("schemaregistry: payload of %d bytes is shorter than the wire header", len(data))
+	}
+	if data[0] != magicByte {
+		return 0, nil, fmt.Errorf("schemaregistry: unknown magic byte %d", data[0])
+	}
+	return int(binary.BigEndian.Uint32(data[1:headerSize])), data[headerSize:], nil
+}
+
+// appendMessageIndexes appends the path of a Protobuf message within its
+// file, as zig-zag varints prefixed by their count. The common path [0], the
+// first message, is written as a single zero.
+func appendMessageIndexes(dst []byte, indexes []int) []byte {
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return binary.AppendVarint(dst, 0)
+	}
+	dst = binary.AppendVarint(dst, int64(len(indexes)))
+	for _, i := range indexes {
+		dst = binary.AppendVarint(dst, int64(i))
+	}
+	return dst
+}
+
+func readMessageIndexes(data []byte) ([]int, []byte, error) {
+	count, n := binary.Varint(data)
+	if n <= 0 || count < 0 {
+		return nil, nil, fmt.Errorf("schemaregistry: malformed protobuf message indexes")
+	}
+	data = data[n:]
+	if count == 0 {
+		return []int{0}, data, nil
+	}
+	if count > int64(len(data)) {
+		return nil, nil, fmt.Errorf("schemaregistry: %d protobuf message indexes exceed the payload", count)
+	}
+	indexes := make([]int, count)
+	for i := range indexes {
+		v, n := binary.Varint(data)
+		if n <= 0 || v < 0 {
+			return nil, nil, fmt.Errorf("schemaregistry: malformed protobuf message indexes")
+		}
+		indexes[i] = int(v)
+		data = data[n:]
+	}
+	return indexes, data, nil
+}
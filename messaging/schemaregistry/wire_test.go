@@ -0,0 +1,51 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestHeaderRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	data := append(AppendHeader(nil, 258), "body"...)
+	if !bytes.Equal(data[:headerSize], []byte{0, 0, 0, 1, 2}) {
+		t.Fatalf("header = %v", data[:headerSize])
+	}
+	id, body, err := ParseHeader(data)
+	if err != nil || id != 258 || string(body) != "body" {
+		t.Fatalf("ParseHeader = %d, %q, %v", id, body, err)
+	}
+}
+
+func TestParseHeaderRejectsMalformedPayloads(t *testing.T) {
+	t.Parallel()
+
+	for _, data := range [][]byte{nil, {0, 0, 1}, {1, 0, 0, 0, 1}} {
+		if _, _, err := ParseHeader(data); err == nil {
+			t.Fatalf("ParseHeader(%v) succeeded", data)
+		}
+	}
+}
+
+func TestMessageIndexesRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	if got := appendMessageIndexes(nil, []int{0}); !bytes.Equal(got, []byte{0}) {
+		t.Fatalf("first message indexes = %v, want [0]", got)
+	}
+	for _, indexes := range [][]int{{0}, {2}, {1, 0, 3}} {
+		data := appendMessageIndexes(nil, indexes)
+		got, rest, err := readMessageIndexes(append(data, 0xff))
+		if err != nil {
+			t.Fatalf("readMessageIndexes(%v): %v", indexes, err)
+		}
+		if !reflect.DeepEqual(got, indexes) || !bytes.Equal(rest, []byte{0xff}) {
+			t.Fatalf("readMessageIndexes(%v) = %v, %v", indexes, got, rest)
+		}
+	}
+	if _, _, err := readMessageIndexes([]byte{0x10}); err == nil {
+		t.Fatal("expected error for indexes exceeding the payload")
+	}
+}
@@ -98,6 +98,21 @@ func histogramOptions(opts []InstrumentOption) []metric.Float64HistogramOption {
 	return out
 }
 
+func gaugeOptions(opts []InstrumentOption) []metric.Int64GaugeOption {
+	cfg := instrumentOptions{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	out := []metric.Int64GaugeOption{}
+	if cfg.description != "" {
+		out = append(out, metric.WithDescription(cfg.description))
+	}
+	if cfg.unit != "" {
+		out = append(out, metric.WithUnit(cfg.unit))
+	}
+	return out
+}
+
 // Int64Counter wraps an int64 counter instrument.
 type Int64Counter struct {
 	counter metric.Int64Counter
@@ -139,3 +154,25 @@ func (h *Float64Histogram) Record(ctx context.Context, value float64, attrs ...M
 		h.histogram.Record(ctx, value, metricAttributes(attrs))
 	}
 }
+
+// Int64Gauge wraps an int64 gauge instrument that records the current value
+// of something, such as a concurrency limit.
+type Int64Gauge struct {
+	gauge metric.Int64Gauge
+}
+
+// NewInt64Gauge creates an int64 gauge from the named meter.
+func NewInt64Gauge(meterName, instrumentName string, opts ...InstrumentOption) (*Int64Gauge, error) {
+	gauge, err := Meter(meterName).Int64Gauge(instrumentName, gaugeOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return &Int64Gauge{gauge: gauge}, nil
+}
+
+// Record records the gauge's current value.
+func (g *Int64Gauge) Record(ctx context.Context, value int64, attrs ...MetricAttribute) {
+	if g != nil {
+		g.gauge.Record(ctx, value, metricAttributes(attrs))
+	}
+}
@@ -30,6 +30,17 @@ func TestInstrumentWrappers(t *testing.T) {
 		t.Fatalf("unexpected histogram error: %v", err)
 	}
 	histogram.Record(context.Background(), 1.5, MetricBoolAttribute("ok", true))
+
+	gauge, err := NewInt64Gauge("test-meter", "test_limit",
+		WithInstrumentDescription("test gauge"),
+		WithInstrumentUnit("{call}"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected gauge error: %v", err)
+	}
+	gauge.Record(context.Background(), 20, MetricStringAttribute("name", "test"))
+	var nilGauge *Int64Gauge
+	nilGauge.Record(context.Background(), 1)
 }
 
 func TestMetricAttributeConstructors(t *testing.T) {
@@ -0,0 +1,337 @@
+package resilience
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Common adaptive limiter errors.
+var (
+	ErrLimiterFull    = errors.New("adaptive concurrency limit reached")
+	ErrLimiterTimeout = errors.New("adaptive concurrency limit wait timeout")
+)
+
+var (
+	_ encoding.TextMarshaler   = LimitAlgorithm("")
+	_ encoding.TextUnmarshaler = (*LimitAlgorithm)(nil)
+)
+
+// LimitAlgorithm selects how an AdaptiveLimiter adjusts its concurrency limit.
+// An unrecognized value behaves as LimitAIMD.
+type LimitAlgorithm string
+
+const (
+	// LimitAIMD grows the limit by one per successful sample while the limit
+	// is in use and multiplies it by BackoffRatio on errors or timeouts.
+	LimitAIMD LimitAlgorithm = "aimd"
+	// LimitVegas estimates the queue from the ratio of the minimum observed
+	// latency to the current one and keeps it between log-scaled thresholds.
+	LimitVegas LimitAlgorithm = "vegas"
+	// LimitGradient scales the limit by the ratio of long-term to short-term
+	// latency, shrinking it as soon as latency rises above the baseline.
+	LimitGradient LimitAlgorithm = "gradient"
+)
+
+// MarshalText serializes an algorithm for config encoders.
+func (a LimitAlgorithm) MarshalText() ([]byte, error) {
+	algorithm := a
+	if algorithm == "" {
+		algorithm = LimitAIMD
+	}
+	if !algorithm.valid() {
+		return nil, invalidLimitAlgorithm(algorithm)
+	}
+	return []byte(algorithm), nil
+}
+
+// UnmarshalText parses an algorithm from config text.
+func (a *LimitAlgorithm) UnmarshalText(text []byte) error {
+	algorithm := LimitAlgorithm(text)
+	if algorithm == "" {
+		*a = LimitAIMD
+		return nil
+	}
+	if !algorithm.valid() {
+		return invalidLimitAlgorithm(algorithm)
+	}
+	*a = algorithm
+	return nil
+}
+
+func (a LimitAlgorithm) valid() bool {
+	switch a {
+	case LimitAIMD, LimitVegas, LimitGradient:
+		return true
+	default:
+		return false
+	}
+}
+
+func invalidLimitAlgorithm(algorithm LimitAlgorithm) error {
+	return fmt.Errorf("limit algorithm %q: must be one of aimd, vegas, gradient", string(algorithm))
+}
+
+// AdaptiveLimiterConfig configures an adaptive concurrency limiter.
+type AdaptiveLimiterConfig struct {
+	// Name identifies this limiter for metrics/logging.
+	Name string
+	// Algorithm selects how the limit adapts. Default: aimd.
+	Algorithm LimitAlgorithm
+	// InitialLimit is the starting concurrency limit. Default: 20.
+	InitialLimit int
+	// MinLimit is the lowest the limit may fall. Default: 1.
+	MinLimit int
+	// MaxLimit is the highest the limit may grow. Default: 1000.
+	MaxLimit int
+	// MaxWait is how long to wait for a slot. 0 means fail immediately.
+	MaxWait time.Duration
+	// BackoffRatio multiplies the AIMD limit on a drop. Default: 0.9.
+	BackoffRatio float64
+	// Timeout makes AIMD treat slower calls as drops. 0 disables it.
+	Timeout time.Duration
+	// Tolerance is how far short-term latency may exceed the baseline before
+	// the gradient algorithm shrinks the limit. Default: 1.5.
+	Tolerance float64
+	// IsDropped reports whether a call's error signals overload. Default: any
+	// error except context.Canceled.
+	IsDropped func(err error) bool
+	// OnReject is called when a call is rejected.
+	OnReject func(name string)
+	// OnLimitChange is called after the integer limit changes, e.g. to record
+	// it in a gauge.
+	OnLimitChange func(name string, from, to int)
+}
+
+// DefaultAdaptiveLimiterConfig returns sensible defaults.
+func DefaultAdaptiveLimiterConfig(name string) AdaptiveLimiterConfig {
+	return AdaptiveLimiterConfig{
+		Name:         name,
+		Algorithm:    LimitAIMD,
+		InitialLimit: 20,
+		MinLimit:     1,
+		MaxLimit:     1000,
+		BackoffRatio: 0.9,
+		Tolerance:    1.5,
+	}
+}
+
+// AdaptiveLimiter is a bulkhead whose limit adapts to observed latency and
+// errors instead of being fixed.
+type AdaptiveLimiter struct {
+	config AdaptiveLimiterConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	released chan struct{}
+
+	// minRTT is the Vegas no-load latency estimate.
+	minRTT time.Duration
+	// longRTT is the gradient algorithm's smoothed baseline latency.
+	longRTT float64
+}
+
+// NewAdaptiveLimiter creates a new adaptive limiter.
+func NewAdaptiveLimiter(config AdaptiveLimiterConfig) *AdaptiveLimiter {
+	defaults := DefaultAdaptiveLimiterConfig(config.Name)
+	if config.Algorithm == "" {
+		config.Algorithm = defaults.Algorithm
+	}
+	if config.MinLimit <= 0 {
+		config.MinLimit = defaults.MinLimit
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = defaults.MaxLimit
+	}
+	if config.MaxLimit < config.MinLimit {
+		config.MaxLimit = config.MinLimit
+	}
+	if config.InitialLimit <= 0 {
+		config.InitialLimit = defaults.InitialLimit
+	}
+	config.InitialLimit = min(max(config.InitialLimit, config.MinLimit), config.MaxLimit)
+	if config.BackoffRatio <= 0 || config.BackoffRatio >= 1 {
+		config.BackoffRatio = defaults.BackoffRatio
+	}
+	if config.Tolerance < 1 {
+		config.Tolerance = defaults.Tolerance
+	}
+	if config.IsDropped == nil {
+		config.IsDropped = func(err error) bool { return !errors.Is(err, context.Canceled) }
+	}
+
+	return &AdaptiveLimiter{
+		config:   config,
+		now:      time.Now,
+		limit:    float64(config.InitialLimit),
+		released: make(chan struct{}),
+	}
+}
+
+// Execute runs fn within the current limit and feeds its latency and error
+// back into the algorithm. Returns ErrLimiterFull or ErrLimiterTimeout if no
+// slot is available.
+func (l *AdaptiveLimiter) Execute(ctx context.Context, fn func() error) error {
+	if err := l.acquire(ctx); err != nil {
+		if l.config.OnReject != nil {
+			l.config.OnReject(l.config.Name)
+		}
+		return err
+	}
+
+	start := l.now()
+	var err error
+	panicked := true
+	defer func() {
+		// A panicking call frees its slot and counts as a drop.
+		l.release(l.now().Sub(start), panicked || (err != nil && l.config.IsDropped(err)))
+	}()
+	err = fn()
+	panicked = false
+	return err
+}
+
+// ExecuteAdaptive runs a function that returns a value within the limiter.
+func ExecuteAdaptive[T any](ctx context.Context, l *AdaptiveLimiter, fn func() (T, error)) (T, error) {
+	var result T
+	err := l.Execute(ctx, func() error {
+		var fnErr error
+		result, fnErr = fn()
+		return fnErr
+	})
+	return result, err
+}
+
+// Limit returns the current concurrency limit.
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// InFlight returns the number of calls currently holding a slot.
+func (l *AdaptiveLimiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+func (l *AdaptiveLimiter) acquire(ctx context.Context) error {
+	var timer *time.Timer
+	for {
+		l.mu.Lock()
+		if l.inFlight < int(l.limit) {
+			l.inFlight++
+			l.mu.Unlock()
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil
+		}
+		released := l.released
+		l.mu.Unlock()
+
+		if l.config.MaxWait <= 0 {
+			return ErrLimiterFull
+		}
+		if timer == nil {
+			timer = time.NewTimer(l.config.MaxWait)
+		}
+		select {
+		case <-released:
+		case <-timer.C:
+			return ErrLimiterTimeout
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// release frees a slot, records the sample, and wakes waiters.
+func (l *AdaptiveLimiter) release(rtt time.Duration, dropped bool) {
+	l.mu.Lock()
+	inFlight := l.inFlight
+	l.inFlight--
+	from := int(l.limit)
+	l.limit = l.clamp(l.next(rtt, inFlight, dropped))
+	to := int(l.limit)
+	close(l.released)
+	l.released = make(chan struct{})
+	l.mu.Unlock()
+
+	if from != to && l.config.OnLimitChange != nil {
+		l.config.OnLimitChange(l.config.Name, from, to)
+	}
+}
+
+// next computes the new limit from one sample. inFlight includes the sample's
+// own call.
+func (l *AdaptiveLimiter) next(rtt time.Duration, inFlight int, dropped bool) float64 {
+	limit := l.limit
+	proposed := l.propose(rtt, dropped)
+	// A limit the caller is nowhere near using says nothing about spare
+	// capacity, so it may shrink but not grow.
+	if float64(inFlight)*2 < limit {
+		return math.Min(limit, proposed)
+	}
+	return proposed
+}
+
+func (l *AdaptiveLimiter) propose(rtt time.Duration, dropped bool) float64 {
+	limit := l.limit
+	switch l.config.Algorithm {
+	case LimitVegas:
+		logLimit := max(1, math.Log10(limit))
+		if dropped {
+			return limit - logLimit
+		}
+		if l.minRTT == 0 || rtt < l.minRTT {
+			l.minRTT = rtt
+		}
+		if rtt <= 0 {
+			return limit
+		}
+		queue := limit * (1 - float64(l.minRTT)/float64(rtt))
+		switch {
+		case queue <= logLimit:
+			return limit + 6*logLimit
+		case queue < 3*logLimit:
+			return limit + logLimit
+		case queue > 6*logLimit:
+			return limit - logLimit
+		default:
+			return limit
+		}
+	case LimitGradient:
+		const smoothing = 0.2
+		sample := float64(rtt)
+		if l.longRTT == 0 {
+			l.longRTT = sample
+		}
+		// The baseline follows the latency slowly so a sustained rise keeps
+		// shrinking the limit for a while.
+		l.longRTT = l.longRTT*0.95 + sample*0.05
+		gradient := 0.5
+		if !dropped && sample > 0 {
+			gradient = math.Max(0.5, math.Min(1, l.config.Tolerance*l.longRTT/sample))
+		}
+		target := limit*gradient + math.Sqrt(limit)
+		return limit*(1-smoothing) + target*smoothing
+	default:
+		if dropped || (l.config.Timeout > 0 && rtt > l.config.Timeout) {
+			return limit * l.config.BackoffRatio
+		}
+		return limit + 1
+	}
+}
+
+func (l *AdaptiveLimiter) clamp(limit float64) float64 {
+	return math.Min(float64(l.config.MaxLimit), math.Max(float64(l.config.MinLimit), limit))
+}
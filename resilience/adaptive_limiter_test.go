@@ -0,0 +1,254 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLatency makes every Execute call on l take d of fake time.
+type fakeLatency struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeLatency) clock() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeLatency) call(l *AdaptiveLimiter, d time.Duration, err error) error {
+	return l.Execute(context.Background(), func() error {
+		f.mu.Lock()
+		f.now = f.now.Add(d)
+		f.mu.Unlock()
+		return err
+	})
+}
+
+func newTestAdaptiveLimiter(cfg AdaptiveLimiterConfig) (*AdaptiveLimiter, *fakeLatency) {
+	l := NewAdaptiveLimiter(cfg)
+	f := &fakeLatency{now: time.Unix(0, 0)}
+	l.now = f.clock
+	return l, f
+}
+
+func TestAdaptiveLimiter_AIMD(t *testing.T) {
+	t.Parallel()
+
+	var changes [][2]int
+	l, f := newTestAdaptiveLimiter(AdaptiveLimiterConfig{
+		Name:          "aimd",
+		InitialLimit:  2,
+		MaxLimit:      4,
+		Timeout:       100 * time.Millisecond,
+		OnLimitChange: func(_ string, from, to int) { changes = append(changes, [2]int{from, to}) },
+	})
+
+	for range 5 {
+		if err := f.call(l, time.Millisecond, nil); err != nil {
+			t.Fatalf("call: %v", err)
+		}
+	}
+	// With one call in flight, growth stops once the limit exceeds twice the
+	// observed concurrency.
+	if got := l.Limit(); got != 3 {
+		t.Fatalf("limit after successes = %d, want 3", got)
+	}
+
+	if err := f.call(l, time.Millisecond, errors.New("overloaded")); err == nil {
+		t.Fatal("expected the call's error")
+	}
+	if got := l.Limit(); got != 2 {
+		t.Fatalf("limit after drop = %d, want 2", got)
+	}
+	_ = f.call(l, time.Second, nil) // slower than Timeout counts as a drop
+	if got := l.Limit(); got != 2 {
+		t.Fatalf("limit after timeout sample = %d, want 2 (2.43 floored)", got)
+	}
+	if len(changes) == 0 || changes[0] != [2]int{2, 3} {
+		t.Fatalf("OnLimitChange calls = %v", changes)
+	}
+
+	// Cancellation is not an overload signal.
+	before := l.limit
+	_ = f.call(l, time.Millisecond, context.Canceled)
+	if l.limit < before {
+		t.Fatalf("context.Canceled shrank the limit from %v to %v", before, l.limit)
+	}
+}
+
+func TestAdaptiveLimiter_RejectsAndWaits(t *testing.T) {
+	t.Parallel()
+
+	var rejected int
+	l := NewAdaptiveLimiter(AdaptiveLimiterConfig{
+		Name:         "full",
+		InitialLimit: 1,
+		MaxLimit:     1,
+		OnReject:     func(string) { rejected++ },
+	})
+	hold := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_ = l.Execute(context.Background(), func() error {
+			close(started)
+			<-hold
+			return nil
+		})
+	}()
+	<-started
+
+	if err := l.Execute(context.Background(), func() error { return nil }); !errors.Is(err, ErrLimiterFull) {
+		t.Fatalf("Execute while full = %v, want ErrLimiterFull", err)
+	}
+	if rejected != 1 || l.InFlight() != 1 {
+		t.Fatalf("rejected=%d inFlight=%d", rejected, l.InFlight())
+	}
+
+	l.config.MaxWait = 10 * time.Millisecond
+	if err := l.Execute(context.Background(), func() error { return nil }); !errors.Is(err, ErrLimiterTimeout) {
+		t.Fatalf("Execute after MaxWait = %v, want ErrLimiterTimeout", err)
+	}
+
+	l.config.MaxWait = time.Second
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Execute(context.Background(), func() error { return nil })
+	}()
+	close(hold)
+	if err := <-done; err != nil {
+		t.Fatalf("waiter error = %v", err)
+	}
+}
+
+func TestAdaptiveLimiter_Vegas(t *testing.T) {
+	t.Parallel()
+
+	l, f := newTestAdaptiveLimiter(AdaptiveLimiterConfig{Algorithm: LimitVegas, InitialLimit: 1, MaxLimit: 100})
+	for range 3 {
+		_ = f.call(l, 10*time.Millisecond, nil)
+	}
+	grown := l.Limit()
+	if grown <= 1 {
+		t.Fatalf("limit at base latency = %d, want growth", grown)
+	}
+
+	// Raise latency under load: hold enough calls in flight that the limit is
+	// in use, then complete slow samples.
+	l.mu.Lock()
+	l.inFlight = grown - 1
+	l.mu.Unlock()
+	_ = f.call(l, 100*time.Millisecond, nil)
+	if got := l.Limit(); got >= grown {
+		t.Fatalf("limit under queueing = %d, want below %d", got, grown)
+	}
+
+	if err := f.call(l, 10*time.Millisecond, errors.New("boom")); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestAdaptiveLimiter_Gradient(t *testing.T) {
+	t.Parallel()
+
+	l, f := newTestAdaptiveLimiter(AdaptiveLimiterConfig{Algorithm: LimitGradient, InitialLimit: 20, MaxLimit: 100})
+	l.mu.Lock()
+	l.inFlight = 15 // keep the limit in use
+	l.mu.Unlock()
+
+	for range 20 {
+		_ = f.call(l, 10*time.Millisecond, nil)
+	}
+	steady := l.Limit()
+	if steady <= 20 {
+		t.Fatalf("limit at steady latency = %d, want growth above 20", steady)
+	}
+	for range 10 {
+		_ = f.call(l, 200*time.Millisecond, nil)
+	}
+	if got := l.Limit(); got >= steady {
+		t.Fatalf("limit after latency spike = %d, want below %d", got, steady)
+	}
+}
+
+func TestAdaptiveLimiter_DefaultsAndClamp(t *testing.T) {
+	t.Parallel()
+
+	l := NewAdaptiveLimiter(AdaptiveLimiterConfig{InitialLimit: 500, MinLimit: 5, MaxLimit: 50})
+	if l.Limit() != 50 || l.config.Algorithm != LimitAIMD || l.config.BackoffRatio != 0.9 {
+		t.Fatalf("limit=%d config=%+v", l.Limit(), l.config)
+	}
+	for range 50 {
+		l.release(0, true)
+		l.mu.Lock()
+		l.inFlight++
+		l.mu.Unlock()
+	}
+	if l.Limit() != 5 {
+		t.Fatalf("limit after repeated drops = %d, want MinLimit 5", l.Limit())
+	}
+
+	got, err := ExecuteAdaptive(context.Background(), l, func() (int, error) { return 7, nil })
+	if err != nil || got != 7 {
+		t.Fatalf("ExecuteAdaptive = %d, %v", got, err)
+	}
+}
+
+func TestAdaptiveLimiter_PanicReleasesSlot(t *testing.T) {
+	t.Parallel()
+
+	l := NewAdaptiveLimiter(AdaptiveLimiterConfig{InitialLimit: 2})
+	func() {
+		defer func() { _ = recover() }()
+		_ = l.Execute(context.Background(), func() error { panic("boom") })
+	}()
+	if l.InFlight() != 0 || l.Limit() != 1 {
+		t.Fatalf("after panic inFlight=%d limit=%d, want 0 and a backed-off limit", l.InFlight(), l.Limit())
+	}
+}
+
+func TestExecutePolicy_AdaptiveLimiter(t *testing.T) {
+	t.Parallel()
+
+	policy := NewPolicy().WithAdaptiveLimiter(AdaptiveLimiterConfig{Name: "p", InitialLimit: 1, MaxLimit: 1})
+	hold := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_, _ = Execute(context.Background(), policy, func(context.Context) (int, error) {
+			close(started)
+			<-hold
+			return 0, nil
+		})
+	}()
+	<-started
+	defer close(hold)
+
+	if _, err := Execute(context.Background(), policy, func(context.Context) (int, error) { return 1, nil }); !errors.Is(err, ErrLimiterFull) {
+		t.Fatalf("second Execute = %v, want ErrLimiterFull", err)
+	}
+}
+
+func TestLimitAlgorithmText(t *testing.T) {
+	t.Parallel()
+
+	var a LimitAlgorithm
+	if err := a.UnmarshalText([]byte("vegas")); err != nil || a != LimitVegas {
+		t.Fatalf("UnmarshalText vegas = %q, %v", a, err)
+	}
+	if err := a.UnmarshalText(nil); err != nil || a != LimitAIMD {
+		t.Fatalf("empty UnmarshalText = %q, %v", a, err)
+	}
+	if err := a.UnmarshalText([]byte("bbr")); err == nil {
+		t.Fatal("UnmarshalText accepted an unknown algorithm")
+	}
+	if text, err := LimitGradient.MarshalText(); err != nil || string(text) != "gradient" {
+		t.Fatalf("MarshalText = %q, %v", text, err)
+	}
+	if _, err := LimitAlgorithm("bbr").MarshalText(); err == nil {
+		t.Fatal("MarshalText accepted an unknown algorithm")
+	}
+}
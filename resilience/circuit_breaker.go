@@ -45,6 +45,7 @@ type CircuitBreakerConfig struct {
 	// Name identifies this circuit breaker for metrics/logging.
 	Name string
 	// MaxFailures is the number of failures before opening the circuit.
+	// Only used by the consecutive window.
 	MaxFailures int
 	// Timeout is how long to wait before transitioning from open to half-open.
 	Timeout time.Duration
@@ -52,6 +53,28 @@ type CircuitBreakerConfig struct {
 	HalfOpenMaxCalls int
 	// OnStateChange is called when state changes.
 	OnStateChange func(name string, from, to State)
+
+	// Window selects how the circuit decides to open. Default: consecutive.
+	// The count and time windows open on failure or slow-call rates instead
+	// of MaxFailures.
+	Window CircuitWindow
+	// WindowSize is the number of recent calls the count window covers.
+	// Default: 100.
+	WindowSize int
+	// WindowDuration is the period the time window covers. Default: 60s.
+	WindowDuration time.Duration
+	// MinimumCalls is the number of calls the window must hold before rates
+	// are evaluated. Default: 10.
+	MinimumCalls int
+	// FailureRateThreshold is the failure fraction (0, 1] at which the
+	// circuit opens. Default: 0.5.
+	FailureRateThreshold float64
+	// SlowCallDuration marks calls that take at least this long as slow.
+	// 0 disables slow-call tracking.
+	SlowCallDuration time.Duration
+	// SlowCallRateThreshold is the slow-call fraction (0, 1] at which the
+	// circuit opens. Default: 1.
+	SlowCallRateThreshold float64
 }
 
 // DefaultCircuitBreakerConfig returns sensible defaults.
@@ -73,6 +96,7 @@ func DefaultCircuitBreakerConfig(name string) CircuitBreakerConfig {
 //   - Half-Open: Testing if service recovered, limited requests allowed
 type CircuitBreaker struct {
 	config CircuitBreakerConfig
+	window *callWindow
 
 	mu              sync.RWMutex
 	state           State
@@ -94,10 +118,33 @@ func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
 		config.HalfOpenMaxCalls = 1
 	}
 
-	return &CircuitBreaker{
+	cb := &CircuitBreaker{
 		config: config,
 		state:  StateClosed,
 	}
+	if config.Window.sliding() {
+		if config.WindowSize <= 0 {
+			config.WindowSize = 100
+		}
+		if config.WindowDuration < windowBuckets {
+			config.WindowDuration = 60 * time.Second
+		}
+		if config.MinimumCalls <= 0 {
+			config.MinimumCalls = 10
+		}
+		if config.Window == CircuitWindowCount {
+			config.MinimumCalls = min(config.MinimumCalls, config.WindowSize)
+		}
+		if config.FailureRateThreshold <= 0 || config.FailureRateThreshold > 1 {
+			config.FailureRateThreshold = 0.5
+		}
+		if config.SlowCallRateThreshold <= 0 || config.SlowCallRateThreshold > 1 {
+			config.SlowCallRateThreshold = 1
+		}
+		cb.config = config
+		cb.window = newCallWindow(config.Window, config.WindowSize, config.WindowDuration)
+	}
+	return cb
 }
 
 // Execute runs the given function through the circuit breaker.
@@ -107,8 +154,9 @@ func (cb *CircuitBreaker) Execute(fn func() error) error {
 		return ErrCircuitOpen
 	}
 
+	start := time.Now()
 	err := fn()
-	cb.recordResult(err)
+	cb.recordResult(err, time.Since(start))
 	return err
 }
 
@@ -127,12 +175,20 @@ func (cb *CircuitBreaker) Reset() {
 	cb.failures = 0
 	cb.successes = 0
 	cb.halfOpenCalls = 0
+	if cb.window != nil {
+		cb.window.reset()
+	}
 }
 
-// Failures returns the current failure count.
+// Failures returns the current failure count. With a sliding window it is the
+// number of failures in the window.
 func (cb *CircuitBreaker) Failures() int {
 	cb.mu.RLock()
 	defer cb.mu.RUnlock()
+	if cb.window != nil {
+		_, failures, _ := cb.window.totals(time.Now())
+		return failures
+	}
 	return cb.failures
 }
 
@@ -160,10 +216,14 @@ func (cb *CircuitBreaker) allowRequest() bool {
 }
 
 // recordResult records the result of a request.
-func (cb *CircuitBreaker) recordResult(err error) {
+func (cb *CircuitBreaker) recordResult(err error, duration time.Duration) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
+	if cb.window != nil {
+		cb.onWindowResult(err != nil, cb.config.SlowCallDuration > 0 && duration >= cb.config.SlowCallDuration)
+		return
+	}
 	if err != nil {
 		cb.onFailure()
 	} else {
@@ -171,6 +231,38 @@ func (cb *CircuitBreaker) recordResult(err error) {
 	}
 }
 
+// onWindowResult handles a request in the sliding window modes. In half-open
+// state a slow call counts as a failed probe.
+func (cb *CircuitBreaker) onWindowResult(failed, slow bool) {
+	now := time.Now()
+	if failed {
+		cb.lastFailureTime = now
+	}
+
+	switch cb.currentState() {
+	case StateClosed:
+		cb.window.record(now, failed, slow)
+		calls, failures, slowCalls := cb.window.totals(now)
+		if calls < cb.config.MinimumCalls {
+			return
+		}
+		if float64(failures) >= cb.config.FailureRateThreshold*float64(calls) ||
+			(cb.config.SlowCallDuration > 0 && float64(slowCalls) >= cb.config.SlowCallRateThreshold*float64(calls)) {
+			// The open timeout runs from the moment the circuit trips.
+			cb.lastFailureTime = now
+			cb.toState(StateOpen)
+		}
+	case StateHalfOpen:
+		if failed || slow {
+			cb.lastFailureTime = now
+			cb.toState(StateOpen)
+			return
+		}
+		cb.onSuccess()
+	default:
+	}
+}
+
 // onSuccess handles a successful request.
 func (cb *CircuitBreaker) onSuccess() {
 	switch cb.currentState() {
@@ -227,6 +319,9 @@ func (cb *CircuitBreaker) toState(to State) {
 		cb.failures = 0
 		cb.successes = 0
 		cb.halfOpenCalls = 0
+		if cb.window != nil {
+			cb.window.reset()
+		}
 	case StateHalfOpen:
 		cb.halfOpenCalls = 0
 		cb.successes = 0
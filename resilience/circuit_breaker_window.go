@@ -0,0 +1,178 @@
+package resilience
+
+import (
+	"encoding"
+	"fmt"
+	"time"
+)
+
+var (
+	_ encoding.TextMarshaler   = CircuitWindow("")
+	_ encoding.TextUnmarshaler = (*CircuitWindow)(nil)
+)
+
+// CircuitWindow selects how a CircuitBreaker decides to open.
+// An unrecognized value behaves as CircuitWindowConsecutive.
+type CircuitWindow string
+
+const (
+	// CircuitWindowConsecutive opens after MaxFailures failures with no
+	// success in between.
+	CircuitWindowConsecutive CircuitWindow = "consecutive"
+	// CircuitWindowCount evaluates failure and slow-call rates over the last
+	// WindowSize calls.
+	CircuitWindowCount CircuitWindow = "count"
+	// CircuitWindowTime evaluates failure and slow-call rates over the calls
+	// made in the last WindowDuration.
+	CircuitWindowTime CircuitWindow = "time"
+)
+
+// MarshalText serializes a window mode for config encoders.
+func (w CircuitWindow) MarshalText() ([]byte, error) {
+	window := w
+	if window == "" {
+		window = CircuitWindowConsecutive
+	}
+	if !window.valid() {
+		return nil, invalidCircuitWindow(window)
+	}
+	return []byte(window), nil
+}
+
+// UnmarshalText parses a window mode from config text.
+func (w *CircuitWindow) UnmarshalText(text []byte) error {
+	window := CircuitWindow(text)
+	if window == "" {
+		*w = CircuitWindowConsecutive
+		return nil
+	}
+	if !window.valid() {
+		return invalidCircuitWindow(window)
+	}
+	*w = window
+	return nil
+}
+
+func (w CircuitWindow) valid() bool {
+	switch w {
+	case CircuitWindowConsecutive, CircuitWindowCount, CircuitWindowTime:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w CircuitWindow) sliding() bool {
+	return w == CircuitWindowCount || w == CircuitWindowTime
+}
+
+func invalidCircuitWindow(window CircuitWindow) error {
+	return fmt.Errorf("circuit window %q: must be one of consecutive, count, time", string(window))
+}
+
+// windowBuckets is the number of slices a time-based window is split into.
+const windowBuckets = 10
+
+// callOutcome is one recorded call in a count-based window.
+type callOutcome struct {
+	failed bool
+	slow   bool
+}
+
+// windowBucket counts the calls in one slice of a time-based window.
+type windowBucket struct {
+	epoch    int64
+	calls    int
+	failures int
+	slow     int
+}
+
+// callWindow aggregates recent call outcomes for the sliding window modes.
+// It is guarded by the circuit breaker's mutex.
+type callWindow struct {
+	mode CircuitWindow
+	// count mode
+	ring     []callOutcome
+	next     int
+	full     bool
+	failures int
+	slow     int
+	// time mode
+	width   time.Duration
+	buckets [windowBuckets]windowBucket
+}
+
+func newCallWindow(mode CircuitWindow, size int, duration time.Duration) *callWindow {
+	w := &callWindow{mode: mode}
+	if mode == CircuitWindowTime {
+		w.width = duration / windowBuckets
+	} else {
+		w.ring = make([]callOutcome, size)
+	}
+	return w
+}
+
+func (w *callWindow) record(now time.Time, failed, slow bool) {
+	if w.mode == CircuitWindowTime {
+		epoch := now.UnixNano() / int64(w.width)
+		bucket := &w.buckets[epoch%windowBuckets]
+		if bucket.epoch != epoch {
+			*bucket = windowBucket{epoch: epoch}
+		}
+		bucket.calls++
+		if failed {
+			bucket.failures++
+		}
+		if slow {
+			bucket.slow++
+		}
+		return
+	}
+	if w.full {
+		w.tally(w.ring[w.next], -1)
+	}
+	outcome := callOutcome{failed: failed, slow: slow}
+	w.tally(outcome, 1)
+	w.ring[w.next] = outcome
+	w.next = (w.next + 1) % len(w.ring)
+	if w.next == 0 {
+		w.full = true
+	}
+}
+
+// totals returns the calls, failures, and slow calls currently in the window.
+func (w *callWindow) totals(now time.Time) (calls, failures, slow int) {
+	if w.mode == CircuitWindowTime {
+		epoch := now.UnixNano() / int64(w.width)
+		for _, bucket := range w.buckets {
+			if bucket.calls > 0 && epoch-bucket.epoch < windowBuckets {
+				calls += bucket.calls
+				failures += bucket.failures
+				slow += bucket.slow
+			}
+		}
+		return calls, failures, slow
+	}
+	calls = w.next
+	if w.full {
+		calls = len(w.ring)
+	}
+	return calls, w.failures, w.slow
+}
+
+func (w *callWindow) tally(outcome callOutcome, delta int) {
+	if outcome.failed {
+		w.failures += delta
+	}
+	if outcome.slow {
+		w.slow += delta
+	}
+}
+
+func (w *callWindow) reset() {
+	clear(w.ring)
+	w.next = 0
+	w.full = false
+	w.failures, w.slow = 0, 0
+	w.buckets = [windowBuckets]windowBucket{}
+}
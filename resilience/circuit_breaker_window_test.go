@@ -0,0 +1,137 @@
+package resilience
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_CountWindowFailureRate(t *testing.T) {
+	t.Parallel()
+
+	var transitions []State
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Name:                 "rate",
+		Window:               CircuitWindowCount,
+		WindowSize:           10,
+		MinimumCalls:         5,
+		FailureRateThreshold: 0.3,
+		Timeout:              time.Hour,
+		OnStateChange:        func(_ string, _, to State) { transitions = append(transitions, to) },
+	})
+	fail := errors.New("fail")
+
+	// Failures interleaved with successes never trip the consecutive mode,
+	// but a 2-in-6 failure rate is above the 30% threshold.
+	outcomes := []error{nil, fail, nil, nil, nil}
+	for _, err := range outcomes {
+		_ = cb.Execute(func() error { return err })
+	}
+	if cb.State() != StateClosed {
+		t.Fatalf("state at 1/5 failures = %v, want closed", cb.State())
+	}
+	_ = cb.Execute(func() error { return fail })
+	if cb.State() != StateOpen || len(transitions) != 1 {
+		t.Fatalf("state at 2/6 failures = %v, transitions %v", cb.State(), transitions)
+	}
+	if err := cb.Execute(func() error { return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Execute while open = %v", err)
+	}
+}
+
+func TestCircuitBreaker_MinimumCallsAndEviction(t *testing.T) {
+	t.Parallel()
+
+	cb := NewCircuitBreaker(CircuitBreakerConfig{Window: CircuitWindowCount, WindowSize: 4, MinimumCalls: 4})
+	fail := errors.New("fail")
+
+	for range 3 {
+		_ = cb.Execute(func() error { return fail })
+	}
+	if cb.State() != StateClosed {
+		t.Fatal("opened before MinimumCalls")
+	}
+	if cb.Failures() != 3 {
+		t.Fatalf("Failures = %d, want 3", cb.Failures())
+	}
+
+	// Successes push the old failures out of the window.
+	for range 4 {
+		cb.window.record(time.Now(), false, false)
+	}
+	if cb.Failures() != 0 {
+		t.Fatalf("Failures after eviction = %d, want 0", cb.Failures())
+	}
+}
+
+func TestCircuitBreaker_SlowCallRate(t *testing.T) {
+	t.Parallel()
+
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Window:                CircuitWindowTime,
+		WindowDuration:        time.Minute,
+		MinimumCalls:          2,
+		SlowCallDuration:      5 * time.Millisecond,
+		SlowCallRateThreshold: 0.5,
+		Timeout:               20 * time.Millisecond,
+	})
+
+	_ = cb.Execute(func() error { return nil })
+	_ = cb.Execute(func() error { time.Sleep(10 * time.Millisecond); return nil })
+	if cb.State() != StateOpen {
+		t.Fatalf("state with 1/2 slow calls = %v, want open", cb.State())
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("state after timeout = %v, want half-open", cb.State())
+	}
+	// A slow probe reopens the circuit.
+	_ = cb.Execute(func() error { time.Sleep(10 * time.Millisecond); return nil })
+	if cb.State() != StateOpen {
+		t.Fatalf("state after slow probe = %v, want open", cb.State())
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	_ = cb.Execute(func() error { return nil })
+	if cb.State() != StateClosed {
+		t.Fatalf("state after fast probe = %v, want closed", cb.State())
+	}
+	if calls, _, _ := cb.window.totals(time.Now()); calls != 0 {
+		t.Fatalf("window not reset on close: %d calls", calls)
+	}
+}
+
+func TestCallWindow_TimeBucketsExpire(t *testing.T) {
+	t.Parallel()
+
+	w := newCallWindow(CircuitWindowTime, 0, 10*time.Second)
+	start := time.Unix(1_700_000_000, 0)
+	w.record(start, true, false)
+	w.record(start.Add(5*time.Second), false, true)
+
+	if calls, failures, slow := w.totals(start.Add(9 * time.Second)); calls != 2 || failures != 1 || slow != 1 {
+		t.Fatalf("totals = %d, %d, %d", calls, failures, slow)
+	}
+	if calls, failures, _ := w.totals(start.Add(10 * time.Second)); calls != 1 || failures != 0 {
+		t.Fatalf("totals after first bucket expired = %d calls, %d failures", calls, failures)
+	}
+}
+
+func TestCircuitWindowText(t *testing.T) {
+	t.Parallel()
+
+	var w CircuitWindow
+	if err := w.UnmarshalText([]byte("time")); err != nil || w != CircuitWindowTime {
+		t.Fatalf("UnmarshalText time = %q, %v", w, err)
+	}
+	if err := w.UnmarshalText(nil); err != nil || w != CircuitWindowConsecutive {
+		t.Fatalf("empty UnmarshalText = %q, %v", w, err)
+	}
+	if err := w.UnmarshalText([]byte("rolling")); err == nil {
+		t.Fatal("UnmarshalText accepted an unknown window")
+	}
+	if text, err := CircuitWindowCount.MarshalText(); err != nil || string(text) != "count" {
+		t.Fatalf("MarshalText = %q, %v", text, err)
+	}
+}
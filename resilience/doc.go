@@ -3,7 +3,10 @@
 // This package includes:
 //   - CircuitBreaker: Prevents cascading failures by failing fast
 //   - Retry: Retries failed operations with exponential backoff
+//   - RetryBudget: Caps retries at a fraction of successful calls
+//   - Hedger: Fires speculative attempts for slow calls
 //   - Bulkhead: Limits concurrent access to isolate failures
+//   - AdaptiveLimiter: A bulkhead whose limit adapts to latency and errors (AIMD, Vegas, gradient)
 //   - RateLimiter: Controls request rate with token bucket algorithm
 //
 // These patterns can be combined for comprehensive resilience:
@@ -0,0 +1,177 @@
+package resilience
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// hedgeSamples is the number of recent latencies a Hedger keeps.
+const hedgeSamples = 256
+
+// HedgeConfig configures hedged requests.
+type HedgeConfig struct {
+	// Name identifies this hedger for metrics/logging.
+	Name string
+	// Delay is how long to wait for an attempt before firing a hedge. With
+	// Percentile set it is only used until MinSamples latencies are observed;
+	// 0 means no hedging until then.
+	Delay time.Duration
+	// Percentile, in (0, 1), derives the delay from observed latency, e.g.
+	// 0.95 hedges the slowest 5% of calls. 0 always uses Delay.
+	Percentile float64
+	// MinDelay is a floor for the percentile-derived delay.
+	MinDelay time.Duration
+	// MinSamples is the number of latencies needed before Percentile is used.
+	// Default: 20.
+	MinSamples int
+	// MaxHedges is the number of speculative attempts beyond the first.
+	// Default: 1.
+	MaxHedges int
+	// Budget, when set, is charged one token per hedge; a hedge the budget
+	// cannot cover is not sent.
+	Budget *RetryBudget
+	// OnHedge is called when a speculative attempt is fired. attempt is
+	// one-based and counts the original call.
+	OnHedge func(name string, attempt int)
+}
+
+// Hedger fires speculative duplicate attempts for calls that run longer than
+// usual and returns the first success, canceling the others. Only hedge
+// idempotent calls that are safe to run concurrently.
+type Hedger struct {
+	config HedgeConfig
+	now    func() time.Time
+
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	// delay caches the percentile delay; stale counts samples since.
+	delay time.Duration
+	stale int
+}
+
+// NewHedger creates a new hedger.
+func NewHedger(config HedgeConfig) *Hedger {
+	if config.MinSamples <= 0 {
+		config.MinSamples = 20
+	}
+	if config.MaxHedges <= 0 {
+		config.MaxHedges = 1
+	}
+	if config.Percentile >= 1 {
+		config.Percentile = 0
+	}
+	return &Hedger{
+		config:  config,
+		now:     time.Now,
+		samples: make([]time.Duration, 0, hedgeSamples),
+	}
+}
+
+// Delay returns how long the next call will wait before hedging. 0 means the
+// call will not be hedged.
+func (h *Hedger) Delay() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.config.Percentile <= 0 || len(h.samples) < h.config.MinSamples {
+		return h.config.Delay
+	}
+	if h.delay == 0 || h.stale >= hedgeSamples/16 {
+		sorted := slices.Clone(h.samples)
+		slices.Sort(sorted)
+		h.delay = max(sorted[int(h.config.Percentile*float64(len(sorted)))], h.config.MinDelay)
+		h.stale = 0
+	}
+	return h.delay
+}
+
+func (h *Hedger) observe(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.samples) < hedgeSamples {
+		h.samples = append(h.samples, latency)
+	} else {
+		h.samples[h.next] = latency
+		h.next = (h.next + 1) % hedgeSamples
+	}
+	h.stale++
+}
+
+type hedgeResult[T any] struct {
+	value   T
+	err     error
+	latency time.Duration
+}
+
+// Hedge runs fn and, if it has not returned within the hedger's delay, runs it
+// again concurrently, up to MaxHedges extra times. The first success wins and
+// the other attempts' contexts are canceled. An attempt that fails while no
+// other is in flight returns its error immediately; hedging does not retry.
+func Hedge[T any](ctx context.Context, h *Hedger, fn func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	delay := h.Delay()
+	if delay <= 0 {
+		// Unhedged calls still feed the latency samples, so a percentile
+		// hedger warms up on ordinary traffic.
+		start := h.now()
+		value, err := fn(ctx)
+		if err == nil {
+			h.observe(h.now().Sub(start))
+		}
+		return value, err
+	}
+
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult[T], h.config.MaxHedges+1)
+	launch := func() {
+		go func() {
+			start := h.now()
+			value, err := fn(hedgeCtx)
+			results <- hedgeResult[T]{value: value, err: err, latency: h.now().Sub(start)}
+		}()
+	}
+
+	launch()
+	attempts, pending := 1, 1
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	hedgeC := timer.C
+
+	var lastErr error
+	for {
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				h.observe(r.latency)
+				return r.value, nil
+			}
+			lastErr = r.err
+			if pending == 0 {
+				return zero, lastErr
+			}
+		case <-hedgeC:
+			if h.config.Budget != nil && !h.config.Budget.TryRetry() {
+				hedgeC = nil
+				continue
+			}
+			attempts++
+			pending++
+			if h.config.OnHedge != nil {
+				h.config.OnHedge(h.config.Name, attempts)
+			}
+			launch()
+			if attempts > h.config.MaxHedges {
+				hedgeC = nil
+			} else {
+				timer.Reset(delay)
+			}
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedge_SecondAttemptWinsAndCancelsFirst(t *testing.T) {
+	t.Parallel()
+
+	var hedges atomic.Int32
+	h := NewHedger(HedgeConfig{
+		Name:    "search",
+		Delay:   10 * time.Millisecond,
+		OnHedge: func(_ string, attempt int) { hedges.Add(int32(attempt)) },
+	})
+
+	var calls atomic.Int32
+	canceled := make(chan struct{})
+	got, err := Hedge(context.Background(), h, func(ctx context.Context) (string, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			close(canceled)
+			return "", ctx.Err()
+		}
+		return "hedge", nil
+	})
+	if err != nil || got != "hedge" {
+		t.Fatalf("Hedge = %q, %v", got, err)
+	}
+	if hedges.Load() != 2 {
+		t.Fatalf("OnHedge attempt sum = %d, want 2", hedges.Load())
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("losing attempt was not canceled")
+	}
+}
+
+func TestHedge_FastCallsAndErrors(t *testing.T) {
+	t.Parallel()
+
+	h := NewHedger(HedgeConfig{Delay: time.Second})
+	var calls atomic.Int32
+	if _, err := Hedge(context.Background(), h, func(context.Context) (int, error) {
+		calls.Add(1)
+		return 1, nil
+	}); err != nil || calls.Load() != 1 {
+		t.Fatalf("fast call: err=%v calls=%d", err, calls.Load())
+	}
+
+	failure := errors.New("bad request")
+	if _, err := Hedge(context.Background(), h, func(context.Context) (int, error) {
+		return 0, failure
+	}); !errors.Is(err, failure) {
+		t.Fatalf("err = %v, want the attempt's error without hedging", err)
+	}
+
+	// Without a delay or enough samples, calls run unhedged.
+	plain := NewHedger(HedgeConfig{Percentile: 0.9})
+	if plain.Delay() != 0 {
+		t.Fatalf("Delay before samples = %v, want 0", plain.Delay())
+	}
+}
+
+func TestHedger_PercentileDelay(t *testing.T) {
+	t.Parallel()
+
+	h := NewHedger(HedgeConfig{Percentile: 0.9, MinSamples: 10, MinDelay: 5 * time.Millisecond, Delay: time.Second})
+	for i := range 9 {
+		h.observe(time.Duration(i+1) * time.Millisecond)
+	}
+	if got := h.Delay(); got != time.Second {
+		t.Fatalf("Delay while warming up = %v, want the fixed Delay", got)
+	}
+	h.observe(100 * time.Millisecond)
+	if got := h.Delay(); got != 100*time.Millisecond {
+		t.Fatalf("p90 of 1..9ms,100ms = %v, want 100ms", got)
+	}
+
+	floor := NewHedger(HedgeConfig{Percentile: 0.5, MinSamples: 1, MinDelay: 5 * time.Millisecond})
+	floor.observe(time.Millisecond)
+	if got := floor.Delay(); got != 5*time.Millisecond {
+		t.Fatalf("Delay = %v, want MinDelay", got)
+	}
+}
+
+func TestHedge_PercentileWarmsUpThroughHedge(t *testing.T) {
+	t.Parallel()
+
+	var hedges atomic.Int32
+	h := NewHedger(HedgeConfig{
+		Percentile: 0.5,
+		MinSamples: 5,
+		OnHedge:    func(string, int) { hedges.Add(1) },
+	})
+	for range 5 {
+		if _, err := Hedge(context.Background(), h, func(context.Context) (int, error) {
+			time.Sleep(time.Millisecond)
+			return 1, nil
+		}); err != nil {
+			t.Fatalf("warm-up call: %v", err)
+		}
+	}
+	if hedges.Load() != 0 || h.Delay() <= 0 {
+		t.Fatalf("after warm-up: hedges = %d, Delay = %v; want no hedges and a percentile delay", hedges.Load(), h.Delay())
+	}
+
+	var calls atomic.Int32
+	got, err := Hedge(context.Background(), h, func(ctx context.Context) (string, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "hedge", nil
+	})
+	if err != nil || got != "hedge" || hedges.Load() != 1 {
+		t.Fatalf("slow call: Hedge = %q, %v with %d hedges, want the hedge to win", got, err, hedges.Load())
+	}
+}
+
+func TestHedge_BudgetDeniesHedge(t *testing.T) {
+	t.Parallel()
+
+	b := NewRetryBudget(RetryBudgetConfig{MinRetriesPerSecond: 1, Window: time.Second})
+	if !b.TryRetry() {
+		t.Fatal("reserve retry denied")
+	}
+	h := NewHedger(HedgeConfig{Delay: time.Millisecond, Budget: b})
+	var calls atomic.Int32
+	got, err := Hedge(context.Background(), h, func(context.Context) (int, error) {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return 7, nil
+	})
+	if err != nil || got != 7 || calls.Load() != 1 {
+		t.Fatalf("Hedge = %d, %v with %d calls, want no hedge", got, err, calls.Load())
+	}
+}
+
+func TestExecutePolicy_HedgeAndRetryBudget(t *testing.T) {
+	t.Parallel()
+
+	var retries atomic.Int32
+	policy := NewPolicy().
+		WithRetryBudget(RetryBudgetConfig{Ratio: 0.1, MinRetriesPerSecond: 1, Window: time.Second}).
+		WithHedge(HedgeConfig{Delay: 5 * time.Millisecond}).
+		WithRetry(RetryConfig{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			Strategy:       ConstantBackoff,
+			OnRetry:        func(int, error, time.Duration) { retries.Add(1) },
+		})
+
+	// The first attempt stalls, the hedge succeeds, and the budget pays for it.
+	var calls atomic.Int32
+	got, err := Execute(context.Background(), policy, func(ctx context.Context) (int, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return 2, nil
+	})
+	if err != nil || got != 2 {
+		t.Fatalf("Execute = %d, %v", got, err)
+	}
+
+	// The hedge spent the reserve, so a failing call is not retried.
+	failure := errors.New("down")
+	if _, err := Execute(context.Background(), policy, func(context.Context) (int, error) {
+		return 0, failure
+	}); !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Fatalf("err = %v, want ErrRetryBudgetExhausted", err)
+	}
+	if retries.Load() != 0 {
+		t.Fatalf("retries = %d, want 0", retries.Load())
+	}
+}
@@ -1,14 +1,35 @@
 package resilience
 
 import (
+	"context"
+	"math"
 	"sync"
 	"time"
 )
 
-// KeyedRateLimiterConfig configures a keyed token-bucket limiter.
+// KeyedRateLimiterConfig configures a keyed rate limiter.
 type KeyedRateLimiterConfig struct {
 	CleanupInterval time.Duration
 	BucketTTL       time.Duration
+
+	// LazyCleanup skips the background cleanup goroutine: idle buckets are
+	// then only evicted by Take calls, at most once per CleanupInterval, and
+	// Stop need not be called.
+	LazyCleanup bool
+
+	// Algorithm selects how requests are accounted. Default: token_bucket.
+	Algorithm RateLimitAlgorithm
+
+	// Store keeps bucket state outside the process so every replica shares one
+	// budget per key. Nil keeps buckets in memory; CleanupInterval and
+	// BucketTTL only apply to in-memory buckets.
+	Store RateLimitStore
+
+	// FailOpen allows requests when Store fails. By default they are denied.
+	FailOpen bool
+
+	// OnStoreError is called with every Store failure, e.g. for logging.
+	OnStoreError func(key string, err error)
 }
 
 // RateLimitDecision captures the outcome of a rate-limit check.
@@ -21,6 +42,7 @@ type RateLimitDecision struct {
 }
 
 type keyedBucket struct {
+	algorithm  RateLimitAlgorithm
 	limit      int
 	interval   time.Duration
 	tokens     float64
@@ -28,9 +50,17 @@ type keyedBucket struct {
 	refillRate float64
 	lastRefill time.Time
 	lastAccess time.Time
+
+	// tat is the GCRA theoretical arrival time.
+	tat time.Time
+
+	// windowStart, prevCount and currCount track the sliding window.
+	windowStart time.Time
+	prevCount   int
+	currCount   int
 }
 
-// KeyedRateLimiter manages per-key token buckets.
+// KeyedRateLimiter manages per-key rate-limit buckets.
 type KeyedRateLimiter struct {
 	cfg         KeyedRateLimiterConfig
 	nowFunc     func() time.Time
@@ -50,6 +80,9 @@ func NewKeyedRateLimiter(cfg KeyedRateLimiterConfig) *KeyedRateLimiter {
 	if cfg.BucketTTL <= 0 {
 		cfg.BucketTTL = 10 * time.Minute
 	}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = RateLimitTokenBucket
+	}
 
 	now := time.Now()
 	rl := &KeyedRateLimiter{
@@ -60,15 +93,34 @@ func NewKeyedRateLimiter(cfg KeyedRateLimiterConfig) *KeyedRateLimiter {
 		stopCh:      make(chan struct{}),
 		stoppedCh:   make(chan struct{}),
 	}
-	go rl.runCleanup()
+	if cfg.LazyCleanup {
+		close(rl.stoppedCh)
+	} else {
+		go rl.runCleanup()
+	}
 	return rl
 }
 
-// Allow applies a token-bucket limit for the given key and interval.
+// Allow applies limit requests per interval for the given key. Store failures
+// resolve according to FailOpen; use Take to observe them.
 func (rl *KeyedRateLimiter) Allow(key string, limit int, interval time.Duration) RateLimitDecision {
-	now := rl.nowFunc()
+	decision, _ := rl.Take(context.Background(), key, limit, interval)
+	return decision
+}
+
+// Take is Allow with a context for the Store round trip. On a Store failure
+// it returns the FailOpen decision together with the error.
+func (rl *KeyedRateLimiter) Take(ctx context.Context, key string, limit int, interval time.Duration) (RateLimitDecision, error) {
 	normalizedLimit, normalizedInterval := normalizeKeyedLimit(limit, interval)
+	if rl.cfg.Store != nil {
+		return rl.takeFromStore(ctx, key, RateLimit{
+			Limit:     normalizedLimit,
+			Interval:  normalizedInterval,
+			Algorithm: rl.cfg.Algorithm,
+		})
+	}
 
+	now := rl.nowFunc()
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
@@ -76,13 +128,30 @@ func (rl *KeyedRateLimiter) Allow(key string, limit int, interval time.Duration)
 
 	bucket, ok := rl.buckets[key]
 	if !ok || bucket.limit != normalizedLimit || bucket.interval != normalizedInterval {
-		bucket = newKeyedBucket(normalizedLimit, normalizedInterval, now)
+		bucket = newKeyedBucket(rl.cfg.Algorithm, normalizedLimit, normalizedInterval, now)
 		rl.buckets[key] = bucket
 	}
 
 	decision := bucket.allow(now)
 	decision.Limit = normalizedLimit
-	return decision
+	return decision, nil
+}
+
+func (rl *KeyedRateLimiter) takeFromStore(ctx context.Context, key string, limit RateLimit) (RateLimitDecision, error) {
+	decision, err := rl.cfg.Store.Take(ctx, key, limit)
+	if err == nil {
+		decision.Limit = limit.Limit
+		return decision, nil
+	}
+	if rl.cfg.OnStoreError != nil {
+		rl.cfg.OnStoreError(key, err)
+	}
+	now := rl.nowFunc()
+	if rl.cfg.FailOpen {
+		return RateLimitDecision{Allowed: true, Limit: limit.Limit, Remaining: limit.Limit - 1, ResetAt: now}, err
+	}
+	retryAfter := limit.emissionInterval()
+	return RateLimitDecision{Limit: limit.Limit, RetryAfter: retryAfter, ResetAt: now.Add(retryAfter)}, err
 }
 
 // Stop releases the background cleanup loop. Existing buckets remain usable for direct Allow calls.
@@ -135,9 +204,10 @@ func (rl *KeyedRateLimiter) cleanupLocked(now time.Time) {
 	rl.lastCleanup = now
 }
 
-func newKeyedBucket(limit int, interval time.Duration, now time.Time) *keyedBucket {
+func newKeyedBucket(algorithm RateLimitAlgorithm, limit int, interval time.Duration, now time.Time) *keyedBucket {
 	maxTokens := float64(limit)
 	return &keyedBucket{
+		algorithm:  algorithm,
 		limit:      limit,
 		interval:   interval,
 		tokens:     maxTokens,
@@ -149,10 +219,21 @@ func newKeyedBucket(limit int, interval time.Duration, now time.Time) *keyedBuck
 }
 
 func (b *keyedBucket) allow(now time.Time) RateLimitDecision {
+	b.lastAccess = now
+	switch b.algorithm {
+	case RateLimitGCRA:
+		return b.allowGCRA(now)
+	case RateLimitSlidingWindow:
+		return b.allowSlidingWindow(now)
+	default:
+		return b.allowTokenBucket(now)
+	}
+}
+
+func (b *keyedBucket) allowTokenBucket(now time.Time) RateLimitDecision {
 	elapsed := now.Sub(b.lastRefill).Seconds()
 	b.tokens = minFloat(b.maxTokens, b.tokens+elapsed*b.refillRate)
 	b.lastRefill = now
-	b.lastAccess = now
 
 	if b.tokens >= 1 {
 		b.tokens--
@@ -174,6 +255,63 @@ func (b *keyedBucket) allow(now time.Time) RateLimitDecision {
 	}
 }
 
+// allowGCRA spaces requests one emission interval (interval/limit) apart and
+// tolerates a burst of limit requests.
+func (b *keyedBucket) allowGCRA(now time.Time) RateLimitDecision {
+	emission := b.interval / time.Duration(b.limit)
+	tat := b.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	allowAt := tat.Add(emission - b.interval)
+	if now.Before(allowAt) {
+		return RateLimitDecision{RetryAfter: allowAt.Sub(now), ResetAt: tat}
+	}
+	b.tat = tat.Add(emission)
+	return RateLimitDecision{
+		Allowed:   true,
+		Remaining: int((b.interval - b.tat.Sub(now)) / emission),
+		ResetAt:   b.tat,
+	}
+}
+
+// allowSlidingWindow weights the previous fixed window's count by how much of
+// it still overlaps the sliding interval.
+func (b *keyedBucket) allowSlidingWindow(now time.Time) RateLimitDecision {
+	windowStart := now.Truncate(b.interval)
+	if !windowStart.Equal(b.windowStart) {
+		if windowStart.Sub(b.windowStart) == b.interval {
+			b.prevCount = b.currCount
+		} else {
+			b.prevCount = 0
+		}
+		b.currCount = 0
+		b.windowStart = windowStart
+	}
+	elapsed := now.Sub(windowStart)
+	estimate := float64(b.prevCount)*(1-elapsed.Seconds()/b.interval.Seconds()) + float64(b.currCount)
+	resetAt := windowStart.Add(2 * b.interval)
+	if estimate+1 > float64(b.limit) {
+		var retryAfter time.Duration
+		if b.currCount+1 > b.limit {
+			// Wait into the next window, where this window's count is the
+			// weighted previous count.
+			weight := float64(b.limit-1) / float64(b.currCount)
+			retryAfter = windowStart.Add(b.interval).Sub(now) + time.Duration((1-weight)*float64(b.interval))
+		} else {
+			weight := float64(b.limit-1-b.currCount) / float64(b.prevCount)
+			retryAfter = time.Duration((1-weight)*float64(b.interval)) - elapsed
+		}
+		return RateLimitDecision{RetryAfter: max(retryAfter, time.Millisecond), ResetAt: resetAt}
+	}
+	b.currCount++
+	return RateLimitDecision{
+		Allowed:   true,
+		Remaining: max(0, int(math.Floor(float64(b.limit)-estimate-1))),
+		ResetAt:   resetAt,
+	}
+}
+
 func (b *keyedBucket) resetAfter(remaining int) time.Duration {
 	used := b.limit - remaining
 	if used <= 0 {
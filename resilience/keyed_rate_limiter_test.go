@@ -1,6 +1,8 @@
 package resilience
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -58,3 +60,143 @@ func TestKeyedRateLimiter_DeniesWhenExhausted(t *testing.T) {
 		t.Fatalf("expected positive RetryAfter, got %v", d.RetryAfter)
 	}
 }
+
+func TestKeyedRateLimiter_GCRA(t *testing.T) {
+	base := time.Unix(0, 0)
+	now := base
+	rl := NewKeyedRateLimiter(KeyedRateLimiterConfig{Algorithm: RateLimitGCRA})
+	rl.nowFunc = func() time.Time { return now }
+	defer rl.Stop()
+
+	for i := range 3 {
+		d := rl.Allow("k", 3, 3*time.Second)
+		if !d.Allowed || d.Remaining != 2-i {
+			t.Fatalf("request %d = %+v", i, d)
+		}
+	}
+	d := rl.Allow("k", 3, 3*time.Second)
+	if d.Allowed || d.RetryAfter != time.Second {
+		t.Fatalf("burst overflow = %+v, want RetryAfter 1s", d)
+	}
+	if !d.ResetAt.Equal(base.Add(3 * time.Second)) {
+		t.Fatalf("ResetAt = %v", d.ResetAt)
+	}
+
+	now = base.Add(time.Second)
+	if d := rl.Allow("k", 3, 3*time.Second); !d.Allowed || d.Remaining != 0 {
+		t.Fatalf("after one emission interval = %+v", d)
+	}
+}
+
+func TestKeyedRateLimiter_SlidingWindow(t *testing.T) {
+	base := time.Unix(60, 0)
+	now := base
+	rl := NewKeyedRateLimiter(KeyedRateLimiterConfig{Algorithm: RateLimitSlidingWindow})
+	rl.nowFunc = func() time.Time { return now }
+	defer rl.Stop()
+
+	for i := range 4 {
+		if d := rl.Allow("k", 4, time.Minute); !d.Allowed || d.Remaining != 3-i {
+			t.Fatalf("request %d = %+v", i, d)
+		}
+	}
+	if d := rl.Allow("k", 4, time.Minute); d.Allowed || d.RetryAfter != 75*time.Second {
+		t.Fatalf("exhausted window = %+v, want RetryAfter 75s", d)
+	}
+
+	// A quarter into the next window, 3 of the previous 4 still count.
+	now = base.Add(time.Minute + 15*time.Second)
+	if d := rl.Allow("k", 4, time.Minute); !d.Allowed || d.Remaining != 0 {
+		t.Fatalf("request with weighted estimate 3 = %+v", d)
+	}
+	d := rl.Allow("k", 4, time.Minute)
+	if d.Allowed {
+		t.Fatalf("request with weighted estimate 4 allowed: %+v", d)
+	}
+	if d.RetryAfter != 15*time.Second {
+		t.Fatalf("RetryAfter = %v, want 15s", d.RetryAfter)
+	}
+	now = now.Add(d.RetryAfter)
+	if d := rl.Allow("k", 4, time.Minute); !d.Allowed {
+		t.Fatalf("request after RetryAfter denied: %+v", d)
+	}
+
+	// A gap longer than one window forgets the history entirely.
+	now = base.Add(10 * time.Minute)
+	if d := rl.Allow("k", 4, time.Minute); !d.Allowed || d.Remaining != 3 {
+		t.Fatalf("after idle gap = %+v", d)
+	}
+}
+
+func TestKeyedRateLimiter_DelegatesToStore(t *testing.T) {
+	store := &stubRateLimitStore{decision: RateLimitDecision{Allowed: true, Remaining: 4}}
+	rl := NewKeyedRateLimiter(KeyedRateLimiterConfig{Store: store, Algorithm: RateLimitGCRA})
+	defer rl.Stop()
+
+	d, err := rl.Take(context.Background(), "k", 0, 0)
+	if err != nil || !d.Allowed || d.Limit != 1 || d.Remaining != 4 {
+		t.Fatalf("Take = %+v, %v", d, err)
+	}
+	if store.got != (RateLimit{Limit: 1, Interval: time.Minute, Algorithm: RateLimitGCRA}) {
+		t.Fatalf("store received %+v", store.got)
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if len(rl.buckets) != 0 {
+		t.Fatal("store-backed limiter created in-memory buckets")
+	}
+}
+
+func TestKeyedRateLimiter_StoreFailurePolicy(t *testing.T) {
+	storeErr := errors.New("redis down")
+	for _, failOpen := range []bool{false, true} {
+		var reported error
+		rl := NewKeyedRateLimiter(KeyedRateLimiterConfig{
+			Store:        &stubRateLimitStore{err: storeErr},
+			FailOpen:     failOpen,
+			OnStoreError: func(_ string, err error) { reported = err },
+		})
+		d, err := rl.Take(context.Background(), "k", 10, time.Second)
+		rl.Stop()
+		if !errors.Is(err, storeErr) || !errors.Is(reported, storeErr) {
+			t.Fatalf("failOpen=%v: err=%v reported=%v", failOpen, err, reported)
+		}
+		if d.Allowed != failOpen {
+			t.Fatalf("failOpen=%v: Allowed=%v", failOpen, d.Allowed)
+		}
+		if !failOpen && d.RetryAfter != 100*time.Millisecond {
+			t.Fatalf("fail-closed RetryAfter = %v", d.RetryAfter)
+		}
+		if allowed := rl.Allow("k", 10, time.Second).Allowed; allowed != failOpen {
+			t.Fatalf("failOpen=%v: Allow=%v", failOpen, allowed)
+		}
+	}
+}
+
+type stubRateLimitStore struct {
+	decision RateLimitDecision
+	err      error
+	got      RateLimit
+}
+
+func (s *stubRateLimitStore) Take(_ context.Context, _ string, limit RateLimit) (RateLimitDecision, error) {
+	s.got = limit
+	return s.decision, s.err
+}
+
+func TestKeyedRateLimiter_LazyCleanup(t *testing.T) {
+	rl := NewKeyedRateLimiter(KeyedRateLimiterConfig{LazyCleanup: true, CleanupInterval: time.Minute, BucketTTL: time.Minute})
+	now := time.Now()
+	rl.nowFunc = func() time.Time { return now }
+	rl.Allow("idle", 1, time.Second)
+
+	now = now.Add(2 * time.Minute)
+	rl.Allow("active", 1, time.Second)
+	rl.mu.Lock()
+	_, kept := rl.buckets["idle"]
+	rl.mu.Unlock()
+	if kept {
+		t.Fatal("Take did not evict the idle bucket")
+	}
+	rl.Stop() // must not block without a cleanup goroutine
+}
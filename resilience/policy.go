@@ -19,17 +19,23 @@
 
 // Policy composes resilience primitives into a single reusable execution policy.
 type Policy struct {
-	Retry          *RetryConfig
-	CircuitBreaker *CircuitBreakerConfig
-	Bulkhead       *BulkheadConfig
-	RateLimiter    *RateLimiterConfig
-	Timeout        time.Duration
-	timeoutMode    TimeoutMode
-
-	once sync.Once
-	cb   *CircuitBreaker
-	bh   *Bulkhead
-	rl   *RateLimiter
+	Retry           *RetryConfig
+	RetryBudget     *RetryBudgetConfig
+	Hedge           *HedgeConfig
+	CircuitBreaker  *CircuitBreakerConfig
+	Bulkhead        *BulkheadConfig
+	AdaptiveLimiter *AdaptiveLimiterConfig
+	RateLimiter     *RateLimiterConfig
+	Timeout         time.Duration
+	timeoutMode     TimeoutMode
+
+	once   sync.Once
+	budget *RetryBudget
+	hd     *Hedger
+	cb     *CircuitBreaker
+	bh     *Bulkhead
+	al     *AdaptiveLimiter
+	rl     *RateLimiter
 }
 
 // NewPolicy creates an empty policy that can be configured fluently.
@@ -43,6 +49,20 @@ func (p *Policy) WithRetry(cfg RetryConfig) *Policy {
 	return p
 }
 
+// WithRetryBudget caps retries and hedges across all executions of the policy
+// at a fraction of its successful calls.
+func (p *Policy) WithRetryBudget(cfg RetryBudgetConfig) *Policy {
+	p.RetryBudget = &cfg
+	return p
+}
+
+// WithHedge configures hedged requests. The wrapped function must be
+// idempotent and safe to call concurrently.
+func (p *Policy) WithHedge(cfg HedgeConfig) *Policy {
+	p.Hedge = &cfg
+	return p
+}
+
 // WithCircuitBreaker configures circuit breaker behavior.
 func (p *Policy) WithCircuitBreaker(cfg CircuitBreakerConfig) *Policy {
 	p.CircuitBreaker = &cfg
@@ -55,6 +75,12 @@ func (p *Policy) WithBulkhead(cfg BulkheadConfig) *Policy {
 	return p
 }
 
+// WithAdaptiveLimiter configures adaptive concurrency limiting behavior.
+func (p *Policy) WithAdaptiveLimiter(cfg AdaptiveLimiterConfig) *Policy {
+	p.AdaptiveLimiter = &cfg
+	return p
+}
+
 // WithRateLimiter configures rate limiting behavior.
 func (p *Policy) WithRateLimiter(cfg RateLimiterConfig) *Policy {
 	p.RateLimiter = &cfg
@@ -80,12 +106,25 @@ func (p *Policy) init() {
 		return
 	}
 	p.once.Do(func() {
+		if p.RetryBudget != nil {
+			p.budget = NewRetryBudget(*p.RetryBudget)
+		}
+		if p.Hedge != nil {
+			hedgeCfg := *p.Hedge
+			if hedgeCfg.Budget == nil {
+				hedgeCfg.Budget = p.budget
+			}
+			p.hd = NewHedger(hedgeCfg)
+		}
 		if p.CircuitBreaker != nil {
 			p.cb = NewCircuitBreaker(*p.CircuitBreaker)
 		}
 		if p.Bulkhead != nil {
 			p.bh = NewBulkhead(*p.Bulkhead)
 		}
+		if p.AdaptiveLimiter != nil {
+			p.al = NewAdaptiveLimiter(*p.AdaptiveLimiter)
+		}
 		if p.RateLimiter != nil {
 			p.rl = NewRateLimiter(*p.RateLimiter)
 		}
@@ -95,7 +134,10 @@ func (p *Policy) init() {
 // Execute runs fn through the configured resilience stack.
 //
 // Execution order from outermost to innermost:
-// rate limiter → bulkhead → circuit breaker → timeout → retry → fn.
+// rate limiter → bulkhead → adaptive limiter → circuit breaker → timeout → retry → hedge → fn.
+//
+// A retry budget is shared by every execution of the policy: retries and
+// hedges draw from it and successful executions refill it.
 func Execute[T any](ctx context.Context, p *Policy, fn func(ctx context.Context) (T, error)) (T, error) {
 	if p == nil {
 		return fn(ctx)
@@ -111,14 +153,33 @@ func Execute[T any](ctx context.Context, p *Policy, fn func(ctx context.Context)
 	}
 
 	call := fn
+	if p.hd != nil {
+		inner := call
+		call = func(callCtx context.Context) (T, error) {
+			return Hedge(callCtx, p.hd, inner)
+		}
+	}
 	if p.Retry != nil {
 		retryCfg := *p.Retry
+		if retryCfg.Budget == nil {
+			retryCfg.Budget = p.budget
+		}
 		inner := call
 		call = func(callCtx context.Context) (T, error) {
 			return Retry(callCtx, retryCfg, func() (T, error) {
 				return inner(callCtx)
 			})
 		}
+	} else if p.budget != nil {
+		// Without Retry nothing else credits the budget for hedges to draw on.
+		inner := call
+		call = func(callCtx context.Context) (T, error) {
+			result, err := inner(callCtx)
+			if err == nil {
+				p.budget.RecordSuccess()
+			}
+			return result, err
+		}
 	}
 	if p.Timeout > 0 {
 		inner := call
@@ -148,6 +209,14 @@ func Execute[T any](ctx context.Context, p *Policy, fn func(ctx context.Context)
 			return result, resultErr
 		}
 	}
+	if p.al != nil {
+		inner := call
+		call = func(callCtx context.Context) (T, error) {
+			return ExecuteAdaptive(callCtx, p.al, func() (T, error) {
+				return inner(callCtx)
+			})
+		}
+	}
 	if p.bh != nil {
 		inner := call
 		return ExecuteWithResult(ctx, p.bh, func() (T, error) {
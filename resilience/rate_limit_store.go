@@ -0,0 +1,87 @@
+package resilience
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"time"
+)
+
+var (
+	_ encoding.TextMarshaler   = RateLimitAlgorithm("")
+	_ encoding.TextUnmarshaler = (*RateLimitAlgorithm)(nil)
+)
+
+// RateLimitAlgorithm selects how a KeyedRateLimiter accounts for requests.
+// An unrecognized value behaves as RateLimitTokenBucket.
+type RateLimitAlgorithm string
+
+const (
+	// RateLimitTokenBucket refills limit tokens evenly over the interval and
+	// allows bursts up to limit.
+	RateLimitTokenBucket RateLimitAlgorithm = "token_bucket"
+	// RateLimitGCRA is the generic cell rate algorithm: it keeps a single
+	// timestamp per key and behaves like a token bucket without float drift.
+	RateLimitGCRA RateLimitAlgorithm = "gcra"
+	// RateLimitSlidingWindow approximates a sliding log by weighting the
+	// previous fixed window's count, smoothing bursts at window boundaries.
+	RateLimitSlidingWindow RateLimitAlgorithm = "sliding_window"
+)
+
+// MarshalText serializes an algorithm for config encoders.
+func (a RateLimitAlgorithm) MarshalText() ([]byte, error) {
+	algorithm := a
+	if algorithm == "" {
+		algorithm = RateLimitTokenBucket
+	}
+	if !algorithm.valid() {
+		return nil, invalidRateLimitAlgorithm(algorithm)
+	}
+	return []byte(algorithm), nil
+}
+
+// UnmarshalText parses an algorithm from config text.
+func (a *RateLimitAlgorithm) UnmarshalText(text []byte) error {
+	algorithm := RateLimitAlgorithm(text)
+	if algorithm == "" {
+		*a = RateLimitTokenBucket
+		return nil
+	}
+	if !algorithm.valid() {
+		return invalidRateLimitAlgorithm(algorithm)
+	}
+	*a = algorithm
+	return nil
+}
+
+func (a RateLimitAlgorithm) valid() bool {
+	switch a {
+	case RateLimitTokenBucket, RateLimitGCRA, RateLimitSlidingWindow:
+		return true
+	default:
+		return false
+	}
+}
+
+func invalidRateLimitAlgorithm(algorithm RateLimitAlgorithm) error {
+	return fmt.Errorf("rate limit algorithm %q: must be one of token_bucket, gcra, sliding_window", string(algorithm))
+}
+
+// RateLimit is one limit check: Limit requests per Interval under Algorithm.
+type RateLimit struct {
+	Limit     int
+	Interval  time.Duration
+	Algorithm RateLimitAlgorithm
+}
+
+// emissionInterval is the time one request's budget takes to replenish.
+func (l RateLimit) emissionInterval() time.Duration {
+	return l.Interval / time.Duration(l.Limit)
+}
+
+// RateLimitStore holds keyed limiter state outside the process, so replicas
+// share one budget per key. Take must check and consume one request
+// atomically and report the outcome; KeyedRateLimiter fills in Limit.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit RateLimit) (RateLimitDecision, error)
+}
@@ -0,0 +1,26 @@
+package resilience
+
+import (
+	"testing"
+)
+
+func TestRateLimitAlgorithmText(t *testing.T) {
+	t.Parallel()
+
+	var a RateLimitAlgorithm
+	if err := a.UnmarshalText(nil); err != nil || a != RateLimitTokenBucket {
+		t.Fatalf("empty UnmarshalText = %q, %v", a, err)
+	}
+	if err := a.UnmarshalText([]byte("gcra")); err != nil || a != RateLimitGCRA {
+		t.Fatalf("UnmarshalText gcra = %q, %v", a, err)
+	}
+	if err := a.UnmarshalText([]byte("leaky")); err == nil {
+		t.Fatal("UnmarshalText accepted an unknown algorithm")
+	}
+	if text, err := RateLimitAlgorithm("").MarshalText(); err != nil || string(text) != "token_bucket" {
+		t.Fatalf("empty MarshalText = %q, %v", text, err)
+	}
+	if _, err := RateLimitAlgorithm("leaky").MarshalText(); err == nil {
+		t.Fatal("MarshalText accepted an unknown algorithm")
+	}
+}
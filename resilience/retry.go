@@ -3,6 +3,7 @@
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math"
 	"math/rand/v2"
 	"time"
@@ -47,6 +48,10 @@ type RetryConfig struct {
 	RetryIf func(error) bool
 	// OnRetry is called before each retry.
 	OnRetry func(attempt int, err error, backoff time.Duration)
+	// Budget, when set, is charged one token per retry and credited on
+	// success. A retry the budget cannot cover is skipped and the last error
+	// is returned wrapped in ErrRetryBudgetExhausted.
+	Budget *RetryBudget
 }
 
 // DefaultRetryConfig returns sensible defaults.
@@ -85,6 +90,9 @@ func Retry[T any](ctx context.Context, cfg RetryConfig, fn func() (T, error)) (T
 
 		result, err := fn()
 		if err == nil {
+			if cfg.Budget != nil {
+				cfg.Budget.RecordSuccess()
+			}
 			return result, nil
 		}
 
@@ -100,6 +108,10 @@ func Retry[T any](ctx context.Context, cfg RetryConfig, fn func() (T, error)) (T
 			break
 		}
 
+		if cfg.Budget != nil && !cfg.Budget.TryRetry() {
+			return zero, fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+		}
+
 		backoff := calculateBackoff(attempt, cfg)
 
 		if cfg.OnRetry != nil {
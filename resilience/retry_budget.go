@@ -0,0 +1,136 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRetryBudgetExhausted is returned, wrapping the last attempt's error, when
+// a retry is skipped because the shared retry budget is spent.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// budgetBuckets is the number of slices a RetryBudget window is split into.
+const budgetBuckets = 10
+
+// RetryBudgetConfig configures a retry budget.
+type RetryBudgetConfig struct {
+	// Name identifies this budget for metrics/logging.
+	Name string
+	// Ratio is the number of retries allowed per successful call within
+	// Window, e.g. 0.1 allows one retry per ten successes. Default: 0.1.
+	Ratio float64
+	// MinRetriesPerSecond is a floor that lets a trickle of retries through
+	// when there are few or no successes. Default: 10.
+	MinRetriesPerSecond int
+	// Window is how far back successes and retries are counted. Default: 10s.
+	Window time.Duration
+	// OnExhausted is called when a retry or hedge is denied.
+	OnExhausted func(name string)
+}
+
+// DefaultRetryBudgetConfig returns sensible defaults.
+func DefaultRetryBudgetConfig(name string) RetryBudgetConfig {
+	return RetryBudgetConfig{
+		Name:                name,
+		Ratio:               0.1,
+		MinRetriesPerSecond: 10,
+		Window:              10 * time.Second,
+	}
+}
+
+// RetryBudget caps retries at a fraction of successful calls so that, during
+// an outage, callers stop multiplying load on the failing dependency. Share
+// one budget between every RetryConfig and HedgeConfig that calls the same
+// dependency. It is safe for concurrent use.
+type RetryBudget struct {
+	config  RetryBudgetConfig
+	now     func() time.Time
+	reserve float64
+
+	mu      sync.Mutex
+	buckets [budgetBuckets]budgetBucket
+}
+
+// budgetBucket counts one slice of the budget window.
+type budgetBucket struct {
+	epoch     int64
+	successes int
+	retries   int
+}
+
+// NewRetryBudget creates a new retry budget.
+func NewRetryBudget(config RetryBudgetConfig) *RetryBudget {
+	defaults := DefaultRetryBudgetConfig(config.Name)
+	if config.Ratio <= 0 {
+		config.Ratio = defaults.Ratio
+	}
+	if config.MinRetriesPerSecond <= 0 {
+		config.MinRetriesPerSecond = defaults.MinRetriesPerSecond
+	}
+	if config.Window < budgetBuckets {
+		config.Window = defaults.Window
+	}
+	return &RetryBudget{
+		config:  config,
+		now:     time.Now,
+		reserve: float64(config.MinRetriesPerSecond) * config.Window.Seconds(),
+	}
+}
+
+// RecordSuccess deposits a successful call into the budget.
+func (b *RetryBudget) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current().successes++
+}
+
+// TryRetry withdraws one retry from the budget. It returns false, and leaves
+// the budget unchanged, if the retry would exceed it.
+func (b *RetryBudget) TryRetry() bool {
+	b.mu.Lock()
+	ok := b.remainingLocked() >= 1
+	if ok {
+		b.current().retries++
+	}
+	b.mu.Unlock()
+
+	if !ok && b.config.OnExhausted != nil {
+		b.config.OnExhausted(b.config.Name)
+	}
+	return ok
+}
+
+// Remaining returns the number of retries currently available.
+func (b *RetryBudget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return max(0, int(b.remainingLocked()))
+}
+
+func (b *RetryBudget) remainingLocked() float64 {
+	epoch := b.epoch()
+	var successes, retries int
+	for _, bucket := range b.buckets {
+		if epoch-bucket.epoch < budgetBuckets {
+			successes += bucket.successes
+			retries += bucket.retries
+		}
+	}
+	return b.config.Ratio*float64(successes) + b.reserve - float64(retries)
+}
+
+// current returns the bucket for now, clearing it if it belongs to an older
+// slice of the window.
+func (b *RetryBudget) current() *budgetBucket {
+	epoch := b.epoch()
+	bucket := &b.buckets[epoch%budgetBuckets]
+	if bucket.epoch != epoch {
+		*bucket = budgetBucket{epoch: epoch}
+	}
+	return bucket
+}
+
+func (b *RetryBudget) epoch() int64 {
+	return b.now().UnixNano() / int64(b.config.Window/budgetBuckets)
+}
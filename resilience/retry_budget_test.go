@@ -0,0 +1,84 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryBudget_RatioAndReserve(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	var exhausted atomic.Int32
+	b := NewRetryBudget(RetryBudgetConfig{
+		Name:                "db",
+		Ratio:               0.5,
+		MinRetriesPerSecond: 1,
+		Window:              time.Second,
+		OnExhausted:         func(string) { exhausted.Add(1) },
+	})
+	b.now = func() time.Time { return now }
+
+	// The reserve alone covers one retry per second.
+	if !b.TryRetry() || b.TryRetry() {
+		t.Fatal("expected exactly the reserve retry without successes")
+	}
+	for range 4 {
+		b.RecordSuccess()
+	}
+	if got := b.Remaining(); got != 2 {
+		t.Fatalf("Remaining after 4 successes = %d, want 2", got)
+	}
+	if !b.TryRetry() || !b.TryRetry() || b.TryRetry() {
+		t.Fatal("expected two retries funded by successes")
+	}
+	if exhausted.Load() != 2 {
+		t.Fatalf("OnExhausted calls = %d, want 2", exhausted.Load())
+	}
+
+	// Once the window has passed, old successes and retries no longer count.
+	now = now.Add(time.Second)
+	if got := b.Remaining(); got != 1 {
+		t.Fatalf("Remaining in a new window = %d, want the reserve", got)
+	}
+}
+
+func TestRetry_BudgetExhausted(t *testing.T) {
+	t.Parallel()
+
+	b := NewRetryBudget(RetryBudgetConfig{Ratio: 0.1, MinRetriesPerSecond: 1, Window: time.Second})
+	failure := errors.New("unavailable")
+	var calls atomic.Int32
+	cfg := RetryConfig{MaxAttempts: 5, InitialBackoff: time.Millisecond, Strategy: ConstantBackoff, Budget: b}
+
+	_, err := Retry(context.Background(), cfg, func() (int, error) {
+		calls.Add(1)
+		return 0, failure
+	})
+	if !errors.Is(err, ErrRetryBudgetExhausted) || !errors.Is(err, failure) {
+		t.Fatalf("err = %v, want budget exhaustion wrapping the last error", err)
+	}
+	// One reserve retry, then the budget stops further attempts.
+	if calls.Load() != 2 {
+		t.Fatalf("calls = %d, want 2", calls.Load())
+	}
+
+	if got, err := Retry(context.Background(), cfg, func() (int, error) { return 1, nil }); err != nil || got != 1 {
+		t.Fatalf("Retry = %d, %v", got, err)
+	}
+}
+
+func TestNewRetryBudget_Defaults(t *testing.T) {
+	t.Parallel()
+
+	b := NewRetryBudget(RetryBudgetConfig{})
+	if b.config.Ratio != 0.1 || b.config.MinRetriesPerSecond != 10 || b.config.Window != 10*time.Second {
+		t.Fatalf("config = %+v", b.config)
+	}
+	if b.Remaining() != 100 {
+		t.Fatalf("Remaining = %d, want 100", b.Remaining())
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"net/http"
@@ -30,6 +31,19 @@ type RateLimitConfig struct {
 
 	// BucketTTL is how long an unused bucket survives before eviction. Default: 10 minutes.
 	BucketTTL time.Duration
+
+	// Algorithm selects the limiting algorithm. Default: token_bucket.
+	Algorithm resilience.RateLimitAlgorithm
+
+	// Store shares bucket state across replicas (e.g. cache/redis.RateLimitStore).
+	// Nil keeps buckets in process memory, so each replica enforces its own limit.
+	Store resilience.RateLimitStore
+
+	// FailOpen lets requests through when Store fails. By default they are rejected.
+	FailOpen bool
+
+	// OnStoreError is called with every Store failure.
+	OnStoreError func(key string, err error)
 }
 
 func (cfg *RateLimitConfig) applyDefaults() {
@@ -61,6 +75,10 @@ func NewRateLimiter(cfg RateLimitConfig) *RateLimiterInstance {
 		limiter: resilience.NewKeyedRateLimiter(resilience.KeyedRateLimiterConfig{
 			CleanupInterval: cfg.CleanupInterval,
 			BucketTTL:       cfg.BucketTTL,
+			Algorithm:       cfg.Algorithm,
+			Store:           cfg.Store,
+			FailOpen:        cfg.FailOpen,
+			OnStoreError:    cfg.OnStoreError,
 		}),
 	}
 }
@@ -77,7 +95,12 @@ func (rl *RateLimiterInstance) Stop() {
 // Allow checks whether the given key is permitted another request at the given RPM.
 // Returns (allowed, limit, remaining, retryAfterSecs, resetUnix).
 func (rl *RateLimiterInstance) Allow(key string, rpm int) (allowed bool, limit, remaining int, retryAfterSecs float64, resetUnix int64) {
-	decision := rl.limiter.Allow(key, rpm, time.Minute)
+	return rl.AllowContext(context.Background(), key, rpm)
+}
+
+// AllowContext is Allow bounded by ctx for the round trip to a shared Store.
+func (rl *RateLimiterInstance) AllowContext(ctx context.Context, key string, rpm int) (allowed bool, limit, remaining int, retryAfterSecs float64, resetUnix int64) {
+	decision, _ := rl.limiter.Take(ctx, key, rpm, time.Minute)
 	return decision.Allowed, decision.Limit, decision.Remaining, decision.RetryAfter.Seconds(), decision.ResetAt.Unix()
 }
 
@@ -86,7 +109,7 @@ func RateLimit(limiter *RateLimiterInstance) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		key, rpm := resolveKeyAndRPM(c, limiter.cfg)
 
-		allowed, limit, remaining, retryAfter, resetUnix := limiter.Allow(key, rpm)
+		allowed, limit, remaining, retryAfter, resetUnix := limiter.AllowContext(c.Request.Context(), key, rpm)
 
 		c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
 		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
@@ -1,12 +1,16 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/kbukum/gokit/resilience"
 )
 
 func TestRateLimitConfig_ApplyDefaults(t *testing.T) {
@@ -81,6 +85,50 @@ func TestRateLimit_Middleware(t *testing.T) {
 	}
 }
 
+func TestRateLimit_SharedStore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &countingRateLimitStore{}
+	var reported error
+	rl := NewRateLimiter(RateLimitConfig{
+		RequestsPerMinute: 5,
+		Algorithm:         resilience.RateLimitGCRA,
+		Store:             store,
+		OnStoreError:      func(_ string, err error) { reported = err },
+	})
+	defer rl.Stop()
+	r := gin.New()
+	r.Use(RateLimit(rl))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	if w.Code != http.StatusOK || store.calls != 1 || store.last.Algorithm != resilience.RateLimitGCRA {
+		t.Fatalf("code=%d calls=%d last=%+v", w.Code, store.calls, store.last)
+	}
+
+	store.err = errors.New("store down")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	if w.Code != http.StatusTooManyRequests || reported == nil {
+		t.Fatalf("store failure should fail closed: code=%d reported=%v", w.Code, reported)
+	}
+}
+
+type countingRateLimitStore struct {
+	calls int
+	last  resilience.RateLimit
+	err   error
+}
+
+func (s *countingRateLimitStore) Take(_ context.Context, _ string, limit resilience.RateLimit) (resilience.RateLimitDecision, error) {
+	s.calls++
+	s.last = limit
+	if s.err != nil {
+		return resilience.RateLimitDecision{}, s.err
+	}
+	return resilience.RateLimitDecision{Allowed: true, Remaining: limit.Limit - 1, ResetAt: time.Now()}, nil
+}
+
 func TestRateLimit_LimitFuncTakesPrecedence(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	rl := NewRateLimiter(RateLimitConfig{
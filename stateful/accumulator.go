@@ -16,10 +16,14 @@ type Accumulator[V any] struct {
 	created   time.Time
 	lastFlush time.Time
 	mu        sync.RWMutex
+	checkTick *time.Ticker
+	stopCheck chan struct{}
+	closeOnce sync.Once
 }
 
 // NewAccumulator creates a new accumulator with the given store and configuration.
 // If no Measurer is provided in options, CountMeasurer is used by default.
+// If config.CheckInterval is set, triggers are also checked in the background until Close.
 func NewAccumulator[V any](store Store[V], config Config[V], opts ...Option[V]) *Accumulator[V] {
 	acc := &Accumulator[V]{
 		store:    store,
@@ -33,6 +37,12 @@ func NewAccumulator[V any](store Store[V], config Config[V], opts ...Option[V])
 		opt(acc)
 	}
 
+	if config.CheckInterval > 0 {
+		acc.checkTick = time.NewTicker(config.CheckInterval)
+		acc.stopCheck = make(chan struct{})
+		go acc.checkLoop()
+	}
+
 	return acc
 }
 
@@ -148,11 +158,37 @@ func (a *Accumulator[V]) Touch(ctx context.Context) error {
 	return a.store.Touch(ctx)
 }
 
-// Close releases resources held by the accumulator.
+// Check evaluates triggers and flushes if they fire, as Append does after adding a value.
+// Use it to let time and idle triggers fire between appends when CheckInterval is unset.
+func (a *Accumulator[V]) Check(ctx context.Context) error {
+	return a.checkAndFlush(ctx)
+}
+
+// Close stops background trigger checks and releases resources held by the accumulator.
 func (a *Accumulator[V]) Close() error {
+	a.closeOnce.Do(func() {
+		if a.checkTick != nil {
+			a.checkTick.Stop()
+			close(a.stopCheck)
+		}
+	})
 	return a.store.Close()
 }
 
+// checkLoop runs the background trigger checks.
+func (a *Accumulator[V]) checkLoop() {
+	for {
+		select {
+		case <-a.checkTick.C:
+			if err := a.checkAndFlush(context.Background()); err != nil && a.config.OnError != nil {
+				a.config.OnError(err)
+			}
+		case <-a.stopCheck:
+			return
+		}
+	}
+}
+
 // checkAndFlush checks triggers and flushes if conditions are met.
 // Respects MinInterval rate limiting.
 func (a *Accumulator[V]) checkAndFlush(ctx context.Context) error {
@@ -29,7 +29,8 @@ type Config[V any] struct {
 	// Prevents too-frequent processing even if triggers fire. Zero means no rate limiting.
 	MinInterval time.Duration
 
-	// Triggers define when to flush. Can be time-based, size-based, or custom.
+	// Triggers define when to flush. Can be time-based, size-based, idle, content-based,
+	// composite (AllOf, AnyOf, Not), or custom; BuildTrigger creates them from config.
 	// Multiple triggers are evaluated according to TriggerMode (ANY or ALL).
 	Triggers []Trigger[V]
 
@@ -37,6 +38,11 @@ type Config[V any] struct {
 	// flush if ANY trigger fires (OR logic) TriggerAll: flush only if ALL triggers fire (AND logic)
 	TriggerMode TriggerMode
 
+	// CheckInterval evaluates triggers in the background at this interval, so time and
+	// idle triggers fire while no values arrive. Zero means triggers are only checked on
+	// Append or Check.
+	CheckInterval time.Duration
+
 	// OnFlush is called when the accumulator flushes values. Receives the flushed values
 	// and should return nil on success.
 	OnFlush FlushHandler[V]
@@ -4,14 +4,16 @@
 // # Core Concepts
 //
 // Accumulator - Collects values of type V
-// and flushes them based on configurable triggers (time, size, custom).
+// and flushes them based on configurable triggers (time, size, idle, content match, custom),
+// composable with AllOf, AnyOf and Not and buildable from config with BuildTrigger.
 // Supports bounded FIFO buffers, keep-alive TTL, rate limiting, and type-aware measurement.
 //
 // Manager - Manages multiple named accumulators for multi-tenant use cases (per session, per user, per stream, etc.).
 //
-// Store - Pluggable storage backend interface. Built-in implementations: memory (fast, local)
-// and Redis (durable, distributed).
-// Users can implement custom stores for any backend (Postgres, DynamoDB, filesystem, etc.).
+// Store - Pluggable storage backend interface. Built-in implementations: memory (fast, local),
+// plus Redis lists (cache/redis) and SQL tables (database), which are durable and shared
+// across replicas. Users can implement custom stores for any backend (DynamoDB, filesystem, etc.).
+// Persistent backends that implement KeyLister let Manager.Recover adopt keys after a restart.
 //
 // # Use Cases
 //
@@ -3,3 +3,8 @@ module github.com/kbukum/gokit/stateful
 go 1.26.0
 
 toolchain go1.26.6
+
+require (
+	github.com/go-viper/mapstructure/v2 v2.5.0
+	go.yaml.in/yaml/v3 v3.0.5
+)
@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -162,6 +163,47 @@ func (m *Manager[K, V]) Cleanup() int {
 	return count
 }
 
+// Recover adopts the keys a persistent backend holds values for, such as
+// buffers left by an earlier run or by a replica that stopped. Call it at
+// startup, and periodically to take over keys from replicas that died.
+//
+// Each key not yet managed gets an accumulator from the factory. One that
+// has already expired is flushed through OnFlush and then expired as by
+// Cleanup, so its values are processed instead of stranded; if the flush
+// fails it stays managed. parse converts a backend key to K. Returns the
+// number of keys adopted; per-key errors are joined.
+func (m *Manager[K, V]) Recover(ctx context.Context, keys KeyLister, parse func(string) (K, error)) (int, error) {
+	names, err := keys.Keys(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("manager recover: list keys: %w", err)
+	}
+	var errs []error
+	count := 0
+	for _, name := range names {
+		k, err := parse(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("manager recover: parse key %q: %w", name, err))
+			continue
+		}
+		if m.Get(k) != nil {
+			continue
+		}
+		acc := m.GetOrCreate(k)
+		count++
+		if !acc.IsExpired(ctx) {
+			continue
+		}
+		if _, err := acc.Flush(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("manager recover %q: %w", name, err))
+			continue
+		}
+		if m.Delete(k) && acc.config.OnExpire != nil {
+			acc.config.OnExpire(ctx, name)
+		}
+	}
+	return count, errors.Join(errs...)
+}
+
 // Close stops the cleanup ticker and closes all accumulators.
 // The manager should not be used after calling Close.
 func (m *Manager[K, V]) Close() error {
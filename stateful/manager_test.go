@@ -293,3 +293,81 @@ func(key string) *Accumulator[int] {
 }
 
 // Test concurrent appends
+
+// ---------------------------------------------------------------------------
+// Manager: Recover adopts persisted keys
+// ---------------------------------------------------------------------------
+
+// persistedStore is a MemoryStore with a settable last activity, standing in
+// for a store whose values outlived the process.
+type persistedStore struct {
+	*MemoryStore[int]
+	last time.Time
+}
+
+func (s *persistedStore) LastActivity(context.Context) (time.Time, error) { return s.last, nil }
+
+// persistedBackend holds one persistedStore per key and lists keys with values.
+type persistedBackend map[string]*persistedStore
+
+func (b persistedBackend) Keys(ctx context.Context) ([]string, error) {
+	var keys []string
+	for k, s := range b {
+		if n, _ := s.Size(ctx); n > 0 {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func TestManager_Recover(t *testing.T) {
+	ctx := context.Background()
+	backend := persistedBackend{
+		"stale":  {MemoryStore: NewMemoryStore[int](), last: time.Now().Add(-time.Hour)},
+		"active": {MemoryStore: NewMemoryStore[int](), last: time.Now()},
+		"empty":  {MemoryStore: NewMemoryStore[int](), last: time.Now().Add(-time.Hour)},
+	}
+	_ = backend["stale"].Append(ctx, 1)
+	_ = backend["stale"].Append(ctx, 2)
+	_ = backend["active"].Append(ctx, 3)
+
+	var mu sync.Mutex
+	flushed := map[string][]int{}
+	var expired []string
+	mgr := NewManager(func(key string) *Accumulator[int] {
+		return NewAccumulator[int](backend[key], Config[int]{
+			TTL:       time.Minute,
+			KeepAlive: true,
+			OnFlush: func(_ context.Context, values []int) error {
+				mu.Lock()
+				defer mu.Unlock()
+				flushed[key] = values
+				return nil
+			},
+			OnExpire: func(_ context.Context, key string) { expired = append(expired, key) },
+		})
+	}, 0)
+	defer mgr.Close()
+
+	n, err := mgr.Recover(ctx, backend, func(s string) (string, error) { return s, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("recovered %d keys, want 2", n)
+	}
+	if got := flushed["stale"]; len(got) != 2 {
+		t.Errorf("stale key flushed %v, want [1 2]", got)
+	}
+	if len(expired) != 1 || expired[0] != "stale" {
+		t.Errorf("expired = %v, want [stale]", expired)
+	}
+	if keys := mgr.List(); len(keys) != 1 || keys[0] != "active" {
+		t.Errorf("managed keys = %v, want [active]", keys)
+	}
+
+	// Already managed keys are left alone.
+	if n, _ := mgr.Recover(ctx, backend, func(s string) (string, error) { return s, nil }); n != 0 {
+		t.Errorf("second Recover adopted %d keys, want 0", n)
+	}
+}
@@ -37,3 +37,10 @@ type Store[V any] interface {
 	// Close releases any resources held by the store.
 	Close() error
 }
+
+// KeyLister is implemented by persistent store backends that keep one Store
+// per key and can list the keys currently holding values. Manager.Recover
+// uses it to adopt keys left by an earlier run or another replica.
+type KeyLister interface {
+	Keys(ctx context.Context) ([]string, error)
+}
@@ -3,6 +3,7 @@
 import (
 	"context"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -83,3 +84,109 @@ func (t *customTrigger[V]) Name() string {
 func (t *customTrigger[V]) ShouldFlush(ctx context.Context, acc *Accumulator[V]) bool {
 	return t.fn(ctx, acc)
 }
+
+// IdleTrigger creates a trigger that fires once the accumulator holds values and none
+// has arrived for gap, closing a session after a pause. Idle time is measured from the
+// store's last activity. Set Config.CheckInterval so the trigger is evaluated while no
+// values arrive.
+func IdleTrigger[V any](gap time.Duration) Trigger[V] {
+	return &idleTrigger[V]{gap: gap}
+}
+
+type idleTrigger[V any] struct {
+	gap time.Duration
+}
+
+func (t *idleTrigger[V]) Name() string {
+	return "idle:" + t.gap.String()
+}
+
+func (t *idleTrigger[V]) ShouldFlush(ctx context.Context, acc *Accumulator[V]) bool {
+	size, err := acc.store.Size(ctx)
+	if err == nil && size > 0 {
+		var last time.Time
+		if last, err = acc.store.LastActivity(ctx); err == nil {
+			return time.Since(last) >= t.gap
+		}
+	}
+	if err != nil && acc.config.OnError != nil {
+		acc.config.OnError(err)
+	}
+	return false
+}
+
+// MatchTrigger creates a trigger that fires when the most recently appended value
+// matches fn, for example a chat turn ending a sentence.
+func MatchTrigger[V any](name string, fn func(V) bool) Trigger[V] {
+	return &matchTrigger[V]{name: name, fn: fn}
+}
+
+type matchTrigger[V any] struct {
+	name string
+	fn   func(V) bool
+}
+
+func (t *matchTrigger[V]) Name() string {
+	return "match:" + t.name
+}
+
+func (t *matchTrigger[V]) ShouldFlush(ctx context.Context, acc *Accumulator[V]) bool {
+	values, err := acc.store.Get(ctx)
+	if err != nil {
+		if acc.config.OnError != nil {
+			acc.config.OnError(err)
+		}
+		return false
+	}
+	return len(values) > 0 && t.fn(values[len(values)-1])
+}
+
+// AllOf creates a trigger that fires when every one of triggers fires (AND).
+// It never fires without triggers.
+func AllOf[V any](triggers ...Trigger[V]) Trigger[V] {
+	return &compositeTrigger[V]{op: "all", triggers: triggers}
+}
+
+// AnyOf creates a trigger that fires when at least one of triggers fires (OR).
+func AnyOf[V any](triggers ...Trigger[V]) Trigger[V] {
+	return &compositeTrigger[V]{op: "any", triggers: triggers}
+}
+
+// Not creates a trigger that fires when trigger does not.
+func Not[V any](trigger Trigger[V]) Trigger[V] {
+	return &compositeTrigger[V]{op: "not", triggers: []Trigger[V]{trigger}}
+}
+
+type compositeTrigger[V any] struct {
+	op       string
+	triggers []Trigger[V]
+}
+
+func (t *compositeTrigger[V]) Name() string {
+	names := make([]string, len(t.triggers))
+	for i, trigger := range t.triggers {
+		names[i] = trigger.Name()
+	}
+	return t.op + "(" + strings.Join(names, ",") + ")"
+}
+
+func (t *compositeTrigger[V]) ShouldFlush(ctx context.Context, acc *Accumulator[V]) bool {
+	switch t.op {
+	case "not":
+		return !t.triggers[0].ShouldFlush(ctx, acc)
+	case "all":
+		for _, trigger := range t.triggers {
+			if !trigger.ShouldFlush(ctx, acc) {
+				return false
+			}
+		}
+		return len(t.triggers) > 0
+	default:
+		for _, trigger := range t.triggers {
+			if trigger.ShouldFlush(ctx, acc) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,88 @@
+package stateful
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TriggerSpec declares a trigger in configuration, so flush behavior can be
+// tuned without a code change. It decodes with the config loader's
+// mapstructure hooks, so After takes duration strings such as "2s". Composite
+// types nest further specs:
+//
+//	trigger:
+//	  type: any
+//	  triggers:
+//	    - type: size
+//	      threshold: 50
+//	    - type: all
+//	      triggers:
+//	        - type: idle
+//	          after: 2s
+//	        - type: match
+//	          match: sentence_end
+type TriggerSpec struct {
+	// Type is one of time, size, idle, match, all, any or not.
+	Type string `yaml:"type" mapstructure:"type"`
+	// After is the duration for time and idle triggers.
+	After time.Duration `yaml:"after" mapstructure:"after"`
+	// Threshold is the measured size for size triggers.
+	Threshold int `yaml:"threshold" mapstructure:"threshold"`
+	// Match names the predicate of a match trigger, looked up in the matchers
+	// passed to BuildTrigger.
+	Match string `yaml:"match" mapstructure:"match"`
+	// Triggers are the children of all, any and not (exactly one) triggers.
+	Triggers []TriggerSpec `yaml:"triggers" mapstructure:"triggers"`
+}
+
+// BuildTrigger builds the trigger spec declares. Match triggers take their
+// predicate from matchers by name, so code registers predicates and config
+// chooses among them.
+func BuildTrigger[V any](spec TriggerSpec, matchers map[string]func(V) bool) (Trigger[V], error) {
+	switch spec.Type {
+	case "time":
+		if spec.After <= 0 {
+			return nil, errors.New("stateful: time trigger needs a positive after")
+		}
+		return TimeTrigger[V](spec.After), nil
+	case "idle":
+		if spec.After <= 0 {
+			return nil, errors.New("stateful: idle trigger needs a positive after")
+		}
+		return IdleTrigger[V](spec.After), nil
+	case "size":
+		if spec.Threshold <= 0 {
+			return nil, errors.New("stateful: size trigger needs a positive threshold")
+		}
+		return SizeTrigger[V](spec.Threshold), nil
+	case "match":
+		fn, ok := matchers[spec.Match]
+		if !ok {
+			return nil, fmt.Errorf("stateful: unknown matcher %q", spec.Match)
+		}
+		return MatchTrigger(spec.Match, fn), nil
+	case "all", "any", "not":
+		if len(spec.Triggers) == 0 || (spec.Type == "not" && len(spec.Triggers) != 1) {
+			return nil, fmt.Errorf("stateful: %s trigger has %d children", spec.Type, len(spec.Triggers))
+		}
+		children := make([]Trigger[V], len(spec.Triggers))
+		for i, child := range spec.Triggers {
+			t, err := BuildTrigger(child, matchers)
+			if err != nil {
+				return nil, fmt.Errorf("%s[%d]: %w", spec.Type, i, err)
+			}
+			children[i] = t
+		}
+		switch spec.Type {
+		case "all":
+			return AllOf(children...), nil
+		case "any":
+			return AnyOf(children...), nil
+		default:
+			return Not(children[0]), nil
+		}
+	default:
+		return nil, fmt.Errorf("stateful: unknown trigger type %q", spec.Type)
+	}
+}
@@ -0,0 +1,111 @@
+package stateful
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+func TestBuildTrigger_Tree(t *testing.T) {
+	spec := TriggerSpec{Type: "any", Triggers: []TriggerSpec{
+		{Type: "size", Threshold: 50},
+		{Type: "all", Triggers: []TriggerSpec{
+			{Type: "idle", After: 2 * time.Second},
+			{Type: "not", Triggers: []TriggerSpec{{Type: "match", Match: "question"}}},
+		}},
+		{Type: "time", After: time.Minute},
+	}}
+	matchers := map[string]func(string) bool{
+		"question": func(v string) bool { return strings.HasSuffix(v, "?") },
+	}
+
+	trigger, err := BuildTrigger(spec, matchers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "any(size:>=50,all(idle:2s,not(match:question)),time:1m0s)"
+	if got := trigger.Name(); got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+
+	acc := NewAccumulator(NewMemoryStore[string](), Config[string]{})
+	_ = acc.Append(context.Background(), "hi?")
+	if trigger.ShouldFlush(context.Background(), acc) {
+		t.Error("should not fire before any condition holds")
+	}
+}
+
+func TestBuildTrigger_Errors(t *testing.T) {
+	tests := []struct {
+		spec TriggerSpec
+		want string
+	}{
+		{TriggerSpec{Type: "hourly"}, `unknown trigger type "hourly"`},
+		{TriggerSpec{Type: "time"}, "positive after"},
+		{TriggerSpec{Type: "idle", After: -time.Second}, "positive after"},
+		{TriggerSpec{Type: "size"}, "positive threshold"},
+		{TriggerSpec{Type: "match", Match: "missing"}, `unknown matcher "missing"`},
+		{TriggerSpec{Type: "any"}, "any trigger has 0 children"},
+		{TriggerSpec{Type: "not", Triggers: []TriggerSpec{{Type: "size", Threshold: 1}, {Type: "size", Threshold: 2}}},
+			"not trigger has 2 children"},
+		{TriggerSpec{Type: "all", Triggers: []TriggerSpec{{Type: "size", Threshold: 1}, {Type: "time"}}},
+			"all[1]: stateful: time trigger needs a positive after"},
+	}
+	for _, tt := range tests {
+		_, err := BuildTrigger[int](tt.spec, nil)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("BuildTrigger(%+v) error = %v, want %q", tt.spec, err, tt.want)
+		}
+	}
+}
+
+// TestTriggerSpec_DecodesYAML decodes the TriggerSpec doc example the way
+// config.Load does: YAML into a map, then mapstructure with the duration hook.
+func TestTriggerSpec_DecodesYAML(t *testing.T) {
+	blob := []byte(`
+trigger:
+  type: any
+  triggers:
+    - type: size
+      threshold: 50
+    - type: all
+      triggers:
+        - type: idle
+          after: 2s
+        - type: match
+          match: sentence_end
+`)
+	var raw map[string]any
+	if err := yaml.Unmarshal(blob, &raw); err != nil {
+		t.Fatalf("unmarshal yaml: %v", err)
+	}
+	var cfg struct {
+		Trigger TriggerSpec `mapstructure:"trigger"`
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     &cfg,
+		TagName:    "mapstructure",
+	})
+	if err != nil {
+		t.Fatalf("new decoder: %v", err)
+	}
+	if err := decoder.Decode(raw); err != nil {
+		t.Fatalf("decode trigger spec: %v", err)
+	}
+
+	trigger, err := BuildTrigger(cfg.Trigger, map[string]func(string) bool{
+		"sentence_end": func(v string) bool { return strings.HasSuffix(v, ".") },
+	})
+	if err != nil {
+		t.Fatalf("BuildTrigger: %v", err)
+	}
+	want := "any(size:>=50,all(idle:2s,match:sentence_end))"
+	if got := trigger.Name(); got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+}
@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 )
@@ -101,3 +102,103 @@ func TestTrigger_Names(t *testing.T) {
 }
 
 // Test Touch method
+
+func TestMatchTrigger_FlushesOnLatestValue(t *testing.T) {
+	var flushed [][]string
+	acc := NewAccumulator(NewMemoryStore[string](), Config[string]{
+		Triggers: []Trigger[string]{MatchTrigger("sentence-end", func(v string) bool {
+			return strings.HasSuffix(v, ".")
+		})},
+		OnFlush: func(_ context.Context, values []string) error {
+			flushed = append(flushed, values)
+			return nil
+		},
+	})
+	ctx := context.Background()
+
+	for _, word := range []string{"Hello", "world.", "Bye"} {
+		_ = acc.Append(ctx, word)
+	}
+	if len(flushed) != 1 || len(flushed[0]) != 2 {
+		t.Fatalf("flushed = %v, want [[Hello world.]]", flushed)
+	}
+	if size, _ := acc.Size(ctx); size != 1 {
+		t.Errorf("size = %d, want 1", size)
+	}
+}
+
+func TestIdleTrigger_FiresAfterGap(t *testing.T) {
+	acc := NewAccumulator(NewMemoryStore[int](), Config[int]{
+		Triggers: []Trigger[int]{IdleTrigger[int](30 * time.Millisecond)},
+	})
+	ctx := context.Background()
+	trigger := acc.config.Triggers[0]
+
+	if trigger.ShouldFlush(ctx, acc) {
+		t.Error("should not fire while empty")
+	}
+	_ = acc.Append(ctx, 1)
+	if trigger.ShouldFlush(ctx, acc) {
+		t.Error("should not fire right after an append")
+	}
+	time.Sleep(40 * time.Millisecond)
+	if !trigger.ShouldFlush(ctx, acc) {
+		t.Error("should fire after the idle gap")
+	}
+}
+
+func TestCompositeTriggers(t *testing.T) {
+	acc := NewAccumulator(NewMemoryStore[int](), Config[int]{})
+	ctx := context.Background()
+	_ = acc.Append(ctx, 1)
+	_ = acc.Append(ctx, 2)
+
+	yes := CustomTrigger("yes", func(context.Context, *Accumulator[int]) bool { return true })
+	no := CustomTrigger("no", func(context.Context, *Accumulator[int]) bool { return false })
+
+	tests := []struct {
+		trigger Trigger[int]
+		name    string
+		want    bool
+	}{
+		{AllOf(yes, SizeTrigger[int](2)), "all(custom:yes,size:>=2)", true},
+		{AllOf(yes, no), "all(custom:yes,custom:no)", false},
+		{AllOf[int](), "all()", false},
+		{AnyOf(no, SizeTrigger[int](2)), "any(custom:no,size:>=2)", true},
+		{AnyOf(no, no), "any(custom:no,custom:no)", false},
+		{Not(no), "not(custom:no)", true},
+		{AnyOf(Not(yes), AllOf(yes, Not(no))), "any(not(custom:yes),all(custom:yes,not(custom:no)))", true},
+	}
+	for _, tt := range tests {
+		if got := tt.trigger.Name(); got != tt.name {
+			t.Errorf("Name() = %q, want %q", got, tt.name)
+		}
+		if got := tt.trigger.ShouldFlush(ctx, acc); got != tt.want {
+			t.Errorf("%s: ShouldFlush = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAccumulator_CheckInterval_FlushesIdleSession(t *testing.T) {
+	flushed := make(chan []int, 1)
+	acc := NewAccumulator(NewMemoryStore[int](), Config[int]{
+		Triggers:      []Trigger[int]{IdleTrigger[int](20 * time.Millisecond)},
+		CheckInterval: 5 * time.Millisecond,
+		OnFlush: func(_ context.Context, values []int) error {
+			flushed <- values
+			return nil
+		},
+	})
+	defer func() { _ = acc.Close() }()
+
+	_ = acc.Append(context.Background(), 1)
+	_ = acc.Append(context.Background(), 2)
+	select {
+	case values := <-flushed:
+		if len(values) != 2 {
+			t.Errorf("flushed %v, want [1 2]", values)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("idle session was not flushed in the background")
+	}
+}
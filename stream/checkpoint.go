@@ -0,0 +1,310 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kbukum/gokit/fs"
+)
+
+// Checkpointable is implemented by iterators whose progress can be saved and
+// restored: sources snapshot their offset, stateful operators their state.
+// Snapshot and Restore are called between calls to Next, never concurrently
+// with them.
+type Checkpointable interface {
+	// Snapshot returns the iterator's position or state.
+	Snapshot(ctx context.Context) ([]byte, error)
+	// Restore resumes from a snapshot. It is called before the first Next.
+	Restore(ctx context.Context, snapshot []byte) error
+}
+
+// CheckpointCommitter is optionally implemented by a Checkpointable iterator
+// to act once a checkpoint including its snapshot is saved, for example to
+// acknowledge messages or commit broker offsets.
+type CheckpointCommitter interface {
+	CommitCheckpoint(ctx context.Context) error
+}
+
+// Checkpoint is a consistent snapshot of every Checkpointed stage of a run.
+type Checkpoint struct {
+	// ID increases by one with every checkpoint saved for the pipeline.
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	// Records is the number of values delivered to the sink across all runs.
+	Records int64 `json:"records"`
+	// State holds each stage's snapshot by stage name.
+	State map[string][]byte `json:"state"`
+}
+
+// CheckpointStore persists the latest checkpoint of a pipeline.
+type CheckpointStore interface {
+	// Load returns the latest checkpoint, or nil if none was saved.
+	Load(ctx context.Context) (*Checkpoint, error)
+	// Save replaces the latest checkpoint. It must not leave a partial
+	// checkpoint behind if it fails.
+	Save(ctx context.Context, cp *Checkpoint) error
+}
+
+// CheckpointConfig configures DrainWithCheckpoints.
+type CheckpointConfig struct {
+	// Store persists checkpoints. Required.
+	Store CheckpointStore
+	// Interval is the time between checkpoints, checked after each value.
+	// Default: 10s, unless Every is set.
+	Interval time.Duration
+	// Every takes a checkpoint after this many values (0 = by Interval only).
+	Every int
+}
+
+func (c CheckpointConfig) withDefaults() CheckpointConfig {
+	if c.Interval <= 0 && c.Every <= 0 {
+		c.Interval = 10 * time.Second
+	}
+	return c
+}
+
+// Checkpointed names the iterator created by p as a checkpointed stage. In a
+// run started by DrainWithCheckpoints the iterator is restored from the
+// stage's last snapshot and included in every checkpoint; elsewhere the stage
+// has no effect.
+//
+// The iterator must implement Checkpointable, as those of FromSlice and
+// KeyedReduce do, and name must be unique and stable across runs.
+func Checkpointed[T any](p *Pipeline[T], name string) *Pipeline[T] {
+	return &Pipeline[T]{
+		create: func(ctx context.Context) Iterator[T] {
+			iter := p.create(ctx)
+			run, ok := ctx.Value(checkpointRunKey{}).(*checkpointRun)
+			if !ok {
+				return iter
+			}
+			if err := run.register(ctx, name, iter); err != nil {
+				return &failedIter[T]{source: iter, err: err}
+			}
+			return iter
+		},
+	}
+}
+
+// DrainWithCheckpoints is Drain with checkpointing. The run resumes from the
+// latest checkpoint in cfg.Store, then periodically saves a new one, and
+// saves a final one when the input is exhausted, so a later run of a finished
+// job emits nothing.
+//
+// A checkpoint is taken after the sink returns for a value, so it covers
+// exactly the values the sink has handled. Restored state therefore counts
+// each input value once; values the sink handled after the last checkpoint
+// are delivered again after a crash, so sink side effects should be
+// idempotent. This holds only when every operator between a Checkpointed
+// stage and the sink is synchronous: values in flight in Buffer, Merge,
+// Parallel, Partition or operators built on them are not part of a
+// checkpoint.
+func DrainWithCheckpoints[T any](p *Pipeline[T], sink func(context.Context, T) error, cfg CheckpointConfig) *Runnable {
+	cfg = cfg.withDefaults()
+	return &Runnable{
+		run: func(ctx context.Context) error {
+			if cfg.Store == nil {
+				return errors.New("stream: checkpoint store is required")
+			}
+			last, err := cfg.Store.Load(ctx)
+			if err != nil {
+				return fmt.Errorf("stream: load checkpoint: %w", err)
+			}
+			run := &checkpointRun{stages: make(map[string]Checkpointable)}
+			if last != nil {
+				run.id, run.records, run.restore = last.ID, last.Records, last.State
+			}
+			iter := p.create(context.WithValue(ctx, checkpointRunKey{}, run))
+			defer func() { _ = iter.Close() }()
+
+			since, lastAt := 0, time.Now()
+			for {
+				val, ok, err := iter.Next(ctx)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return run.checkpoint(ctx, cfg.Store)
+				}
+				if err := sink(ctx, val); err != nil {
+					return err
+				}
+				run.records++
+				since++
+				if (cfg.Every > 0 && since >= cfg.Every) || (cfg.Interval > 0 && time.Since(lastAt) >= cfg.Interval) {
+					if err := run.checkpoint(ctx, cfg.Store); err != nil {
+						return err
+					}
+					since, lastAt = 0, time.Now()
+				}
+			}
+		},
+	}
+}
+
+type checkpointRunKey struct{}
+
+// checkpointRun tracks the checkpointed stages of one DrainWithCheckpoints run.
+type checkpointRun struct {
+	id      int64
+	records int64
+	restore map[string][]byte
+	names   []string
+	stages  map[string]Checkpointable
+}
+
+func (r *checkpointRun) register(ctx context.Context, name string, iter any) error {
+	c, ok := iter.(Checkpointable)
+	if !ok {
+		return fmt.Errorf("stream: checkpointed stage %q does not support checkpoints", name)
+	}
+	if _, dup := r.stages[name]; dup {
+		return fmt.Errorf("stream: duplicate checkpointed stage %q", name)
+	}
+	if snapshot, ok := r.restore[name]; ok {
+		if err := c.Restore(ctx, snapshot); err != nil {
+			return fmt.Errorf("stream: restore stage %q: %w", name, err)
+		}
+	}
+	r.names = append(r.names, name)
+	r.stages[name] = c
+	return nil
+}
+
+func (r *checkpointRun) checkpoint(ctx context.Context, store CheckpointStore) error {
+	cp := &Checkpoint{ID: r.id + 1, CreatedAt: time.Now(), Records: r.records, State: make(map[string][]byte, len(r.names))}
+	for _, name := range r.names {
+		snapshot, err := r.stages[name].Snapshot(ctx)
+		if err != nil {
+			return fmt.Errorf("stream: snapshot stage %q: %w", name, err)
+		}
+		cp.State[name] = snapshot
+	}
+	if err := store.Save(ctx, cp); err != nil {
+		return fmt.Errorf("stream: save checkpoint %d: %w", cp.ID, err)
+	}
+	r.id = cp.ID
+	for _, name := range r.names {
+		if c, ok := r.stages[name].(CheckpointCommitter); ok {
+			if err := c.CommitCheckpoint(ctx); err != nil {
+				return fmt.Errorf("stream: commit stage %q: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// failedIter reports err from Next and closes source.
+type failedIter[T any] struct {
+	source Iterator[T]
+	err    error
+}
+
+func (it *failedIter[T]) Next(context.Context) (result T, ok bool, err error) {
+	return result, false, it.err
+}
+
+func (it *failedIter[T]) Close() error { return it.source.Close() }
+
+// DirCheckpoints keeps the latest checkpoint as checkpoint.json in a local
+// directory, replacing it atomically on every save.
+type DirCheckpoints struct {
+	path string
+}
+
+// CheckpointsInDir stores checkpoints in dir, which is created if needed.
+func CheckpointsInDir(dir string) *DirCheckpoints {
+	return &DirCheckpoints{path: filepath.Join(dir, "checkpoint.json")}
+}
+
+// Load implements CheckpointStore.
+func (s *DirCheckpoints) Load(context.Context) (*Checkpoint, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeCheckpoint(data)
+}
+
+// Save implements CheckpointStore.
+func (s *DirCheckpoints) Save(_ context.Context, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return fs.WriteAtomicReplace(s.path, data, ".checkpoint-")
+}
+
+// BlobStore is the subset of storage.Storage used to persist checkpoints, so
+// any storage backend can hold them.
+type BlobStore interface {
+	Upload(ctx context.Context, path string, reader io.Reader) error
+	Download(ctx context.Context, path string) (io.ReadCloser, error)
+	Exists(ctx context.Context, path string) (bool, error)
+}
+
+// StorageCheckpoints keeps the latest checkpoint as a single object in a
+// BlobStore. Saves rely on the backend replacing objects atomically, as
+// object stores do.
+type StorageCheckpoints struct {
+	store BlobStore
+	path  string
+}
+
+// CheckpointsInStorage stores checkpoints in store at path.
+func CheckpointsInStorage(store BlobStore, path string) *StorageCheckpoints {
+	return &StorageCheckpoints{store: store, path: path}
+}
+
+// Load implements CheckpointStore.
+func (s *StorageCheckpoints) Load(ctx context.Context) (*Checkpoint, error) {
+	exists, err := s.store.Exists(ctx, s.path)
+	if err != nil || !exists {
+		return nil, err
+	}
+	rc, err := s.store.Download(ctx, s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCheckpoint(data)
+}
+
+// Save implements CheckpointStore.
+func (s *StorageCheckpoints) Save(ctx context.Context, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return s.store.Upload(ctx, s.path, bytes.NewReader(data))
+}
+
+func decodeCheckpoint(data []byte) (*Checkpoint, error) {
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("stream: decode checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+var (
+	_ CheckpointStore = (*DirCheckpoints)(nil)
+	_ CheckpointStore = (*StorageCheckpoints)(nil)
+	_ Checkpointable  = (*sliceIter[int])(nil)
+	_ Checkpointable  = (*keyedReduceIter[int, int])(nil)
+	_ Checkpointable  = (*MemoryState[int])(nil)
+)
@@ -0,0 +1,184 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// totalsPipeline sums purchases per user from a checkpointed slice source.
+func totalsPipeline(src []purchase) *Pipeline[Keyed[int]] {
+	source := Checkpointed(FromSlice(src), "source")
+	return Checkpointed(KeyedReduce(source, func(p purchase) string { return p.user }, 0,
+		func(sum int, p purchase) int { return sum + p.amount }, KeyedConfig[int]{}), "totals")
+}
+
+func TestDrainWithCheckpoints_ResumesAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	src := []purchase{{"ann", 1}, {"bob", 2}, {"ann", 3}, {"bob", 4}, {"ann", 5}, {"bob", 6}}
+	store := CheckpointsInDir(t.TempDir())
+	boom := errors.New("boom")
+
+	var got []Keyed[int]
+	failing := func(_ context.Context, k Keyed[int]) error {
+		if len(got) == 4 {
+			return boom
+		}
+		got = append(got, k)
+		return nil
+	}
+	cfg := CheckpointConfig{Store: store, Every: 3}
+	if err := DrainWithCheckpoints(totalsPipeline(src), failing, cfg).Run(ctx); !errors.Is(err, boom) {
+		t.Fatalf("expected sink error, got %v", err)
+	}
+
+	// The checkpoint after the third value replays the fourth, with totals
+	// restored rather than recounted.
+	got = got[:3]
+	collect := func(_ context.Context, k Keyed[int]) error {
+		got = append(got, k)
+		return nil
+	}
+	if err := DrainWithCheckpoints(totalsPipeline(src), collect, cfg).Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := []Keyed[int]{{"ann", 1}, {"bob", 2}, {"ann", 4}, {"bob", 6}, {"ann", 9}, {"bob", 12}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("totals = %v, want %v", got, want)
+	}
+
+	cp, err := store.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.ID != 3 || cp.Records != 6 {
+		t.Errorf("checkpoint id=%d records=%d, want id=3 records=6", cp.ID, cp.Records)
+	}
+
+	// A finished job resumes at the end of its input.
+	got = nil
+	if err := DrainWithCheckpoints(totalsPipeline(src), collect, cfg).Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no values after completion, got %v", got)
+	}
+}
+
+func TestCheckpointed_OutsideCheckpointedRun(t *testing.T) {
+	got, err := Collect(context.Background(), totalsPipeline([]purchase{{"ann", 1}, {"ann", 2}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Keyed[int]{{"ann", 1}, {"ann", 3}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("totals = %v, want %v", got, want)
+	}
+}
+
+func TestCheckpointed_UnsupportedStage(t *testing.T) {
+	p := Checkpointed(Map(FromSlice([]int{1}), func(_ context.Context, v int) (int, error) { return v, nil }), "mapped")
+	err := DrainWithCheckpoints(p, func(context.Context, int) error { return nil },
+		CheckpointConfig{Store: CheckpointsInDir(t.TempDir())}).Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "does not support checkpoints") {
+		t.Errorf("expected unsupported stage error, got %v", err)
+	}
+}
+
+func TestCheckpointed_DuplicateName(t *testing.T) {
+	p := Concat(Checkpointed(FromSlice([]int{1}), "src"), Checkpointed(FromSlice([]int{2}), "src"))
+	err := DrainWithCheckpoints(p, func(context.Context, int) error { return nil },
+		CheckpointConfig{Store: CheckpointsInDir(t.TempDir())}).Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("expected duplicate stage error, got %v", err)
+	}
+}
+
+// committingIter counts checkpoint commits.
+type committingIter struct {
+	sliceIter[int]
+	commits int
+}
+
+func (it *committingIter) CommitCheckpoint(context.Context) error {
+	it.commits++
+	return nil
+}
+
+func TestDrainWithCheckpoints_CommitsAfterSave(t *testing.T) {
+	iter := &committingIter{sliceIter: sliceIter[int]{items: []int{1, 2, 3, 4, 5}}}
+	p := Checkpointed(From[int](iter), "src")
+	err := DrainWithCheckpoints(p, func(context.Context, int) error { return nil },
+		CheckpointConfig{Store: CheckpointsInDir(t.TempDir()), Every: 2}).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Checkpoints after values 2 and 4, plus the final one.
+	if iter.commits != 3 {
+		t.Errorf("commits = %d, want 3", iter.commits)
+	}
+}
+
+// memBlobs is an in-memory BlobStore.
+type memBlobs map[string][]byte
+
+func (m memBlobs) Upload(_ context.Context, path string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	m[path] = data
+	return err
+}
+
+func (m memBlobs) Download(_ context.Context, path string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(m[path])), nil
+}
+
+func (m memBlobs) Exists(_ context.Context, path string) (bool, error) {
+	_, ok := m[path]
+	return ok, nil
+}
+
+func TestCheckpointsInStorage(t *testing.T) {
+	ctx := context.Background()
+	store := CheckpointsInStorage(memBlobs{}, "jobs/orders/checkpoint.json")
+	cp, err := store.Load(ctx)
+	if err != nil || cp != nil {
+		t.Fatalf("Load on empty store = %v, %v; want nil, nil", cp, err)
+	}
+	saved := &Checkpoint{ID: 7, Records: 42, State: map[string][]byte{"src": []byte("3")}}
+	if err := store.Save(ctx, saved); err != nil {
+		t.Fatal(err)
+	}
+	cp, err = store.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.ID != 7 || cp.Records != 42 || string(cp.State["src"]) != "3" {
+		t.Errorf("loaded %+v, want %+v", cp, saved)
+	}
+}
+
+func TestMemoryState_SnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryState[int]()
+	_ = s.Set(ctx, "a", 1, 0)
+	_ = s.Set(ctx, "b", 2, 0)
+	snapshot, err := s.Snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewMemoryState[int]()
+	_ = restored.Set(ctx, "stale", 9, 0)
+	if err := restored.Restore(ctx, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok, _ := restored.Get(ctx, "b"); !ok || v != 2 {
+		t.Errorf("b = %d, %v; want 2, true", v, ok)
+	}
+	if _, ok, _ := restored.Get(ctx, "stale"); ok {
+		t.Error("expected restore to replace existing values")
+	}
+}
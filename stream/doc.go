@@ -20,7 +20,8 @@
 // No operator buffers without bound. Buffer clamps size <= 0 to 1;
 // Broadcaster clamps its per-subscriber buffer to at least 1;
 // the time/size-aware operators (Batch, TumblingWindow, SlidingWindow) emit
-// and release each group as it completes.
+// and release each group as it completes. Event-time windows hold only windows the watermark
+// has not passed plus AllowedLateness.
 // Concurrent operators (Parallel, Merge, Buffer) run owned goroutines bounded by ctx cancellation
 // and closed via the iterator's Close.
 //
@@ -53,6 +54,25 @@
 //   - Debounce: wait for silence before emitting the latest value
 //   - TumblingWindow: non-overlapping fixed-duration windows
 //   - SlidingWindow: overlapping windows with configurable slide
+//   - EventTimeTumbling, EventTimeSliding, EventTimeSession: event-time windows fired by a
+//     watermark, with allowed lateness and a side-output pipeline for late records
+//
+// Keyed/stateful:
+//
+//   - GroupByKey: split each batch or window into per-key groups
+//   - KeyedReduce: running aggregate per key, with state in a StateStore and an idle TTL
+//   - Join: windowed stream-stream inner join on key and event time
+//   - JoinTable: enrich a stream with the latest row per key from a changelog stream
+//
+// Keyed state lives in a StateStore: MemoryState by default, StateFromCache for any
+// cache.Store-shaped backend, or StateFromAppendStores for stateful.Store-shaped backends.
+//
+// Checkpointing:
+//
+//   - Checkpointed: include a source's offset or an operator's state in checkpoints
+//   - DrainWithCheckpoints: Drain that resumes from the latest checkpoint and saves new ones
+//   - CheckpointsInDir, CheckpointsInStorage: keep checkpoints in a local directory or a
+//     storage.Storage-shaped backend
 //
 // Push fan-out:
 //
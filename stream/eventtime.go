@@ -0,0 +1,293 @@
+package stream
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// EventTimeConfig configures event-time windowing.
+type EventTimeConfig[T any] struct {
+	// Timestamp extracts a record's event time.
+	Timestamp func(T) time.Time
+	// MaxOutOfOrderness holds the watermark this far behind the latest event
+	// time seen, so records that much out of order still reach their window
+	// before it fires.
+	MaxOutOfOrderness time.Duration
+	// AllowedLateness keeps a window after it fires. A record arriving within
+	// it re-emits the window as an update; later records go to the late pipeline.
+	AllowedLateness time.Duration
+}
+
+// Window is a group of records whose event times fall in [Start, End).
+type Window[T any] struct {
+	Start time.Time
+	End   time.Time
+	Items []T
+	// Update marks a re-emission of a window that already fired, carrying
+	// every record so far, after a late record arrived within AllowedLateness.
+	Update bool
+}
+
+// EventTimeTumbling groups records into non-overlapping windows of size by
+// event time. Windows are aligned to multiples of size since the zero
+// time.Time, which is midnight UTC for sizes that divide a day.
+//
+// A window fires once the watermark — the latest event time seen minus
+// MaxOutOfOrderness — reaches its end; the remaining windows fire when the
+// source is exhausted. Records for windows closed longer than AllowedLateness
+// are sent to the late pipeline.
+//
+// Both pipelines share one upstream, as with Partition, and should be consumed
+// concurrently. Close the late pipeline's iterator to discard late records.
+func EventTimeTumbling[T any](p *Pipeline[T], size time.Duration, cfg EventTimeConfig[T]) (windows *Pipeline[Window[T]], late *Pipeline[T]) {
+	return EventTimeSliding(p, size, size, cfg)
+}
+
+// EventTimeSliding groups records into overlapping windows of size starting
+// every slide, by event time. A record belongs to every window covering its
+// timestamp, and is late only if all of them have closed. With slide greater
+// than size the windows leave gaps; records in a gap belong to no window and
+// are dropped, though their event time still advances the watermark. Firing
+// and late records work as in EventTimeTumbling.
+func EventTimeSliding[T any](p *Pipeline[T], size, slide time.Duration, cfg EventTimeConfig[T]) (windows *Pipeline[Window[T]], late *Pipeline[T]) {
+	size = max(size, time.Nanosecond)
+	slide = max(slide, time.Nanosecond)
+	return eventTimeWindows(p, cfg, func(ts time.Time) []windowSpan {
+		var spans []windowSpan
+		for start := ts.Truncate(slide); start.After(ts.Add(-size)); start = start.Add(-slide) {
+			spans = append(spans, windowSpan{start: start, end: start.Add(size)})
+		}
+		return spans
+	}, 0)
+}
+
+// EventTimeSession groups records into sessions that close after gap without
+// records. A session spans from its first record to gap after its last, and
+// sessions merge when a record bridges them. Firing and late records work as
+// in EventTimeTumbling.
+func EventTimeSession[T any](p *Pipeline[T], gap time.Duration, cfg EventTimeConfig[T]) (windows *Pipeline[Window[T]], late *Pipeline[T]) {
+	gap = max(gap, time.Nanosecond)
+	return eventTimeWindows(p, cfg, func(ts time.Time) []windowSpan {
+		return []windowSpan{{start: ts, end: ts.Add(gap)}}
+	}, gap)
+}
+
+// eventTimeWindows splits the windowed output into window and late branches.
+func eventTimeWindows[T any](p *Pipeline[T], cfg EventTimeConfig[T], assign func(time.Time) []windowSpan, gap time.Duration) (*Pipeline[Window[T]], *Pipeline[T]) {
+	out := &Pipeline[windowOutput[T]]{
+		create: func(ctx context.Context) Iterator[windowOutput[T]] {
+			return &eventTimeIter[T]{source: p.create(ctx), cfg: cfg, assign: assign, merge: gap > 0}
+		},
+	}
+	onTime, lateOut := Partition(out, func(o windowOutput[T]) bool { return !o.isLate })
+	windows := Map(onTime, func(_ context.Context, o windowOutput[T]) (Window[T], error) { return o.window, nil })
+	late := Map(lateOut, func(_ context.Context, o windowOutput[T]) (T, error) { return o.late, nil })
+	return windows, late
+}
+
+type windowSpan struct {
+	start, end time.Time
+}
+
+// windowOutput is either a window or a late record.
+type windowOutput[T any] struct {
+	window Window[T]
+	late   T
+	isLate bool
+}
+
+// windowState is an open window. dirty marks records not yet emitted.
+type windowState[T any] struct {
+	windowSpan
+	items   []T
+	emitted bool
+	dirty   bool
+}
+
+type eventTimeIter[T any] struct {
+	source Iterator[T]
+	cfg    EventTimeConfig[T]
+	assign func(time.Time) []windowSpan
+	merge  bool // merge overlapping windows (sessions)
+
+	open    []*windowState[T]
+	pending []windowOutput[T]
+	maxTS   time.Time
+	started bool
+	done    bool
+}
+
+func (it *eventTimeIter[T]) Next(ctx context.Context) (result windowOutput[T], ok bool, err error) {
+	for {
+		if len(it.pending) > 0 {
+			out := it.pending[0]
+			it.pending = it.pending[1:]
+			return out, true, nil
+		}
+		if it.done {
+			return windowOutput[T]{}, false, nil
+		}
+		val, ok, err := it.source.Next(ctx)
+		if err != nil {
+			return windowOutput[T]{}, false, err
+		}
+		if !ok {
+			// End of input: no later record can arrive, so every window fires.
+			it.done = true
+			it.fire(func(*windowState[T]) bool { return true })
+			it.open = nil
+			continue
+		}
+		it.add(val)
+	}
+}
+
+func (it *eventTimeIter[T]) Close() error { return it.source.Close() }
+
+// watermark returns the event time up to which input is considered complete.
+func (it *eventTimeIter[T]) watermark() (time.Time, bool) {
+	return it.maxTS.Add(-it.cfg.MaxOutOfOrderness), it.started
+}
+
+// expired reports whether a window ending at end is past its allowed lateness.
+func (it *eventTimeIter[T]) expired(end time.Time) bool {
+	wm, ok := it.watermark()
+	return ok && !end.Add(it.cfg.AllowedLateness).After(wm)
+}
+
+func (it *eventTimeIter[T]) add(val T) {
+	ts := it.cfg.Timestamp(val)
+	spans := it.assign(ts)
+	if len(spans) == 0 {
+		// A record between hopping windows is not late; it has no window.
+		it.observe(ts)
+		return
+	}
+	var added []*windowState[T]
+	if it.merge {
+		if w := it.addMerging(val, spans[0]); w != nil {
+			added = append(added, w)
+		}
+	} else {
+		for _, span := range spans {
+			if it.expired(span.end) {
+				continue
+			}
+			w := it.lookup(span)
+			w.items = append(w.items, val)
+			w.dirty = true
+			added = append(added, w)
+		}
+	}
+	if len(added) == 0 {
+		it.pending = append(it.pending, windowOutput[T]{late: val, isLate: true})
+		return
+	}
+
+	// A record for a window the watermark already passed fires it at once.
+	if wm, ok := it.watermark(); ok {
+		for _, w := range added {
+			if !w.end.After(wm) {
+				it.emit(w)
+			}
+		}
+	}
+	it.observe(ts)
+}
+
+// observe advances the watermark to event time ts if it is the latest seen.
+func (it *eventTimeIter[T]) observe(ts time.Time) {
+	if !it.started || ts.After(it.maxTS) {
+		it.maxTS, it.started = ts, true
+		it.advance()
+	}
+}
+
+// lookup returns the open window for span, creating it if needed.
+func (it *eventTimeIter[T]) lookup(span windowSpan) *windowState[T] {
+	for _, w := range it.open {
+		if w.start.Equal(span.start) && w.end.Equal(span.end) {
+			return w
+		}
+	}
+	w := &windowState[T]{windowSpan: span}
+	it.open = append(it.open, w)
+	return w
+}
+
+// addMerging adds val as a new session and merges every open session it
+// overlaps into it. It returns nil if val opens a session that already expired.
+func (it *eventTimeIter[T]) addMerging(val T, span windowSpan) *windowState[T] {
+	merged := &windowState[T]{windowSpan: span, dirty: true}
+	kept := it.open[:0]
+	var overlapped bool
+	for _, w := range it.open {
+		if w.start.Before(span.end) && span.start.Before(w.end) {
+			overlapped = true
+			merged.start = minTime(merged.start, w.start)
+			merged.end = maxTime(merged.end, w.end)
+			merged.items = append(merged.items, w.items...)
+			merged.emitted = merged.emitted || w.emitted
+			continue
+		}
+		kept = append(kept, w)
+	}
+	if !overlapped && it.expired(span.end) {
+		return nil
+	}
+	merged.items = append(merged.items, val)
+	it.open = append(kept, merged)
+	return merged
+}
+
+// advance fires windows the watermark has passed and drops windows past
+// their allowed lateness.
+func (it *eventTimeIter[T]) advance() {
+	wm, _ := it.watermark()
+	it.fire(func(w *windowState[T]) bool { return !w.end.After(wm) })
+	it.open = slices.DeleteFunc(it.open, func(w *windowState[T]) bool { return it.expired(w.end) })
+}
+
+// fire emits dirty windows matching due, earliest end first.
+func (it *eventTimeIter[T]) fire(due func(*windowState[T]) bool) {
+	var ready []*windowState[T]
+	for _, w := range it.open {
+		if w.dirty && due(w) {
+			ready = append(ready, w)
+		}
+	}
+	slices.SortFunc(ready, func(a, b *windowState[T]) int {
+		if c := a.end.Compare(b.end); c != 0 {
+			return c
+		}
+		return a.start.Compare(b.start)
+	})
+	for _, w := range ready {
+		it.emit(w)
+	}
+}
+
+func (it *eventTimeIter[T]) emit(w *windowState[T]) {
+	it.pending = append(it.pending, windowOutput[T]{window: Window[T]{
+		Start:  w.start,
+		End:    w.end,
+		Items:  slices.Clone(w.items),
+		Update: w.emitted,
+	}})
+	w.emitted, w.dirty = true, false
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,187 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var eventBase = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// at returns a record at sec seconds after eventBase.
+func at(sec int) timedValue {
+	return timedValue{val: sec, ts: eventBase.Add(time.Duration(sec) * time.Second)}
+}
+
+func eventTimeCfg(outOfOrder, lateness time.Duration) EventTimeConfig[timedValue] {
+	return EventTimeConfig[timedValue]{
+		Timestamp:         func(v timedValue) time.Time { return v.ts },
+		MaxOutOfOrderness: outOfOrder,
+		AllowedLateness:   lateness,
+	}
+}
+
+type windowSummary struct {
+	start, end int
+	vals       []int
+	update     bool
+}
+
+// collectEventTime consumes both branches concurrently and summarizes the windows.
+func collectEventTime(t *testing.T, windows *Pipeline[Window[timedValue]], late *Pipeline[timedValue]) (got []windowSummary, lateVals []int) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	lateCh := make(chan []timedValue, 1)
+	go func() {
+		vals, _ := Collect(ctx, late)
+		lateCh <- vals
+	}()
+	ws, err := Collect(ctx, windows)
+	if err != nil {
+		t.Fatalf("collect windows: %v", err)
+	}
+	for _, w := range ws {
+		s := windowSummary{
+			start:  int(w.Start.Sub(eventBase) / time.Second),
+			end:    int(w.End.Sub(eventBase) / time.Second),
+			update: w.Update,
+		}
+		for _, v := range w.Items {
+			s.vals = append(s.vals, v.val)
+		}
+		got = append(got, s)
+	}
+	for _, v := range <-lateCh {
+		lateVals = append(lateVals, v.val)
+	}
+	return got, lateVals
+}
+
+func TestEventTimeTumbling_OutOfOrder(t *testing.T) {
+	src := FromSlice([]timedValue{at(1), at(3), at(12), at(8), at(16), at(25), at(4)})
+	windows, late := EventTimeTumbling(src, 10*time.Second, eventTimeCfg(5*time.Second, 0))
+
+	got, lateVals := collectEventTime(t, windows, late)
+	want := []windowSummary{
+		{start: 0, end: 10, vals: []int{1, 3, 8}},
+		{start: 10, end: 20, vals: []int{12, 16}},
+		{start: 20, end: 30, vals: []int{25}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("windows = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(lateVals, []int{4}) {
+		t.Errorf("late = %v, want [4]", lateVals)
+	}
+}
+
+func TestEventTimeTumbling_AllowedLatenessUpdates(t *testing.T) {
+	src := FromSlice([]timedValue{at(1), at(12), at(5), at(25), at(7)})
+	windows, late := EventTimeTumbling(src, 10*time.Second, eventTimeCfg(0, 10*time.Second))
+
+	got, lateVals := collectEventTime(t, windows, late)
+	want := []windowSummary{
+		{start: 0, end: 10, vals: []int{1}},
+		{start: 0, end: 10, vals: []int{1, 5}, update: true},
+		{start: 10, end: 20, vals: []int{12}},
+		{start: 20, end: 30, vals: []int{25}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("windows = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(lateVals, []int{7}) {
+		t.Errorf("late = %v, want [7]", lateVals)
+	}
+}
+
+func TestEventTimeSliding(t *testing.T) {
+	src := FromSlice([]timedValue{at(1), at(6), at(12)})
+	windows, late := EventTimeSliding(src, 10*time.Second, 5*time.Second, eventTimeCfg(0, 0))
+
+	got, lateVals := collectEventTime(t, windows, late)
+	want := []windowSummary{
+		{start: -5, end: 5, vals: []int{1}},
+		{start: 0, end: 10, vals: []int{1, 6}},
+		{start: 5, end: 15, vals: []int{6, 12}},
+		{start: 10, end: 20, vals: []int{12}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("windows = %+v, want %+v", got, want)
+	}
+	if len(lateVals) != 0 {
+		t.Errorf("late = %v, want none", lateVals)
+	}
+}
+
+func TestEventTimeSliding_GapsDropRecords(t *testing.T) {
+	src := FromSlice([]timedValue{at(1), at(15), at(22), at(35)})
+	windows, late := EventTimeSliding(src, 10*time.Second, 20*time.Second, eventTimeCfg(0, 0))
+
+	got, lateVals := collectEventTime(t, windows, late)
+	want := []windowSummary{
+		{start: 0, end: 10, vals: []int{1}},
+		{start: 20, end: 30, vals: []int{22}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("windows = %+v, want %+v", got, want)
+	}
+	if len(lateVals) != 0 {
+		t.Errorf("late = %v, want none: records between windows are not late", lateVals)
+	}
+}
+
+func TestEventTimeSession_Merges(t *testing.T) {
+	src := FromSlice([]timedValue{at(1), at(3), at(10), at(6), at(30), at(2)})
+	windows, late := EventTimeSession(src, 5*time.Second, eventTimeCfg(3*time.Second, 0))
+
+	got, lateVals := collectEventTime(t, windows, late)
+	want := []windowSummary{
+		{start: 1, end: 15, vals: []int{1, 3, 10, 6}},
+		{start: 30, end: 35, vals: []int{30}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("windows = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(lateVals, []int{2}) {
+		t.Errorf("late = %v, want [2]", lateVals)
+	}
+}
+
+func TestEventTimeWindows_DiscardLate(t *testing.T) {
+	src := FromSlice([]timedValue{at(1), at(20), at(2), at(3), at(40)})
+	windows, late := EventTimeTumbling(src, 10*time.Second, eventTimeCfg(0, 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_ = late.Iter(ctx).Close()
+
+	got, err := Collect(ctx, windows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Errorf("expected 3 windows, got %d", len(got))
+	}
+}
+
+func TestEventTimeWindows_SourceError(t *testing.T) {
+	boom := errors.New("boom")
+	ch := make(chan result[timedValue], 2)
+	ch <- result[timedValue]{val: at(1), ok: true}
+	ch <- result[timedValue]{err: boom}
+	src := FromFunc(func(ctx context.Context) Iterator[timedValue] {
+		return &channelIter[timedValue]{ch: ch}
+	})
+	windows, late := EventTimeTumbling(src, 10*time.Second, eventTimeCfg(0, 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	go func() { _, _ = Collect(ctx, late) }()
+	if _, err := Collect(ctx, windows); !errors.Is(err, boom) {
+		t.Errorf("expected source error, got %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Joined pairs matching records from two inputs.
+type Joined[L, R any] struct {
+	Key   string
+	Left  L
+	Right R
+}
+
+// JoinConfig configures a windowed stream-stream join.
+type JoinConfig[L, R any] struct {
+	LeftKey   func(L) string
+	RightKey  func(R) string
+	LeftTime  func(L) time.Time
+	RightTime func(R) time.Time
+	// Within is the largest event-time distance between joined records.
+	Within time.Duration
+	// LeftStore and RightStore buffer each side's records per key.
+	// Default: a new MemoryState per run.
+	LeftStore  StateStore[[]L]
+	RightStore StateStore[[]R]
+	// TTL drops a key's buffered records after this long without records (0 = keep).
+	TTL time.Duration
+}
+
+// Join is an inner join of two streams on key, pairing records whose event
+// times are at most cfg.Within apart. Each pair is emitted once, when the
+// later of its two records arrives. Both inputs are consumed concurrently.
+//
+// Records older than the newest event time seen on either input minus Within
+// can no longer match and are dropped from a key's buffer when the key is next
+// used, so inputs should be roughly in event-time order.
+func Join[L, R any](left *Pipeline[L], right *Pipeline[R], cfg JoinConfig[L, R]) *Pipeline[Joined[L, R]] {
+	merged := Merge(
+		Map(left, func(_ context.Context, l L) (joinInput[L, R], error) { return joinInput[L, R]{left: l}, nil }),
+		Map(right, func(_ context.Context, r R) (joinInput[L, R], error) {
+			return joinInput[L, R]{right: r, isRight: true}, nil
+		}),
+	)
+	return &Pipeline[Joined[L, R]]{
+		create: func(ctx context.Context) Iterator[Joined[L, R]] {
+			leftStore, rightStore := cfg.LeftStore, cfg.RightStore
+			if leftStore == nil {
+				leftStore = NewMemoryState[[]L]()
+			}
+			if rightStore == nil {
+				rightStore = NewMemoryState[[]R]()
+			}
+			return &joinIter[L, R]{source: merged.create(ctx), cfg: cfg, leftStore: leftStore, rightStore: rightStore}
+		},
+	}
+}
+
+// joinInput is a record from either side of a join.
+type joinInput[L, R any] struct {
+	left    L
+	right   R
+	isRight bool
+}
+
+type joinIter[L, R any] struct {
+	source     Iterator[joinInput[L, R]]
+	cfg        JoinConfig[L, R]
+	leftStore  StateStore[[]L]
+	rightStore StateStore[[]R]
+	maxTS      time.Time
+	pending    []Joined[L, R]
+}
+
+func (it *joinIter[L, R]) Next(ctx context.Context) (result Joined[L, R], ok bool, err error) {
+	for len(it.pending) == 0 {
+		in, ok, err := it.source.Next(ctx)
+		if err != nil || !ok {
+			return Joined[L, R]{}, false, err
+		}
+		if in.isRight {
+			err = it.addRight(ctx, in.right)
+		} else {
+			err = it.addLeft(ctx, in.left)
+		}
+		if err != nil {
+			return Joined[L, R]{}, false, err
+		}
+	}
+	out := it.pending[0]
+	it.pending = it.pending[1:]
+	return out, true, nil
+}
+
+func (it *joinIter[L, R]) Close() error { return it.source.Close() }
+
+func (it *joinIter[L, R]) addLeft(ctx context.Context, l L) error {
+	k, ts := it.cfg.LeftKey(l), it.cfg.LeftTime(l)
+	it.observe(ts)
+	rights, err := joinBuffer(ctx, it.rightStore, k, it.cfg.RightTime, it.horizon(), it.cfg.TTL)
+	if err != nil {
+		return err
+	}
+	for _, r := range rights {
+		if within(ts, it.cfg.RightTime(r), it.cfg.Within) {
+			it.pending = append(it.pending, Joined[L, R]{Key: k, Left: l, Right: r})
+		}
+	}
+	return joinAppend(ctx, it.leftStore, k, l, it.cfg.LeftTime, it.horizon(), it.cfg.TTL)
+}
+
+func (it *joinIter[L, R]) addRight(ctx context.Context, r R) error {
+	k, ts := it.cfg.RightKey(r), it.cfg.RightTime(r)
+	it.observe(ts)
+	lefts, err := joinBuffer(ctx, it.leftStore, k, it.cfg.LeftTime, it.horizon(), it.cfg.TTL)
+	if err != nil {
+		return err
+	}
+	for _, l := range lefts {
+		if within(ts, it.cfg.LeftTime(l), it.cfg.Within) {
+			it.pending = append(it.pending, Joined[L, R]{Key: k, Left: l, Right: r})
+		}
+	}
+	return joinAppend(ctx, it.rightStore, k, r, it.cfg.RightTime, it.horizon(), it.cfg.TTL)
+}
+
+func (it *joinIter[L, R]) observe(ts time.Time) {
+	if ts.After(it.maxTS) {
+		it.maxTS = ts
+	}
+}
+
+// horizon is the event time before which buffered records can no longer match.
+func (it *joinIter[L, R]) horizon() time.Time {
+	return it.maxTS.Add(-it.cfg.Within)
+}
+
+// joinBuffer returns key's buffered records that are not before horizon,
+// writing the buffer back if any were dropped.
+func joinBuffer[V any](ctx context.Context, store StateStore[[]V], key string, ts func(V) time.Time, horizon time.Time, ttl time.Duration) ([]V, error) {
+	buf, _, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("stream: join state %q: %w", key, err)
+	}
+	kept := slices.DeleteFunc(slices.Clone(buf), func(v V) bool { return ts(v).Before(horizon) })
+	if len(kept) == len(buf) {
+		return buf, nil
+	}
+	if len(kept) == 0 {
+		err = store.Delete(ctx, key)
+	} else {
+		err = store.Set(ctx, key, kept, ttl)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("stream: join state %q: %w", key, err)
+	}
+	return kept, nil
+}
+
+// joinAppend adds v to key's buffer, dropping records before horizon.
+func joinAppend[V any](ctx context.Context, store StateStore[[]V], key string, v V, ts func(V) time.Time, horizon time.Time, ttl time.Duration) error {
+	buf, err := joinBuffer(ctx, store, key, ts, horizon, ttl)
+	if err != nil {
+		return err
+	}
+	if err := store.Set(ctx, key, append(slices.Clone(buf), v), ttl); err != nil {
+		return fmt.Errorf("stream: join state %q: %w", key, err)
+	}
+	return nil
+}
+
+func within(a, b time.Time, d time.Duration) bool {
+	diff := a.Sub(b)
+	return diff <= d && diff >= -d
+}
+
+// TableJoinConfig configures a stream-table join.
+type TableJoinConfig[T, V any] struct {
+	Key      func(T) string
+	TableKey func(V) string
+	// Store holds the latest table row per key. A persistent store keeps the
+	// table across runs. Default: a new MemoryState per run.
+	Store StateStore[V]
+	// TTL drops a table row after this long without updates (0 = keep).
+	TTL time.Duration
+}
+
+// JoinTable joins each record of p with the latest row for its key from
+// table, a changelog stream whose records upsert rows. Both inputs are
+// consumed concurrently, so a record sees the rows received before it.
+// Records without a row are dropped (inner join).
+func JoinTable[T, V any](p *Pipeline[T], table *Pipeline[V], cfg TableJoinConfig[T, V]) *Pipeline[Joined[T, V]] {
+	merged := Merge(
+		Map(p, func(_ context.Context, t T) (joinInput[T, V], error) { return joinInput[T, V]{left: t}, nil }),
+		Map(table, func(_ context.Context, v V) (joinInput[T, V], error) {
+			return joinInput[T, V]{right: v, isRight: true}, nil
+		}),
+	)
+	return &Pipeline[Joined[T, V]]{
+		create: func(ctx context.Context) Iterator[Joined[T, V]] {
+			store := cfg.Store
+			if store == nil {
+				store = NewMemoryState[V]()
+			}
+			return &tableJoinIter[T, V]{source: merged.create(ctx), cfg: cfg, store: store}
+		},
+	}
+}
+
+type tableJoinIter[T, V any] struct {
+	source Iterator[joinInput[T, V]]
+	cfg    TableJoinConfig[T, V]
+	store  StateStore[V]
+}
+
+func (it *tableJoinIter[T, V]) Next(ctx context.Context) (result Joined[T, V], ok bool, err error) {
+	for {
+		in, ok, err := it.source.Next(ctx)
+		if err != nil || !ok {
+			return Joined[T, V]{}, false, err
+		}
+		if in.isRight {
+			k := it.cfg.TableKey(in.right)
+			if err := it.store.Set(ctx, k, in.right, it.cfg.TTL); err != nil {
+				return Joined[T, V]{}, false, fmt.Errorf("stream: table state %q: %w", k, err)
+			}
+			continue
+		}
+		k := it.cfg.Key(in.left)
+		row, found, err := it.store.Get(ctx, k)
+		if err != nil {
+			return Joined[T, V]{}, false, fmt.Errorf("stream: table state %q: %w", k, err)
+		}
+		if found {
+			return Joined[T, V]{Key: k, Left: in.left, Right: row}, true, nil
+		}
+	}
+}
+
+func (it *tableJoinIter[T, V]) Close() error { return it.source.Close() }
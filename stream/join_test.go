@@ -0,0 +1,143 @@
+package stream
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+type click struct {
+	user string
+	at   time.Time
+}
+
+type view struct {
+	user string
+	page string
+	at   time.Time
+}
+
+func joinConfig() JoinConfig[click, view] {
+	return JoinConfig[click, view]{
+		LeftKey:   func(c click) string { return c.user },
+		RightKey:  func(v view) string { return v.user },
+		LeftTime:  func(c click) time.Time { return c.at },
+		RightTime: func(v view) time.Time { return v.at },
+		Within:    5 * time.Second,
+	}
+}
+
+func TestJoin_OnlyPairsWithinWindow(t *testing.T) {
+	sec := func(n int) time.Time { return eventBase.Add(time.Duration(n) * time.Second) }
+	clicks := FromSlice([]click{{"ann", sec(10)}, {"bob", sec(11)}, {"ann", sec(30)}})
+	views := FromSlice([]view{{"ann", "home", sec(7)}, {"ann", "cart", sec(14)}, {"bob", "home", sec(40)}, {"ann", "pay", sec(50)}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	got, err := Collect(ctx, Join(clicks, views, joinConfig()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pairs []string
+	for _, j := range got {
+		pairs = append(pairs, j.Key+":"+j.Right.page)
+		if d := j.Left.at.Sub(j.Right.at); d > 5*time.Second || d < -5*time.Second {
+			t.Errorf("joined records %v apart", d)
+		}
+	}
+	// Arrival order across the inputs is not fixed, and a far-ahead record can
+	// prune buffers before a pair meets, so only check which pairs may appear.
+	sort.Strings(pairs)
+	for _, p := range pairs {
+		if p != "ann:home" && p != "ann:cart" {
+			t.Errorf("unexpected pair %s", p)
+		}
+	}
+}
+
+func TestJoin_Deterministic(t *testing.T) {
+	sec := func(n int) time.Time { return eventBase.Add(time.Duration(n) * time.Second) }
+	viewsCh := make(chan result[view])
+	views := FromFunc(func(ctx context.Context) Iterator[view] { return &channelIter[view]{ch: viewsCh} })
+	clicksCh := make(chan result[click])
+	clicks := FromFunc(func(ctx context.Context) Iterator[click] { return &channelIter[click]{ch: clicksCh} })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	iter := Join(clicks, views, joinConfig()).Iter(ctx)
+	defer func() { _ = iter.Close() }()
+
+	viewsCh <- result[view]{val: view{"ann", "home", sec(7)}, ok: true}
+	viewsCh <- result[view]{val: view{"ann", "cart", sec(9)}, ok: true}
+	clicksCh <- result[click]{val: click{"ann", sec(10)}, ok: true}
+	for _, page := range []string{"home", "cart"} {
+		j, ok, err := iter.Next(ctx)
+		if err != nil || !ok || j.Right.page != page || j.Key != "ann" {
+			t.Fatalf("Next = %+v, %v, %v; want ann:%s", j, ok, err, page)
+		}
+	}
+	clicksCh <- result[click]{val: click{"ann", sec(20)}, ok: true} // too far from both views
+	viewsCh <- result[view]{val: view{"ann", "pay", sec(22)}, ok: true}
+	j, ok, err := iter.Next(ctx)
+	if err != nil || !ok || j.Right.page != "pay" || !j.Left.at.Equal(sec(20)) {
+		t.Fatalf("Next = %+v, %v, %v; want the 20s click with pay", j, ok, err)
+	}
+	close(viewsCh)
+	close(clicksCh)
+	if _, ok, err := iter.Next(ctx); ok || err != nil {
+		t.Fatalf("expected end of join, got %v, %v", ok, err)
+	}
+}
+
+func TestJoinTable_LatestRow(t *testing.T) {
+	store := NewMemoryState[view]()
+	tableCh := make(chan result[view])
+	table := FromFunc(func(ctx context.Context) Iterator[view] { return &channelIter[view]{ch: tableCh} })
+	clicksCh := make(chan result[click])
+	clicks := FromFunc(func(ctx context.Context) Iterator[click] { return &channelIter[click]{ch: clicksCh} })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	joined := JoinTable(clicks, table, TableJoinConfig[click, view]{
+		Key:      func(c click) string { return c.user },
+		TableKey: func(v view) string { return v.user },
+		Store:    store,
+	})
+	out := make(chan Joined[click, view])
+	go func() {
+		defer close(out)
+		_ = ForEach(ctx, joined, func(_ context.Context, j Joined[click, view]) error {
+			out <- j
+			return nil
+		})
+	}()
+
+	// upsert waits until the join has applied the row, so later clicks see it.
+	upsert := func(v view) {
+		tableCh <- result[view]{val: v, ok: true}
+		for {
+			if row, ok, _ := store.Get(ctx, v.user); ok && row.page == v.page {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	upsert(view{user: "ann", page: "home"})
+	clicksCh <- result[click]{val: click{user: "bob"}, ok: true} // no row: dropped
+	clicksCh <- result[click]{val: click{user: "ann"}, ok: true}
+	if j := <-out; j.Key != "ann" || j.Right.page != "home" {
+		t.Fatalf("joined %+v, want ann with home", j)
+	}
+
+	upsert(view{user: "ann", page: "cart"})
+	clicksCh <- result[click]{val: click{user: "ann"}, ok: true}
+	if j := <-out; j.Right.page != "cart" {
+		t.Errorf("expected latest row cart, got %q", j.Right.page)
+	}
+	close(tableCh)
+	close(clicksCh)
+	if _, ok := <-out; ok {
+		t.Error("expected no more joined records")
+	}
+}
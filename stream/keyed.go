@@ -0,0 +1,117 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Keyed is a value tagged with the key it was grouped or aggregated by.
+type Keyed[V any] struct {
+	Key   string
+	Value V
+}
+
+// KeyedConfig configures the state of a keyed operator.
+type KeyedConfig[S any] struct {
+	// Store holds per-key state. Default: a new MemoryState per run.
+	Store StateStore[S]
+	// TTL drops a key's state after this long without records (0 = keep).
+	TTL time.Duration
+}
+
+func (c KeyedConfig[S]) store() StateStore[S] {
+	if c.Store == nil {
+		return NewMemoryState[S]()
+	}
+	return c.Store
+}
+
+// GroupByKey splits each batch — from Batch, TumblingWindow or a mapped
+// event-time Window — into one group per key, emitted in first-seen key
+// order with values in arrival order. Only one batch is held at a time.
+func GroupByKey[T any](p *Pipeline[[]T], key func(T) string) *Pipeline[Keyed[[]T]] {
+	return FlatMap(p, func(_ context.Context, batch []T) (Iterator[Keyed[[]T]], error) {
+		index := make(map[string]int)
+		var groups []Keyed[[]T]
+		for _, v := range batch {
+			k := key(v)
+			i, ok := index[k]
+			if !ok {
+				i = len(groups)
+				index[k] = i
+				groups = append(groups, Keyed[[]T]{Key: k})
+			}
+			groups[i].Value = append(groups[i].Value, v)
+		}
+		return &sliceIter[Keyed[[]T]]{items: groups}, nil
+	})
+}
+
+// KeyedReduce keeps a running aggregate per key, starting from init, and
+// emits the key's updated aggregate for every record. Aggregates live in
+// cfg.Store rather than in the pipeline, so only active keys use memory and
+// a persistent store carries them across runs.
+func KeyedReduce[T, A any](p *Pipeline[T], key func(T) string, init A, fn func(A, T) A, cfg KeyedConfig[A]) *Pipeline[Keyed[A]] {
+	return &Pipeline[Keyed[A]]{
+		create: func(ctx context.Context) Iterator[Keyed[A]] {
+			return &keyedReduceIter[T, A]{
+				source: p.create(ctx),
+				key:    key,
+				init:   init,
+				fn:     fn,
+				store:  cfg.store(),
+				ttl:    cfg.TTL,
+			}
+		},
+	}
+}
+
+type keyedReduceIter[T, A any] struct {
+	source Iterator[T]
+	key    func(T) string
+	init   A
+	fn     func(A, T) A
+	store  StateStore[A]
+	ttl    time.Duration
+}
+
+func (it *keyedReduceIter[T, A]) Next(ctx context.Context) (result Keyed[A], ok bool, err error) {
+	val, ok, err := it.source.Next(ctx)
+	if err != nil || !ok {
+		return Keyed[A]{}, false, err
+	}
+	k := it.key(val)
+	acc, found, err := it.store.Get(ctx, k)
+	if err != nil {
+		return Keyed[A]{}, false, fmt.Errorf("stream: keyed reduce %q: %w", k, err)
+	}
+	if !found {
+		acc = it.init
+	}
+	acc = it.fn(acc, val)
+	if err := it.store.Set(ctx, k, acc, it.ttl); err != nil {
+		return Keyed[A]{}, false, fmt.Errorf("stream: keyed reduce %q: %w", k, err)
+	}
+	return Keyed[A]{Key: k, Value: acc}, true, nil
+}
+
+func (it *keyedReduceIter[T, A]) Close() error { return it.source.Close() }
+
+// Snapshot implements Checkpointable. A store that is itself Checkpointable,
+// such as MemoryState, is snapshotted; other stores persist state on their own
+// and contribute nothing.
+func (it *keyedReduceIter[T, A]) Snapshot(ctx context.Context) ([]byte, error) {
+	if c, ok := it.store.(Checkpointable); ok {
+		return c.Snapshot(ctx)
+	}
+	return nil, nil
+}
+
+// Restore implements Checkpointable.
+func (it *keyedReduceIter[T, A]) Restore(ctx context.Context, snapshot []byte) error {
+	if c, ok := it.store.(Checkpointable); ok && snapshot != nil {
+		return c.Restore(ctx, snapshot)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type purchase struct {
+	user   string
+	amount int
+}
+
+func TestGroupByKey(t *testing.T) {
+	src := FromSlice([][]purchase{
+		{{"ann", 1}, {"bob", 2}, {"ann", 3}},
+		{{"cat", 4}},
+	})
+	groups, err := Collect(context.Background(), GroupByKey(src, func(p purchase) string { return p.user }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Keyed[[]purchase]{
+		{Key: "ann", Value: []purchase{{"ann", 1}, {"ann", 3}}},
+		{Key: "bob", Value: []purchase{{"bob", 2}}},
+		{Key: "cat", Value: []purchase{{"cat", 4}}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("groups = %+v, want %+v", groups, want)
+	}
+}
+
+func TestKeyedReduce_RunningTotals(t *testing.T) {
+	src := FromSlice([]purchase{{"ann", 1}, {"bob", 2}, {"ann", 3}, {"bob", 4}})
+	totals := KeyedReduce(src, func(p purchase) string { return p.user }, 0,
+		func(sum int, p purchase) int { return sum + p.amount }, KeyedConfig[int]{})
+
+	got, err := Collect(context.Background(), totals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Keyed[int]{{"ann", 1}, {"bob", 2}, {"ann", 4}, {"bob", 6}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("totals = %v, want %v", got, want)
+	}
+}
+
+func TestKeyedReduce_StateSurvivesRunsAndExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryState[int]()
+	store.now = func() time.Time { return now }
+	cfg := KeyedConfig[int]{Store: store, TTL: time.Hour}
+	count := func(items []purchase) []Keyed[int] {
+		got, err := Collect(ctx, KeyedReduce(FromSlice(items), func(p purchase) string { return p.user }, 0,
+			func(n int, _ purchase) int { return n + 1 }, cfg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	count([]purchase{{"ann", 1}, {"ann", 1}})
+	if got := count([]purchase{{"ann", 1}}); got[0].Value != 3 {
+		t.Errorf("expected count to continue from shared store, got %d", got[0].Value)
+	}
+	now = now.Add(time.Hour)
+	if got := count([]purchase{{"ann", 1}}); got[0].Value != 1 {
+		t.Errorf("expected expired state to restart at init, got %d", got[0].Value)
+	}
+}
@@ -1,6 +1,10 @@
 package stream
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
 
 // Iterator provides pull-based sequential access to a stream of values.
 // Structurally compatible with provider.Iterator[T].
@@ -155,3 +159,21 @@ func (it *sliceIter[T]) Next(_ context.Context) (result T, ok bool, err error) {
 }
 
 func (it *sliceIter[T]) Close() error { return nil }
+
+// Snapshot implements Checkpointable with the index of the next value.
+func (it *sliceIter[T]) Snapshot(context.Context) ([]byte, error) {
+	return json.Marshal(it.index)
+}
+
+// Restore implements Checkpointable.
+func (it *sliceIter[T]) Restore(_ context.Context, snapshot []byte) error {
+	var index int
+	if err := json.Unmarshal(snapshot, &index); err != nil {
+		return err
+	}
+	if index < 0 || index > len(it.items) {
+		return fmt.Errorf("stream: offset %d outside %d values", index, len(it.items))
+	}
+	it.index = index
+	return nil
+}
@@ -0,0 +1,282 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// StateStore holds the per-key state of keyed operators. A value written
+// with a positive ttl expires ttl after it was last written.
+type StateStore[V any] interface {
+	// Get returns the value for key. Returns (zero, false, nil) if it is missing or expired.
+	Get(ctx context.Context, key string) (V, bool, error)
+	// Set stores the value for key.
+	Set(ctx context.Context, key string, value V, ttl time.Duration) error
+	// Delete removes the value for key.
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryState is the default in-process StateStore. Expired values are
+// dropped when read and swept as the store grows.
+type MemoryState[V any] struct {
+	mu        sync.Mutex
+	entries   map[string]memoryStateEntry[V]
+	sweepSize int
+	now       func() time.Time
+}
+
+type memoryStateEntry[V any] struct {
+	value   V
+	expires time.Time // zero = never
+}
+
+// NewMemoryState creates an empty in-memory state store.
+func NewMemoryState[V any]() *MemoryState[V] {
+	return &MemoryState[V]{entries: make(map[string]memoryStateEntry[V]), sweepSize: 64, now: time.Now}
+}
+
+// Get implements StateStore.
+func (s *MemoryState[V]) Get(_ context.Context, key string) (V, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok || s.expired(e) {
+		delete(s.entries, key)
+		var zero V
+		return zero, false, nil
+	}
+	return e.value, true, nil
+}
+
+// Set implements StateStore.
+func (s *MemoryState[V]) Set(_ context.Context, key string, value V, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := memoryStateEntry[V]{value: value}
+	if ttl > 0 {
+		e.expires = s.now().Add(ttl)
+	}
+	s.entries[key] = e
+	// Sweep when the map doubles so idle keys do not accumulate.
+	if len(s.entries) >= s.sweepSize {
+		for k, e := range s.entries {
+			if s.expired(e) {
+				delete(s.entries, k)
+			}
+		}
+		s.sweepSize = max(2*len(s.entries), 64)
+	}
+	return nil
+}
+
+// Delete implements StateStore.
+func (s *MemoryState[V]) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.entries, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of stored values, including expired ones not yet swept.
+func (s *MemoryState[V]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// memoryStateSnapshot is the JSON form of a MemoryState entry.
+type memoryStateSnapshot[V any] struct {
+	Value   V         `json:"value"`
+	Expires time.Time `json:"expires"`
+}
+
+// Snapshot implements Checkpointable, encoding unexpired values as JSON.
+func (s *MemoryState[V]) Snapshot(context.Context) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]memoryStateSnapshot[V], len(s.entries))
+	for k, e := range s.entries {
+		if !s.expired(e) {
+			out[k] = memoryStateSnapshot[V]{Value: e.value, Expires: e.expires}
+		}
+	}
+	return json.Marshal(out)
+}
+
+// Restore implements Checkpointable, replacing all values with the snapshot's.
+func (s *MemoryState[V]) Restore(_ context.Context, snapshot []byte) error {
+	var in map[string]memoryStateSnapshot[V]
+	if err := json.Unmarshal(snapshot, &in); err != nil {
+		return fmt.Errorf("stream: decode state snapshot: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = make(map[string]memoryStateEntry[V], len(in))
+	for k, e := range in {
+		s.entries[k] = memoryStateEntry[V]{value: e.Value, expires: e.Expires}
+	}
+	s.sweepSize = max(2*len(s.entries), 64)
+	return nil
+}
+
+func (s *MemoryState[V]) expired(e memoryStateEntry[V]) bool {
+	return !e.expires.IsZero() && !s.now().Before(e.expires)
+}
+
+// ByteStore is a key-value store with expiry. It is structurally compatible
+// with cache.Store, so any cache backend can hold operator state.
+type ByteStore interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// CacheState is a StateStore that keeps JSON-encoded values in a ByteStore.
+type CacheState[V any] struct {
+	store  ByteStore
+	prefix string
+}
+
+// StateFromCache stores state in store under keys prefixed with prefix.
+func StateFromCache[V any](store ByteStore, prefix string) *CacheState[V] {
+	return &CacheState[V]{store: store, prefix: prefix}
+}
+
+// Get implements StateStore.
+func (s *CacheState[V]) Get(ctx context.Context, key string) (V, bool, error) {
+	var v V
+	raw, ok, err := s.store.Get(ctx, s.prefix+key)
+	if err != nil || !ok {
+		return v, false, err
+	}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return v, false, fmt.Errorf("stream: decode state %q: %w", key, err)
+	}
+	return v, true, nil
+}
+
+// Set implements StateStore.
+func (s *CacheState[V]) Set(ctx context.Context, key string, value V, ttl time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("stream: encode state %q: %w", key, err)
+	}
+	return s.store.Set(ctx, s.prefix+key, raw, ttl)
+}
+
+// Delete implements StateStore.
+func (s *CacheState[V]) Delete(ctx context.Context, key string) error {
+	return s.store.Delete(ctx, s.prefix+key)
+}
+
+// AppendStore is an append-only value log. It is structurally compatible with
+// stateful.Store[V], so stateful backends can hold operator state.
+type AppendStore[V any] interface {
+	Append(ctx context.Context, value V) error
+	Get(ctx context.Context) ([]V, error)
+	Flush(ctx context.Context) ([]V, error)
+	LastActivity(ctx context.Context) (time.Time, error)
+	Close() error
+}
+
+// AppendState is a StateStore keeping each key's value as the single entry
+// of its own AppendStore. TTL is measured from the store's last activity.
+type AppendState[V any] struct {
+	open func(key string) AppendStore[V]
+	now  func() time.Time
+
+	mu     sync.Mutex
+	stores map[string]*appendStateEntry[V]
+}
+
+type appendStateEntry[V any] struct {
+	store AppendStore[V]
+	ttl   time.Duration
+}
+
+// StateFromAppendStores opens one AppendStore per key with open, for
+// example a stateful.Store[V] namespaced by key.
+func StateFromAppendStores[V any](open func(key string) AppendStore[V]) *AppendState[V] {
+	return &AppendState[V]{open: open, now: time.Now, stores: make(map[string]*appendStateEntry[V])}
+}
+
+func (s *AppendState[V]) entry(key string) *appendStateEntry[V] {
+	e, ok := s.stores[key]
+	if !ok {
+		e = &appendStateEntry[V]{store: s.open(key)}
+		s.stores[key] = e
+	}
+	return e
+}
+
+// Get implements StateStore.
+func (s *AppendState[V]) Get(ctx context.Context, key string) (V, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var zero V
+	e := s.entry(key)
+	if e.ttl > 0 {
+		last, err := e.store.LastActivity(ctx)
+		if err != nil {
+			return zero, false, fmt.Errorf("stream: state %q: %w", key, err)
+		}
+		if !s.now().Before(last.Add(e.ttl)) {
+			return zero, false, s.deleteLocked(ctx, key, e)
+		}
+	}
+	values, err := e.store.Get(ctx)
+	if err != nil {
+		return zero, false, fmt.Errorf("stream: state %q: %w", key, err)
+	}
+	if len(values) == 0 {
+		return zero, false, nil
+	}
+	return values[len(values)-1], true, nil
+}
+
+// Set implements StateStore.
+func (s *AppendState[V]) Set(ctx context.Context, key string, value V, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.entry(key)
+	e.ttl = ttl
+	if _, err := e.store.Flush(ctx); err != nil {
+		return fmt.Errorf("stream: state %q: %w", key, err)
+	}
+	if err := e.store.Append(ctx, value); err != nil {
+		return fmt.Errorf("stream: state %q: %w", key, err)
+	}
+	return nil
+}
+
+// Delete implements StateStore. It flushes and closes the key's store.
+func (s *AppendState[V]) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.stores[key]
+	if !ok {
+		return nil
+	}
+	return s.deleteLocked(ctx, key, e)
+}
+
+func (s *AppendState[V]) deleteLocked(ctx context.Context, key string, e *appendStateEntry[V]) error {
+	delete(s.stores, key)
+	_, err := e.store.Flush(ctx)
+	if cerr := e.store.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("stream: state %q: %w", key, err)
+	}
+	return nil
+}
+
+var (
+	_ StateStore[int] = (*MemoryState[int])(nil)
+	_ StateStore[int] = (*CacheState[int])(nil)
+	_ StateStore[int] = (*AppendState[int])(nil)
+)
@@ -0,0 +1,159 @@
+package stream
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryState_TTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryState[int]()
+	s.now = func() time.Time { return now }
+
+	_ = s.Set(ctx, "a", 1, time.Minute)
+	_ = s.Set(ctx, "b", 2, 0)
+	if v, ok, _ := s.Get(ctx, "a"); !ok || v != 1 {
+		t.Fatalf("Get(a) = %d, %v", v, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok, _ := s.Get(ctx, "a"); ok {
+		t.Error("expected a to expire")
+	}
+	if v, ok, _ := s.Get(ctx, "b"); !ok || v != 2 {
+		t.Errorf("Get(b) = %d, %v; values without TTL never expire", v, ok)
+	}
+	_ = s.Delete(ctx, "b")
+	if s.Len() != 0 {
+		t.Errorf("expected empty store, got %d entries", s.Len())
+	}
+}
+
+func TestMemoryState_SweepsExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryState[int]()
+	s.now = func() time.Time { return now }
+
+	for i := range 63 {
+		_ = s.Set(ctx, string(rune('a'+i)), i, time.Second)
+	}
+	now = now.Add(time.Second)
+	_ = s.Set(ctx, "live", 1, 0)
+	if s.Len() != 1 {
+		t.Errorf("expected expired keys to be swept, got %d entries", s.Len())
+	}
+}
+
+// mapByteStore is a ByteStore shaped like cache.Store.
+type mapByteStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	ttls map[string]time.Duration
+}
+
+func newMapByteStore() *mapByteStore {
+	return &mapByteStore{data: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+func (m *mapByteStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.data[key]
+	return v, ok, nil
+}
+
+func (m *mapByteStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key], m.ttls[key] = value, ttl
+	return nil
+}
+
+func (m *mapByteStore) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+func TestCacheState_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	backend := newMapByteStore()
+	s := StateFromCache[[]string](backend, "orders:")
+
+	if err := s.Set(ctx, "u1", []string{"a", "b"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if string(backend.data["orders:u1"]) != `["a","b"]` || backend.ttls["orders:u1"] != time.Hour {
+		t.Fatalf("stored %q with ttl %v", backend.data["orders:u1"], backend.ttls["orders:u1"])
+	}
+	v, ok, err := s.Get(ctx, "u1")
+	if err != nil || !ok || len(v) != 2 || v[1] != "b" {
+		t.Fatalf("Get = %v, %v, %v", v, ok, err)
+	}
+	_ = s.Delete(ctx, "u1")
+	if _, ok, _ := s.Get(ctx, "u1"); ok {
+		t.Error("expected deleted key to be missing")
+	}
+
+	backend.data["orders:bad"] = []byte("{")
+	if _, _, err := s.Get(ctx, "bad"); err == nil {
+		t.Error("expected decode error")
+	}
+}
+
+// logStore is an AppendStore shaped like stateful.Store.
+type logStore struct {
+	values []int
+	last   time.Time
+	closed bool
+}
+
+func (l *logStore) Append(_ context.Context, v int) error {
+	l.values = append(l.values, v)
+	return nil
+}
+func (l *logStore) Get(context.Context) ([]int, error) { return l.values, nil }
+func (l *logStore) Flush(context.Context) ([]int, error) {
+	v := l.values
+	l.values = nil
+	return v, nil
+}
+func (l *logStore) LastActivity(context.Context) (time.Time, error) { return l.last, nil }
+func (l *logStore) Close() error {
+	l.closed = true
+	return nil
+}
+
+func TestAppendState_KeepsLatestValue(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	opened := map[string]*logStore{}
+	s := StateFromAppendStores(func(key string) AppendStore[int] {
+		l := &logStore{last: now}
+		opened[key] = l
+		return l
+	})
+	s.now = func() time.Time { return now }
+
+	_ = s.Set(ctx, "k", 1, time.Minute)
+	_ = s.Set(ctx, "k", 2, time.Minute)
+	if v, ok, _ := s.Get(ctx, "k"); !ok || v != 2 {
+		t.Fatalf("Get = %d, %v; want 2", v, ok)
+	}
+	if len(opened["k"].values) != 1 {
+		t.Errorf("expected one stored value, got %v", opened["k"].values)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok, _ := s.Get(ctx, "k"); ok {
+		t.Error("expected value to expire after TTL")
+	}
+	if !opened["k"].closed {
+		t.Error("expected expired store to be closed")
+	}
+}
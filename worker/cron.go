@@ -0,0 +1,275 @@
+package worker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	gkerrors "github.com/kbukum/gokit/errors"
+)
+
+// Schedule computes the activation times of a scheduled job.
+type Schedule interface {
+	// Next returns the first activation time strictly after the given time,
+	// or the zero time if there is none.
+	Next(after time.Time) time.Time
+}
+
+// cronSearchYears bounds how far Next looks ahead for a matching time, so that
+// impossible expressions such as "0 0 30 2 *" terminate.
+const cronSearchYears = 5
+
+// cronField is the value range of one cron field.
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+// lastImplicit is the upper bound of "*" and "n/step", which excludes the
+// Sunday alias 7 so that it is not selected twice.
+func (f cronField) lastImplicit() int {
+	if f.name == cronDow.name {
+		return 6
+	}
+	return f.max
+}
+
+var (
+	cronSecond = cronField{name: "second", min: 0, max: 59}
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Day of week accepts 7 as an alias for Sunday.
+	cronDow = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// CronSchedule is a Schedule parsed from a cron expression.
+type CronSchedule struct {
+	expr    string
+	loc     *time.Location
+	second  uint64
+	minute  uint64
+	hour    uint64
+	dom     uint64
+	month   uint64
+	dow     uint64
+	domStar bool
+	dowStar bool
+}
+
+// ParseCron parses a standard cron expression evaluated in loc (time.Local if
+// nil).
+//
+// Five fields are minute, hour, day of month, month, and day of week; six
+// fields prepend seconds. Fields accept *, ?, lists (1,15), ranges (1-5),
+// steps (*/10, 8-18/2), and month and weekday names (JAN, MON-FRI). When both
+// day of month and day of week are restricted, a day matching either runs the
+// job, as in Vixie cron; a field starting with * (such as */2) or ? counts as
+// unrestricted, so "0 0 */2 * MON" runs on odd days that are Mondays. The descriptors @yearly, @annually, @monthly,
+// @weekly, @daily, @midnight, and @hourly are supported, and a CRON_TZ= or
+// TZ= prefix overrides loc:
+//
+//	s, err := worker.ParseCron("CRON_TZ=Europe/Berlin 0 2 * * MON-FRI", nil)
+func ParseCron(expr string, loc *time.Location) (*CronSchedule, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	spec := strings.TrimSpace(expr)
+	if prefix, rest, ok := strings.Cut(spec, " "); ok && (strings.HasPrefix(prefix, "CRON_TZ=") || strings.HasPrefix(prefix, "TZ=")) {
+		_, name, _ := strings.Cut(prefix, "=")
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, invalidCron(expr, fmt.Sprintf("unknown time zone %q", name))
+		}
+		loc, spec = l, strings.TrimSpace(rest)
+	}
+	if d, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = d
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, invalidCron(expr, fmt.Sprintf("expected 5 or 6 fields, got %d", len(fields)))
+	}
+
+	s := &CronSchedule{expr: expr, loc: loc}
+	var err error
+	for i, f := range []struct {
+		dst   *uint64
+		field cronField
+	}{
+		{&s.second, cronSecond},
+		{&s.minute, cronMinute},
+		{&s.hour, cronHour},
+		{&s.dom, cronDom},
+		{&s.month, cronMonth},
+		{&s.dow, cronDow},
+	} {
+		if *f.dst, err = parseCronField(fields[i], f.field); err != nil {
+			return nil, invalidCron(expr, err.Error())
+		}
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domStar = isCronDayStar(fields[3])
+	s.dowStar = isCronDayStar(fields[5])
+	return s, nil
+}
+
+// MustParseCron is like ParseCron but panics on an invalid expression. It is
+// intended for expressions fixed at compile time.
+func MustParseCron(expr string, loc *time.Location) *CronSchedule {
+	s, err := ParseCron(expr, loc)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// String returns the expression the schedule was parsed from.
+func (s *CronSchedule) String() string { return s.expr }
+
+// Location returns the time zone the schedule is evaluated in.
+func (s *CronSchedule) Location() *time.Location { return s.loc }
+
+// Next returns the first matching time strictly after the given time, in the
+// schedule's location, or the zero time if none exists within five years.
+func (s *CronSchedule) Next(after time.Time) time.Time {
+	t := after.In(s.loc)
+	// Start at the next whole second.
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+	limit := t.Year() + cronSearchYears
+
+	for t.Year() <= limit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc)
+			continue
+		}
+		// Below a day, step in absolute time so DST transitions never move t
+		// backwards.
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Add(time.Hour - time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute - time.Duration(t.Second())*time.Second)
+			continue
+		}
+		if s.second&(1<<uint(t.Second())) == 0 {
+			t = t.Add(time.Second)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func isCronWildcard(field string) bool {
+	return field == "*" || field == "?"
+}
+
+// isCronDayStar reports whether a day field leaves the day unrestricted for
+// the day-of-month/day-of-week union. Vixie cron treats any field starting
+// with * that way, steps included.
+func isCronDayStar(field string) bool {
+	return strings.HasPrefix(field, "*") || field == "?"
+}
+
+// parseCronField parses one comma-separated cron field into a bitmask.
+func parseCronField(expr string, field cronField) (uint64, error) {
+	var mask uint64
+	for part := range strings.SplitSeq(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepExpr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s: invalid step %q", field.name, stepExpr)
+			}
+			step = n
+		}
+
+		var lo, hi int
+		switch {
+		case isCronWildcard(rangeExpr):
+			lo, hi = field.min, field.lastImplicit()
+		default:
+			loExpr, hiExpr, isRange := strings.Cut(rangeExpr, "-")
+			var err error
+			if lo, err = parseCronValue(loExpr, field); err != nil {
+				return 0, err
+			}
+			switch {
+			case isRange:
+				if hi, err = parseCronValue(hiExpr, field); err != nil {
+					return 0, err
+				}
+			case hasStep:
+				hi = field.lastImplicit()
+			default:
+				hi = lo
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("%s: range %q is reversed", field.name, rangeExpr)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
+
+func parseCronValue(expr string, field cronField) (int, error) {
+	if v, ok := field.names[strings.ToLower(expr)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(expr)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid value %q", field.name, expr)
+	}
+	if v < field.min || v > field.max {
+		return 0, fmt.Errorf("%s: %d out of range %d-%d", field.name, v, field.min, field.max)
+	}
+	return v, nil
+}
+
+func invalidCron(expr, reason string) error {
+	return gkerrors.InvalidInput("cron", reason).WithDetail("value", expr)
+}
@@ -0,0 +1,125 @@
+package worker_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/worker"
+)
+
+func TestParseCronNext(t *testing.T) {
+	t.Parallel()
+
+	utc := time.UTC
+	from := time.Date(2026, 1, 30, 10, 17, 45, 500, utc) // a Friday
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 30, 10, 18, 0, 0, utc)},
+		{"*/15 * * * * *", time.Date(2026, 1, 30, 10, 18, 0, 0, utc)},
+		{"30 10 * * * *", time.Date(2026, 1, 30, 11, 10, 30, 0, utc)},
+		{"0 2 * * MON-FRI", time.Date(2026, 2, 2, 2, 0, 0, 0, utc)},
+		{"0 9-17/4 * * *", time.Date(2026, 1, 30, 13, 0, 0, 0, utc)},
+		{"0 0 1,15 * *", time.Date(2026, 2, 1, 0, 0, 0, 0, utc)},
+		{"0 0 29 feb *", time.Date(2028, 2, 29, 0, 0, 0, 0, utc)},
+		{"0 0 * * 7", time.Date(2026, 2, 1, 0, 0, 0, 0, utc)},
+		// Restricted day of month and weekday match either.
+		{"0 0 13 * FRI", time.Date(2026, 1, 30, 0, 0, 0, 0, utc).AddDate(0, 0, 7)},
+		// A stepped star is still a star: odd days that are Mondays.
+		{"0 0 */2 * MON", time.Date(2026, 2, 9, 0, 0, 0, 0, utc)},
+		{"@monthly", time.Date(2026, 2, 1, 0, 0, 0, 0, utc)},
+		{"@hourly", time.Date(2026, 1, 30, 11, 0, 0, 0, utc)},
+	}
+	for _, tc := range cases {
+		s, err := worker.ParseCron(tc.expr, utc)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tc.expr, err)
+		}
+		if got := s.Next(from); !got.Equal(tc.want) {
+			t.Errorf("%q Next = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+
+	if got := worker.MustParseCron("0 0 30 2 *", utc).Next(from); !got.IsZero() {
+		t.Errorf("impossible date Next = %v, want zero", got)
+	}
+}
+
+func TestParseCronTimeZones(t *testing.T) {
+	t.Parallel()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+
+	s := worker.MustParseCron("CRON_TZ=Europe/Berlin 0 2 * * MON-FRI", time.UTC)
+	if s.Location().String() != "Europe/Berlin" {
+		t.Fatalf("Location = %v", s.Location())
+	}
+	got := s.Next(time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, 1, 30, 2, 0, 0, 0, berlin); !got.Equal(want) {
+		t.Fatalf("Next = %v, want %v", got, want)
+	}
+
+	// 02:30 does not exist on the spring-forward day; the next run is the
+	// following day.
+	daily := worker.MustParseCron("30 2 * * *", berlin)
+	got = daily.Next(time.Date(2026, 3, 29, 0, 0, 0, 0, berlin))
+	if want := time.Date(2026, 3, 30, 2, 30, 0, 0, berlin); !got.Equal(want) {
+		t.Fatalf("Next across DST gap = %v, want %v", got, want)
+	}
+
+	// Every 30 minutes across fall-back keeps moving forward.
+	half := worker.MustParseCron("*/30 * * * *", berlin)
+	prev := time.Date(2026, 10, 25, 1, 45, 0, 0, berlin)
+	for range 6 {
+		next := half.Next(prev)
+		if d := next.Sub(prev); d <= 0 || d > 30*time.Minute {
+			t.Fatalf("Next(%v) = %v, step %v", prev, next, d)
+		}
+		prev = next
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * * FUN",
+		"TZ=Mars/Olympus * * * * *",
+	} {
+		if _, err := worker.ParseCron(expr, nil); err == nil {
+			t.Errorf("ParseCron(%q) succeeded", expr)
+		}
+	}
+}
+
+func TestMisfirePolicyText(t *testing.T) {
+	t.Parallel()
+
+	var p worker.MisfirePolicy
+	if err := p.UnmarshalText([]byte("catch_up")); err != nil || p != worker.MisfireCatchUp {
+		t.Fatalf("UnmarshalText catch_up = %q, %v", p, err)
+	}
+	if err := p.UnmarshalText(nil); err != nil || p != worker.MisfireSkip {
+		t.Fatalf("empty UnmarshalText = %q, %v", p, err)
+	}
+	if err := p.UnmarshalText([]byte("later")); err == nil {
+		t.Fatal("UnmarshalText accepted an unknown policy")
+	}
+	if text, err := worker.MisfireRunOnce.MarshalText(); err != nil || string(text) != "run_once" {
+		t.Fatalf("MarshalText = %q, %v", text, err)
+	}
+}
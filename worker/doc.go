@@ -27,6 +27,15 @@
 //	    fmt.Println(event.Type, event.Data)
 //	}
 //
+// PoolConfig.Classes splits the queue into named classes scheduled by weight or priority;
+// Submit places a task with WithClass and WithPriority.
+//
+// PoolConfig.Autoscale lets the pool grow between MinSize and MaxSize workers on backlog or queue
+// wait and shrink when workers stay idle; Pool.Health reports saturation and unhealthy workers.
+//
+// Setting PoolConfig.Durable stores tasks in a QueueBackend instead of memory, so they survive
+// restarts and are retried with backoff until they succeed or are dead-lettered.
+//
 // # Middleware
 //
 // Middleware[I, O] wraps a Handler with cross-cutting behavior.
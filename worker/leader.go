@@ -0,0 +1,287 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kbukum/gokit/component"
+)
+
+// Locker grants time-limited leases used to elect a single leader among
+// replicas. Implementations must make Acquire atomic across processes.
+type Locker interface {
+	// Acquire takes the lease named key for owner, or extends it if owner
+	// already holds it, so that it expires ttl from now. It reports whether
+	// owner holds the lease afterwards.
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	// Release gives up the lease if owner holds it.
+	Release(ctx context.Context, key, owner string) error
+}
+
+// MemoryLocker is an in-process Locker for tests and single-instance
+// deployments.
+type MemoryLocker struct {
+	now    func() time.Time
+	mu     sync.Mutex
+	leases map[string]memoryLease
+}
+
+type memoryLease struct {
+	owner   string
+	expires time.Time
+}
+
+// NewMemoryLocker creates an empty in-process locker.
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{now: time.Now, leases: make(map[string]memoryLease)}
+}
+
+// Acquire implements Locker.
+func (l *MemoryLocker) Acquire(_ context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if lease, ok := l.leases[key]; ok && lease.owner != owner && now.Before(lease.expires) {
+		return false, nil
+	}
+	l.leases[key] = memoryLease{owner: owner, expires: now.Add(ttl)}
+	return true, nil
+}
+
+// Release implements Locker.
+func (l *MemoryLocker) Release(_ context.Context, key, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lease, ok := l.leases[key]; ok && lease.owner == owner {
+		delete(l.leases, key)
+	}
+	return nil
+}
+
+// errLeaseTaken explains a leadership loss where renewal found another owner.
+var errLeaseTaken = errors.New("lease taken over by another owner")
+
+// LeaderConfig configures a LeaderElector.
+type LeaderConfig struct {
+	// Locker grants the lease. Required.
+	Locker Locker
+	// Key names the lease shared by all candidates. Default: the elector name.
+	Key string
+	// Owner identifies this candidate. Default: hostname plus a random suffix.
+	Owner string
+	// TTL is how long a lease lasts without renewal. Default: 30s.
+	TTL time.Duration
+	// RenewInterval is how often the lease is acquired or renewed. It must be
+	// well below TTL. Default: TTL / 3.
+	RenewInterval time.Duration
+	// OnElected is called when this candidate becomes leader.
+	OnElected func()
+	// OnRevoked is called when this candidate stops being leader.
+	OnRevoked func()
+}
+
+// LeaderElector is a Component that competes for a lease and reports whether
+// this replica currently holds it. A leader that fails to renew steps down
+// immediately rather than risk two leaders, and reports degraded health until
+// the locker is reachable again.
+//
+// Example:
+//
+//	elector := worker.NewLeaderElector("reports", worker.LeaderConfig{Locker: locker})
+//	registry.Register(elector)
+//	if elector.IsLeader() { ... }
+type LeaderElector struct {
+	name   string
+	config LeaderConfig
+
+	cancel  context.CancelFunc
+	done    chan struct{}
+	running atomic.Bool
+
+	mu        sync.RWMutex
+	leaderCtx context.Context
+	revoke    context.CancelFunc
+	lastErr   error
+	lostErr   error
+}
+
+// NewLeaderElector creates a LeaderElector with the given name and config.
+func NewLeaderElector(name string, config LeaderConfig) *LeaderElector {
+	if config.Key == "" {
+		config.Key = name
+	}
+	if config.Owner == "" {
+		host, _ := os.Hostname()
+		config.Owner = fmt.Sprintf("%s-%s", host, uuid.NewString()[:8])
+	}
+	if config.TTL <= 0 {
+		config.TTL = 30 * time.Second
+	}
+	if config.RenewInterval <= 0 || config.RenewInterval >= config.TTL {
+		config.RenewInterval = config.TTL / 3
+	}
+	return &LeaderElector{name: name, config: config}
+}
+
+// Name returns the component name.
+func (e *LeaderElector) Name() string { return e.name }
+
+// Owner returns the identity this candidate campaigns with.
+func (e *LeaderElector) Owner() string { return e.config.Owner }
+
+// Start makes a first attempt to acquire the lease and then keeps
+// campaigning in the background. A locker error on the first attempt is
+// reported through Health, not returned.
+func (e *LeaderElector) Start(ctx context.Context) error {
+	if e.config.Locker == nil {
+		return fmt.Errorf("leader elector %s: no locker configured", e.name)
+	}
+	if e.running.Load() {
+		return nil
+	}
+	e.campaign(ctx)
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	e.running.Store(true)
+	go e.loop(loopCtx) //nolint:contextcheck // campaign loop is long-lived and detached from the lifecycle Start ctx
+	return nil
+}
+
+// Stop ends the campaign and releases the lease if held.
+func (e *LeaderElector) Stop(ctx context.Context) error {
+	if !e.running.Load() {
+		return nil
+	}
+	e.cancel()
+	<-e.done
+	e.running.Store(false)
+
+	if e.stepDown(nil) {
+		return e.config.Locker.Release(ctx, e.config.Key, e.config.Owner)
+	}
+	return nil
+}
+
+// IsLeader reports whether this replica currently holds the lease.
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leaderCtx != nil
+}
+
+// LeaderContext returns a context that is canceled when leadership is lost,
+// and false if this replica is not the leader.
+func (e *LeaderElector) LeaderContext() (context.Context, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leaderCtx, e.leaderCtx != nil
+}
+
+// Health reports unhealthy when not running, degraded when the locker is
+// failing or the last campaign lost leadership, and healthy otherwise.
+func (e *LeaderElector) Health(_ context.Context) component.Health {
+	if !e.running.Load() {
+		return component.Health{Name: e.name, Status: component.StatusUnhealthy, Message: "not running"}
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	switch {
+	case e.lostErr != nil:
+		return component.Health{Name: e.name, Status: component.StatusDegraded, Message: "leadership lost: " + e.lostErr.Error()}
+	case e.lastErr != nil:
+		return component.Health{Name: e.name, Status: component.StatusDegraded, Message: e.lastErr.Error()}
+	case e.leaderCtx != nil:
+		return component.Health{Name: e.name, Status: component.StatusHealthy, Message: "leader"}
+	default:
+		return component.Health{Name: e.name, Status: component.StatusHealthy, Message: "follower"}
+	}
+}
+
+// Describe returns summary information for the bootstrap startup display.
+func (e *LeaderElector) Describe() component.Description {
+	return component.Description{
+		Name:    e.name,
+		Type:    "leader-elector",
+		Details: fmt.Sprintf("key=%s owner=%s ttl=%s", e.config.Key, e.config.Owner, e.config.TTL),
+	}
+}
+
+func (e *LeaderElector) loop(ctx context.Context) {
+	defer close(e.done)
+	ticker := time.NewTicker(e.config.RenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.campaign(ctx)
+		}
+	}
+}
+
+// campaign acquires or renews the lease once.
+func (e *LeaderElector) campaign(ctx context.Context) {
+	attemptCtx, cancel := context.WithTimeout(ctx, e.config.RenewInterval)
+	held, err := e.config.Locker.Acquire(attemptCtx, e.config.Key, e.config.Owner, e.config.TTL)
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		e.stepDown(err)
+		return
+	}
+	if !held {
+		var lost error
+		if e.stepDown(nil) {
+			lost = errLeaseTaken
+		}
+		e.mu.Lock()
+		e.lastErr, e.lostErr = nil, lost
+		e.mu.Unlock()
+		return
+	}
+
+	e.mu.Lock()
+	elected := e.leaderCtx == nil
+	if elected {
+		e.leaderCtx, e.revoke = context.WithCancel(context.Background())
+	}
+	e.lastErr, e.lostErr = nil, nil
+	e.mu.Unlock()
+	if elected && e.config.OnElected != nil {
+		e.config.OnElected()
+	}
+}
+
+// stepDown gives up leadership locally and reports whether this replica was
+// the leader. err, if non-nil, is why.
+func (e *LeaderElector) stepDown(err error) bool {
+	e.mu.Lock()
+	wasLeader := e.leaderCtx != nil
+	if wasLeader {
+		e.revoke()
+		e.leaderCtx, e.revoke = nil, nil
+	}
+	if err != nil {
+		e.lastErr = err
+		if wasLeader {
+			e.lostErr = err
+		}
+	}
+	e.mu.Unlock()
+	if wasLeader && e.config.OnRevoked != nil {
+		e.config.OnRevoked()
+	}
+	return wasLeader
+}
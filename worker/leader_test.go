@@ -0,0 +1,168 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/component"
+	"github.com/kbukum/gokit/worker"
+)
+
+// flakyLocker wraps a MemoryLocker and fails Acquire while broken is set.
+type flakyLocker struct {
+	*worker.MemoryLocker
+	broken atomic.Bool
+}
+
+func (f *flakyLocker) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	if f.broken.Load() {
+		return false, errors.New("locker unreachable")
+	}
+	return f.MemoryLocker.Acquire(ctx, key, owner, ttl)
+}
+
+func waitUntil(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func TestMemoryLocker(t *testing.T) {
+	t.Parallel()
+
+	l := worker.NewMemoryLocker()
+	ctx := context.Background()
+	if ok, _ := l.Acquire(ctx, "k", "a", 20*time.Millisecond); !ok {
+		t.Fatal("a could not acquire a free lease")
+	}
+	if ok, _ := l.Acquire(ctx, "k", "b", time.Minute); ok {
+		t.Fatal("b acquired a held lease")
+	}
+	_ = l.Release(ctx, "k", "b")
+	if ok, _ := l.Acquire(ctx, "k", "a", 20*time.Millisecond); !ok {
+		t.Fatal("foreign release or renewal failed")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if ok, _ := l.Acquire(ctx, "k", "b", time.Minute); !ok {
+		t.Fatal("b could not take an expired lease")
+	}
+}
+
+func TestLeaderElectorFailoverAndHealth(t *testing.T) {
+	t.Parallel()
+
+	locker := &flakyLocker{MemoryLocker: worker.NewMemoryLocker()}
+	var mu sync.Mutex
+	var events []string
+	record := func(e string) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		}
+	}
+	a := worker.NewLeaderElector("a", worker.LeaderConfig{
+		Locker: locker, Key: "jobs", Owner: "a", TTL: 60 * time.Millisecond,
+		OnElected: record("a elected"), OnRevoked: record("a revoked"),
+	})
+	b := worker.NewLeaderElector("b", worker.LeaderConfig{Locker: locker, Key: "jobs", Owner: "b", TTL: 60 * time.Millisecond})
+
+	if a.Health(context.Background()).Status != component.StatusUnhealthy {
+		t.Fatal("elector healthy before Start")
+	}
+	ctx := context.Background()
+	_ = a.Start(ctx)
+	_ = b.Start(ctx)
+	defer func() { _ = b.Stop(ctx) }()
+
+	leaderCtx, ok := a.LeaderContext()
+	if !ok || b.IsLeader() {
+		t.Fatalf("a leader=%v b leader=%v, want only a", a.IsLeader(), b.IsLeader())
+	}
+	if h := a.Health(ctx); h.Status != component.StatusHealthy || h.Message != "leader" {
+		t.Fatalf("a health = %+v", h)
+	}
+
+	// A failing locker makes the leader step down and report degraded health.
+	locker.broken.Store(true)
+	waitUntil(t, func() bool { return !a.IsLeader() })
+	if leaderCtx.Err() == nil {
+		t.Fatal("leader context not canceled on loss")
+	}
+	if h := a.Health(ctx); h.Status != component.StatusDegraded {
+		t.Fatalf("a health after loss = %+v, want degraded", h)
+	}
+
+	// Once the lease expires the other candidate takes over.
+	locker.broken.Store(false)
+	waitUntil(t, func() bool { return a.IsLeader() || b.IsLeader() })
+	waitUntil(t, func() bool { return a.Health(ctx).Status == component.StatusHealthy })
+
+	// Stopping the leader releases the lease for the other.
+	leader, follower := a, b
+	if b.IsLeader() {
+		leader, follower = b, a
+	}
+	_ = leader.Stop(ctx)
+	waitUntil(t, follower.IsLeader)
+	if follower == b {
+		_ = a.Stop(ctx)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) < 2 || events[0] != "a elected" || events[1] != "a revoked" {
+		t.Fatalf("callbacks = %v", events)
+	}
+}
+
+func TestSchedulerSingletonJobs(t *testing.T) {
+	t.Parallel()
+
+	locker := worker.NewMemoryLocker()
+	var runs [2]atomic.Int32
+	schedulers := make([]*worker.Scheduler, 2)
+	for i := range schedulers {
+		schedulers[i] = worker.NewScheduler("jobs",
+			worker.Job{Name: "cleanup", Interval: 10 * time.Millisecond, Singleton: true, Fn: func(context.Context) error {
+				runs[i].Add(1)
+				return nil
+			}},
+		).WithLeaderElection(worker.LeaderConfig{Locker: locker, Owner: string(rune('a' + i)), TTL: 60 * time.Millisecond})
+		if err := schedulers[i].Start(context.Background()); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if runs[0].Load() == 0 || runs[1].Load() != 0 {
+		t.Fatalf("runs = %d, %d; want only the leader's", runs[0].Load(), runs[1].Load())
+	}
+	if h := schedulers[1].Health(context.Background()); h.Status != component.StatusHealthy || h.Message != "1 jobs running as follower" {
+		t.Fatalf("follower health = %+v", h)
+	}
+
+	// When the leader shuts down, the follower takes over on its next campaign.
+	_ = schedulers[0].Stop(context.Background())
+	defer func() { _ = schedulers[1].Stop(context.Background()) }()
+	waitUntil(t, func() bool { return runs[1].Load() > 0 })
+}
+
+func TestSchedulerSingletonNeedsLeaderElection(t *testing.T) {
+	t.Parallel()
+
+	s := worker.NewScheduler("jobs", worker.Job{Name: "cleanup", Interval: time.Hour, Singleton: true, Fn: func(context.Context) error { return nil }})
+	if err := s.Start(context.Background()); err == nil {
+		_ = s.Stop(context.Background())
+		t.Fatal("Start accepted a singleton job without leader election")
+	}
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"encoding"
+
+	gkerrors "github.com/kbukum/gokit/errors"
+)
+
+var (
+	_ encoding.TextMarshaler   = MisfirePolicy("")
+	_ encoding.TextUnmarshaler = (*MisfirePolicy)(nil)
+)
+
+// MisfirePolicy controls what a scheduled worker does about activation times
+// it missed because a run overran them or the process was suspended.
+type MisfirePolicy string
+
+const (
+	// MisfireSkip drops missed activations and waits for the next one.
+	MisfireSkip MisfirePolicy = "skip"
+	// MisfireRunOnce runs once immediately for any number of missed
+	// activations, then resumes the schedule.
+	MisfireRunOnce MisfirePolicy = "run_once"
+	// MisfireCatchUp runs once for every missed activation, back to back.
+	MisfireCatchUp MisfirePolicy = "catch_up"
+)
+
+// MarshalText serializes a misfire policy for config encoders.
+func (m MisfirePolicy) MarshalText() ([]byte, error) {
+	policy := m
+	if policy == "" {
+		policy = MisfireSkip
+	}
+	if !policy.valid() {
+		return nil, invalidMisfirePolicy(policy)
+	}
+	return []byte(policy), nil
+}
+
+// UnmarshalText parses a misfire policy from config text.
+func (m *MisfirePolicy) UnmarshalText(text []byte) error {
+	policy := MisfirePolicy(text)
+	if policy == "" {
+		*m = MisfireSkip
+		return nil
+	}
+	if !policy.valid() {
+		return invalidMisfirePolicy(policy)
+	}
+	*m = policy
+	return nil
+}
+
+func (m MisfirePolicy) valid() bool {
+	switch m {
+	case MisfireSkip, MisfireRunOnce, MisfireCatchUp:
+		return true
+	default:
+		return false
+	}
+}
+
+func invalidMisfirePolicy(policy MisfirePolicy) error {
+	return gkerrors.InvalidInput("misfire", "must be one of skip, run_once, catch_up").WithDetail("value", string(policy))
+}
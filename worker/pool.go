@@ -7,18 +7,32 @@
 	"sync"
 	"sync/atomic"
 	"time"
+
+	gkerrors "github.com/kbukum/gokit/errors"
 )
 
 // PoolConfig configures a worker pool.
 type PoolConfig struct {
 	Name        string            `yaml:"name"         mapstructure:"name"`
-	Size        int               `yaml:"size"         mapstructure:"size"`         // fixed pool size (default: runtime.NumCPU)
+	Size        int               `yaml:"size"         mapstructure:"size"`         // pool size, or initial size with Autoscale (default: runtime.NumCPU)
 	QueueSize   int               `yaml:"queue_size"   mapstructure:"queue_size"`   // bounded task queue (0 = unbuffered)
 	Overflow    OverflowPolicy    `yaml:"overflow"     mapstructure:"overflow"`     // block | reject | drop_oldest (default: block)
 	EventBuffer int               `yaml:"event_buffer" mapstructure:"event_buffer"` // event channel buffer per task (default: 64)
 	GracePeriod time.Duration     `yaml:"grace_period" mapstructure:"grace_period"` // shutdown grace (default: 5s)
-	Dispatch    DispatchStrategy  `yaml:"dispatch"     mapstructure:"dispatch"`     // round_robin | least_loaded (default: round_robin)
+	Dispatch    DispatchStrategy  `yaml:"dispatch"     mapstructure:"dispatch"`     // round_robin | least_loaded (default: round_robin); not with Classes or Autoscale
 	Supervisor  *SupervisorConfig `yaml:"supervisor,omitempty" mapstructure:"supervisor"`
+	// Durable stores tasks in a QueueBackend instead of the in-memory queue;
+	// QueueSize, Overflow and Classes are then ignored.
+	Durable *DurableConfig `yaml:"durable,omitempty" mapstructure:"durable"`
+	// Classes splits the queue into named backlogs scheduled by Scheduling.
+	// Setting either enables per-class queues; QueueSize and Overflow then apply per class.
+	Classes    []ClassConfig    `yaml:"classes,omitempty" mapstructure:"classes"`
+	Scheduling SchedulingPolicy `yaml:"scheduling"        mapstructure:"scheduling"` // weighted_fair | strict_priority (default: weighted_fair)
+	Aging      time.Duration    `yaml:"aging"             mapstructure:"aging"`      // wait that raises a task's priority by one (0 = no aging)
+	// Autoscale grows and shrinks the pool with load. Tasks then always go
+	// through the shared queue, without supervisor affinity routing, so a
+	// least_loaded Dispatch is rejected.
+	Autoscale *AutoscaleConfig `yaml:"autoscale,omitempty" mapstructure:"autoscale"`
 }
 
 func (c PoolConfig) withDefaults() PoolConfig {
@@ -37,13 +51,35 @@ func (c PoolConfig) withDefaults() PoolConfig {
 	return c
 }
 
+// Validate rejects settings the pool cannot honor together: a least_loaded
+// Dispatch with task classes, whose idle workers always take the next task in
+// scheduling order, or with Autoscale, whose workers share one queue.
+func (c *PoolConfig) Validate() error {
+	if c.Dispatch != LeastLoaded {
+		return nil
+	}
+	if len(c.Classes) > 0 || c.Scheduling != "" {
+		return gkerrors.InvalidInput("dispatch", "does not apply to a pool with task classes").WithDetail("value", string(c.Dispatch))
+	}
+	if c.Autoscale != nil {
+		return gkerrors.InvalidInput("dispatch", "does not apply to an autoscaled pool").WithDetail("value", string(c.Dispatch))
+	}
+	return nil
+}
+
 // PoolStats reports pool utilization.
 type PoolStats struct {
-	Active int `json:"active"` // workers currently executing tasks
-	Idle   int `json:"idle"`   // workers waiting for tasks
-	Queued int `json:"queued"` // tasks waiting in the queue
-	Total  int `json:"total"`  // total tasks submitted
-	Failed int `json:"failed"` // tasks that returned an error
+	Workers int `json:"workers"` // running workers
+	Active  int `json:"active"`  // workers currently executing tasks
+	Idle    int `json:"idle"`    // workers waiting for tasks
+	Queued  int `json:"queued"`  // tasks waiting in the in-memory queue (not a durable backend)
+	Total   int `json:"total"`   // total tasks submitted
+	Failed  int `json:"failed"`  // tasks that returned an error
+
+	ScaleUps   int `json:"scale_ups,omitempty"`   // workers added by the autoscaler
+	ScaleDowns int `json:"scale_downs,omitempty"` // idle workers stopped by the autoscaler
+
+	Classes map[string]ClassStats `json:"classes,omitempty"` // per-class breakdown when classes are enabled
 }
 
 // taskEnvelope wraps a task submission for internal dispatch.
@@ -51,13 +87,17 @@ type taskEnvelope[I, O any] struct {
 	task   I
 	handle *TaskHandle[O]
 	ctx    context.Context
+	class  *taskClass[I, O] // nil without task classes
+	queued time.Time        // submission time, set only when autoscaling
 }
 
-// Pool manages a fixed set of worker goroutines executing a Handler.
+// Pool manages a set of worker goroutines executing a Handler. The set is
+// fixed unless PoolConfig.Autoscale is set.
 type Pool[I, O any] struct {
 	handler  Handler[I, O]
 	cfg      PoolConfig
 	dispatch dispatcher
+	cfgErr   error // from PoolConfig.Validate; fails every Submit
 
 	// Shared queue is the pool-wide bounded backlog.
 	// Affinity channels are size-1 supervisor steering hints for healthy workers.
@@ -75,6 +115,7 @@ type Pool[I, O any] struct {
 	poolCtx      context.Context
 	wg           sync.WaitGroup // tracks worker goroutines
 	supWg        sync.WaitGroup // tracks supervisor goroutine
+	scaleWg      sync.WaitGroup // tracks autoscaler goroutine; it starts workers, so Stop waits for it before wg
 	taskWg       sync.WaitGroup // tracks accepted tasks until completion or cancellation
 
 	stopped    atomic.Bool
@@ -84,36 +125,72 @@ type Pool[I, O any] struct {
 	// mu serializes Stop with task acceptance so taskWg cannot race with shutdown waits.
 	mu         sync.Mutex
 	supervisor *supervisor[I, O]
+	durable    *durableQueue[O]
+	classes    *classScheduler[I, O]
+	scaler     *autoscaler[I, O]
 }
 
 // NewPool creates a new worker pool with the given handler and configuration.
+// If cfg fails PoolConfig.Validate, every Submit returns that error.
 func NewPool[I, O any](handler Handler[I, O], cfg PoolConfig) *Pool[I, O] {
+	cfgErr := cfg.Validate()
 	cfg = cfg.withDefaults()
 	acceptCtx, acceptCancel := context.WithCancel(context.Background()) //nolint:gosec // G118: cancel is retained on Pool and invoked in Stop()
 	poolCtx, cancel := context.WithCancel(context.Background())         //nolint:gosec // G118: cancel is retained on Pool and invoked in Stop()
 
+	// Worker slots are allocated up front; an autoscaled pool uses up to MaxSize of them.
+	capacity := cfg.Size
+	var scale AutoscaleConfig
+	if cfg.Autoscale != nil {
+		scale = cfg.Autoscale.withDefaults(cfg.Size)
+		capacity = scale.MaxSize
+		cfg.Size = min(max(cfg.Size, scale.MinSize), scale.MaxSize)
+	}
+
 	p := &Pool[I, O]{
 		handler:      handler,
 		cfg:          cfg,
 		dispatch:     newDispatcher(cfg.Dispatch),
+		cfgErr:       cfgErr,
 		queue:        make(chan taskEnvelope[I, O], cfg.QueueSize),
-		affinities:   make([]chan taskEnvelope[I, O], cfg.Size),
-		stats:        make([]workerStats, cfg.Size),
-		events:       make(chan Event[O], cfg.EventBuffer*cfg.Size),
+		affinities:   make([]chan taskEnvelope[I, O], capacity),
+		stats:        make([]workerStats, capacity),
+		events:       make(chan Event[O], cfg.EventBuffer*capacity),
 		acceptCancel: acceptCancel,
 		acceptCtx:    acceptCtx,
 		cancel:       cancel,
 		poolCtx:      poolCtx,
 	}
 
-	for i := range cfg.Size {
+	switch {
+	case cfg.Durable != nil:
+		p.durable = newDurableQueue[O](*cfg.Durable, capacity)
+	case len(cfg.Classes) > 0 || cfg.Scheduling != "":
+		p.classes = newClassScheduler[I, O](cfg)
+	}
+	for i := range capacity {
 		p.affinities[i] = make(chan taskEnvelope[I, O], 1)
-		p.wg.Add(1)
-		go p.runWorker(i)
 	}
-
 	if cfg.Supervisor != nil {
 		p.supervisor = newSupervisor(p, *cfg.Supervisor)
+	}
+
+	if cfg.Autoscale != nil {
+		p.scaler = newAutoscaler(p, scale)
+		p.scaler.start(cfg.Size)
+		p.scaleWg.Add(1)
+		go func() {
+			defer p.scaleWg.Done()
+			p.scaler.run(poolCtx)
+		}()
+	} else {
+		for i := range cfg.Size {
+			p.wg.Add(1)
+			go p.runWorkerLoop(poolCtx, i)
+		}
+	}
+
+	if p.supervisor != nil {
 		p.supWg.Add(1)
 		go func() {
 			defer p.supWg.Done()
@@ -124,17 +201,52 @@ func NewPool[I, O any](handler Handler[I, O], cfg PoolConfig) *Pool[I, O] {
 	return p
 }
 
+// runWorkerLoop runs the worker loop for the pool's queue mode in slot idx
+// until ctx is canceled or the pool stops.
+func (p *Pool[I, O]) runWorkerLoop(ctx context.Context, idx int) {
+	switch {
+	case p.durable != nil:
+		p.runDurableWorker(ctx, idx)
+	case p.classes != nil:
+		p.runClassWorker(ctx, idx)
+	default:
+		p.runWorker(ctx, idx)
+	}
+}
+
 // Submit sends a task to the pool. Returns a handle to track the task.
-func (p *Pool[I, O]) Submit(ctx context.Context, task I) (*TaskHandle[O], error) {
+// WithClass and WithPriority place the task when the pool has task classes;
+// a pool without them accepts only DefaultClass.
+//
+// In a durable pool the task is stored in the backend before Submit returns;
+// ctx bounds only that write, and the handle completes when the task finally
+// succeeds or is dead-lettered here, or with ErrTaskDeferred when the pool stops first.
+func (p *Pool[I, O]) Submit(ctx context.Context, task I, opts ...SubmitOption) (*TaskHandle[O], error) {
 	if p.stopped.Load() {
 		return nil, p.stoppedError()
 	}
+	if p.cfgErr != nil {
+		return nil, p.cfgErr
+	}
+	var o submitOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := p.checkClass(o.class); err != nil {
+		return nil, err
+	}
+	if p.durable != nil {
+		return p.submitDurable(ctx, task)
+	}
 
 	// Task context is canceled if either the caller cancels or the pool shuts down.
 	taskCtx, taskCancel := context.WithCancel(ctx)
 	context.AfterFunc(p.poolCtx, taskCancel) //nolint:contextcheck // pool ctx is intentionally separate to allow shutdown to cancel in-flight tasks
 	handle := newTaskHandle[O](taskCancel, p.cfg.EventBuffer)
 	env := taskEnvelope[I, O]{task: task, handle: handle, ctx: taskCtx}
+	if p.scaler != nil {
+		env.queued = time.Now()
+	}
 
 	p.mu.Lock()
 	if p.stopped.Load() {
@@ -150,9 +262,12 @@ func (p *Pool[I, O]) Submit(ctx context.Context, task I) (*TaskHandle[O], error)
 		submitted *TaskHandle[O]
 		err       error
 	)
-	if p.supervisor == nil {
+	switch {
+	case p.classes != nil:
+		submitted, err = p.enqueueClass(ctx, env, o)
+	case p.supervisor == nil || p.scaler != nil:
 		submitted, err = p.enqueue(ctx, env)
-	} else {
+	default:
 		idx := p.pickWorkerForRouting()
 		if idx < 0 {
 			taskCancel()
@@ -167,11 +282,11 @@ func (p *Pool[I, O]) Submit(ctx context.Context, task I) (*TaskHandle[O], error)
 	return submitted, err
 }
 
-// SubmitBatch sends multiple tasks. Returns handles in the same order.
-func (p *Pool[I, O]) SubmitBatch(ctx context.Context, tasks []I) ([]*TaskHandle[O], error) {
+// SubmitBatch sends multiple tasks with the same options. Returns handles in the same order.
+func (p *Pool[I, O]) SubmitBatch(ctx context.Context, tasks []I, opts ...SubmitOption) ([]*TaskHandle[O], error) {
 	handles := make([]*TaskHandle[O], 0, len(tasks))
 	for _, task := range tasks {
-		h, err := p.Submit(ctx, task)
+		h, err := p.Submit(ctx, task, opts...)
 		if err != nil {
 			// Cancel already-submitted tasks
 			for _, prev := range handles {
@@ -191,6 +306,7 @@ func (p *Pool[I, O]) Events() <-chan Event[O] {
 
 // Stop performs graceful shutdown: stops accepting tasks,
 // waits for in-flight work to finish within GracePeriod, then force-cancels remaining.
+// A durable pool leaves queued tasks in its backend and releases canceled ones for redelivery.
 func (p *Pool[I, O]) Stop(ctx context.Context) error {
 	p.mu.Lock()
 	if !p.stopped.CompareAndSwap(false, true) {
@@ -215,6 +331,7 @@ func (p *Pool[I, O]) Stop(ctx context.Context) error {
 	case <-graceCtx.Done():
 		p.cancel()
 		p.drainPending(p.poolCtx.Err())
+		p.scaleWg.Wait()
 		p.wg.Wait()
 		<-tasksDone
 	}
@@ -223,8 +340,12 @@ func (p *Pool[I, O]) Stop(ctx context.Context) error {
 	// Submit callers may already be past the fast stopped check,
 	// so shutdown is signaled only by poolCtx.
 	p.cancel()
+	p.scaleWg.Wait()
 	p.wg.Wait()
 	p.supWg.Wait()
+	if p.durable != nil {
+		p.durable.deferAll()
+	}
 
 	close(p.events)
 	return nil
@@ -239,13 +360,28 @@ func (p *Pool[I, O]) Stats() PoolStats {
 		queued += len(p.affinities[i])
 	}
 
-	return PoolStats{
-		Active: active,
-		Idle:   p.cfg.Size - active,
-		Queued: queued,
-		Total:  int(p.totalTasks.Load()),
-		Failed: int(p.failCount.Load()),
+	workers := p.cfg.Size
+	if p.scaler != nil {
+		workers = p.scaler.running()
+	}
+	stats := PoolStats{
+		Workers: workers,
+		Active:  active,
+		Idle:    max(workers-active, 0),
+		Queued:  queued,
+		Total:   int(p.totalTasks.Load()),
+		Failed:  int(p.failCount.Load()),
 	}
+	if p.scaler != nil {
+		stats.ScaleUps = int(p.scaler.scaleUps.Load())
+		stats.ScaleDowns = int(p.scaler.scaleDowns.Load())
+	}
+	if p.classes != nil {
+		var classQueued int
+		classQueued, stats.Classes = p.classes.stats()
+		stats.Queued += classQueued
+	}
+	return stats
 }
 
 // pickWorkerForRouting picks a healthy worker for supervisor affinity routing.
@@ -260,8 +396,8 @@ func (p *Pool[I, O]) pickWorkerForRouting() int {
 		return idx
 	}
 
-	for i := range p.cfg.Size {
-		candidate := (idx + i + 1) % p.cfg.Size
+	for i := range len(p.stats) {
+		candidate := (idx + i + 1) % len(p.stats)
 		if p.supervisor.shouldAcceptTask(candidate) {
 			return candidate
 		}
@@ -272,7 +408,8 @@ func (p *Pool[I, O]) pickWorkerForRouting() int {
 // runWorker is the goroutine loop for a single worker. If a supervisor is configured,
 // panics are caught per-task, the task is failed,
 // and the supervisor is notified to decide whether to keep the worker alive.
-func (p *Pool[I, O]) runWorker(idx int) {
+// The worker exits when ctx is canceled, after finishing its current task.
+func (p *Pool[I, O]) runWorker(ctx context.Context, idx int) {
 	defer p.wg.Done()
 
 	workerID := fmt.Sprintf("%s-w%d", p.cfg.Name, idx)
@@ -281,7 +418,7 @@ func (p *Pool[I, O]) runWorker(idx int) {
 
 	for {
 		select {
-		case <-p.poolCtx.Done():
+		case <-ctx.Done():
 			return
 		case env := <-affinity:
 			p.runEnvelope(workerID, idx, env)
@@ -290,7 +427,7 @@ func (p *Pool[I, O]) runWorker(idx int) {
 		}
 
 		select {
-		case <-p.poolCtx.Done():
+		case <-ctx.Done():
 			return
 		case env := <-affinity:
 			p.runEnvelope(workerID, idx, env)
@@ -304,6 +441,11 @@ func (p *Pool[I, O]) runEnvelope(workerID string, idx int, env taskEnvelope[I, O
 	p.stats[idx].active.Add(1)
 	defer p.stats[idx].active.Add(-1)
 	defer p.taskWg.Done()
+	if p.scaler != nil {
+		p.scaler.observeWait(time.Since(env.queued))
+		p.scaler.busy(idx)
+		defer p.scaler.busy(idx)
+	}
 	p.executeTask(workerID, idx, env)
 }
 
@@ -315,44 +457,71 @@ func (p *Pool[I, O]) drainPending(err error) {
 		p.drainChannel(ch, err)
 	}
 	p.drainChannel(p.queue, err)
+	if p.classes != nil {
+		for _, env := range p.classes.drain() {
+			p.failPending(env, err)
+		}
+	}
 }
 
 func (p *Pool[I, O]) drainChannel(ch chan taskEnvelope[I, O], err error) {
 	for {
 		select {
 		case env := <-ch:
-			env.handle.Cancel()
-			var zero O
-			env.handle.emit(errorEvent[O](err))
-			env.handle.complete(zero, err)
-			p.taskWg.Done()
+			p.failPending(env, err)
 		default:
 			return
 		}
 	}
 }
 
+// failPending completes a task that was queued but never ran.
+func (p *Pool[I, O]) failPending(env taskEnvelope[I, O], err error) {
+	env.handle.Cancel()
+	var zero O
+	env.handle.emit(errorEvent[O](err))
+	env.handle.complete(zero, err)
+	p.taskWg.Done()
+}
+
 func (p *Pool[I, O]) stoppedError() error {
 	return fmt.Errorf("worker: pool %q is stopped", p.cfg.Name)
 }
 
 // executeTask runs a single task within a worker goroutine.
 func (p *Pool[I, O]) executeTask(workerID string, idx int, env taskEnvelope[I, O]) {
-	handle := env.handle
+	if err := p.supervisorBackoff(env.ctx, idx); err != nil {
+		env.handle.complete(*new(O), err)
+		return
+	}
+	result, err := p.runHandler(workerID, idx, env)
+	env.handle.complete(result, err)
+}
 
-	// Apply supervisor backoff delay if this worker has recent failures
-	if p.supervisor != nil {
-		if d := p.supervisor.backoff(idx); d > 0 {
-			t := time.NewTimer(d)
-			select {
-			case <-t.C:
-			case <-env.ctx.Done():
-				t.Stop()
-				handle.complete(*new(O), env.ctx.Err())
-				return
-			}
-		}
+// supervisorBackoff applies the supervisor backoff delay if this worker has recent failures.
+// It returns the task context's error if the task is canceled while waiting.
+func (p *Pool[I, O]) supervisorBackoff(ctx context.Context, idx int) error {
+	if p.supervisor == nil {
+		return nil
+	}
+	d := p.supervisor.backoff(idx)
+	if d <= 0 {
+		return nil
 	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runHandler invokes the handler, emitting the final result or error event.
+// It does not complete the task handle.
+func (p *Pool[I, O]) runHandler(workerID string, idx int, env taskEnvelope[I, O]) (result O, err error) {
+	handle := env.handle
 
 	// Build emit function that tags events with worker/task IDs and forwards
 	emit := func(e Event[O]) {
@@ -373,36 +542,39 @@ func (p *Pool[I, O]) executeTask(workerID string, idx int, env taskEnvelope[I, O
 
 	// Catch panics so the worker goroutine survives and the task handle is always completed —
 	// callers waiting on handle.Result() or handle.Events() will never hang.
-	// Report crash to supervisor BEFORE completing the handle
+	// Report crash to supervisor BEFORE returning
 	// so supervisor state is consistent when callers observe task completion.
 	defer func() {
 		if r := recover(); r != nil {
-			var result O
-			var err error
 			switch v := r.(type) {
 			case error:
 				err = fmt.Errorf("worker: panic: %w", v)
 			default:
 				err = fmt.Errorf("worker: panic: %v", v)
 			}
-			p.failCount.Add(1)
+			p.recordFailure(env)
 			emit(errorEvent[O](err))
 			if p.supervisor != nil {
 				p.supervisor.reportCrash(idx, r)
 			}
-			handle.complete(result, err)
 		}
 	}()
 
-	var result O
-	err := p.handler.Handle(env.ctx, env.task, emit)
+	err = p.handler.Handle(env.ctx, env.task, emit)
 
 	if err != nil {
-		p.failCount.Add(1)
+		p.recordFailure(env)
 		emit(errorEvent[O](err))
 	} else {
 		emit(resultEvent(result))
 	}
+	return result, err
+}
 
-	handle.complete(result, err)
+// recordFailure counts a failed task in the pool and class stats.
+func (p *Pool[I, O]) recordFailure(env taskEnvelope[I, O]) {
+	p.failCount.Add(1)
+	if env.class != nil {
+		env.class.failed.Add(1)
+	}
 }
@@ -0,0 +1,248 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/kbukum/gokit/component"
+)
+
+// AutoscaleConfig lets a pool grow and shrink between MinSize and MaxSize
+// workers. PoolConfig.Size is then the initial worker count.
+type AutoscaleConfig struct {
+	MinSize           int           `yaml:"min_size"             mapstructure:"min_size"`             // fewest workers kept running (default: 1)
+	MaxSize           int           `yaml:"max_size"             mapstructure:"max_size"`             // most workers started (default: PoolConfig.Size)
+	Interval          time.Duration `yaml:"interval"             mapstructure:"interval"`             // how often load is evaluated (default: 1s)
+	ScaleUpQueueDepth int           `yaml:"scale_up_queue_depth" mapstructure:"scale_up_queue_depth"` // queued tasks per worker that trigger growth (default: 1)
+	ScaleUpLatency    time.Duration `yaml:"scale_up_latency"     mapstructure:"scale_up_latency"`     // queue wait that triggers growth (0 = queue depth only)
+	ScaleUpStep       int           `yaml:"scale_up_step"        mapstructure:"scale_up_step"`        // workers added per evaluation (default: 1)
+	IdleTimeout       time.Duration `yaml:"idle_timeout"         mapstructure:"idle_timeout"`         // idle time before a worker above MinSize stops (default: 30s)
+}
+
+func (c AutoscaleConfig) withDefaults(size int) AutoscaleConfig {
+	if c.MinSize <= 0 {
+		c.MinSize = 1
+	}
+	if c.MaxSize <= 0 {
+		c.MaxSize = size
+	}
+	c.MaxSize = max(c.MaxSize, c.MinSize)
+	if c.Interval <= 0 {
+		c.Interval = time.Second
+	}
+	if c.ScaleUpQueueDepth <= 0 {
+		c.ScaleUpQueueDepth = 1
+	}
+	if c.ScaleUpStep <= 0 {
+		c.ScaleUpStep = 1
+	}
+	if c.IdleTimeout <= 0 {
+		c.IdleTimeout = 30 * time.Second
+	}
+	return c
+}
+
+// Worker slot states.
+const (
+	slotFree int32 = iota
+	slotRunning
+	slotRetiring
+)
+
+// workerSlot is one of MaxSize positions a worker goroutine can run in.
+type workerSlot struct {
+	state    atomic.Int32
+	cancel   context.CancelFunc
+	lastBusy atomic.Int64 // unix nanoseconds when the worker last started or finished a task
+}
+
+// autoscaler starts and stops pool workers based on backlog, queue wait and
+// idleness. It also replaces workers the supervisor marked unhealthy, which
+// restarts them with a clean failure history.
+type autoscaler[I, O any] struct {
+	pool  *Pool[I, O]
+	cfg   AutoscaleConfig
+	slots []workerSlot
+	now   func() time.Time
+
+	maxWait    atomic.Int64 // longest queue wait since the last evaluation
+	scaleUps   atomic.Int64
+	scaleDowns atomic.Int64
+	replace    int // unhealthy workers retired but not yet replaced; used only by run
+}
+
+func newAutoscaler[I, O any](pool *Pool[I, O], cfg AutoscaleConfig) *autoscaler[I, O] {
+	return &autoscaler[I, O]{
+		pool:  pool,
+		cfg:   cfg,
+		slots: make([]workerSlot, cfg.MaxSize),
+		now:   time.Now,
+	}
+}
+
+func (a *autoscaler[I, O]) run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.evaluate()
+		}
+	}
+}
+
+// start launches up to n workers in free slots and returns how many started.
+func (a *autoscaler[I, O]) start(n int) int {
+	var started int
+	for i := range a.slots {
+		if started == n {
+			break
+		}
+		slot := &a.slots[i]
+		if !slot.state.CompareAndSwap(slotFree, slotRunning) {
+			continue
+		}
+		ctx, cancel := context.WithCancel(a.pool.poolCtx)
+		slot.cancel = cancel
+		slot.lastBusy.Store(a.now().UnixNano())
+		a.pool.wg.Add(1)
+		go func() {
+			a.pool.runWorkerLoop(ctx, i)
+			cancel()
+			if a.pool.supervisor != nil {
+				a.pool.supervisor.resetWorker(i)
+			}
+			slot.state.Store(slotFree)
+		}()
+		started++
+	}
+	return started
+}
+
+// retire asks the worker in slot i to stop after its current task.
+func (a *autoscaler[I, O]) retire(i int) bool {
+	slot := &a.slots[i]
+	if !slot.state.CompareAndSwap(slotRunning, slotRetiring) {
+		return false
+	}
+	slot.cancel()
+	return true
+}
+
+// running returns the number of workers that have not been asked to stop.
+func (a *autoscaler[I, O]) running() int {
+	var n int
+	for i := range a.slots {
+		if a.slots[i].state.Load() == slotRunning {
+			n++
+		}
+	}
+	return n
+}
+
+func (a *autoscaler[I, O]) busy(idx int) {
+	a.slots[idx].lastBusy.Store(a.now().UnixNano())
+}
+
+func (a *autoscaler[I, O]) observeWait(wait time.Duration) {
+	for {
+		cur := a.maxWait.Load()
+		if int64(wait) <= cur || a.maxWait.CompareAndSwap(cur, int64(wait)) {
+			return
+		}
+	}
+}
+
+// evaluate replaces unhealthy workers, then grows the pool if every worker
+// is busy and the backlog or queue wait is over its threshold, or shrinks it
+// by stopping workers idle for longer than IdleTimeout.
+func (a *autoscaler[I, O]) evaluate() {
+	p := a.pool
+	if p.acceptCtx.Err() != nil {
+		return
+	}
+
+	if p.supervisor != nil {
+		for i := range a.slots {
+			if a.slots[i].state.Load() == slotRunning && !p.supervisor.shouldAcceptTask(i) && a.retire(i) {
+				a.replace++
+				p.emitScaleLog(fmt.Sprintf("worker %s-w%d unhealthy, replacing", p.cfg.Name, i))
+			}
+		}
+		// A replacement waits for a free slot when the pool is at MaxSize.
+		a.replace -= a.start(a.replace)
+	}
+
+	running := a.running()
+	stats := p.Stats()
+	wait := time.Duration(a.maxWait.Swap(0))
+	backlog := stats.Queued >= a.cfg.ScaleUpQueueDepth*max(running, 1) ||
+		(a.cfg.ScaleUpLatency > 0 && wait >= a.cfg.ScaleUpLatency)
+
+	switch {
+	case running < a.cfg.MinSize:
+		a.start(a.cfg.MinSize - running)
+	case backlog && stats.Active >= running && running < a.cfg.MaxSize:
+		n := a.start(min(a.cfg.ScaleUpStep, a.cfg.MaxSize-running))
+		if n > 0 {
+			a.scaleUps.Add(int64(n))
+			p.emitScaleLog(fmt.Sprintf("scaled up to %d workers (%d queued)", running+n, stats.Queued))
+		}
+	case !backlog && running > a.cfg.MinSize:
+		idleSince := a.now().Add(-a.cfg.IdleTimeout).UnixNano()
+		var retired int
+		for i := range a.slots {
+			if running-retired == a.cfg.MinSize {
+				break
+			}
+			slot := &a.slots[i]
+			if slot.state.Load() == slotRunning && p.stats[i].active.Load() == 0 &&
+				slot.lastBusy.Load() <= idleSince && a.retire(i) {
+				retired++
+			}
+		}
+		if retired > 0 {
+			a.scaleDowns.Add(int64(retired))
+			p.emitScaleLog(fmt.Sprintf("scaled down to %d workers", running-retired))
+		}
+	}
+}
+
+// Health reports the pool's state. It is degraded while the pool is at its
+// maximum size with a backlog, or while the supervisor has unhealthy workers,
+// and unhealthy once the pool is stopped.
+func (p *Pool[I, O]) Health(_ context.Context) component.Health {
+	if p.stopped.Load() {
+		return component.Health{Name: p.cfg.Name, Status: component.StatusUnhealthy, Message: "stopped"}
+	}
+	stats := p.Stats()
+	capacity := stats.Workers
+	if p.scaler != nil {
+		capacity = p.scaler.cfg.MaxSize
+	}
+	msg := fmt.Sprintf("%d/%d workers busy, %d queued", stats.Active, stats.Workers, stats.Queued)
+	if p.supervisor != nil {
+		if n := p.supervisor.unhealthy(); n > 0 {
+			return component.Health{Name: p.cfg.Name, Status: component.StatusDegraded,
+				Message: fmt.Sprintf("%s, %d unhealthy", msg, n)}
+		}
+	}
+	if stats.Workers >= capacity && stats.Active >= stats.Workers && stats.Queued > 0 {
+		return component.Health{Name: p.cfg.Name, Status: component.StatusDegraded, Message: "saturated: " + msg}
+	}
+	return component.Health{Name: p.cfg.Name, Status: component.StatusHealthy, Message: msg}
+}
+
+// emitScaleLog reports an autoscaling decision through the pool event channel.
+func (p *Pool[I, O]) emitScaleLog(msg string) {
+	e := LogEvent[O](msg, map[string]any{"source": "autoscaler"})
+	e.WorkerID = p.cfg.Name
+	select {
+	case p.events <- e:
+	default:
+	}
+}
@@ -0,0 +1,226 @@
+package worker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/component"
+	"github.com/kbukum/gokit/worker"
+)
+
+// waitForStats polls pool stats until cond holds or the deadline passes.
+func waitForStats(t *testing.T, pool *worker.Pool[string, string], what string, cond func(worker.PoolStats) bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond(pool.Stats()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s; stats = %+v", what, pool.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPoolAutoscaleUpAndDown(t *testing.T) {
+	t.Parallel()
+
+	gate := make(chan struct{})
+	h := worker.HandlerFunc[string, string](func(ctx context.Context, task string, emit func(worker.Event[string])) error {
+		select {
+		case <-gate:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	pool := worker.NewPool(h, worker.PoolConfig{
+		Name:      "autoscale",
+		Size:      1,
+		QueueSize: 16,
+		Autoscale: &worker.AutoscaleConfig{
+			MinSize:     1,
+			MaxSize:     4,
+			Interval:    5 * time.Millisecond,
+			IdleTimeout: 50 * time.Millisecond,
+		},
+	})
+	defer func() { _ = pool.Stop(context.Background()) }()
+
+	if s := pool.Stats(); s.Workers != 1 || s.Idle != 1 {
+		t.Fatalf("initial stats = %+v, want 1 idle worker", s)
+	}
+	handles, err := pool.SubmitBatch(context.Background(), []string{"a", "b", "c", "d", "e", "f"})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	waitForStats(t, pool, "scale up to MaxSize", func(s worker.PoolStats) bool { return s.Workers == 4 && s.Active == 4 })
+	if s := pool.Stats(); s.ScaleUps != 3 || s.Queued != 2 {
+		t.Fatalf("stats at max = %+v, want 3 scale-ups and 2 queued", s)
+	}
+	if h := pool.Health(context.Background()); h.Status != component.StatusDegraded {
+		t.Fatalf("saturated health = %+v, want degraded", h)
+	}
+
+	close(gate)
+	for _, h := range handles {
+		if _, err := h.Result(); err != nil {
+			t.Fatalf("result: %v", err)
+		}
+	}
+
+	waitForStats(t, pool, "scale down to MinSize", func(s worker.PoolStats) bool { return s.Workers == 1 })
+	if s := pool.Stats(); s.ScaleDowns != 3 || s.Idle != 1 {
+		t.Fatalf("stats after idling = %+v, want 3 scale-downs and 1 idle worker", s)
+	}
+	if h := pool.Health(context.Background()); h.Status != component.StatusHealthy {
+		t.Fatalf("idle health = %+v, want healthy", h)
+	}
+
+	// A pool scaled down still runs new work.
+	handle, err := pool.Submit(context.Background(), "late")
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if _, err := handle.Result(); err != nil {
+		t.Fatalf("late result: %v", err)
+	}
+}
+
+func TestPoolAutoscaleOnQueueLatency(t *testing.T) {
+	t.Parallel()
+
+	h := worker.HandlerFunc[string, string](func(ctx context.Context, task string, emit func(worker.Event[string])) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	pool := worker.NewPool(h, worker.PoolConfig{
+		Name:      "autoscale-latency",
+		Size:      1,
+		QueueSize: 16,
+		Autoscale: &worker.AutoscaleConfig{
+			MaxSize:           2,
+			Interval:          5 * time.Millisecond,
+			ScaleUpQueueDepth: 100, // only latency can trigger growth
+			ScaleUpLatency:    10 * time.Millisecond,
+			IdleTimeout:       time.Minute,
+		},
+	})
+	defer func() { _ = pool.Stop(context.Background()) }()
+
+	handles, err := pool.SubmitBatch(context.Background(), []string{"a", "b", "c", "d", "e", "f"})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	waitForStats(t, pool, "scale up on latency", func(s worker.PoolStats) bool { return s.Workers == 2 })
+	for _, h := range handles {
+		if _, err := h.Result(); err != nil {
+			t.Fatalf("result: %v", err)
+		}
+	}
+	if s := pool.Stats(); s.ScaleUps != 1 || s.ScaleDowns != 0 {
+		t.Fatalf("stats = %+v, want 1 scale-up and no scale-down before IdleTimeout", s)
+	}
+}
+
+func TestPoolAutoscaleReplacesUnhealthyWorkers(t *testing.T) {
+	t.Parallel()
+
+	h := worker.HandlerFunc[string, string](func(ctx context.Context, task string, emit func(worker.Event[string])) error {
+		if task == "crash" {
+			panic("boom")
+		}
+		return nil
+	})
+	pool := worker.NewPool(h, worker.PoolConfig{
+		Name:      "autoscale-supervised",
+		Size:      2,
+		QueueSize: 4,
+		Supervisor: &worker.SupervisorConfig{
+			RestartPolicy: worker.RestartOnFailure,
+			MaxRestarts:   1,
+			BackoffBase:   time.Millisecond,
+		},
+		Autoscale: &worker.AutoscaleConfig{
+			MinSize:  2,
+			MaxSize:  2,
+			Interval: 5 * time.Millisecond,
+		},
+	})
+	defer func() { _ = pool.Stop(context.Background()) }()
+
+	for range 2 {
+		handle, err := pool.Submit(context.Background(), "crash")
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+		if _, err := handle.Result(); err == nil {
+			t.Fatal("crash task succeeded")
+		}
+	}
+
+	// Both crashes may hit the same worker; either way every unhealthy worker
+	// is replaced and the pool returns to full strength.
+	deadline := time.Now().Add(5 * time.Second)
+	for pool.Health(context.Background()).Status != component.StatusHealthy {
+		if time.Now().After(deadline) {
+			t.Fatalf("health = %+v, want healthy after replacement", pool.Health(context.Background()))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	waitForStats(t, pool, "replacement workers", func(s worker.PoolStats) bool { return s.Workers == 2 })
+	if s := pool.Stats(); s.ScaleUps != 0 || s.ScaleDowns != 0 {
+		t.Fatalf("replacements counted as scaling: %+v", s)
+	}
+
+	for range 4 {
+		handle, err := pool.Submit(context.Background(), "ok")
+		if err != nil {
+			t.Fatalf("submit after replacement: %v", err)
+		}
+		if _, err := handle.Result(); err != nil {
+			t.Fatalf("result after replacement: %v", err)
+		}
+	}
+}
+
+func TestPoolHealthStopped(t *testing.T) {
+	t.Parallel()
+
+	h := worker.HandlerFunc[string, string](func(ctx context.Context, task string, emit func(worker.Event[string])) error {
+		return nil
+	})
+	pool := worker.NewPool(h, worker.PoolConfig{Name: "health", Size: 2})
+	if h := pool.Health(context.Background()); h.Status != component.StatusHealthy || h.Name != "health" {
+		t.Fatalf("health = %+v, want healthy", h)
+	}
+	if s := pool.Stats(); s.Workers != 2 || s.Idle != 2 {
+		t.Fatalf("fixed pool stats = %+v, want 2 idle workers", s)
+	}
+	_ = pool.Stop(context.Background())
+	if h := pool.Health(context.Background()); h.Status != component.StatusUnhealthy {
+		t.Fatalf("health after stop = %+v, want unhealthy", h)
+	}
+}
+
+func TestPoolAutoscaleRejectsLeastLoadedDispatch(t *testing.T) {
+	t.Parallel()
+
+	cfg := worker.PoolConfig{
+		Name:       "autoscale-dispatch",
+		Size:       1,
+		Dispatch:   worker.LeastLoaded,
+		Supervisor: &worker.SupervisorConfig{MaxRestarts: 3},
+		Autoscale:  &worker.AutoscaleConfig{MaxSize: 2},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("least_loaded dispatch with autoscale should be invalid")
+	}
+	pool := worker.NewPool(worker.HandlerFunc[string, string](func(context.Context, string, func(worker.Event[string])) error {
+		return nil
+	}), cfg)
+	defer func() { _ = pool.Stop(context.Background()) }()
+	if _, err := pool.Submit(context.Background(), "x"); err == nil {
+		t.Fatal("submit to an invalid pool should fail")
+	}
+}
@@ -0,0 +1,424 @@
+package worker
+
+import (
+	"container/heap"
+	"context"
+	"encoding"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gkerrors "github.com/kbukum/gokit/errors"
+)
+
+var (
+	_ encoding.TextMarshaler   = SchedulingPolicy("")
+	_ encoding.TextUnmarshaler = (*SchedulingPolicy)(nil)
+)
+
+// SchedulingPolicy controls the order in which a pool with task classes runs
+// queued tasks.
+type SchedulingPolicy string
+
+const (
+	// SchedulingWeightedFair shares workers between classes in proportion to
+	// their weights; a class with no backlog lends its share to the others.
+	SchedulingWeightedFair SchedulingPolicy = "weighted_fair"
+	// SchedulingStrictPriority always runs the highest-priority task first.
+	// Set PoolConfig.Aging so that low-priority tasks cannot starve.
+	SchedulingStrictPriority SchedulingPolicy = "strict_priority"
+)
+
+// DefaultClass is the class of tasks submitted without WithClass. It is added
+// with weight 1 if Classes does not declare it.
+const DefaultClass = "default"
+
+// MarshalText serializes a scheduling policy for config encoders.
+func (s SchedulingPolicy) MarshalText() ([]byte, error) {
+	policy := s
+	if policy == "" {
+		policy = SchedulingWeightedFair
+	}
+	if !policy.valid() {
+		return nil, invalidSchedulingPolicy(policy)
+	}
+	return []byte(policy), nil
+}
+
+// UnmarshalText parses a scheduling policy from config text.
+func (s *SchedulingPolicy) UnmarshalText(text []byte) error {
+	policy := SchedulingPolicy(text)
+	if policy == "" {
+		*s = SchedulingWeightedFair
+		return nil
+	}
+	if !policy.valid() {
+		return invalidSchedulingPolicy(policy)
+	}
+	*s = policy
+	return nil
+}
+
+func (s SchedulingPolicy) valid() bool {
+	switch s {
+	case SchedulingWeightedFair, SchedulingStrictPriority:
+		return true
+	default:
+		return false
+	}
+}
+
+func invalidSchedulingPolicy(policy SchedulingPolicy) error {
+	return gkerrors.InvalidInput("scheduling", "must be one of weighted_fair, strict_priority").WithDetail("value", string(policy))
+}
+
+// ClassConfig declares a named task class, such as a tenant or a workload
+// tier, with its own bounded backlog.
+type ClassConfig struct {
+	Name      string `yaml:"name"       mapstructure:"name"`
+	Weight    int    `yaml:"weight"     mapstructure:"weight"`     // share of workers under weighted_fair (default: 1)
+	Priority  int    `yaml:"priority"   mapstructure:"priority"`   // added to each task's priority; orders classes under strict_priority
+	QueueSize int    `yaml:"queue_size" mapstructure:"queue_size"` // backlog bound (default: PoolConfig.QueueSize, minimum 1)
+}
+
+// ClassStats reports utilization of one task class.
+type ClassStats struct {
+	Queued int `json:"queued"` // tasks waiting in the class backlog
+	Active int `json:"active"` // tasks of the class currently executing
+	Total  int `json:"total"`  // tasks submitted to the class
+	Failed int `json:"failed"` // tasks of the class that returned an error
+}
+
+// SubmitOption customizes a single Submit call.
+type SubmitOption func(*submitOptions)
+
+type submitOptions struct {
+	class    string
+	priority int
+}
+
+// WithClass submits a task to the named class. Submit fails if the pool does
+// not declare it.
+func WithClass(name string) SubmitOption {
+	return func(o *submitOptions) { o.class = name }
+}
+
+// WithPriority sets a task's priority; higher runs first within its class,
+// and across classes under strict_priority. Default: 0.
+func WithPriority(priority int) SubmitOption {
+	return func(o *submitOptions) { o.priority = priority }
+}
+
+// classItem is a queued task with its scheduling key.
+type classItem[I, O any] struct {
+	env      taskEnvelope[I, O]
+	priority int
+	enqueued time.Duration // since scheduler start
+	seq      uint64
+}
+
+// taskClass is the backlog and counters of one class.
+type taskClass[I, O any] struct {
+	cfg   ClassConfig
+	items classHeap[I, O]
+	// vtime is the class's virtual finish time under weighted_fair.
+	vtime float64
+
+	active atomic.Int64
+	total  atomic.Int64
+	failed atomic.Int64
+}
+
+// classHeap orders a class backlog by effective priority.
+type classHeap[I, O any] struct {
+	items []*classItem[I, O]
+	less  func(a, b *classItem[I, O]) bool
+}
+
+func (h *classHeap[I, O]) Len() int           { return len(h.items) }
+func (h *classHeap[I, O]) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h *classHeap[I, O]) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *classHeap[I, O]) Push(x any)         { h.items = append(h.items, x.(*classItem[I, O])) }
+func (h *classHeap[I, O]) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items[len(h.items)-1] = nil
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// classScheduler holds the backlogs of a pool with task classes. Workers take
+// tasks from it in scheduling order instead of reading the shared queue.
+type classScheduler[I, O any] struct {
+	policy SchedulingPolicy
+	aging  time.Duration
+	start  time.Time
+
+	mu      sync.Mutex
+	classes []*taskClass[I, O]
+	byName  map[string]*taskClass[I, O]
+	seq     uint64
+	vnow    float64
+	// added and taken are closed and replaced to wake waiting workers and
+	// blocked submitters.
+	added chan struct{}
+	taken chan struct{}
+}
+
+func newClassScheduler[I, O any](cfg PoolConfig) *classScheduler[I, O] {
+	s := &classScheduler[I, O]{
+		policy: cfg.Scheduling,
+		aging:  cfg.Aging,
+		start:  time.Now(),
+		byName: make(map[string]*taskClass[I, O]),
+		added:  make(chan struct{}),
+		taken:  make(chan struct{}),
+	}
+	if s.policy == "" {
+		s.policy = SchedulingWeightedFair
+	}
+	classes := cfg.Classes
+	if !hasClass(classes, DefaultClass) {
+		classes = append([]ClassConfig{{Name: DefaultClass}}, classes...)
+	}
+	for _, c := range classes {
+		if c.Weight <= 0 {
+			c.Weight = 1
+		}
+		if c.QueueSize <= 0 {
+			c.QueueSize = max(cfg.QueueSize, 1)
+		}
+		class := &taskClass[I, O]{cfg: c}
+		class.items.less = s.less
+		s.classes = append(s.classes, class)
+		s.byName[c.Name] = class
+	}
+	return s
+}
+
+func hasClass(classes []ClassConfig, name string) bool {
+	for _, c := range classes {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkClass returns an error unless the pool declares the named class. A
+// pool without task classes, including a durable one, has only DefaultClass.
+func (p *Pool[I, O]) checkClass(name string) error {
+	if name == "" || name == DefaultClass {
+		return nil
+	}
+	if p.classes != nil {
+		if _, ok := p.classes.byName[name]; ok {
+			return nil
+		}
+	}
+	return gkerrors.InvalidInput("class", fmt.Sprintf("pool %q has no class %q", p.cfg.Name, name))
+}
+
+// less reports whether a should run before b. With aging, each Aging interval
+// a task waits adds one to its priority; since all waiting tasks age at the
+// same rate, the order is fixed at enqueue time.
+func (s *classScheduler[I, O]) less(a, b *classItem[I, O]) bool {
+	if s.aging > 0 {
+		ka := int64(a.priority)*int64(s.aging) - int64(a.enqueued)
+		kb := int64(b.priority)*int64(s.aging) - int64(b.enqueued)
+		if ka != kb {
+			return ka > kb
+		}
+	} else if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	return a.seq < b.seq
+}
+
+// pickLocked returns the class whose head task runs next, or nil if all
+// backlogs are empty.
+func (s *classScheduler[I, O]) pickLocked() *taskClass[I, O] {
+	var next *taskClass[I, O]
+	for _, c := range s.classes {
+		if c.items.Len() == 0 {
+			continue
+		}
+		switch {
+		case next == nil:
+			next = c
+		case s.policy == SchedulingStrictPriority:
+			if s.less(c.items.items[0], next.items.items[0]) {
+				next = c
+			}
+		case c.vtime < next.vtime:
+			next = c
+		}
+	}
+	return next
+}
+
+// take blocks until a task is available or ctx is done. ready, if non-nil,
+// reports whether the calling worker may take tasks now.
+func (s *classScheduler[I, O]) take(ctx context.Context, ready func() bool) (taskEnvelope[I, O], bool) {
+	for {
+		s.mu.Lock()
+		if ready == nil || ready() {
+			if c := s.pickLocked(); c != nil {
+				item := heap.Pop(&c.items).(*classItem[I, O])
+				s.vnow = c.vtime
+				c.vtime += 1 / float64(c.cfg.Weight)
+				close(s.taken)
+				s.taken = make(chan struct{})
+				s.mu.Unlock()
+				return item.env, true
+			}
+		}
+		added := s.added
+		s.mu.Unlock()
+
+		// Readiness can change without a new task, so re-check periodically.
+		var recheck <-chan time.Time
+		if ready != nil {
+			recheck = time.After(classRecheckInterval)
+		}
+		select {
+		case <-ctx.Done():
+			return taskEnvelope[I, O]{}, false
+		case <-added:
+		case <-recheck:
+		}
+	}
+}
+
+// classRecheckInterval bounds how long a worker that was not ready waits
+// before checking again.
+const classRecheckInterval = time.Second
+
+// pushLocked adds env to class c. The caller has checked capacity.
+func (s *classScheduler[I, O]) pushLocked(c *taskClass[I, O], env taskEnvelope[I, O], priority int) {
+	if c.items.Len() == 0 {
+		c.vtime = max(c.vtime, s.vnow)
+	}
+	s.seq++
+	heap.Push(&c.items, &classItem[I, O]{
+		env:      env,
+		priority: c.cfg.Priority + priority,
+		enqueued: time.Since(s.start),
+		seq:      s.seq,
+	})
+	close(s.added)
+	s.added = make(chan struct{})
+}
+
+// removeOldestLocked evicts the longest-waiting task of class c.
+func (s *classScheduler[I, O]) removeOldestLocked(c *taskClass[I, O]) taskEnvelope[I, O] {
+	oldest := 0
+	for i, item := range c.items.items {
+		if item.seq < c.items.items[oldest].seq {
+			oldest = i
+		}
+	}
+	return heap.Remove(&c.items, oldest).(*classItem[I, O]).env
+}
+
+// drain removes every queued task.
+func (s *classScheduler[I, O]) drain() []taskEnvelope[I, O] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var envs []taskEnvelope[I, O]
+	for _, c := range s.classes {
+		for _, item := range c.items.items {
+			envs = append(envs, item.env)
+		}
+		c.items.items = nil
+	}
+	return envs
+}
+
+func (s *classScheduler[I, O]) stats() (queued int, classes map[string]ClassStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	classes = make(map[string]ClassStats, len(s.classes))
+	for _, c := range s.classes {
+		n := c.items.Len()
+		queued += n
+		classes[c.cfg.Name] = ClassStats{
+			Queued: n,
+			Active: int(c.active.Load()),
+			Total:  int(c.total.Load()),
+			Failed: int(c.failed.Load()),
+		}
+	}
+	return queued, classes
+}
+
+// enqueueClass adds env to its class backlog, applying the pool's
+// OverflowPolicy to that class's QueueSize.
+func (p *Pool[I, O]) enqueueClass(ctx context.Context, env taskEnvelope[I, O], opts submitOptions) (*TaskHandle[O], error) {
+	s := p.classes
+	name := opts.class
+	if name == "" {
+		name = DefaultClass
+	}
+	c := s.byName[name] // Submit has checked the class
+	env.class = c
+
+	for {
+		s.mu.Lock()
+		if c.items.Len() < c.cfg.QueueSize {
+			s.pushLocked(c, env, opts.priority)
+			s.mu.Unlock()
+			c.total.Add(1)
+			return env.handle, nil
+		}
+		switch p.cfg.Overflow {
+		case OverflowReject:
+			s.mu.Unlock()
+			env.handle.Cancel()
+			return nil, ErrQueueFull
+		case OverflowDropOldest:
+			dropped := s.removeOldestLocked(c)
+			s.pushLocked(c, env, opts.priority)
+			s.mu.Unlock()
+			p.failDroppedTask(dropped)
+			c.total.Add(1)
+			return env.handle, nil
+		}
+		taken := s.taken
+		s.mu.Unlock()
+
+		select {
+		case <-taken:
+		case <-ctx.Done():
+			env.handle.Cancel()
+			return nil, ctx.Err()
+		case <-p.acceptCtx.Done():
+			env.handle.Cancel()
+			return nil, p.stoppedError()
+		case <-p.poolCtx.Done():
+			env.handle.Cancel()
+			return nil, p.stoppedError()
+		}
+	}
+}
+
+// runClassWorker is the worker loop for a pool with task classes. Under a
+// supervisor, a worker marked unhealthy stops taking tasks.
+func (p *Pool[I, O]) runClassWorker(ctx context.Context, idx int) {
+	defer p.wg.Done()
+
+	workerID := fmt.Sprintf("%s-w%d", p.cfg.Name, idx)
+	var ready func() bool
+	if p.supervisor != nil {
+		ready = func() bool { return p.supervisor.shouldAcceptTask(idx) }
+	}
+	for {
+		env, ok := p.classes.take(ctx, ready)
+		if !ok {
+			return
+		}
+		env.class.active.Add(1)
+		p.runEnvelope(workerID, idx, env)
+		env.class.active.Add(-1)
+	}
+}
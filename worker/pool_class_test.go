@@ -0,0 +1,289 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/worker"
+)
+
+// orderRecorder is a handler that blocks on the task "gate" until release is
+// closed and records the order in which other tasks run.
+type orderRecorder struct {
+	started chan struct{}
+	release chan struct{}
+
+	mu    sync.Mutex
+	order []string
+}
+
+func newOrderRecorder() *orderRecorder {
+	return &orderRecorder{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (r *orderRecorder) Handle(ctx context.Context, task string, _ func(worker.Event[string])) error {
+	if task == "gate" {
+		close(r.started)
+		select {
+		case <-r.release:
+		case <-ctx.Done():
+		}
+		return nil
+	}
+	r.mu.Lock()
+	r.order = append(r.order, task)
+	r.mu.Unlock()
+	if strings.HasPrefix(task, "fail") {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func (r *orderRecorder) ran() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.order...)
+}
+
+// submitBehindGate occupies the single worker of pool with the gate task so
+// that the tasks submitted by fn queue up, then releases it and waits for all
+// of them.
+func submitBehindGate(t *testing.T, pool *worker.Pool[string, string], r *orderRecorder, fn func() []*worker.TaskHandle[string]) {
+	t.Helper()
+	if _, err := pool.Submit(context.Background(), "gate"); err != nil {
+		t.Fatalf("submit gate: %v", err)
+	}
+	<-r.started
+	handles := fn()
+	close(r.release)
+	for _, h := range handles {
+		<-h.Done()
+	}
+}
+
+func TestPoolClassesWeightedFair(t *testing.T) {
+	t.Parallel()
+
+	r := newOrderRecorder()
+	pool := worker.NewPool[string, string](r, worker.PoolConfig{
+		Name: "wfq",
+		Size: 1,
+		Classes: []worker.ClassConfig{
+			{Name: "bulk", Weight: 1, QueueSize: 100},
+			{Name: "interactive", Weight: 3, QueueSize: 100},
+		},
+	})
+	defer func() { _ = pool.Stop(context.Background()) }()
+
+	submitBehindGate(t, pool, r, func() []*worker.TaskHandle[string] {
+		var handles []*worker.TaskHandle[string]
+		for _, class := range []string{"bulk", "interactive"} {
+			for range 8 {
+				h, err := pool.Submit(context.Background(), class, worker.WithClass(class))
+				if err != nil {
+					t.Fatalf("submit %s: %v", class, err)
+				}
+				handles = append(handles, h)
+			}
+		}
+		return handles
+	})
+
+	var interactive int
+	for _, task := range r.ran()[:8] {
+		if task == "interactive" {
+			interactive++
+		}
+	}
+	if interactive != 6 {
+		t.Fatalf("first 8 tasks = %v, want 6 interactive for a 3:1 weight", r.ran()[:8])
+	}
+}
+
+func TestPoolStrictPriority(t *testing.T) {
+	t.Parallel()
+
+	r := newOrderRecorder()
+	pool := worker.NewPool[string, string](r, worker.PoolConfig{
+		Name:       "strict",
+		Size:       1,
+		QueueSize:  10,
+		Scheduling: worker.SchedulingStrictPriority,
+		Classes:    []worker.ClassConfig{{Name: "urgent", Priority: 10}},
+	})
+	defer func() { _ = pool.Stop(context.Background()) }()
+
+	submitBehindGate(t, pool, r, func() []*worker.TaskHandle[string] {
+		var handles []*worker.TaskHandle[string]
+		for _, s := range []struct {
+			task string
+			opts []worker.SubmitOption
+		}{
+			{"p1", []worker.SubmitOption{worker.WithPriority(1)}},
+			{"p5", []worker.SubmitOption{worker.WithPriority(5)}},
+			{"urgent", []worker.SubmitOption{worker.WithClass("urgent")}},
+			{"p3", []worker.SubmitOption{worker.WithPriority(3)}},
+		} {
+			h, err := pool.Submit(context.Background(), s.task, s.opts...)
+			if err != nil {
+				t.Fatalf("submit %s: %v", s.task, err)
+			}
+			handles = append(handles, h)
+		}
+		return handles
+	})
+
+	if got := strings.Join(r.ran(), ","); got != "urgent,p5,p3,p1" {
+		t.Fatalf("order = %s, want urgent,p5,p3,p1", got)
+	}
+}
+
+func TestPoolStrictPriorityAging(t *testing.T) {
+	t.Parallel()
+
+	r := newOrderRecorder()
+	pool := worker.NewPool[string, string](r, worker.PoolConfig{
+		Name:       "aging",
+		Size:       1,
+		QueueSize:  10,
+		Scheduling: worker.SchedulingStrictPriority,
+		Aging:      10 * time.Millisecond,
+	})
+	defer func() { _ = pool.Stop(context.Background()) }()
+
+	submitBehindGate(t, pool, r, func() []*worker.TaskHandle[string] {
+		old, _ := pool.Submit(context.Background(), "old")
+		time.Sleep(60 * time.Millisecond) // ages "old" past priority 2
+		fresh, _ := pool.Submit(context.Background(), "fresh", worker.WithPriority(2))
+		return []*worker.TaskHandle[string]{old, fresh}
+	})
+
+	if got := strings.Join(r.ran(), ","); got != "old,fresh" {
+		t.Fatalf("order = %s, want old,fresh", got)
+	}
+}
+
+func TestPoolClassOverflowAndStats(t *testing.T) {
+	t.Parallel()
+
+	r := newOrderRecorder()
+	pool := worker.NewPool[string, string](r, worker.PoolConfig{
+		Name:      "class-overflow",
+		Size:      1,
+		QueueSize: 4,
+		Overflow:  worker.OverflowReject,
+		Classes:   []worker.ClassConfig{{Name: "tenant-a", QueueSize: 1}},
+	})
+	defer func() { _ = pool.Stop(context.Background()) }()
+
+	if _, err := pool.Submit(context.Background(), "x", worker.WithClass("missing")); err == nil {
+		t.Fatal("submit to an undeclared class should fail")
+	}
+
+	submitBehindGate(t, pool, r, func() []*worker.TaskHandle[string] {
+		a, err := pool.Submit(context.Background(), "fail-a", worker.WithClass("tenant-a"))
+		if err != nil {
+			t.Fatalf("first tenant-a submit: %v", err)
+		}
+		if _, err := pool.Submit(context.Background(), "a2", worker.WithClass("tenant-a")); !errors.Is(err, worker.ErrQueueFull) {
+			t.Fatalf("second tenant-a submit = %v, want ErrQueueFull", err)
+		}
+		d, err := pool.Submit(context.Background(), "d")
+		if err != nil {
+			t.Fatalf("default class submit while tenant-a is full: %v", err)
+		}
+		if q := pool.Stats().Classes["tenant-a"].Queued; q != 1 {
+			t.Fatalf("tenant-a queued = %d, want 1", q)
+		}
+		return []*worker.TaskHandle[string]{a, d}
+	})
+
+	stats := pool.Stats()
+	tenant, def := stats.Classes["tenant-a"], stats.Classes[worker.DefaultClass]
+	if tenant.Total != 1 || tenant.Failed != 1 || def.Total != 2 || def.Failed != 0 {
+		t.Fatalf("class stats = %+v", stats.Classes)
+	}
+}
+
+func TestPoolClassesStopFailsQueuedTasks(t *testing.T) {
+	t.Parallel()
+
+	r := newOrderRecorder()
+	pool := worker.NewPool[string, string](r, worker.PoolConfig{
+		Name:        "class-stop",
+		Size:        1,
+		QueueSize:   4,
+		GracePeriod: 20 * time.Millisecond,
+		Classes:     []worker.ClassConfig{{Name: "batch"}},
+	})
+	if _, err := pool.Submit(context.Background(), "gate"); err != nil {
+		t.Fatalf("submit gate: %v", err)
+	}
+	<-r.started
+	queued, err := pool.Submit(context.Background(), "never", worker.WithClass("batch"))
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if err := pool.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if _, err := queued.Result(); err == nil {
+		t.Fatal("queued task should fail when the pool stops")
+	}
+	if len(r.ran()) != 0 {
+		t.Fatalf("queued task ran: %v", r.ran())
+	}
+}
+
+func TestPoolWithoutClassesRejectsUndeclaredClass(t *testing.T) {
+	t.Parallel()
+
+	configs := map[string]worker.PoolConfig{
+		"plain":      {Size: 1},
+		"durable":    {Size: 1, Durable: &worker.DurableConfig{Backend: worker.NewMemoryQueue()}},
+		"autoscaled": {Size: 1, Autoscale: &worker.AutoscaleConfig{MaxSize: 2}},
+	}
+	for name, cfg := range configs {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg.Name = name
+			pool := worker.NewPool[string, string](newOrderRecorder(), cfg)
+			defer func() { _ = pool.Stop(context.Background()) }()
+
+			if _, err := pool.Submit(context.Background(), "x", worker.WithClass("missing")); err == nil {
+				t.Fatal("submit to an undeclared class should fail")
+			}
+			h, err := pool.Submit(context.Background(), "x", worker.WithClass(worker.DefaultClass))
+			if err != nil {
+				t.Fatalf("submit to the default class: %v", err)
+			}
+			if _, err := h.Result(); err != nil {
+				t.Fatalf("result: %v", err)
+			}
+		})
+	}
+}
+
+func TestPoolClassesRejectLeastLoadedDispatch(t *testing.T) {
+	t.Parallel()
+
+	cfg := worker.PoolConfig{
+		Name:     "class-dispatch",
+		Size:     1,
+		Dispatch: worker.LeastLoaded,
+		Classes:  []worker.ClassConfig{{Name: "batch"}},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("least_loaded dispatch with classes should be invalid")
+	}
+	pool := worker.NewPool[string, string](newOrderRecorder(), cfg)
+	defer func() { _ = pool.Stop(context.Background()) }()
+	if _, err := pool.Submit(context.Background(), "x"); err == nil {
+		t.Fatal("submit to an invalid pool should fail")
+	}
+}
@@ -0,0 +1,339 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gkerrors "github.com/kbukum/gokit/errors"
+)
+
+// DurableConfig backs a pool with a QueueBackend instead of its in-memory
+// queue, so queued and in-flight tasks survive a restart. Tasks are encoded
+// as JSON and delivered at least once; handlers should be idempotent.
+type DurableConfig struct {
+	// Backend stores the tasks. Default: a MemoryQueue.
+	Backend           QueueBackend  `yaml:"-"                  mapstructure:"-"`
+	VisibilityTimeout time.Duration `yaml:"visibility_timeout" mapstructure:"visibility_timeout"` // lease per delivery, renewed while running (default: 30s)
+	PollInterval      time.Duration `yaml:"poll_interval"      mapstructure:"poll_interval"`      // idle wait when the queue is empty (default: 1s)
+	MaxAttempts       int           `yaml:"max_attempts"       mapstructure:"max_attempts"`       // deliveries before dead-lettering (default: 5)
+	BackoffBase       time.Duration `yaml:"backoff_base"       mapstructure:"backoff_base"`       // first retry delay, doubled per attempt (default: 1s)
+	BackoffMax        time.Duration `yaml:"backoff_max"        mapstructure:"backoff_max"`        // retry delay cap (default: 5m)
+}
+
+func (c DurableConfig) withDefaults() DurableConfig {
+	if c.Backend == nil {
+		c.Backend = NewMemoryQueue()
+	}
+	if c.VisibilityTimeout <= 0 {
+		c.VisibilityTimeout = 30 * time.Second
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 5
+	}
+	if c.BackoffBase <= 0 {
+		c.BackoffBase = time.Second
+	}
+	if c.BackoffMax <= 0 {
+		c.BackoffMax = 5 * time.Minute
+	}
+	return c
+}
+
+// backoff returns the retry delay after the given number of deliveries.
+func (c DurableConfig) backoff(attempts int) time.Duration {
+	d := c.BackoffBase
+	for range attempts - 1 {
+		d *= 2
+		if d >= c.BackoffMax {
+			return c.BackoffMax
+		}
+	}
+	return min(d, c.BackoffMax)
+}
+
+// durableHandle is a task submitted through this pool whose outcome has not
+// been reported yet. ctx is canceled by the handle's Cancel.
+type durableHandle[O any] struct {
+	handle *TaskHandle[O]
+	ctx    context.Context
+}
+
+// durableQueue tracks local handles of tasks stored in the backend. A task
+// delivered to this pool completes its handle; one delivered to another
+// replica leaves the handle pending until Stop.
+type durableQueue[O any] struct {
+	cfg  DurableConfig
+	wake chan struct{}
+
+	mu      sync.Mutex
+	handles map[string]durableHandle[O]
+}
+
+func newDurableQueue[O any](cfg DurableConfig, size int) *durableQueue[O] {
+	return &durableQueue[O]{
+		cfg:     cfg.withDefaults(),
+		wake:    make(chan struct{}, size),
+		handles: make(map[string]durableHandle[O]),
+	}
+}
+
+func (d *durableQueue[O]) track(h durableHandle[O]) {
+	d.mu.Lock()
+	d.handles[h.handle.ID()] = h
+	d.mu.Unlock()
+}
+
+func (d *durableQueue[O]) lookup(id string) (durableHandle[O], bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	h, ok := d.handles[id]
+	return h, ok
+}
+
+// finish reports a task's outcome to its local handle, if any.
+func (d *durableQueue[O]) finish(id string, result O, err error) {
+	d.mu.Lock()
+	h, ok := d.handles[id]
+	delete(d.handles, id)
+	d.mu.Unlock()
+	if ok {
+		h.handle.complete(result, err)
+	}
+}
+
+// deferAll completes every pending handle with ErrTaskDeferred.
+func (d *durableQueue[O]) deferAll() {
+	d.mu.Lock()
+	handles := d.handles
+	d.handles = make(map[string]durableHandle[O])
+	d.mu.Unlock()
+	var zero O
+	for _, h := range handles {
+		h.handle.emit(errorEvent[O](ErrTaskDeferred))
+		h.handle.complete(zero, ErrTaskDeferred)
+	}
+}
+
+// notify wakes an idle worker after a local submission.
+func (d *durableQueue[O]) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// submitDurable stores a task in the backend. ctx bounds only the enqueue;
+// the task itself is canceled through its handle.
+func (p *Pool[I, O]) submitDurable(ctx context.Context, task I) (*TaskHandle[O], error) {
+	payload, err := json.Marshal(task)
+	if err != nil {
+		return nil, gkerrors.InvalidInput("task", "cannot be encoded for the durable queue").WithCause(err)
+	}
+	handleCtx, cancel := context.WithCancel(context.Background())
+	handle := newTaskHandle[O](cancel, p.cfg.EventBuffer)
+
+	p.mu.Lock()
+	if p.stopped.Load() {
+		p.mu.Unlock()
+		cancel()
+		return nil, p.stoppedError()
+	}
+	p.durable.track(durableHandle[O]{handle: handle, ctx: handleCtx})
+	p.mu.Unlock()
+
+	if err := p.durable.cfg.Backend.Enqueue(ctx, handle.ID(), payload); err != nil {
+		p.durable.mu.Lock()
+		delete(p.durable.handles, handle.ID())
+		p.durable.mu.Unlock()
+		cancel()
+		return nil, fmt.Errorf("worker: pool %q enqueue: %w", p.cfg.Name, err)
+	}
+	p.totalTasks.Add(1)
+	p.durable.notify()
+	return handle, nil
+}
+
+// runDurableWorker is the worker loop for a durable pool. It leases tasks
+// from the backend until the pool stops accepting work or ctx is canceled.
+func (p *Pool[I, O]) runDurableWorker(ctx context.Context, idx int) {
+	defer p.wg.Done()
+
+	workerID := fmt.Sprintf("%s-w%d", p.cfg.Name, idx)
+	cfg := p.durable.cfg
+	idle := time.NewTimer(0)
+	defer idle.Stop()
+	<-idle.C
+
+	for p.acceptCtx.Err() == nil && ctx.Err() == nil {
+		var task *QueuedTask
+		if p.supervisor == nil || p.supervisor.shouldAcceptTask(idx) {
+			var err error
+			task, err = cfg.Backend.Dequeue(p.acceptCtx, cfg.VisibilityTimeout)
+			if err != nil && p.acceptCtx.Err() == nil {
+				p.emitDurableLog(fmt.Sprintf("dequeue failed: %v", err))
+			}
+		}
+		if task == nil {
+			idle.Reset(cfg.PollInterval)
+			select {
+			case <-p.acceptCtx.Done():
+			case <-ctx.Done():
+			case <-p.durable.wake:
+				if !idle.Stop() {
+					<-idle.C
+				}
+			case <-idle.C:
+			}
+			continue
+		}
+
+		p.mu.Lock()
+		if p.stopped.Load() {
+			p.mu.Unlock()
+			p.settle(task, func(ctx context.Context) error {
+				return cfg.Backend.Retry(ctx, task, time.Now(), "pool stopped")
+			})
+			return
+		}
+		p.taskWg.Add(1)
+		p.mu.Unlock()
+		p.runDurableTask(workerID, idx, task)
+	}
+}
+
+// runDurableTask executes one delivery and acknowledges, retries or
+// dead-letters it according to the outcome.
+func (p *Pool[I, O]) runDurableTask(workerID string, idx int, task *QueuedTask) {
+	p.stats[idx].active.Add(1)
+	defer p.stats[idx].active.Add(-1)
+	defer p.taskWg.Done()
+	if p.scaler != nil {
+		if task.Attempts == 1 && !task.EnqueuedAt.IsZero() {
+			p.scaler.observeWait(time.Since(task.EnqueuedAt))
+		}
+		p.scaler.busy(idx)
+		defer p.scaler.busy(idx)
+	}
+
+	cfg := p.durable.cfg
+	local, ok := p.durable.lookup(task.ID)
+	if !ok {
+		// Submitted by another replica or before a restart. Nobody reads this
+		// handle, so it starts closed and events reach only the pool channel.
+		local = durableHandle[O]{handle: &TaskHandle[O]{id: task.ID, closed: true}, ctx: context.Background()}
+	}
+	var zero O
+	if err := local.ctx.Err(); err != nil {
+		p.settle(task, func(ctx context.Context) error { return cfg.Backend.Ack(ctx, task) })
+		p.durable.finish(task.ID, zero, err)
+		return
+	}
+
+	var input I
+	if err := json.Unmarshal(task.Payload, &input); err != nil {
+		err = gkerrors.InvalidInput("task", "cannot be decoded from the durable queue").WithCause(err)
+		p.failCount.Add(1)
+		p.settle(task, func(ctx context.Context) error { return cfg.Backend.DeadLetter(ctx, task, err.Error()) })
+		p.durable.finish(task.ID, zero, fmt.Errorf("%w: %w", ErrDeadLettered, err))
+		return
+	}
+
+	taskCtx, cancel := context.WithCancel(p.poolCtx)
+	defer cancel()
+	stopAfter := context.AfterFunc(local.ctx, cancel)
+	defer stopAfter()
+	var lost atomic.Bool
+	stopRenew := p.renewLease(taskCtx, cancel, task, &lost)
+
+	env := taskEnvelope[I, O]{task: input, handle: local.handle, ctx: taskCtx}
+	result, err := zero, p.supervisorBackoff(taskCtx, idx)
+	if err == nil {
+		result, err = p.runHandler(workerID, idx, env)
+	}
+	stopRenew()
+
+	switch {
+	case lost.Load():
+		// Another consumer owns the task now; it reports the outcome.
+		p.emitDurableLog(fmt.Sprintf("task %s: lease lost while running", task.ID))
+	case err == nil || local.ctx.Err() != nil:
+		p.settle(task, func(ctx context.Context) error { return cfg.Backend.Ack(ctx, task) })
+		p.durable.finish(task.ID, result, err)
+	case p.poolCtx.Err() != nil:
+		// Shutting down: make the task visible to other replicas right away.
+		p.settle(task, func(ctx context.Context) error {
+			return cfg.Backend.Retry(ctx, task, time.Now(), err.Error())
+		})
+	case task.Attempts >= cfg.MaxAttempts:
+		p.settle(task, func(ctx context.Context) error { return cfg.Backend.DeadLetter(ctx, task, err.Error()) })
+		p.durable.finish(task.ID, result, fmt.Errorf("%w: %w", ErrDeadLettered, err))
+	default:
+		retryAt := time.Now().Add(cfg.backoff(task.Attempts))
+		p.settle(task, func(ctx context.Context) error { return cfg.Backend.Retry(ctx, task, retryAt, err.Error()) })
+	}
+}
+
+// renewLease extends the task's lease every half visibility timeout until
+// the returned stop function is called. If the lease is lost it sets lost
+// and cancels the task.
+func (p *Pool[I, O]) renewLease(ctx context.Context, cancel context.CancelFunc, task *QueuedTask, lost *atomic.Bool) (stop func()) {
+	cfg := p.durable.cfg
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(cfg.VisibilityTimeout / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := cfg.Backend.Extend(ctx, task, cfg.VisibilityTimeout)
+				switch {
+				case errors.Is(err, ErrLeaseLost):
+					lost.Store(true)
+					cancel()
+					return
+				case err != nil && ctx.Err() == nil:
+					p.emitDurableLog(fmt.Sprintf("task %s: extend lease failed: %v", task.ID, err))
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// settle runs a backend call that records a task's outcome. It is detached
+// from the pool context so that outcomes are still recorded during shutdown.
+func (p *Pool[I, O]) settle(task *QueuedTask, op func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.durable.cfg.VisibilityTimeout)
+	defer cancel()
+	if err := op(ctx); err != nil {
+		p.emitDurableLog(fmt.Sprintf("task %s: %v", task.ID, err))
+	}
+}
+
+// emitDurableLog reports a backend problem through the pool event channel.
+func (p *Pool[I, O]) emitDurableLog(msg string) {
+	e := LogEvent[O](msg, map[string]any{"source": "durable_queue"})
+	e.WorkerID = p.cfg.Name
+	select {
+	case p.events <- e:
+	default:
+	}
+}
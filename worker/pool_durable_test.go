@@ -0,0 +1,184 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/worker"
+)
+
+func TestDurablePoolRetriesThenSucceeds(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	h := worker.HandlerFunc[int, int](func(ctx context.Context, task int, emit func(worker.Event[int])) error {
+		if calls.Add(1) < 3 {
+			return errors.New("transient")
+		}
+		emit(worker.PartialEvent(task * 2))
+		return nil
+	})
+	queue := worker.NewMemoryQueue()
+	pool := worker.NewPool(h, worker.PoolConfig{
+		Name: "durable",
+		Size: 2,
+		Durable: &worker.DurableConfig{
+			Backend:      queue,
+			PollInterval: 5 * time.Millisecond,
+			BackoffBase:  time.Millisecond,
+		},
+	})
+	defer func() { _ = pool.Stop(context.Background()) }()
+
+	handle, err := pool.Submit(context.Background(), 21)
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	var errorsSeen, partials int
+	for e := range handle.Events() {
+		switch e.Type {
+		case worker.EventError:
+			errorsSeen++
+		case worker.EventPartial:
+			partials++
+			if e.Data != 42 || e.TaskID != handle.ID() {
+				t.Errorf("partial event = %+v", e)
+			}
+		}
+	}
+	if _, err := handle.Result(); err != nil {
+		t.Fatalf("result: %v", err)
+	}
+	if errorsSeen != 2 || partials != 1 {
+		t.Fatalf("events: %d errors, %d partials; want 2 and 1", errorsSeen, partials)
+	}
+	if queue.Len() != 0 {
+		t.Fatalf("acknowledged task still queued: Len = %d", queue.Len())
+	}
+}
+
+func TestDurablePoolDeadLetters(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("boom")
+	h := worker.HandlerFunc[string, string](func(context.Context, string, func(worker.Event[string])) error {
+		return boom
+	})
+	queue := worker.NewMemoryQueue()
+	pool := worker.NewPool(h, worker.PoolConfig{
+		Name: "dlq",
+		Size: 1,
+		Durable: &worker.DurableConfig{
+			Backend:      queue,
+			PollInterval: 5 * time.Millisecond,
+			MaxAttempts:  3,
+			BackoffBase:  time.Millisecond,
+		},
+	})
+	defer func() { _ = pool.Stop(context.Background()) }()
+
+	handle, err := pool.Submit(context.Background(), "job")
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	_, err = handle.Result()
+	if !errors.Is(err, worker.ErrDeadLettered) || !errors.Is(err, boom) {
+		t.Fatalf("result error = %v, want dead letter wrapping boom", err)
+	}
+	dead := queue.DeadLetters()
+	if len(dead) != 1 || dead[0].Attempts != 3 || dead[0].LastError != "boom" || string(dead[0].Payload) != `"job"` {
+		t.Fatalf("DeadLetters = %+v", dead)
+	}
+}
+
+func TestDurablePoolResumesAfterRestart(t *testing.T) {
+	t.Parallel()
+
+	queue := worker.NewMemoryQueue()
+	cfg := worker.PoolConfig{
+		Name:        "restart",
+		Size:        1,
+		GracePeriod: 20 * time.Millisecond,
+		Durable:     &worker.DurableConfig{Backend: queue, PollInterval: 5 * time.Millisecond},
+	}
+
+	started := make(chan struct{})
+	blocking := worker.HandlerFunc[int, int](func(ctx context.Context, _ int, _ func(worker.Event[int])) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	first := worker.NewPool(blocking, cfg)
+	inFlight, _ := first.Submit(context.Background(), 1)
+	<-started
+	queued, _ := first.Submit(context.Background(), 2)
+	if err := first.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	for _, h := range []*worker.TaskHandle[int]{inFlight, queued} {
+		if _, err := h.Result(); !errors.Is(err, worker.ErrTaskDeferred) {
+			t.Fatalf("result after stop = %v, want ErrTaskDeferred", err)
+		}
+	}
+	if queue.Len() != 2 {
+		t.Fatalf("Len after stop = %d, want 2", queue.Len())
+	}
+
+	var sum atomic.Int64
+	summing := worker.HandlerFunc[int, int](func(_ context.Context, task int, _ func(worker.Event[int])) error {
+		sum.Add(int64(task))
+		return nil
+	})
+	second := worker.NewPool(summing, cfg)
+	deadline := time.Now().Add(2 * time.Second)
+	for queue.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := second.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if sum.Load() != 3 || queue.Len() != 0 {
+		t.Fatalf("resumed pool ran sum=%d with %d left, want 3 and 0", sum.Load(), queue.Len())
+	}
+}
+
+func TestDurablePoolRedeliversExpiredLease(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// A consumer that crashed mid-task leaves its lease to expire.
+	queue := worker.NewMemoryQueue()
+	_ = queue.Enqueue(ctx, "orphan", []byte("5"))
+	if task, _ := queue.Dequeue(ctx, 30*time.Millisecond); task == nil {
+		t.Fatal("expected a task")
+	}
+
+	h := worker.HandlerFunc[int, int](func(_ context.Context, task int, emit func(worker.Event[int])) error {
+		emit(worker.PartialEvent(task))
+		return nil
+	})
+	pool := worker.NewPool(h, worker.PoolConfig{
+		Name:    "redeliver",
+		Size:    1,
+		Durable: &worker.DurableConfig{Backend: queue, PollInterval: 5 * time.Millisecond},
+	})
+	defer func() { _ = pool.Stop(context.Background()) }()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case e := <-pool.Events():
+			if e.Type == worker.EventResult {
+				if e.TaskID != "orphan" {
+					t.Fatalf("result for task %q, want orphan", e.TaskID)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("expired lease was not redelivered")
+		}
+	}
+}
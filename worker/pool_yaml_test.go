@@ -2,6 +2,7 @@
 
 import (
 	"testing"
+	"time"
 
 	"github.com/go-viper/mapstructure/v2"
 	"go.yaml.in/yaml/v3"
@@ -56,9 +57,12 @@ func decodePoolConfigYAML(t *testing.T, blob []byte) worker.PoolConfig {
 	}
 	var cfg worker.PoolConfig
 	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
-		DecodeHook: mapstructure.TextUnmarshallerHookFunc(),
-		Result:     &cfg,
-		TagName:    "mapstructure",
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.TextUnmarshallerHookFunc(),
+		),
+		Result:  &cfg,
+		TagName: "mapstructure",
 	})
 	if err != nil {
 		t.Fatalf("new decoder: %v", err)
@@ -68,3 +72,57 @@ func decodePoolConfigYAML(t *testing.T, blob []byte) worker.PoolConfig {
 	}
 	return cfg
 }
+
+func TestPoolConfigDurableYAMLDecode(t *testing.T) {
+	t.Parallel()
+
+	cfg := decodePoolConfigYAML(t, []byte("name: yaml-durable\ndurable:\n  visibility_timeout: 1m\n  max_attempts: 8\n"))
+	if cfg.Durable == nil || cfg.Durable.VisibilityTimeout != time.Minute || cfg.Durable.MaxAttempts != 8 {
+		t.Fatalf("durable = %+v", cfg.Durable)
+	}
+}
+
+func TestPoolConfigClassesYAMLDecode(t *testing.T) {
+	t.Parallel()
+
+	cfg := decodePoolConfigYAML(t, []byte(`name: yaml-classes
+scheduling: strict_priority
+aging: 30s
+classes:
+  - name: interactive
+    weight: 4
+    priority: 10
+  - name: bulk
+    queue_size: 1000
+`))
+	if cfg.Scheduling != worker.SchedulingStrictPriority || cfg.Aging != 30*time.Second {
+		t.Fatalf("scheduling = %q, aging = %v", cfg.Scheduling, cfg.Aging)
+	}
+	if len(cfg.Classes) != 2 || cfg.Classes[0].Weight != 4 || cfg.Classes[0].Priority != 10 || cfg.Classes[1].QueueSize != 1000 {
+		t.Fatalf("classes = %+v", cfg.Classes)
+	}
+}
+
+func TestPoolConfigAutoscaleYAMLDecode(t *testing.T) {
+	t.Parallel()
+
+	cfg := decodePoolConfigYAML(t, []byte(`name: yaml-autoscale
+size: 2
+autoscale:
+  min_size: 1
+  max_size: 16
+  interval: 500ms
+  scale_up_queue_depth: 4
+  scale_up_latency: 2s
+  scale_up_step: 2
+  idle_timeout: 1m
+`))
+	a := cfg.Autoscale
+	if a == nil {
+		t.Fatal("autoscale not decoded")
+	}
+	if a.MinSize != 1 || a.MaxSize != 16 || a.Interval != 500*time.Millisecond || a.ScaleUpQueueDepth != 4 ||
+		a.ScaleUpLatency != 2*time.Second || a.ScaleUpStep != 2 || a.IdleTimeout != time.Minute {
+		t.Fatalf("autoscale = %+v", *a)
+	}
+}
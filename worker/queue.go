@@ -0,0 +1,220 @@
+package worker
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	gkerrors "github.com/kbukum/gokit/errors"
+)
+
+var (
+	// ErrLeaseLost is returned by a QueueBackend when a delivery's visibility
+	// timeout expired and the task was leased again or removed.
+	ErrLeaseLost = gkerrors.Conflict("worker queue lease was lost")
+	// ErrDeadLettered is reported, wrapping the last attempt's error, to a task
+	// that exhausted its attempts and was moved to the dead-letter state.
+	ErrDeadLettered = gkerrors.New(gkerrors.ErrCodeInternal, "worker task moved to dead letter", 500)
+	// ErrTaskDeferred is reported to a durable task that was still queued when
+	// its pool stopped. The task stays in the backend and runs later.
+	ErrTaskDeferred = gkerrors.Canceled("worker task deferred to durable queue")
+)
+
+// QueuedTask is one delivery of a task leased from a QueueBackend.
+type QueuedTask struct {
+	// ID identifies the task across deliveries.
+	ID string
+	// Payload is the encoded task.
+	Payload []byte
+	// Attempts counts deliveries, including this one.
+	Attempts int
+	// Lease identifies this delivery; it is stale once the visibility timeout
+	// expires and another consumer leases the task.
+	Lease string
+	// LastError is the reason given when the task was last retried or
+	// dead-lettered.
+	LastError string
+	// EnqueuedAt is when the task was first enqueued.
+	EnqueuedAt time.Time
+}
+
+// QueueBackend persists pool tasks so that they survive restarts. Delivery is
+// at-least-once: a leased task that is neither acknowledged, retried nor
+// dead-lettered before its visibility timeout becomes visible again.
+//
+// Ack, Retry, Extend and DeadLetter return ErrLeaseLost if the delivery's
+// lease is no longer current.
+type QueueBackend interface {
+	// Enqueue stores a task under id, visible immediately.
+	Enqueue(ctx context.Context, id string, payload []byte) error
+	// Dequeue leases the oldest visible task, hiding it from other consumers
+	// for visibility. It returns nil and no error when no task is visible.
+	Dequeue(ctx context.Context, visibility time.Duration) (*QueuedTask, error)
+	// Extend keeps a leased task hidden for visibility from now.
+	Extend(ctx context.Context, task *QueuedTask, visibility time.Duration) error
+	// Ack removes a leased task after it completed.
+	Ack(ctx context.Context, task *QueuedTask) error
+	// Retry returns a leased task to the queue, visible again at at.
+	Retry(ctx context.Context, task *QueuedTask, at time.Time, reason string) error
+	// DeadLetter moves a leased task to the dead-letter state, where it is
+	// kept but never delivered again.
+	DeadLetter(ctx context.Context, task *QueuedTask, reason string) error
+}
+
+// MemoryQueue is an in-process QueueBackend for tests and single-instance
+// deployments. It gives a pool retries and dead-lettering but does not
+// survive a restart.
+type MemoryQueue struct {
+	now func() time.Time
+
+	mu    sync.Mutex
+	seq   int64
+	tasks map[string]*memoryQueued
+}
+
+type memoryQueued struct {
+	task      QueuedTask
+	seq       int64
+	visibleAt time.Time
+	dead      bool
+}
+
+// NewMemoryQueue creates an empty in-process queue.
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{now: time.Now, tasks: make(map[string]*memoryQueued)}
+}
+
+// Enqueue implements QueueBackend.
+func (q *MemoryQueue) Enqueue(_ context.Context, id string, payload []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.tasks[id]; ok {
+		return gkerrors.AlreadyExists("queued task").WithDetail("id", id)
+	}
+	q.seq++
+	now := q.now()
+	q.tasks[id] = &memoryQueued{
+		task:      QueuedTask{ID: id, Payload: slices.Clone(payload), EnqueuedAt: now},
+		seq:       q.seq,
+		visibleAt: now,
+	}
+	return nil
+}
+
+// Dequeue implements QueueBackend.
+func (q *MemoryQueue) Dequeue(_ context.Context, visibility time.Duration) (*QueuedTask, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.now()
+	var next *memoryQueued
+	for _, e := range q.tasks {
+		if e.dead || e.visibleAt.After(now) {
+			continue
+		}
+		if next == nil || e.seq < next.seq {
+			next = e
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+	next.task.Attempts++
+	next.task.Lease = uuid.NewString()
+	next.visibleAt = now.Add(visibility)
+	task := next.task
+	return &task, nil
+}
+
+// Extend implements QueueBackend.
+func (q *MemoryQueue) Extend(_ context.Context, task *QueuedTask, visibility time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	e, err := q.leased(task)
+	if err != nil {
+		return err
+	}
+	e.visibleAt = q.now().Add(visibility)
+	return nil
+}
+
+// Ack implements QueueBackend.
+func (q *MemoryQueue) Ack(_ context.Context, task *QueuedTask) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, err := q.leased(task); err != nil {
+		return err
+	}
+	delete(q.tasks, task.ID)
+	return nil
+}
+
+// Retry implements QueueBackend.
+func (q *MemoryQueue) Retry(_ context.Context, task *QueuedTask, at time.Time, reason string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	e, err := q.leased(task)
+	if err != nil {
+		return err
+	}
+	e.visibleAt = at
+	e.task.Lease = ""
+	e.task.LastError = reason
+	return nil
+}
+
+// DeadLetter implements QueueBackend.
+func (q *MemoryQueue) DeadLetter(_ context.Context, task *QueuedTask, reason string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	e, err := q.leased(task)
+	if err != nil {
+		return err
+	}
+	e.dead = true
+	e.task.Lease = ""
+	e.task.LastError = reason
+	return nil
+}
+
+// Len returns the number of tasks waiting or leased, excluding dead letters.
+func (q *MemoryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var n int
+	for _, e := range q.tasks {
+		if !e.dead {
+			n++
+		}
+	}
+	return n
+}
+
+// DeadLetters returns the dead-lettered tasks in enqueue order.
+func (q *MemoryQueue) DeadLetters() []QueuedTask {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var dead []*memoryQueued
+	for _, e := range q.tasks {
+		if e.dead {
+			dead = append(dead, e)
+		}
+	}
+	slices.SortFunc(dead, func(a, b *memoryQueued) int { return int(a.seq - b.seq) })
+	tasks := make([]QueuedTask, len(dead))
+	for i, e := range dead {
+		tasks[i] = e.task
+	}
+	return tasks
+}
+
+// leased returns the entry for task if its lease is current.
+func (q *MemoryQueue) leased(task *QueuedTask) (*memoryQueued, error) {
+	e, ok := q.tasks[task.ID]
+	if !ok || e.dead || e.task.Lease == "" || e.task.Lease != task.Lease {
+		return nil, ErrLeaseLost
+	}
+	return e, nil
+}
@@ -0,0 +1,83 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kbukum/gokit/worker"
+)
+
+func TestMemoryQueueLeaseLifecycle(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	q := worker.NewMemoryQueue()
+
+	for _, id := range []string{"a", "b"} {
+		if err := q.Enqueue(ctx, id, []byte(id)); err != nil {
+			t.Fatalf("enqueue %s: %v", id, err)
+		}
+	}
+	if err := q.Enqueue(ctx, "a", nil); err == nil {
+		t.Fatal("duplicate enqueue should fail")
+	}
+
+	a, _ := q.Dequeue(ctx, time.Minute)
+	b, _ := q.Dequeue(ctx, time.Minute)
+	if a == nil || b == nil || a.ID != "a" || b.ID != "b" || a.Attempts != 1 {
+		t.Fatalf("dequeue order = %+v, %+v", a, b)
+	}
+	if none, err := q.Dequeue(ctx, time.Minute); none != nil || err != nil {
+		t.Fatalf("leased tasks must be hidden, got %+v, %v", none, err)
+	}
+
+	if err := q.Retry(ctx, a, time.Now(), "boom"); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if err := q.Ack(ctx, a); !errors.Is(err, worker.ErrLeaseLost) {
+		t.Fatalf("ack after retry = %v, want ErrLeaseLost", err)
+	}
+	again, _ := q.Dequeue(ctx, time.Minute)
+	if again == nil || again.ID != "a" || again.Attempts != 2 || again.LastError != "boom" {
+		t.Fatalf("redelivery = %+v", again)
+	}
+
+	if err := q.Ack(ctx, again); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	if err := q.DeadLetter(ctx, b, "poison"); err != nil {
+		t.Fatalf("dead letter: %v", err)
+	}
+	if q.Len() != 0 {
+		t.Fatalf("Len = %d, want 0", q.Len())
+	}
+	dead := q.DeadLetters()
+	if len(dead) != 1 || dead[0].ID != "b" || dead[0].LastError != "poison" {
+		t.Fatalf("DeadLetters = %+v", dead)
+	}
+}
+
+func TestMemoryQueueVisibilityTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	q := worker.NewMemoryQueue()
+	_ = q.Enqueue(ctx, "a", nil)
+
+	first, _ := q.Dequeue(ctx, 20*time.Millisecond)
+	if err := q.Extend(ctx, first, 20*time.Millisecond); err != nil {
+		t.Fatalf("extend: %v", err)
+	}
+	time.Sleep(40 * time.Millisecond)
+
+	second, _ := q.Dequeue(ctx, time.Minute)
+	if second == nil || second.Attempts != 2 || second.Lease == first.Lease {
+		t.Fatalf("expired lease was not redelivered: %+v", second)
+	}
+	if err := q.Ack(ctx, first); !errors.Is(err, worker.ErrLeaseLost) {
+		t.Fatalf("stale ack = %v, want ErrLeaseLost", err)
+	}
+	if err := q.Ack(ctx, second); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+}
@@ -10,11 +10,29 @@
 )
 
 // Job defines a single periodic task managed by a Scheduler.
+// Set exactly one of Interval, Cron, or Schedule; Schedule takes precedence
+// over Cron, and Cron over Interval.
 type Job struct {
 	// Name identifies the job in health reports and logs.
 	Name string
 	// Interval between consecutive runs.
 	Interval time.Duration
+	// Cron is a 5- or 6-field cron expression; see ParseCron.
+	Cron string
+	// Location is the time zone Cron is evaluated in. Default: time.Local.
+	// A CRON_TZ= prefix in Cron overrides it.
+	Location *time.Location
+	// Schedule supplies custom activation times.
+	Schedule Schedule
+	// Jitter delays each Cron or Schedule run by a random duration in [0, Jitter).
+	Jitter time.Duration
+	// Misfire controls activations missed while a Cron or Schedule run
+	// overran them. Default: MisfireSkip.
+	Misfire MisfirePolicy
+	// Singleton restricts the job to the replica holding the scheduler's
+	// lease; see Scheduler.WithLeaderElection. On other replicas each run is
+	// a no-op, and a running job's context is canceled if leadership is lost.
+	Singleton bool
 	// RunOnStart causes the job to execute once immediately when the scheduler starts,
 	// before entering its periodic loop.
 	RunOnStart bool
@@ -33,24 +51,87 @@ type Job struct {
 //	s := worker.NewScheduler("background-jobs",
 //	    worker.Job{Name: "catalog-refresh", Interval: 6 * time.Hour, RunOnStart: true, Fn: refreshFn},
 //	    worker.Job{Name: "cleanup", Interval: 24 * time.Hour, Fn: cleanupFn},
+//	    worker.Job{Name: "report", Cron: "0 2 * * MON-FRI", Location: berlin, Misfire: worker.MisfireRunOnce, Fn: reportFn},
 //	)
 //	registry.Register(s)
 type Scheduler struct {
 	name    string
 	workers []*TickerWorker
+	leader  *LeaderElector
+	// err is the first invalid job definition, reported by Start.
+	err error
+	// singletons names the jobs that need leader election.
+	singletons []string
 }
 
 // NewScheduler creates a Scheduler with the given name and jobs.
+// An invalid Cron expression is reported by Start.
 func NewScheduler(name string, jobs ...Job) *Scheduler {
-	workers := make([]*TickerWorker, 0, len(jobs))
+	s := &Scheduler{name: name, workers: make([]*TickerWorker, 0, len(jobs))}
 	for _, j := range jobs {
 		var opts []TickerOption
 		if j.RunOnStart {
 			opts = append(opts, WithRunOnStart())
 		}
-		workers = append(workers, NewTickerWorker(j.Name, j.Interval, j.Fn, opts...))
+		if j.Singleton {
+			j.Fn = s.singleton(j.Fn)
+			s.singletons = append(s.singletons, j.Name)
+		}
+		schedule := j.Schedule
+		if schedule == nil && j.Cron != "" {
+			cron, err := ParseCron(j.Cron, j.Location)
+			if err != nil && s.err == nil {
+				s.err = fmt.Errorf("scheduler %s: job %s: %w", name, j.Name, err)
+			}
+			if err == nil {
+				schedule = cron
+			}
+		}
+		if schedule == nil {
+			s.workers = append(s.workers, NewTickerWorker(j.Name, j.Interval, j.Fn, opts...))
+			continue
+		}
+		opts = append(opts, WithJitter(j.Jitter), WithMisfirePolicy(j.Misfire))
+		s.workers = append(s.workers, NewScheduledWorker(j.Name, schedule, j.Fn, opts...))
+	}
+	return s
+}
+
+// WithLeaderElection makes the scheduler campaign for a lease with cfg and
+// run Singleton jobs only while it holds it. The lease key defaults to the
+// scheduler name, so replicas running the same scheduler elect one leader.
+//
+//	s := worker.NewScheduler("background-jobs",
+//	    worker.Job{Name: "cleanup", Interval: time.Hour, Singleton: true, Fn: cleanupFn},
+//	).WithLeaderElection(worker.LeaderConfig{Locker: redis.NewLocker(client, "")})
+func (s *Scheduler) WithLeaderElection(cfg LeaderConfig) *Scheduler {
+	if cfg.Key == "" {
+		cfg.Key = s.name
+	}
+	s.leader = NewLeaderElector(s.name+"-leader", cfg)
+	return s
+}
+
+// Leader returns the scheduler's leader elector, or nil without leader election.
+func (s *Scheduler) Leader() *LeaderElector { return s.leader }
+
+// singleton wraps fn so it only runs on the leader, under a context that is
+// also canceled when leadership is lost.
+func (s *Scheduler) singleton(fn TickerFunc) TickerFunc {
+	return func(ctx context.Context) error {
+		if s.leader == nil {
+			return nil
+		}
+		leaderCtx, ok := s.leader.LeaderContext()
+		if !ok {
+			return nil
+		}
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(leaderCtx, cancel)
+		defer stop()
+		return fn(runCtx)
 	}
-	return &Scheduler{name: name, workers: workers}
 }
 
 // Name returns the scheduler's component name.
@@ -59,12 +140,27 @@ func (s *Scheduler) Name() string { return s.name }
 // Start launches all jobs. If any job fails to start, previously started jobs are stopped
 // and the first error is returned.
 func (s *Scheduler) Start(ctx context.Context) error {
+	if s.err != nil {
+		return s.err
+	}
+	if len(s.singletons) > 0 && s.leader == nil {
+		return fmt.Errorf("scheduler %s: singleton jobs %s need WithLeaderElection", s.name, strings.Join(s.singletons, ", "))
+	}
+	// Campaign first so that singleton jobs with RunOnStart see the outcome.
+	if s.leader != nil {
+		if err := s.leader.Start(ctx); err != nil {
+			return fmt.Errorf("scheduler %s: %w", s.name, err)
+		}
+	}
 	for i, w := range s.workers {
 		if err := w.Start(ctx); err != nil {
 			// Roll back already-started workers.
 			for j := i - 1; j >= 0; j-- {
 				_ = s.workers[j].Stop(ctx)
 			}
+			if s.leader != nil {
+				_ = s.leader.Stop(ctx)
+			}
 			return fmt.Errorf("scheduler %s: failed to start job %s: %w", s.name, w.Name(), err)
 		}
 	}
@@ -79,28 +175,46 @@ func (s *Scheduler) Stop(ctx context.Context) error {
 			firstErr = err
 		}
 	}
+	// Release the lease last so another replica takes over only after the
+	// singleton jobs here have returned.
+	if s.leader != nil {
+		if err := s.leader.Stop(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
 	return firstErr
 }
 
-// Health aggregates health from all jobs. The scheduler is healthy only
-// if every job is healthy. If any job is degraded or unhealthy, the
-// scheduler reports the worst status.
+// Health aggregates health from all jobs and the leader elector, if any. The
+// scheduler is healthy only if every job is healthy. If any job is degraded
+// or unhealthy, or leadership was lost, the scheduler reports the worst
+// status.
 func (s *Scheduler) Health(ctx context.Context) component.Health {
 	if len(s.workers) == 0 {
 		return component.Health{Name: s.name, Status: component.StatusHealthy, Message: "no jobs"}
 	}
 	worst := component.StatusHealthy
 	var msgs []string
+	checks := make([]component.Component, 0, len(s.workers)+1)
 	for _, w := range s.workers {
-		h := w.Health(ctx)
+		checks = append(checks, w)
+	}
+	if s.leader != nil {
+		checks = append(checks, s.leader)
+	}
+	for _, c := range checks {
+		h := c.Health(ctx)
 		if statusSeverity(h.Status) > statusSeverity(worst) {
 			worst = h.Status
 		}
 		if h.Status != component.StatusHealthy {
-			msgs = append(msgs, fmt.Sprintf("%s: %s", w.Name(), h.Message))
+			msgs = append(msgs, fmt.Sprintf("%s: %s", c.Name(), h.Message))
 		}
 	}
 	msg := fmt.Sprintf("%d jobs running", len(s.workers))
+	if s.leader != nil {
+		msg += " as " + s.leader.Health(ctx).Message
+	}
 	if len(msgs) > 0 {
 		msg = strings.Join(msgs, "; ")
 	}
@@ -141,3 +141,111 @@ func TestTickerWorkerWithOnError(t *testing.T) {
 		t.Errorf("OnError called %d times, want >= 2", errCount.Load())
 	}
 }
+
+// listSchedule activates at fixed times.
+type listSchedule []time.Time
+
+func (l listSchedule) Next(after time.Time) time.Time {
+	for _, t := range l {
+		if t.After(after) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func TestScheduledWorkerMisfirePolicies(t *testing.T) {
+	t.Parallel()
+
+	// The first run overruns the next three activations.
+	for _, tc := range []struct {
+		policy worker.MisfirePolicy
+		early  int32
+	}{
+		{worker.MisfireSkip, 1},
+		{worker.MisfireRunOnce, 2},
+		{worker.MisfireCatchUp, 4},
+	} {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			t.Parallel()
+
+			start := time.Now()
+			schedule := listSchedule{
+				start.Add(20 * time.Millisecond),
+				start.Add(40 * time.Millisecond),
+				start.Add(50 * time.Millisecond),
+				start.Add(60 * time.Millisecond),
+				start.Add(300 * time.Millisecond),
+			}
+			var calls atomic.Int32
+			w := worker.NewScheduledWorker("misfire", schedule, func(context.Context) error {
+				if calls.Add(1) == 1 {
+					time.Sleep(100 * time.Millisecond)
+				}
+				return nil
+			}, worker.WithMisfirePolicy(tc.policy))
+
+			if err := w.Start(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(200 * time.Millisecond)
+			if got := calls.Load(); got != tc.early {
+				t.Errorf("runs before the last activation = %d, want %d", got, tc.early)
+			}
+			if next := w.NextRun(); !next.Equal(schedule[4]) {
+				t.Errorf("NextRun = %v, want %v", next, schedule[4])
+			}
+			time.Sleep(150 * time.Millisecond)
+			if got := calls.Load(); got != tc.early+1 {
+				t.Errorf("runs after the last activation = %d, want %d", got, tc.early+1)
+			}
+			if !w.NextRun().IsZero() {
+				t.Errorf("NextRun after the schedule ended = %v, want zero", w.NextRun())
+			}
+			_ = w.Stop(context.Background())
+		})
+	}
+}
+
+func TestSchedulerCronJobs(t *testing.T) {
+	t.Parallel()
+
+	var count atomic.Int32
+	s := worker.NewScheduler("cron",
+		worker.Job{Name: "every-second", Cron: "* * * * * *", Location: time.UTC, Jitter: time.Millisecond, Fn: func(context.Context) error {
+			count.Add(1)
+			return nil
+		}},
+	)
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Stop(context.Background()) }()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for s.Workers()[0].NextRun().IsZero() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if next := s.Workers()[0].NextRun(); next.IsZero() || time.Until(next) > time.Second {
+		t.Fatalf("NextRun = %v, want within a second", next)
+	}
+	for count.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if count.Load() == 0 {
+		t.Fatal("cron job never ran")
+	}
+	if h := s.Health(context.Background()); h.Status != component.StatusHealthy {
+		t.Fatalf("health = %s (%s)", h.Status, h.Message)
+	}
+}
+
+func TestSchedulerRejectsInvalidCron(t *testing.T) {
+	t.Parallel()
+
+	s := worker.NewScheduler("bad", worker.Job{Name: "typo", Cron: "0 25 * * *", Fn: func(context.Context) error { return nil }})
+	if err := s.Start(context.Background()); err == nil {
+		_ = s.Stop(context.Background())
+		t.Fatal("Start accepted an invalid cron expression")
+	}
+}
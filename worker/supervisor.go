@@ -42,6 +42,8 @@ func (c SupervisorConfig) withDefaults() SupervisorConfig {
 // and can mark a worker as unhealthy when it exceeds MaxRestarts.
 // This avoids the complexity of goroutine replacement while still providing supervision visibility
 // and policy enforcement.
+// In an autoscaled pool the autoscaler retires unhealthy workers and starts
+// replacements, whose slots begin with a clean failure history.
 type supervisor[I, O any] struct {
 	pool *Pool[I, O]
 	cfg  SupervisorConfig
@@ -52,14 +54,14 @@ type supervisor[I, O any] struct {
 }
 
 func newSupervisor[I, O any](pool *Pool[I, O], cfg SupervisorConfig) *supervisor[I, O] {
-	alive := make([]bool, pool.cfg.Size)
+	alive := make([]bool, len(pool.stats))
 	for i := range alive {
 		alive[i] = true
 	}
 	return &supervisor[I, O]{
 		pool:   pool,
 		cfg:    cfg.withDefaults(),
-		panics: make([]int, pool.cfg.Size),
+		panics: make([]int, len(pool.stats)),
 		alive:  alive,
 	}
 }
@@ -130,6 +132,28 @@ func (s *supervisor[I, O]) healthCheck() {
 	}
 }
 
+// resetWorker clears the failure history of a worker slot so a replacement
+// worker started there begins healthy.
+func (s *supervisor[I, O]) resetWorker(workerIdx int) {
+	s.mu.Lock()
+	s.panics[workerIdx] = 0
+	s.alive[workerIdx] = true
+	s.mu.Unlock()
+}
+
+// unhealthy returns the number of workers marked unhealthy.
+func (s *supervisor[I, O]) unhealthy() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int
+	for i := range s.alive {
+		if !s.alive[i] || (s.cfg.RestartPolicy == RestartNever && s.panics[i] > 0) {
+			n++
+		}
+	}
+	return n
+}
+
 // backoff returns the exponential backoff duration for a worker's Nth panic.
 // Returns 0 if no panics have occurred. Caps at 30 seconds.
 func (s *supervisor[I, O]) backoff(workerIdx int) time.Duration {
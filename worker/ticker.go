@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"math/rand/v2"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -28,7 +29,23 @@ func WithOnError(fn func(error)) TickerOption {
 	return func(w *TickerWorker) { w.onError = fn }
 }
 
-// TickerWorker is a Component that runs a function on a fixed interval.
+// WithJitter delays each scheduled run by a random duration in [0, d) so that
+// many instances sharing a schedule do not fire at the same instant. It only
+// applies to workers created with NewScheduledWorker.
+func WithJitter(d time.Duration) TickerOption {
+	return func(w *TickerWorker) { w.jitter = d }
+}
+
+// WithMisfirePolicy sets how a scheduled worker handles activations missed
+// while a run overran them or the process was suspended. Default: MisfireSkip.
+// It only applies to workers created with NewScheduledWorker.
+func WithMisfirePolicy(p MisfirePolicy) TickerOption {
+	return func(w *TickerWorker) { w.misfire = p }
+}
+
+// TickerWorker is a Component that runs a function on a fixed interval or,
+// when created with NewScheduledWorker, at the activation times of a Schedule.
+// Runs never overlap: the next activation is computed after a run returns.
 //
 // Start launches a background goroutine; Stop signals it and waits for a clean exit.
 // Health reports the last-run time and any recent errors.
@@ -45,12 +62,16 @@ type TickerWorker struct {
 	fn         TickerFunc
 	runOnStart bool
 	onError    func(error)
+	schedule   Schedule
+	jitter     time.Duration
+	misfire    MisfirePolicy
 
 	cancel  context.CancelFunc
 	done    chan struct{}
 	running atomic.Bool
 
 	mu        sync.RWMutex
+	nextRun   time.Time
 	lastRun   time.Time
 	lastErr   error
 	runCount  uint64
@@ -70,6 +91,21 @@ func NewTickerWorker(name string, interval time.Duration, fn TickerFunc, opts ..
 	return w
 }
 
+// NewScheduledWorker creates a TickerWorker that runs fn at each activation
+// time of schedule, such as a CronSchedule. The worker stops scheduling runs
+// once the schedule returns the zero time.
+//
+// Example:
+//
+//	nightly := worker.MustParseCron("CRON_TZ=Europe/Berlin 0 2 * * MON-FRI", nil)
+//	w := worker.NewScheduledWorker("report", nightly, reportFn,
+//	    worker.WithJitter(time.Minute), worker.WithMisfirePolicy(worker.MisfireRunOnce))
+func NewScheduledWorker(name string, schedule Schedule, fn TickerFunc, opts ...TickerOption) *TickerWorker {
+	w := NewTickerWorker(name, 0, fn, opts...)
+	w.schedule = schedule
+	return w
+}
+
 // Name returns the component name.
 func (w *TickerWorker) Name() string { return w.name }
 
@@ -139,6 +175,14 @@ func (w *TickerWorker) RunCount() uint64 {
 	return w.runCount
 }
 
+// NextRun returns when the worker is next due to run, or the zero time if it
+// is not running or has no further activations.
+func (w *TickerWorker) NextRun() time.Time {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.nextRun
+}
+
 // FailCount returns the total number of failed ticks.
 func (w *TickerWorker) FailCount() uint64 {
 	w.mu.RLock()
@@ -149,23 +193,86 @@ func (w *TickerWorker) FailCount() uint64 {
 func (w *TickerWorker) loop(ctx context.Context) {
 	defer close(w.done)
 
+	defer w.setNextRun(time.Time{})
+
 	if w.runOnStart {
 		w.tick(ctx)
 	}
 
+	if w.schedule != nil {
+		w.scheduleLoop(ctx)
+		return
+	}
+
 	ticker := time.NewTicker(w.interval)
 	defer ticker.Stop()
 
+	w.setNextRun(time.Now().Add(w.interval))
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			w.tick(ctx)
+			w.setNextRun(time.Now().Add(w.interval))
 		}
 	}
 }
 
+func (w *TickerWorker) scheduleLoop(ctx context.Context) {
+	next := w.schedule.Next(time.Now())
+	for !next.IsZero() {
+		w.setNextRun(next)
+		delay := time.Until(next)
+		if w.jitter > 0 {
+			delay += rand.N(w.jitter)
+		}
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+		w.tick(ctx)
+		next = w.following(next, time.Now())
+	}
+}
+
+// following returns the activation to wait for after the one due at due,
+// applying the misfire policy to activations that passed before now.
+func (w *TickerWorker) following(due, now time.Time) time.Time {
+	next := w.schedule.Next(due)
+	if next.IsZero() || next.After(now) {
+		return next
+	}
+	switch w.misfire {
+	case MisfireCatchUp:
+		return next
+	case MisfireRunOnce:
+		// The latest missed activation stands in for all of them.
+		for {
+			after := w.schedule.Next(next)
+			if after.IsZero() || after.After(now) {
+				return next
+			}
+			next = after
+		}
+	default:
+		return w.schedule.Next(now)
+	}
+}
+
+func (w *TickerWorker) setNextRun(t time.Time) {
+	w.mu.Lock()
+	w.nextRun = t
+	w.mu.Unlock()
+}
+
 func (w *TickerWorker) tick(ctx context.Context) {
 	err := w.fn(ctx)
 	w.mu.Lock()